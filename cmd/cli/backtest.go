@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HatiCode/kedastral/cmd/forecaster/config"
+	factory "github.com/HatiCode/kedastral/cmd/forecaster/models"
+	"github.com/HatiCode/kedastral/pkg/adapters"
+	"github.com/HatiCode/kedastral/pkg/backtest"
+	"github.com/HatiCode/kedastral/pkg/capacity"
+	"github.com/HatiCode/kedastral/pkg/features"
+	"github.com/HatiCode/kedastral/pkg/models"
+)
+
+// namedPolicy is a capacity policy given on the command line.
+type namedPolicy struct {
+	name   string
+	policy capacity.Policy
+}
+
+// runBacktest implements "kedastral backtest". It accepts the forecaster
+// flags, which configure the models and the default policy, plus flags
+// selecting the history, the scenarios and the output.
+func runBacktest(args []string) error {
+	fs := flag.NewFlagSet("backtest", flag.ExitOnError)
+	cfg := config.RegisterFlags(fs)
+
+	input := fs.String("input", "", "CSV file with ts and value columns to replay instead of querying Prometheus")
+	startFlag := fs.String("start", "", "Start of the replayed period, RFC3339 (default: end - duration)")
+	endFlag := fs.String("end", "", "End of the replayed period, RFC3339 (default: now, or the last row of --input)")
+	duration := fs.Duration("duration", 7*24*time.Hour, "Length of the replayed period when --start is not set")
+	modelList := fs.String("models", "", "Models to compare, comma-separated (default: --model)")
+	var policySpecs []string
+	fs.Func("policy", "Policy to compare as key=value pairs overriding the policy flags, e.g. name=p90,plan-quantile=0.9,headroom=1.1 (repeatable)", func(s string) error {
+		policySpecs = append(policySpecs, s)
+		return nil
+	})
+	startupDelay := fs.Duration("startup-delay", 0, "Time for planned replicas to become ready")
+	scalerLead := fs.Duration("scaler-lead-time", 0, "Lead time the scaler uses to pick the planned step")
+	output := fs.String("output", "table", "Output format: table, json or csv")
+	fs.StringVar(output, "o", "table", "Shorthand for --output")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	format, err := backtest.ParseFormat(*output)
+	if err != nil {
+		return err
+	}
+
+	base := capacity.Policy{
+		TargetPerPod:          cfg.TargetPerPod,
+		Headroom:              cfg.Headroom,
+		LeadTimeSeconds:       int(cfg.LeadTime.Seconds()),
+		MinReplicas:           cfg.MinReplicas,
+		MaxReplicas:           cfg.MaxReplicas,
+		UpMaxFactorPerStep:    cfg.UpMaxFactorPerStep,
+		DownMaxPercentPerStep: cfg.DownMaxPercentPerStep,
+		Quantile:              cfg.PlanQuantile,
+		BurstProbability:      cfg.BurstProbability,
+
+		ScaleDownStabilizationSeconds: int(cfg.DownStabilization.Seconds()),
+		ScaleUpStabilizationSeconds:   int(cfg.UpStabilization.Seconds()),
+		ScaleDownCooldownSeconds:      int(cfg.DownCooldown.Seconds()),
+		ScaleUpCooldownSeconds:        int(cfg.UpCooldown.Seconds()),
+	}
+	if cfg.CapacityCurve != "" {
+		if base.Curve, err = capacity.ParseCurve(cfg.CapacityCurve); err != nil {
+			return fmt.Errorf("--capacity-curve: %w", err)
+		}
+	}
+	if cfg.OverridesFile != "" {
+		if base.Overrides, err = capacity.LoadOverrides(cfg.OverridesFile); err != nil {
+			return err
+		}
+	}
+	policies := []namedPolicy{{name: "default", policy: base}}
+	if len(policySpecs) > 0 {
+		policies = policies[:0]
+		for i, spec := range policySpecs {
+			p, err := parsePolicy(spec, base, fmt.Sprintf("policy%d", i+1))
+			if err != nil {
+				return fmt.Errorf("--policy %q: %w", spec, err)
+			}
+			policies = append(policies, p)
+		}
+	}
+
+	names := []string{cfg.Model}
+	if *modelList != "" {
+		names = strings.Split(*modelList, ",")
+	}
+
+	var adapter adapters.RangeAdapter
+	if *input != "" {
+		adapter = &adapters.CSVAdapter{Path: *input}
+	} else {
+		if cfg.PromQuery == "" {
+			return errors.New("--prom-query or --input is required")
+		}
+		adapter = &adapters.PrometheusAdapter{
+			ServerURL:   cfg.PromURL,
+			Query:       cfg.PromQuery,
+			StepSeconds: int(cfg.Step.Seconds()),
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	start, end, err := period(ctx, adapter, *startFlag, *endFlag, *duration, cfg.Step)
+	if err != nil {
+		return err
+	}
+
+	logger := newLogger(cfg.LogLevel)
+	logger.Info("loading history", "adapter", adapter.Name(), "start", start.Add(-cfg.Window), "end", end)
+	frame, err := backtest.Load(ctx, adapter, features.NewBuilder(), start.Add(-cfg.Window), end)
+	if err != nil {
+		return err
+	}
+	if len(frame.Rows) == 0 {
+		return errors.New("no history in the replayed period")
+	}
+
+	var scenarios []backtest.Scenario
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		for _, p := range policies {
+			modelCfg := *cfg
+			modelCfg.Model = name
+			scenarios = append(scenarios, backtest.Scenario{
+				Name:   scenarioName(name, p.name, len(names), len(policies)),
+				Model:  factory.New(&modelCfg, logger),
+				Policy: p.policy,
+			})
+		}
+	}
+
+	logger.Info("running backtest", "rows", len(frame.Rows), "scenarios", len(scenarios), "interval", cfg.Interval)
+	results, err := backtest.Run(ctx, frame, backtest.Options{
+		Start:             start,
+		End:               end,
+		Step:              cfg.Step,
+		Horizon:           cfg.Horizon,
+		Window:            cfg.Window,
+		Interval:          cfg.Interval,
+		FullTrainInterval: cfg.FullTrainInterval,
+		ChangePoints:      factory.ChangePoints(cfg),
+		ScalerLeadTime:    *scalerLead,
+		StartupDelay:      *startupDelay,
+	}, scenarios)
+	if err != nil {
+		return err
+	}
+	return backtest.Write(os.Stdout, format, results)
+}
+
+// period resolves the replayed period from the flags. Without --end, a CSV
+// recording ends at its last row and Prometheus at the current time.
+func period(ctx context.Context, adapter adapters.RangeAdapter, startFlag, endFlag string, duration, step time.Duration) (start, end time.Time, err error) {
+	switch {
+	case endFlag != "":
+		if end, err = time.Parse(time.RFC3339, endFlag); err != nil {
+			return start, end, fmt.Errorf("--end: %w", err)
+		}
+	case adapter.Name() == "prometheus":
+		end = time.Now().UTC().Truncate(step)
+	default:
+		df, err := adapter.Collect(ctx, 0)
+		if err != nil {
+			return start, end, err
+		}
+		if len(df.Rows) == 0 {
+			return start, end, errors.New("input has no rows")
+		}
+		if end, err = time.Parse(time.RFC3339, df.Rows[len(df.Rows)-1]["ts"].(string)); err != nil {
+			return start, end, err
+		}
+	}
+
+	start = end.Add(-duration)
+	if startFlag != "" {
+		if start, err = time.Parse(time.RFC3339, startFlag); err != nil {
+			return start, end, fmt.Errorf("--start: %w", err)
+		}
+	}
+	if !end.After(start) {
+		return start, end, errors.New("the replayed period is empty")
+	}
+	return start, end, nil
+}
+
+// parsePolicy applies comma-separated key=value overrides to base. Keys are
+// the forecaster's policy flag names; "name" labels the policy in results.
+func parsePolicy(spec string, base capacity.Policy, defaultName string) (namedPolicy, error) {
+	p := namedPolicy{name: defaultName, policy: base}
+	for _, pair := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return p, fmt.Errorf("%q is not key=value", pair)
+		}
+
+		var err error
+		switch key {
+		case "name":
+			p.name = value
+		case "target-per-pod":
+			p.policy.TargetPerPod, err = strconv.ParseFloat(value, 64)
+		case "headroom":
+			p.policy.Headroom, err = strconv.ParseFloat(value, 64)
+		case "lead-time":
+			p.policy.LeadTimeSeconds, err = durationSeconds(value)
+		case "min":
+			p.policy.MinReplicas, err = strconv.Atoi(value)
+		case "max":
+			p.policy.MaxReplicas, err = strconv.Atoi(value)
+		case "up-max-factor":
+			p.policy.UpMaxFactorPerStep, err = strconv.ParseFloat(value, 64)
+		case "down-max-percent":
+			p.policy.DownMaxPercentPerStep, err = strconv.Atoi(value)
+		case "prewarm-steps":
+			p.policy.PrewarmWindowSteps, err = strconv.Atoi(value)
+		case "scale-down-stabilization":
+			p.policy.ScaleDownStabilizationSeconds, err = durationSeconds(value)
+		case "scale-up-stabilization":
+			p.policy.ScaleUpStabilizationSeconds, err = durationSeconds(value)
+		case "scale-down-cooldown":
+			p.policy.ScaleDownCooldownSeconds, err = durationSeconds(value)
+		case "scale-up-cooldown":
+			p.policy.ScaleUpCooldownSeconds, err = durationSeconds(value)
+		case "plan-quantile":
+			p.policy.Quantile, err = strconv.ParseFloat(value, 64)
+			if err == nil && p.policy.Quantile != 0 && !models.IsQuantileLevel(p.policy.Quantile) {
+				err = fmt.Errorf("must be 0 or one of %v", models.QuantileLevels)
+			}
+		case "burst-probability":
+			p.policy.BurstProbability, err = strconv.ParseFloat(value, 64)
+			if err == nil && (p.policy.BurstProbability < 0 || p.policy.BurstProbability > 1) {
+				err = errors.New("must be between 0 and 1")
+			}
+		default:
+			return p, fmt.Errorf("unknown policy key %q", key)
+		}
+		if err != nil {
+			return p, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return p, nil
+}
+
+// scenarioName labels a model and policy combination by whatever varies.
+func scenarioName(model, policy string, models, policies int) string {
+	switch {
+	case policies == 1:
+		return model
+	case models == 1:
+		return policy
+	default:
+		return model + "/" + policy
+	}
+}
+
+// newLogger logs to stderr so that results on stdout can be piped.
+func newLogger(level string) *slog.Logger {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		l = slog.LevelInfo
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: l}))
+}
+
+// durationSeconds parses a duration such as "5m" into whole seconds.
+func durationSeconds(value string) (int, error) {
+	d, err := time.ParseDuration(value)
+	return int(d.Seconds()), err
+}
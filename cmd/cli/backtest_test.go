@@ -0,0 +1,64 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/HatiCode/kedastral/pkg/capacity"
+)
+
+func TestParsePolicy(t *testing.T) {
+	base := capacity.Policy{TargetPerPod: 100, Headroom: 1.2, MinReplicas: 1, MaxReplicas: 10}
+
+	p, err := parsePolicy("name=p90,plan-quantile=0.9,headroom=1.1,lead-time=2m,max=20", base, "policy1")
+	if err != nil {
+		t.Fatalf("parsePolicy error: %v", err)
+	}
+	want := base
+	want.Quantile = 0.9
+	want.Headroom = 1.1
+	want.LeadTimeSeconds = 120
+	want.MaxReplicas = 20
+	if p.name != "p90" || !reflect.DeepEqual(p.policy, want) {
+		t.Errorf("parsePolicy = %q %+v, want p90 %+v", p.name, p.policy, want)
+	}
+
+	p, err = parsePolicy("scale-down-stabilization=5m,scale-up-cooldown=90s", base, "stable")
+	if err != nil {
+		t.Fatalf("parsePolicy error: %v", err)
+	}
+	if p.policy.ScaleDownStabilizationSeconds != 300 || p.policy.ScaleUpCooldownSeconds != 90 {
+		t.Errorf("parsePolicy = %+v, want 300s down stabilization and 90s up cooldown", p.policy)
+	}
+
+	p, err = parsePolicy("min=0", base, "policy2")
+	if err != nil {
+		t.Fatalf("parsePolicy error: %v", err)
+	}
+	if p.name != "policy2" || p.policy.MinReplicas != 0 {
+		t.Errorf("parsePolicy = %q %+v, want default name and min 0", p.name, p.policy)
+	}
+
+	for _, spec := range []string{"headroom", "colour=red", "min=some", "plan-quantile=0.42", "burst-probability=2", "scale-down-cooldown=soon"} {
+		if _, err := parsePolicy(spec, base, "policy"); err == nil {
+			t.Errorf("parsePolicy(%q): expected error", spec)
+		}
+	}
+}
+
+func TestScenarioName(t *testing.T) {
+	tests := []struct {
+		models, policies int
+		want             string
+	}{
+		{1, 1, "arima"},
+		{3, 1, "arima"},
+		{1, 2, "p90"},
+		{2, 2, "arima/p90"},
+	}
+	for _, tt := range tests {
+		if got := scenarioName("arima", "p90", tt.models, tt.policies); got != tt.want {
+			t.Errorf("scenarioName(%d models, %d policies) = %q, want %q", tt.models, tt.policies, got, tt.want)
+		}
+	}
+}
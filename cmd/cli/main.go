@@ -0,0 +1,62 @@
+// Command kedastral is the Kedastral command-line tool.
+//
+// Subcommands:
+//
+//	backtest - Replay history through models and capacity policies and
+//	           compare forecast error, provisioning and scaling churn
+//
+// Usage:
+//
+//	kedastral backtest \
+//	  -prom-url=http://prometheus:9090 \
+//	  -prom-query='sum(rate(http_requests_total[1m]))' \
+//	  -duration=336h \
+//	  -models=baseline,arima \
+//	  -policy=name=p50 -policy=name=p90,plan-quantile=0.9
+//
+// Run "kedastral <command> -h" for the flags of a command.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// version is set via ldflags at build time
+var version = "dev"
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "backtest":
+		err = runBacktest(os.Args[2:])
+	case "version":
+		fmt.Println(version)
+	case "help", "-h", "--help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown command %q\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprint(os.Stderr, `Usage: kedastral <command> [flags]
+
+Commands:
+  backtest   Replay history through models and policies and compare the results
+  version    Print the version
+
+Run "kedastral <command> -h" for the flags of a command.
+`)
+}
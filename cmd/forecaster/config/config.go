@@ -28,7 +28,10 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
+
+	"github.com/HatiCode/kedastral/pkg/models"
 )
 
 // Config holds all forecaster configuration.
@@ -40,15 +43,28 @@ type Config struct {
 	Step                  time.Duration
 	LeadTime              time.Duration
 	TargetPerPod          float64
+	CapacityCurve         string
 	Headroom              float64
 	MinReplicas           int
 	MaxReplicas           int
 	UpMaxFactorPerStep    float64
 	DownMaxPercentPerStep int
+	DownStabilization     time.Duration
+	UpStabilization       time.Duration
+	DownCooldown          time.Duration
+	UpCooldown            time.Duration
+	OverridesFile         string
+	PlanQuantile          float64
+	BurstProbability      float64
 	PromURL               string
 	PromQuery             string
 	Interval              time.Duration
 	Window                time.Duration
+	FullTrainInterval     time.Duration
+	ChangePointMinSegment time.Duration
+	ChangePointPenalty    float64
+	HierarchyFile         string
+	ReconcileMethod       string
 	LogFormat             string
 	LogLevel              string
 	Storage               string
@@ -57,76 +73,209 @@ type Config struct {
 	RedisDB               int
 	RedisTTL              time.Duration
 	Model                 string
+	BaselineWeekBackoff   float64
+	BaselineMaxBlend      float64
+	BaselineSpikeWeight   float64
+	BaselineRiseWeight    float64
+	BaselineDipWeight     float64
+	BaselineNeutralWeight float64
 	ARIMA_P               int
 	ARIMA_D               int
 	ARIMA_Q               int
+	ARIMA_SP              int
+	ARIMA_SD              int
+	ARIMA_SQ              int
+	ARIMA_Season          time.Duration
+	ARIMA_Regressors      string
+	ARIMA_Events          string
+	ARIMA_OrderSearch     time.Duration
+	ProphetChangepoints   int
+	ProphetDailyOrder     int
+	ProphetWeeklyOrder    int
+	ProphetEvents         string
+	RegressionLags        int
+	RegressionFeatures    string
+	RegressionAlpha       float64
+	RegressionL1Ratio     float64
+	RegressionStrategy    string
+	RegressionEvents      string
+	NaiveMethod           string
+	NaivePeriods          string
+	NaiveSeasons          int
+	NaiveLevelWindow      time.Duration
+	StateSpaceTrend       bool
+	StateSpaceSeason      time.Duration
+	StateSpaceHarmonics   int
+	IntermittentMethod    string
+	IntermittentAlpha     float64
+	IntermittentBeta      float64
+	IntermittentThreshold float64
+	RemoteURL             string
+	RemoteTimeout         time.Duration
+	RemoteFallback        string
+	WASMModule            string
+	WASMMemoryLimit       int
+	WASMTimeout           time.Duration
+	EnsembleMembers       string
+	EnsembleMethod        string
+	EnsembleWindow        int
+	AutoCandidates        string
+	AutoMetric            string
+	AutoQuantile          float64
+	AutoFolds             int
+	AutoReselect          time.Duration
 }
 
 // ParseFlags parses command-line flags and environment variables into a Config.
 // Exits with status 1 if required flags (workload, metric, prom-query) are missing.
 // Environment variables are used as fallbacks when flags are not provided.
 func ParseFlags() *Config {
+	cfg := RegisterFlags(flag.CommandLine)
+
+	flag.Parse()
+
+	if cfg.Workload == "" {
+		fmt.Fprintln(os.Stderr, "Error: --workload is required")
+		os.Exit(1)
+	}
+	if cfg.Metric == "" {
+		fmt.Fprintln(os.Stderr, "Error: --metric is required")
+		os.Exit(1)
+	}
+	if cfg.PromQuery == "" {
+		fmt.Fprintln(os.Stderr, "Error: --prom-query is required")
+		os.Exit(1)
+	}
+	if cfg.PlanQuantile != 0 && !models.IsQuantileLevel(cfg.PlanQuantile) {
+		fmt.Fprintf(os.Stderr, "Error: --plan-quantile must be 0 or one of %v\n", models.QuantileLevels)
+		os.Exit(1)
+	}
+	if cfg.BurstProbability < 0 || cfg.BurstProbability > 1 {
+		fmt.Fprintln(os.Stderr, "Error: --burst-probability must be between 0 and 1")
+		os.Exit(1)
+	}
+
+	return cfg
+}
+
+// RegisterFlags defines the forecaster flags on fs, with environment variables
+// and defaults as fallbacks, and returns the Config they are parsed into. It
+// lets other commands, such as the backtest CLI, accept the same model and
+// policy flags. The values are only set once fs is parsed, and are not
+// validated.
+func RegisterFlags(fs *flag.FlagSet) *Config {
 	cfg := &Config{}
 
 	// Server
-	flag.StringVar(&cfg.Listen, "listen", getEnv("LISTEN", ":8081"), "HTTP listen address")
+	fs.StringVar(&cfg.Listen, "listen", getEnv("LISTEN", ":8081"), "HTTP listen address")
 
 	// Workload
-	flag.StringVar(&cfg.Workload, "workload", getEnv("WORKLOAD", ""), "Workload name (required)")
-	flag.StringVar(&cfg.Metric, "metric", getEnv("METRIC", ""), "Metric name (required)")
+	fs.StringVar(&cfg.Workload, "workload", getEnv("WORKLOAD", ""), "Workload name (required)")
+	fs.StringVar(&cfg.Metric, "metric", getEnv("METRIC", ""), "Metric name (required)")
 
 	// Forecast parameters
-	flag.DurationVar(&cfg.Horizon, "horizon", getEnvDuration("HORIZON", 30*time.Minute), "Forecast horizon")
-	flag.DurationVar(&cfg.Step, "step", getEnvDuration("STEP", 1*time.Minute), "Forecast step size")
-	flag.DurationVar(&cfg.LeadTime, "lead-time", getEnvDuration("LEAD_TIME", 5*time.Minute), "Lead time for pre-scaling")
+	fs.DurationVar(&cfg.Horizon, "horizon", getEnvDuration("HORIZON", 30*time.Minute), "Forecast horizon")
+	fs.DurationVar(&cfg.Step, "step", getEnvDuration("STEP", 1*time.Minute), "Forecast step size")
+	fs.DurationVar(&cfg.LeadTime, "lead-time", getEnvDuration("LEAD_TIME", 5*time.Minute), "Lead time for pre-scaling")
 
 	// Capacity policy
-	flag.Float64Var(&cfg.TargetPerPod, "target-per-pod", getEnvFloat("TARGET_PER_POD", 100.0), "Target metric value per pod")
-	flag.Float64Var(&cfg.Headroom, "headroom", getEnvFloat("HEADROOM", 1.2), "Headroom multiplier")
-	flag.IntVar(&cfg.MinReplicas, "min", getEnvInt("MIN_REPLICAS", 1), "Minimum replicas")
-	flag.IntVar(&cfg.MaxReplicas, "max", getEnvInt("MAX_REPLICAS", 100), "Maximum replicas")
-	flag.Float64Var(&cfg.UpMaxFactorPerStep, "up-max-factor", getEnvFloat("UP_MAX_FACTOR", 2.0), "Max scale-up factor per step")
-	flag.IntVar(&cfg.DownMaxPercentPerStep, "down-max-percent", getEnvInt("DOWN_MAX_PERCENT", 50), "Max scale-down percent per step")
+	fs.Float64Var(&cfg.TargetPerPod, "target-per-pod", getEnvFloat("TARGET_PER_POD", 100.0), "Target metric value per pod")
+	fs.StringVar(&cfg.CapacityCurve, "capacity-curve", getEnv("CAPACITY_CURVE", ""), "Nonlinear capacity replacing --target-per-pod: table:replicas=load,... or usl:lambda,sigma,kappa (empty=linear)")
+	fs.Float64Var(&cfg.Headroom, "headroom", getEnvFloat("HEADROOM", 1.2), "Headroom multiplier")
+	fs.IntVar(&cfg.MinReplicas, "min", getEnvInt("MIN_REPLICAS", 1), "Minimum replicas")
+	fs.IntVar(&cfg.MaxReplicas, "max", getEnvInt("MAX_REPLICAS", 100), "Maximum replicas")
+	fs.Float64Var(&cfg.UpMaxFactorPerStep, "up-max-factor", getEnvFloat("UP_MAX_FACTOR", 2.0), "Max scale-up factor per step")
+	fs.IntVar(&cfg.DownMaxPercentPerStep, "down-max-percent", getEnvInt("DOWN_MAX_PERCENT", 50), "Max scale-down percent per step")
+	fs.DurationVar(&cfg.DownStabilization, "scale-down-stabilization", getEnvDuration("SCALE_DOWN_STABILIZATION", 0), "Scale down only to the highest replica recommendation of this trailing window (0=off)")
+	fs.DurationVar(&cfg.UpStabilization, "scale-up-stabilization", getEnvDuration("SCALE_UP_STABILIZATION", 0), "Scale up only to the lowest replica recommendation of this trailing window (0=off)")
+	fs.DurationVar(&cfg.DownCooldown, "scale-down-cooldown", getEnvDuration("SCALE_DOWN_COOLDOWN", 0), "Minimum time after a replica change before scaling down (0=off)")
+	fs.DurationVar(&cfg.UpCooldown, "scale-up-cooldown", getEnvDuration("SCALE_UP_COOLDOWN", 0), "Minimum time after a replica change before scaling up (0=off)")
+	fs.StringVar(&cfg.OverridesFile, "overrides-file", getEnv("OVERRIDES_FILE", ""), "JSON file of scheduled replica floors, caps, fixed counts and headroom (empty=off)")
+	fs.Float64Var(&cfg.PlanQuantile, "plan-quantile", getEnvFloat("PLAN_QUANTILE", 0), "Forecast quantile to plan capacity against, e.g. 0.9 (0=point forecast)")
+	fs.Float64Var(&cfg.BurstProbability, "burst-probability", getEnvFloat("BURST_PROBABILITY", 0), "Burst probability at which intermittent-demand forecasts pre-warm for the full burst; below it the workload may stay at min replicas (0=off)")
 
 	// Prometheus
-	flag.StringVar(&cfg.PromURL, "prom-url", getEnv("PROM_URL", "http://localhost:9090"), "Prometheus URL")
-	flag.StringVar(&cfg.PromQuery, "prom-query", getEnv("PROM_QUERY", ""), "Prometheus query (required)")
+	fs.StringVar(&cfg.PromURL, "prom-url", getEnv("PROM_URL", "http://localhost:9090"), "Prometheus URL")
+	fs.StringVar(&cfg.PromQuery, "prom-query", getEnv("PROM_QUERY", ""), "Prometheus query (required)")
 
 	// Timing
-	flag.DurationVar(&cfg.Interval, "interval", getEnvDuration("INTERVAL", 30*time.Second), "Forecast interval")
-	flag.DurationVar(&cfg.Window, "window", getEnvDuration("WINDOW", 30*time.Minute), "Historical window")
+	fs.DurationVar(&cfg.Interval, "interval", getEnvDuration("INTERVAL", 30*time.Second), "Forecast interval")
+	fs.DurationVar(&cfg.Window, "window", getEnvDuration("WINDOW", 30*time.Minute), "Historical window")
+	fs.DurationVar(&cfg.FullTrainInterval, "full-train-interval", getEnvDuration("FULL_TRAIN_INTERVAL", time.Hour), "How often to retrain on the full window; incremental models are updated with new rows in between (0=always retrain)")
+	fs.DurationVar(&cfg.ChangePointMinSegment, "changepoint-min-segment", getEnvDuration("CHANGEPOINT_MIN_SEGMENT", 0), "Shortest regime kept by change-point detection; training drops history before the latest level or variance shift (0=off)")
+	fs.Float64Var(&cfg.ChangePointPenalty, "changepoint-penalty", getEnvFloat("CHANGEPOINT_PENALTY", 5), "Change-point detection penalty in units of log(rows); higher detects fewer shifts")
+
+	// Hierarchical reconciliation
+	fs.StringVar(&cfg.HierarchyFile, "hierarchy-file", getEnv("HIERARCHY_FILE", ""), "JSON file declaring series below the workload total whose forecasts are reconciled with it (empty=off)")
+	fs.StringVar(&cfg.ReconcileMethod, "reconcile-method", getEnv("RECONCILE_METHOD", "mint"), "Reconciliation method: bottom-up, top-down, ols or mint")
 
 	// Logging
-	flag.StringVar(&cfg.LogFormat, "log-format", getEnv("LOG_FORMAT", "text"), "Log format: text or json")
-	flag.StringVar(&cfg.LogLevel, "log-level", getEnv("LOG_LEVEL", "info"), "Log level: debug, info, warn, error")
+	fs.StringVar(&cfg.LogFormat, "log-format", getEnv("LOG_FORMAT", "text"), "Log format: text or json")
+	fs.StringVar(&cfg.LogLevel, "log-level", getEnv("LOG_LEVEL", "info"), "Log level: debug, info, warn, error")
 
 	// Storage backend
-	flag.StringVar(&cfg.Storage, "storage", getEnv("STORAGE", "memory"), "Storage backend: memory or redis")
-	flag.StringVar(&cfg.RedisAddr, "redis-addr", getEnv("REDIS_ADDR", "localhost:6379"), "Redis server address")
-	flag.StringVar(&cfg.RedisPassword, "redis-password", getEnv("REDIS_PASSWORD", ""), "Redis password (optional)")
-	flag.IntVar(&cfg.RedisDB, "redis-db", getEnvInt("REDIS_DB", 0), "Redis database number")
-	flag.DurationVar(&cfg.RedisTTL, "redis-ttl", getEnvDuration("REDIS_TTL", 30*time.Minute), "Redis snapshot TTL")
+	fs.StringVar(&cfg.Storage, "storage", getEnv("STORAGE", "memory"), "Storage backend: memory or redis")
+	fs.StringVar(&cfg.RedisAddr, "redis-addr", getEnv("REDIS_ADDR", "localhost:6379"), "Redis server address")
+	fs.StringVar(&cfg.RedisPassword, "redis-password", getEnv("REDIS_PASSWORD", ""), "Redis password (optional)")
+	fs.IntVar(&cfg.RedisDB, "redis-db", getEnvInt("REDIS_DB", 0), "Redis database number")
+	fs.DurationVar(&cfg.RedisTTL, "redis-ttl", getEnvDuration("REDIS_TTL", 30*time.Minute), "Redis snapshot TTL")
 
 	// Model selection
-	flag.StringVar(&cfg.Model, "model", getEnv("MODEL", "baseline"), "Forecasting model: baseline or arima")
-	flag.IntVar(&cfg.ARIMA_P, "arima-p", getEnvInt("ARIMA_P", 0), "ARIMA AR order (0=auto, default 1)")
-	flag.IntVar(&cfg.ARIMA_D, "arima-d", getEnvInt("ARIMA_D", 0), "ARIMA differencing order (0=auto, default 1)")
-	flag.IntVar(&cfg.ARIMA_Q, "arima-q", getEnvInt("ARIMA_Q", 0), "ARIMA MA order (0=auto, default 1)")
+	fs.StringVar(&cfg.Model, "model", getEnv("MODEL", "baseline"), "Forecasting model: baseline, seasonal-naive, intermittent, arima, statespace, prophet, regression, remote, wasm, ensemble or auto")
+	fs.Float64Var(&cfg.BaselineWeekBackoff, "baseline-week-backoff", getEnvFloat("BASELINE_WEEK_BACKOFF", 60), "Samples at which baseline hour-of-week and hour-of-day buckets weigh equally (negative=no backoff)")
+	fs.Float64Var(&cfg.BaselineMaxBlend, "baseline-max-blend", getEnvFloat("BASELINE_MAX_BLEND", 0.3), "Share of a bucket's max blended into its mean while the baseline detects upward momentum (negative=0)")
+	fs.Float64Var(&cfg.BaselineSpikeWeight, "baseline-spike-weight", getEnvFloat("BASELINE_SPIKE_WEIGHT", 0.8), "Baseline seasonal weight when seasonality is >1.5x the trend forecast (negative=0)")
+	fs.Float64Var(&cfg.BaselineRiseWeight, "baseline-rise-weight", getEnvFloat("BASELINE_RISE_WEIGHT", 0.7), "Baseline seasonal weight when seasonality is 1.2-1.5x the trend forecast (negative=0)")
+	fs.Float64Var(&cfg.BaselineDipWeight, "baseline-dip-weight", getEnvFloat("BASELINE_DIP_WEIGHT", 0.6), "Baseline seasonal weight when seasonality is <0.8x the trend forecast (negative=0)")
+	fs.Float64Var(&cfg.BaselineNeutralWeight, "baseline-neutral-weight", getEnvFloat("BASELINE_NEUTRAL_WEIGHT", 0.5), "Baseline seasonal weight when seasonality and trend agree (negative=0)")
+	fs.IntVar(&cfg.ARIMA_P, "arima-p", getEnvInt("ARIMA_P", 0), "ARIMA AR order (0=auto: AICc search)")
+	fs.IntVar(&cfg.ARIMA_D, "arima-d", getEnvInt("ARIMA_D", 0), "ARIMA differencing order (0=auto: KPSS test)")
+	fs.IntVar(&cfg.ARIMA_Q, "arima-q", getEnvInt("ARIMA_Q", 0), "ARIMA MA order (0=auto: AICc search)")
+	fs.IntVar(&cfg.ARIMA_SP, "arima-seasonal-p", getEnvInt("ARIMA_SEASONAL_P", 0), "SARIMA seasonal AR order P")
+	fs.IntVar(&cfg.ARIMA_SD, "arima-seasonal-d", getEnvInt("ARIMA_SEASONAL_D", 0), "SARIMA seasonal differencing order D (0 or 1)")
+	fs.IntVar(&cfg.ARIMA_SQ, "arima-seasonal-q", getEnvInt("ARIMA_SEASONAL_Q", 0), "SARIMA seasonal MA order Q")
+	fs.DurationVar(&cfg.ARIMA_Season, "arima-season", getEnvDuration("ARIMA_SEASON", 0), "SARIMA season length, e.g. 24h (0=non-seasonal)")
+	fs.StringVar(&cfg.ARIMA_Regressors, "arima-regressors", getEnv("ARIMA_REGRESSORS", ""), "Feature columns used as ARIMA regressors (ARIMAX), comma-separated")
+	fs.StringVar(&cfg.ARIMA_Events, "arima-events", getEnv("ARIMA_EVENTS", ""), "ARIMA event regressors: name=RFC3339/duration, comma-separated")
+	fs.DurationVar(&cfg.ARIMA_OrderSearch, "arima-order-search-interval", getEnvDuration("ARIMA_ORDER_SEARCH_INTERVAL", 6*time.Hour), "How long auto ARIMA orders are reused before searching again; a change point also triggers a search (negative=every training run)")
+	fs.IntVar(&cfg.ProphetChangepoints, "prophet-changepoints", getEnvInt("PROPHET_CHANGEPOINTS", 0), "Prophet trend changepoints (0=default 10, -1=none)")
+	fs.IntVar(&cfg.ProphetDailyOrder, "prophet-daily-order", getEnvInt("PROPHET_DAILY_ORDER", 0), "Prophet daily Fourier order (0=auto, -1=off)")
+	fs.IntVar(&cfg.ProphetWeeklyOrder, "prophet-weekly-order", getEnvInt("PROPHET_WEEKLY_ORDER", 0), "Prophet weekly Fourier order (0=auto, -1=off)")
+	fs.StringVar(&cfg.ProphetEvents, "prophet-events", getEnv("PROPHET_EVENTS", ""), "Prophet events: name=RFC3339/duration, comma-separated")
 
-	flag.Parse()
+	fs.IntVar(&cfg.RegressionLags, "regression-lags", getEnvInt("REGRESSION_LAGS", 6), "Lagged values used by the regression model (-1=none)")
+	fs.StringVar(&cfg.RegressionFeatures, "regression-features", getEnv("REGRESSION_FEATURES", ""), "Feature columns for the regression model, comma-separated (empty=all)")
+	fs.Float64Var(&cfg.RegressionAlpha, "regression-alpha", getEnvFloat("REGRESSION_ALPHA", 0.1), "Regression regularisation strength")
+	fs.Float64Var(&cfg.RegressionL1Ratio, "regression-l1-ratio", getEnvFloat("REGRESSION_L1_RATIO", 0), "Regression L1 mix: 0=ridge, 1=lasso, between=elastic net")
+	fs.StringVar(&cfg.RegressionStrategy, "regression-strategy", getEnv("REGRESSION_STRATEGY", "direct"), "Regression multi-step strategy: direct or recursive")
+	fs.StringVar(&cfg.RegressionEvents, "regression-events", getEnv("REGRESSION_EVENTS", ""), "Regression events: name=RFC3339/duration, comma-separated")
+	fs.StringVar(&cfg.NaiveMethod, "seasonal-naive-method", getEnv("SEASONAL_NAIVE_METHOD", "last"), "Seasonal naive method: last, average or level")
+	fs.StringVar(&cfg.NaivePeriods, "seasonal-naive-periods", getEnv("SEASONAL_NAIVE_PERIODS", "168h,24h"), "Seasonal naive periods, comma-separated; the longest covered by the window is used")
+	fs.IntVar(&cfg.NaiveSeasons, "seasonal-naive-seasons", getEnvInt("SEASONAL_NAIVE_SEASONS", 4), "Past seasons averaged by the average and level methods")
+	fs.DurationVar(&cfg.NaiveLevelWindow, "seasonal-naive-level-window", getEnvDuration("SEASONAL_NAIVE_LEVEL_WINDOW", time.Hour), "Window over which the level method compares recent and past levels")
+	fs.BoolVar(&cfg.StateSpaceTrend, "statespace-trend", getEnvBool("STATESPACE_TREND", false), "Add a stochastic slope to the state space model (local linear trend)")
+	fs.DurationVar(&cfg.StateSpaceSeason, "statespace-season", getEnvDuration("STATESPACE_SEASON", 0), "State space season length, e.g. 24h (0=no seasonal component)")
+	fs.IntVar(&cfg.StateSpaceHarmonics, "statespace-harmonics", getEnvInt("STATESPACE_HARMONICS", 3), "Trigonometric harmonics of the state space seasonal component")
+	fs.StringVar(&cfg.IntermittentMethod, "intermittent-method", getEnv("INTERMITTENT_METHOD", "tsb"), "Intermittent-demand method: croston, sba or tsb")
+	fs.Float64Var(&cfg.IntermittentAlpha, "intermittent-alpha", getEnvFloat("INTERMITTENT_ALPHA", 0.1), "Smoothing factor of intermittent burst sizes")
+	fs.Float64Var(&cfg.IntermittentBeta, "intermittent-beta", getEnvFloat("INTERMITTENT_BETA", 0.1), "Smoothing factor of the intermittent burst interval (croston, sba) or probability (tsb)")
+	fs.Float64Var(&cfg.IntermittentThreshold, "intermittent-threshold", getEnvFloat("INTERMITTENT_THRESHOLD", 0), "Values at or below this count as idle for the intermittent model")
+	fs.StringVar(&cfg.RemoteURL, "remote-url", getEnv("REMOTE_URL", "http://localhost:9000"), "Base URL of the model plugin sidecar for --model=remote")
+	fs.DurationVar(&cfg.RemoteTimeout, "remote-timeout", getEnvDuration("REMOTE_TIMEOUT", 10*time.Second), "Timeout for each call to the model plugin sidecar")
+	fs.StringVar(&cfg.RemoteFallback, "remote-fallback", getEnv("REMOTE_FALLBACK", "baseline"), "Local model used when the plugin sidecar fails (empty=none)")
+	fs.StringVar(&cfg.WASMModule, "wasm-module", getEnv("WASM_MODULE", ""), "Path to the WebAssembly plugin for --model=wasm")
+	fs.IntVar(&cfg.WASMMemoryLimit, "wasm-memory-limit", getEnvInt("WASM_MEMORY_LIMIT", 128), "Memory limit of the WebAssembly plugin in MiB (at most 4096)")
+	fs.DurationVar(&cfg.WASMTimeout, "wasm-timeout", getEnvDuration("WASM_TIMEOUT", 5*time.Second), "Time limit for each call into the WebAssembly plugin")
 
-	if cfg.Workload == "" {
-		fmt.Fprintln(os.Stderr, "Error: --workload is required")
-		os.Exit(1)
-	}
-	if cfg.Metric == "" {
-		fmt.Fprintln(os.Stderr, "Error: --metric is required")
-		os.Exit(1)
-	}
-	if cfg.PromQuery == "" {
-		fmt.Fprintln(os.Stderr, "Error: --prom-query is required")
-		os.Exit(1)
-	}
+	fs.StringVar(&cfg.EnsembleMembers, "ensemble-members", getEnv("ENSEMBLE_MEMBERS", "baseline,arima"), "Ensemble member models, comma-separated")
+	fs.StringVar(&cfg.EnsembleMethod, "ensemble-method", getEnv("ENSEMBLE_METHOD", "inverse-error"), "Ensemble weighting: inverse-error or stacking")
+	fs.IntVar(&cfg.EnsembleWindow, "ensemble-window", getEnvInt("ENSEMBLE_WINDOW", 500), "Recent forecast/actual pairs used to weight ensemble members")
+
+	fs.StringVar(&cfg.AutoCandidates, "auto-candidates", getEnv("AUTO_CANDIDATES", "baseline,arima(1,1,1),arima(2,1,1),arima(1,1,2),arima(2,1,2)"), "Candidates for --model=auto, comma-separated (model names or arima(p,d,q))")
+	fs.StringVar(&cfg.AutoMetric, "auto-metric", getEnv("AUTO_METRIC", "mae"), "Selection metric for --model=auto: mae, mape or pinball")
+	fs.Float64Var(&cfg.AutoQuantile, "auto-quantile", getEnvFloat("AUTO_QUANTILE", 0.9), "Quantile scored by the pinball metric")
+	fs.IntVar(&cfg.AutoFolds, "auto-folds", getEnvInt("AUTO_FOLDS", 5), "Rolling-origin folds per candidate")
+	fs.DurationVar(&cfg.AutoReselect, "auto-reselect", getEnvDuration("AUTO_RESELECT", 6*time.Hour), "How often --model=auto re-runs selection")
 
 	return cfg
 }
@@ -158,6 +307,15 @@ func getEnvFloat(key string, defaultValue float64) float64 {
 	return defaultValue
 }
 
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
 func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if d, err := time.ParseDuration(value); err == nil {
@@ -184,6 +184,59 @@ func TestGetEnvDuration(t *testing.T) {
 	}
 }
 
+func TestGetEnvBool(t *testing.T) {
+	tests := []struct {
+		name         string
+		key          string
+		defaultValue bool
+		envValue     string
+		want         bool
+	}{
+		{
+			name:         "true",
+			key:          "TEST_BOOL",
+			defaultValue: false,
+			envValue:     "true",
+			want:         true,
+		},
+		{
+			name:         "false overrides default",
+			key:          "TEST_BOOL",
+			defaultValue: true,
+			envValue:     "0",
+			want:         false,
+		},
+		{
+			name:         "invalid bool",
+			key:          "TEST_BOOL",
+			defaultValue: true,
+			envValue:     "maybe",
+			want:         true,
+		},
+		{
+			name:         "not set",
+			key:          "NONEXISTENT_BOOL",
+			defaultValue: false,
+			envValue:     "",
+			want:         false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.envValue != "" {
+				os.Setenv(tt.key, tt.envValue)
+				defer os.Unsetenv(tt.key)
+			}
+
+			got := getEnvBool(tt.key, tt.defaultValue)
+			if got != tt.want {
+				t.Errorf("getEnvBool() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestConfig_Defaults(t *testing.T) {
 	// Reset flag package for testing
 	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
@@ -229,12 +282,24 @@ func TestConfig_Defaults(t *testing.T) {
 	if cfg.Window != 30*time.Minute {
 		t.Errorf("Window = %v, want 30m", cfg.Window)
 	}
+	if cfg.FullTrainInterval != time.Hour {
+		t.Errorf("FullTrainInterval = %v, want 1h", cfg.FullTrainInterval)
+	}
+	if cfg.ChangePointMinSegment != 0 {
+		t.Errorf("ChangePointMinSegment = %v, want 0 (disabled)", cfg.ChangePointMinSegment)
+	}
 	if cfg.LogFormat != "text" {
 		t.Errorf("LogFormat = %q, want %q", cfg.LogFormat, "text")
 	}
 	if cfg.LogLevel != "info" {
 		t.Errorf("LogLevel = %q, want %q", cfg.LogLevel, "info")
 	}
+	if cfg.ARIMA_Season != 0 {
+		t.Errorf("ARIMA_Season = %v, want 0", cfg.ARIMA_Season)
+	}
+	if cfg.ARIMA_OrderSearch != 6*time.Hour {
+		t.Errorf("ARIMA_OrderSearch = %v, want 6h", cfg.ARIMA_OrderSearch)
+	}
 }
 
 func TestConfig_CustomValues(t *testing.T) {
@@ -258,6 +323,10 @@ func TestConfig_CustomValues(t *testing.T) {
 		"-window=1h",
 		"-log-format=json",
 		"-log-level=debug",
+		"-arima-seasonal-p=1",
+		"-arima-seasonal-d=1",
+		"-arima-seasonal-q=1",
+		"-arima-season=24h",
 	}
 
 	cfg := ParseFlags()
@@ -286,4 +355,10 @@ func TestConfig_CustomValues(t *testing.T) {
 	if cfg.LogLevel != "debug" {
 		t.Errorf("LogLevel = %q, want %q", cfg.LogLevel, "debug")
 	}
+	if cfg.ARIMA_SP != 1 || cfg.ARIMA_SD != 1 || cfg.ARIMA_SQ != 1 {
+		t.Errorf("ARIMA seasonal P,D,Q = %d,%d,%d, want 1,1,1", cfg.ARIMA_SP, cfg.ARIMA_SD, cfg.ARIMA_SQ)
+	}
+	if cfg.ARIMA_Season != 24*time.Hour {
+		t.Errorf("ARIMA_Season = %v, want 24h", cfg.ARIMA_Season)
+	}
 }
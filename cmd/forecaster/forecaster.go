@@ -2,7 +2,7 @@
 //
 // This file contains the Forecaster type which orchestrates the forecast pipeline:
 //
-//	collect → buildFeatures → predict → calculateReplicas → storeSnapshot
+//	collect → buildFeatures → predict → [reconcile] → calculateReplicas → storeSnapshot
 //
 // The Forecaster runs continuously via Run(), executing Tick() at regular intervals.
 // Each tick performs one complete forecast cycle, updating the stored snapshot that
@@ -11,15 +11,25 @@
 // The forecast loop is instrumented with Prometheus metrics tracking the duration
 // of each pipeline stage (collect, predict, capacity planning) and any errors
 // encountered during execution.
+//
+// When both the model (models.Stateful) and the store (storage.StateStore)
+// support it, the trained model state is saved after every successful training
+// run and restored when Run starts, so a restarted or newly elected forecaster
+// can serve a forecast on its first tick.
+//
+// With a hierarchy (SetHierarchy), the forecast is reconciled with the
+// forecasts of the series below the workload total before it is planned.
 package main
 
 import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/HatiCode/kedastral/cmd/forecaster/metrics"
+	"github.com/HatiCode/kedastral/pkg/accuracy"
 	"github.com/HatiCode/kedastral/pkg/adapters"
 	"github.com/HatiCode/kedastral/pkg/capacity"
 	"github.com/HatiCode/kedastral/pkg/features"
@@ -41,9 +51,28 @@ type Forecaster struct {
 	logger          *slog.Logger
 	metrics         *metrics.Metrics
 	currentReplicas int
+	history         capacity.History
+
+	// trainer updates incremental models between full retrains and drops
+	// history before change points, as the backtest does.
+	trainer models.Trainer
+
+	// accuracy joins past forecasts with the values collected since.
+	accuracy *accuracy.Tracker
+
+	// hierarchy, when set by SetHierarchy, holds the series reconciled with
+	// the workload total before planning.
+	hierarchy *hierarchy
 }
 
 // New creates a new Forecaster.
+//
+// fullTrainInterval is how often the model is trained on the full window when
+// it implements models.IncrementalModel; in between it is only updated with
+// new rows. Zero retrains on every tick.
+//
+// changePoints enables change-point detection on full training runs; nil
+// trains on the whole window.
 func New(
 	workload string,
 	adapter adapters.Adapter,
@@ -51,7 +80,8 @@ func New(
 	builder *features.Builder,
 	store storage.Store,
 	policy *capacity.Policy,
-	horizon, step, window time.Duration,
+	horizon, step, window, fullTrainInterval time.Duration,
+	changePoints *models.ChangePointOptions,
 	logger *slog.Logger,
 	metrics *metrics.Metrics,
 ) *Forecaster {
@@ -72,6 +102,13 @@ func New(
 		logger:          logger,
 		metrics:         metrics,
 		currentReplicas: policy.MinReplicas,
+
+		trainer: models.Trainer{
+			Step:              step,
+			FullTrainInterval: fullTrainInterval,
+			ChangePoints:      changePoints,
+		},
+		accuracy: accuracy.NewTracker(0),
 	}
 }
 
@@ -80,6 +117,8 @@ func New(
 func (f *Forecaster) Run(ctx context.Context, interval time.Duration) error {
 	f.logger.Info("starting forecast loop", "interval", interval)
 
+	f.restoreState()
+
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
@@ -123,9 +162,16 @@ func (f *Forecaster) Tick(ctx context.Context) error {
 	}
 
 	// Train the model on historical data to learn patterns
-	if err := f.model.Train(ctx, featureFrame); err != nil {
+	if err := f.train(ctx, featureFrame); err != nil {
 		f.logger.Debug("model training skipped or failed", "error", err)
 		// Training is optional for some models (e.g., baseline), so we don't fail here
+	} else {
+		f.saveState()
+	}
+
+	// The name can change with training, e.g. when ARIMA orders are searched.
+	if f.metrics != nil {
+		f.metrics.SetModel(f.model.Name())
 	}
 
 	forecast, predictDuration, err := f.predict(ctx, featureFrame)
@@ -135,8 +181,13 @@ func (f *Forecaster) Tick(ctx context.Context) error {
 		}
 		return fmt.Errorf("predict: %w", err)
 	}
+	f.trackAccuracy(featureFrame, forecast)
+
+	if f.hierarchy != nil {
+		forecast = f.reconcile(ctx, featureFrame, forecast)
+	}
 
-	desiredReplicas, capacityDuration := f.calculateReplicas(forecast.Values)
+	desiredReplicas, capacityDuration := f.calculateReplicas(f.planningSeries(forecast))
 
 	if err := f.storeSnapshot(forecast, desiredReplicas); err != nil {
 		if f.metrics != nil {
@@ -154,6 +205,7 @@ func (f *Forecaster) Tick(ctx context.Context) error {
 	totalDuration := time.Since(start)
 	f.logger.Info("forecast tick complete",
 		"workload", f.workload,
+		"model", f.model.Name(),
 		"current_replicas", f.currentReplicas,
 		"forecast_points", len(forecast.Values),
 		"collect_ms", collectDuration.Milliseconds(),
@@ -165,6 +217,107 @@ func (f *Forecaster) Tick(ctx context.Context) error {
 	return nil
 }
 
+// train fits the model on the feature frame through the trainer (see
+// models.Trainer) and logs what it did. A change point not seen before is
+// also recorded in the metrics.
+func (f *Forecaster) train(ctx context.Context, frame models.FeatureFrame) error {
+	result, err := f.trainer.Train(ctx, f.model, frame, time.Now())
+
+	if result.UpdateErr != nil {
+		f.logger.Debug("incremental update failed, retraining", "error", result.UpdateErr)
+	}
+	if result.Updated > 0 {
+		f.logger.Debug("model updated incrementally", "rows", result.Updated)
+	}
+	if cp := result.ChangePoint; cp != nil && result.NewChangePoint {
+		f.logger.Info("change point detected",
+			"time", time.Unix(int64(cp.Timestamp), 0).UTC(),
+			"kind", cp.Kind,
+			"rows_kept", len(frame.Rows)-cp.Index,
+		)
+		if f.metrics != nil {
+			f.metrics.RecordChangePoint(string(cp.Kind), cp.Timestamp)
+		}
+	}
+	if result.RegimeErr != nil {
+		f.logger.Warn("training since change point failed, using full window",
+			"rows", len(frame.Rows)-result.ChangePoint.Index, "error", result.RegimeErr)
+	}
+	return err
+}
+
+// restoreState loads persisted model state, if any, so that Predict works
+// even when training on the first window fails. Incompatible or unreadable
+// state is logged and ignored.
+func (f *Forecaster) restoreState() {
+	stateful, stateStore, ok := f.stateBackends()
+	if !ok {
+		return
+	}
+
+	key := modelStateKey(f.model.Name())
+	data, found, err := stateStore.GetModelState(f.workload, key)
+	if err != nil {
+		f.logger.Warn("failed to load model state", "model", key, "error", err)
+		return
+	}
+	if !found {
+		f.logger.Debug("no persisted model state", "model", key)
+		return
+	}
+
+	if err := stateful.UnmarshalState(data); err != nil {
+		f.logger.Warn("ignoring persisted model state", "model", key, "error", err)
+		return
+	}
+	f.logger.Info("restored model state", "model", f.model.Name(), "bytes", len(data))
+}
+
+// saveState persists the trained model state. Failures are logged and
+// counted but never fail the tick.
+func (f *Forecaster) saveState() {
+	stateful, stateStore, ok := f.stateBackends()
+	if !ok {
+		return
+	}
+
+	key := modelStateKey(f.model.Name())
+	data, err := stateful.MarshalState()
+	if err == nil {
+		err = stateStore.PutModelState(f.workload, key, data)
+	}
+	if err != nil {
+		if f.metrics != nil {
+			f.metrics.RecordError("store", "state_put_failed")
+		}
+		f.logger.Warn("failed to save model state", "model", key, "error", err)
+		return
+	}
+	f.logger.Debug("saved model state", "model", key, "bytes", len(data))
+}
+
+// stateBackends returns the model and store as their state-persistence
+// interfaces; ok is false unless both support it.
+func (f *Forecaster) stateBackends() (models.Stateful, storage.StateStore, bool) {
+	stateful, ok := f.model.(models.Stateful)
+	if !ok {
+		return nil, nil, false
+	}
+	stateStore, ok := f.store.(storage.StateStore)
+	if !ok {
+		return nil, nil, false
+	}
+	return stateful, stateStore, true
+}
+
+// modelStateKey returns the model family from its name, e.g. "arima" for
+// "arima(2,1,1)". State is keyed by family because the name of a model with
+// searched orders changes between training runs.
+func modelStateKey(name string) string {
+	family, _, _ := strings.Cut(name, "(")
+	return family
+}
+
 // collect retrieves metrics from the adapter.
 func (f *Forecaster) collect(ctx context.Context) (*adapters.DataFrame, time.Duration, error) {
 	start := time.Now()
@@ -223,14 +376,80 @@ func (f *Forecaster) predict(ctx context.Context, features models.FeatureFrame)
 		"duration_ms", duration.Milliseconds(),
 	)
 
+	if ensemble, ok := f.model.(*models.EnsembleModel); ok {
+		f.reportEnsembleWeights(ensemble)
+	}
+
 	return forecast, duration, nil
 }
 
+// reportEnsembleWeights publishes the current ensemble member weights.
+func (f *Forecaster) reportEnsembleWeights(ensemble *models.EnsembleModel) {
+	weights := ensemble.Weights()
+
+	attrs := make([]any, 0, 2*len(weights))
+	for member, weight := range weights {
+		if f.metrics != nil {
+			f.metrics.SetEnsembleWeight(member, weight)
+		}
+		attrs = append(attrs, member, weight)
+	}
+	f.logger.Debug("ensemble weights", attrs...)
+}
+
+// trackAccuracy joins earlier forecasts with the values in frame, records
+// forecast for later evaluation and publishes the updated statistics. The
+// forecast's origin is the newest timestamp in frame.
+func (f *Forecaster) trackAccuracy(frame models.FeatureFrame, forecast models.Forecast) {
+	if f.accuracy == nil || len(frame.Rows) == 0 {
+		return
+	}
+	f.accuracy.Observe(f.workload, frame)
+
+	origin, ok := frame.Rows[len(frame.Rows)-1]["timestamp"]
+	if !ok {
+		return
+	}
+	f.accuracy.Record(f.workload, time.Unix(int64(origin), 0), forecast)
+
+	if report, found := f.accuracy.Report(f.workload); found && f.metrics != nil {
+		f.metrics.SetAccuracy(report)
+	}
+}
+
+// planningSeries returns the forecast series capacity is planned against:
+// the likely bursts of an intermittent-demand forecast when the policy sets a
+// burst probability, else the policy's quantile when configured and
+// available, else the point forecast.
+func (f *Forecaster) planningSeries(forecast models.Forecast) []float64 {
+	if f.policy.BurstProbability > 0 {
+		if forecast.Burst != nil {
+			if series, ok := capacity.BurstSeries(forecast.Burst.Probability, forecast.Burst.Size, *f.policy); ok {
+				return series
+			}
+		}
+		f.logger.Warn("forecast has no burst probability, planning without it",
+			"model", f.model.Name(),
+			"burst_probability", f.policy.BurstProbability,
+		)
+	}
+
+	series, ok := capacity.SelectSeries(forecast.Values, forecast.Quantiles, *f.policy)
+	if !ok {
+		f.logger.Warn("forecast has no requested quantile, planning against point forecast",
+			"model", f.model.Name(),
+			"quantile", f.policy.Quantile,
+		)
+	}
+	return series
+}
+
 // calculateReplicas converts forecast values to desired replica counts.
 func (f *Forecaster) calculateReplicas(values []float64) ([]int, time.Duration) {
 	start := time.Now()
 
-	desiredReplicas := capacity.ToReplicas(
+	desiredReplicas := f.history.ToReplicas(
+		time.Now(),
 		f.currentReplicas,
 		values,
 		int(f.step.Seconds()),
@@ -266,6 +485,9 @@ func (f *Forecaster) storeSnapshot(forecast models.Forecast, desiredReplicas []i
 		HorizonSeconds:  int(f.horizon.Seconds()),
 		Values:          forecast.Values,
 		DesiredReplicas: desiredReplicas,
+		Quantiles:       forecast.Quantiles,
+		Model:           f.model.Name(),
+		Components:      forecast.Components,
 	}
 
 	if err := f.store.Put(snapshot); err != nil {
@@ -276,6 +498,11 @@ func (f *Forecaster) storeSnapshot(forecast models.Forecast, desiredReplicas []i
 	return nil
 }
 
+// GetAccuracy returns the forecast accuracy tracker for HTTP handlers.
+func (f *Forecaster) GetAccuracy() *accuracy.Tracker {
+	return f.accuracy
+}
+
 // GetStore returns the underlying store for HTTP handlers.
 func (f *Forecaster) GetStore() storage.Store {
 	return f.store
@@ -2,12 +2,17 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"log/slog"
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
 	"github.com/HatiCode/kedastral/cmd/forecaster/metrics"
+	"github.com/HatiCode/kedastral/pkg/accuracy"
 	"github.com/HatiCode/kedastral/pkg/adapters"
 	"github.com/HatiCode/kedastral/pkg/capacity"
 	"github.com/HatiCode/kedastral/pkg/features"
@@ -43,6 +48,8 @@ func TestNew(t *testing.T) {
 		30*time.Minute,
 		1*time.Minute,
 		30*time.Minute,
+		time.Hour,
+		nil,
 		logger,
 		m,
 	)
@@ -79,6 +86,8 @@ func TestNew_NilLogger(t *testing.T) {
 		30*time.Minute,
 		1*time.Minute,
 		30*time.Minute,
+		time.Hour,
+		nil,
 		nil, // nil logger
 		m,
 	)
@@ -133,6 +142,8 @@ func TestForecaster_Run_ContextCancellation(t *testing.T) {
 		30*time.Minute,
 		1*time.Minute,
 		30*time.Minute,
+		time.Hour,
+		nil,
 		logger,
 		m,
 	)
@@ -170,6 +181,8 @@ func TestForecaster_Run_Timeout(t *testing.T) {
 		30*time.Minute,
 		1*time.Minute,
 		30*time.Minute,
+		time.Hour,
+		nil,
 		logger,
 		m,
 	)
@@ -218,6 +231,290 @@ func TestForecaster_CalculateReplicas(t *testing.T) {
 	}
 }
 
+func TestForecaster_PlanningSeries(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	burst := &models.BurstForecast{Probability: []float64{0.1, 0.5}, Size: []float64{400, 400}}
+
+	tests := []struct {
+		name             string
+		quantile         float64
+		burstProbability float64
+		burst            *models.BurstForecast
+		want             []float64
+	}{
+		{name: "point forecast", quantile: 0, want: []float64{100, 200}},
+		{name: "p90", quantile: 0.9, want: []float64{150, 260}},
+		{name: "missing quantile", quantile: 0.95, want: []float64{100, 200}},
+		{name: "likely bursts", burstProbability: 0.3, burst: burst, want: []float64{0, 400}},
+		{name: "missing bursts", quantile: 0.9, burstProbability: 0.3, want: []float64{150, 260}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forecast := models.Forecast{
+				Values:    []float64{100, 200},
+				Quantiles: map[string][]float64{"p90": {150, 260}},
+				Burst:     tt.burst,
+			}
+			f := &Forecaster{
+				model:  models.NewBaselineModel("test", 60, 120),
+				policy: &capacity.Policy{Quantile: tt.quantile, BurstProbability: tt.burstProbability},
+				logger: logger,
+			}
+			got := f.planningSeries(forecast)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("planningSeries() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForecaster_Predict_EnsembleWeights(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	m := metrics.New("test-ensemble-weights")
+
+	ensemble := models.NewEnsembleModel("test", 60, 120, []models.Model{
+		models.NewBaselineModel("test", 60, 120),
+		models.NewBaselineModel("test", 60, 120),
+	}, models.EnsembleOptions{})
+
+	f := &Forecaster{
+		model:   ensemble,
+		logger:  logger,
+		metrics: m,
+	}
+
+	features := models.FeatureFrame{Rows: []map[string]float64{{"value": 100, "timestamp": 0}}}
+	if _, _, err := f.predict(context.Background(), features); err != nil {
+		t.Fatalf("predict() error = %v", err)
+	}
+
+	if got := testutil.ToFloat64(m.EnsembleWeight.WithLabelValues("baseline")); got != 1 {
+		t.Errorf("ensemble weight for baseline = %v, want 1 (two equal members share the name)", got)
+	}
+}
+
+func TestForecaster_ModelState_WarmStart(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := storage.NewMemoryStore()
+	ctx := context.Background()
+
+	history := models.FeatureFrame{}
+	for i := range 100 {
+		history.Rows = append(history.Rows, map[string]float64{"value": 100 + float64(i%7)})
+	}
+
+	trained := models.NewARIMAModel("test", 60, 600, 1, 1, 1)
+	if err := trained.Train(ctx, history); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	before := &Forecaster{workload: "test-api", model: trained, store: store, logger: logger}
+	before.saveState()
+
+	if _, found, _ := store.GetModelState("test-api", "arima"); !found {
+		t.Fatal("model state not saved under key \"arima\"")
+	}
+
+	restarted := models.NewARIMAModel("test", 60, 600, 1, 1, 1)
+	after := &Forecaster{workload: "test-api", model: restarted, store: store, logger: logger}
+	after.restoreState()
+
+	if _, err := restarted.Predict(ctx, models.FeatureFrame{}); err != nil {
+		t.Errorf("Predict() after restore error = %v, want warm-started model", err)
+	}
+
+	incompatible := models.NewARIMAModel("test", 60, 600, 2, 1, 1)
+	other := &Forecaster{workload: "test-api", model: incompatible, store: store, logger: logger}
+	other.restoreState()
+
+	if _, err := incompatible.Predict(ctx, models.FeatureFrame{}); err == nil {
+		t.Error("Predict() error = nil, want incompatible state to be ignored")
+	}
+}
+
+// recordingModel is an incremental model that records how it was trained.
+// Training on fewer than minRows rows fails.
+type recordingModel struct {
+	models.Model
+	trains      int
+	trainedRows int
+	updates     [][]float64
+	minRows     int
+	resets      int
+}
+
+func (r *recordingModel) Train(ctx context.Context, history models.FeatureFrame) error {
+	if len(history.Rows) < r.minRows {
+		return fmt.Errorf("need %d rows, got %d", r.minRows, len(history.Rows))
+	}
+	r.trains++
+	r.trainedRows = len(history.Rows)
+	return nil
+}
+
+func (r *recordingModel) Update(ctx context.Context, newRows models.FeatureFrame) error {
+	var ts []float64
+	for _, row := range newRows.Rows {
+		ts = append(ts, row["timestamp"])
+	}
+	r.updates = append(r.updates, ts)
+	return nil
+}
+
+func (r *recordingModel) ResetOrderSearch() {
+	r.resets++
+}
+
+// minuteFrame returns rows at 60s spacing with timestamps from..to inclusive.
+func minuteFrame(from, to int) models.FeatureFrame {
+	frame := models.FeatureFrame{}
+	for i := from; i <= to; i++ {
+		frame.Rows = append(frame.Rows, map[string]float64{"timestamp": float64(i * 60), "value": 1})
+	}
+	return frame
+}
+
+func TestForecaster_Train_Incremental(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+	model := &recordingModel{Model: models.NewBaselineModel("test", 60, 120)}
+	f := &Forecaster{
+		model:   model,
+		step:    time.Minute,
+		trainer: models.Trainer{Step: time.Minute, FullTrainInterval: time.Hour},
+		logger:  logger,
+	}
+
+	steps := []struct {
+		name        string
+		frame       models.FeatureFrame
+		wantTrains  int
+		wantUpdated []float64
+	}{
+		{name: "first tick trains fully", frame: minuteFrame(1, 30), wantTrains: 1},
+		{name: "new rows update", frame: minuteFrame(3, 32), wantTrains: 1, wantUpdated: []float64{31 * 60, 32 * 60}},
+		{name: "no new rows", frame: minuteFrame(3, 32), wantTrains: 1},
+		{name: "gap retrains", frame: minuteFrame(35, 60), wantTrains: 2},
+	}
+
+	for _, step := range steps {
+		updates := len(model.updates)
+		if err := f.train(ctx, step.frame); err != nil {
+			t.Fatalf("%s: train() error = %v", step.name, err)
+		}
+		if model.trains != step.wantTrains {
+			t.Errorf("%s: Train called %d times, want %d", step.name, model.trains, step.wantTrains)
+		}
+		var got []float64
+		if len(model.updates) > updates {
+			got = model.updates[len(model.updates)-1]
+		}
+		if !reflect.DeepEqual(got, step.wantUpdated) {
+			t.Errorf("%s: Update rows = %v, want %v", step.name, got, step.wantUpdated)
+		}
+	}
+
+	f.trainer.FullTrainInterval = 0
+	if err := f.train(ctx, minuteFrame(36, 61)); err != nil {
+		t.Fatalf("train() error = %v", err)
+	}
+	if model.trains != 3 {
+		t.Errorf("Train called %d times, want full retrain when incremental training is disabled", model.trains)
+	}
+}
+
+func TestForecaster_Train_ChangePoint(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+	m := metrics.New("test-changepoint")
+
+	// The level jumps from ~10 to ~50 at row 80 of 120.
+	frame := minuteFrame(0, 119)
+	for i, row := range frame.Rows {
+		row["value"] = float64(10 + i%3)
+		if i >= 80 {
+			row["value"] += 40
+		}
+	}
+
+	tests := []struct {
+		name     string
+		minRows  int
+		wantRows int
+	}{
+		{name: "trains since change point", wantRows: 40},
+		{name: "falls back to full window", minRows: 60, wantRows: 120},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := &recordingModel{Model: models.NewBaselineModel("test", 60, 120), minRows: tt.minRows}
+			f := &Forecaster{
+				model:   model,
+				step:    time.Minute,
+				logger:  logger,
+				metrics: m,
+				trainer: models.Trainer{Step: time.Minute, ChangePoints: &models.ChangePointOptions{MinSegment: 20}},
+			}
+
+			if err := f.train(ctx, frame); err != nil {
+				t.Fatalf("train() error = %v", err)
+			}
+			if model.trainedRows != tt.wantRows {
+				t.Errorf("trained on %d rows, want %d", model.trainedRows, tt.wantRows)
+			}
+			if got := testutil.ToFloat64(m.ChangePointTimestamp); got != 80*60 {
+				t.Errorf("change point timestamp = %v, want %v", got, 80*60)
+			}
+
+			// Only a change point not seen before resets the order search.
+			if err := f.train(ctx, frame); err != nil {
+				t.Fatalf("train() error = %v", err)
+			}
+			if model.resets != 1 {
+				t.Errorf("ResetOrderSearch called %d times, want 1", model.resets)
+			}
+		})
+	}
+}
+
+func TestForecaster_TrackAccuracy(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	m := metrics.New("test-track-accuracy")
+	f := &Forecaster{
+		workload: "test",
+		logger:   logger,
+		metrics:  m,
+		accuracy: accuracy.NewTracker(0),
+	}
+
+	forecast := models.Forecast{Values: []float64{5}, StepSec: 60}
+	f.trackAccuracy(minuteFrame(0, 10), forecast)
+	f.trackAccuracy(minuteFrame(0, 11), forecast)
+
+	report, found := f.GetAccuracy().Report("test")
+	if !found || len(report.Steps) != 1 || report.Steps[0].MAE != 4 {
+		t.Fatalf("Report() = %+v, want one step with MAE 4", report)
+	}
+	if got := testutil.ToFloat64(m.ForecastMAE.WithLabelValues("60")); got != 4 {
+		t.Errorf("MAE metric = %v, want 4", got)
+	}
+}
+
+func TestModelStateKey(t *testing.T) {
+	tests := map[string]string{
+		"baseline":                 "baseline",
+		"arima(2,1,1)":             "arima",
+		"sarima(1,0,0)(1,1,0)[24]": "sarima",
+		"ensemble(baseline,arima)": "ensemble",
+	}
+	for name, want := range tests {
+		if got := modelStateKey(name); got != want {
+			t.Errorf("modelStateKey(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
 func TestForecaster_BuildFeatures(t *testing.T) {
 	builder := features.NewBuilder()
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
@@ -270,6 +567,7 @@ func TestForecaster_StoreSnapshot(t *testing.T) {
 
 	f := &Forecaster{
 		workload: "test-api",
+		model:    models.NewBaselineModel("http_rps", 60, 180),
 		store:    store,
 		step:     1 * time.Minute,
 		horizon:  30 * time.Minute,
@@ -277,8 +575,9 @@ func TestForecaster_StoreSnapshot(t *testing.T) {
 	}
 
 	forecast := models.Forecast{
-		Metric: "http_rps",
-		Values: []float64{100, 110, 120},
+		Metric:     "http_rps",
+		Values:     []float64{100, 110, 120},
+		Components: map[string][]float64{models.ComponentLevel: {90, 90, 90}},
 	}
 	desiredReplicas := []int{2, 3, 3}
 
@@ -304,6 +603,12 @@ func TestForecaster_StoreSnapshot(t *testing.T) {
 	if len(snapshot.DesiredReplicas) != 3 {
 		t.Errorf("len(DesiredReplicas) = %d, want 3", len(snapshot.DesiredReplicas))
 	}
+	if snapshot.Model != "baseline" {
+		t.Errorf("Model = %q, want baseline", snapshot.Model)
+	}
+	if !reflect.DeepEqual(snapshot.Components, forecast.Components) {
+		t.Errorf("Components = %v, want %v", snapshot.Components, forecast.Components)
+	}
 }
 
 func TestForecaster_Tick_WithMetrics(t *testing.T) {
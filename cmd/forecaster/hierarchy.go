@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/HatiCode/kedastral/pkg/adapters"
+	"github.com/HatiCode/kedastral/pkg/models"
+	"github.com/HatiCode/kedastral/pkg/reconcile"
+	"github.com/HatiCode/kedastral/pkg/storage"
+)
+
+// maxHierarchyResiduals is the number of past one-step errors per node kept
+// to estimate the MinT error covariance.
+const maxHierarchyResiduals = 500
+
+// HierarchyNode is a series forecast alongside the workload total, such as
+// the requests of one endpoint. Its forecast is reconciled with the total's
+// and stored as workload "<workload>/<name>".
+type HierarchyNode struct {
+	// Name identifies the node; it must be unique within the hierarchy.
+	Name string
+
+	// Parent is the node this one is part of; empty for the workload total.
+	Parent string
+
+	Adapter adapters.Adapter
+	Model   models.Model
+}
+
+// hierarchyFile is the format of the --hierarchy-file JSON document.
+type hierarchyFile struct {
+	Nodes []struct {
+		Name   string `json:"name"`
+		Parent string `json:"parent"`
+		Query  string `json:"query"`
+	} `json:"nodes"`
+}
+
+// hierarchySpec is a node declared in the hierarchy file.
+type hierarchySpec struct {
+	Name, Parent, Query string
+}
+
+// loadHierarchyFile reads the nodes declared in a hierarchy file:
+//
+//	{"nodes": [
+//	  {"name": "eu", "query": "sum(rate(http_requests_total{region=\"eu\"}[1m]))"},
+//	  {"name": "eu-checkout", "parent": "eu", "query": "..."}
+//	]}
+func loadHierarchyFile(path string) ([]hierarchySpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file hierarchyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	specs := make([]hierarchySpec, 0, len(file.Nodes))
+	for i, n := range file.Nodes {
+		if n.Name == "" || n.Query == "" {
+			return nil, fmt.Errorf("%s: node %d needs a name and a query", path, i)
+		}
+		specs = append(specs, hierarchySpec{Name: n.Name, Parent: n.Parent, Query: n.Query})
+	}
+	return specs, nil
+}
+
+// hierarchy holds the nodes below the workload total and the errors of their
+// past forecasts.
+type hierarchy struct {
+	tree   *reconcile.Hierarchy
+	method reconcile.Method
+	nodes  []HierarchyNode
+
+	// trainers train the node at the same index as the workload's model is
+	// trained, incrementally and since change points.
+	trainers []models.Trainer
+
+	// pending holds the first forecast step of every node (tree order),
+	// whose actual value is expected at pendingTs.
+	pending   []float64
+	pendingTs float64
+	residuals [][]float64
+}
+
+// SetHierarchy enables reconciliation of the workload's forecast with the
+// forecasts of nodes, which form a hierarchy below the workload total.
+// Every tick each node is collected, trained with the workload's training
+// settings and predicted; the reconciled total is then planned and stored as
+// usual.
+//
+// Returns an error if the nodes do not form a tree below the workload.
+func (f *Forecaster) SetHierarchy(nodes []HierarchyNode, method reconcile.Method) error {
+	parents := make(map[string]string, len(nodes))
+	for _, n := range nodes {
+		if _, dup := parents[n.Name]; dup || n.Name == f.workload {
+			return fmt.Errorf("duplicate hierarchy node %q", n.Name)
+		}
+		parent := n.Parent
+		if parent == "" {
+			parent = f.workload
+		}
+		parents[n.Name] = parent
+	}
+	tree, err := reconcile.NewHierarchy(f.workload, parents)
+	if err != nil {
+		return err
+	}
+
+	trainers := make([]models.Trainer, len(nodes))
+	for i := range trainers {
+		trainers[i] = models.Trainer{
+			Step:              f.trainer.Step,
+			FullTrainInterval: f.trainer.FullTrainInterval,
+			ChangePoints:      f.trainer.ChangePoints,
+		}
+	}
+	f.hierarchy = &hierarchy{tree: tree, method: method, nodes: nodes, trainers: trainers}
+	return nil
+}
+
+// reconcile forecasts every hierarchy node, reconciles the node forecasts
+// with the total's and stores them. It returns the reconciled total, or
+// total unchanged if a node cannot be forecast or reconciliation fails.
+func (f *Forecaster) reconcile(ctx context.Context, frame models.FeatureFrame, total models.Forecast) models.Forecast {
+	h := f.hierarchy
+	frames := map[string]models.FeatureFrame{f.workload: frame}
+	base := map[string]models.Forecast{f.workload: total}
+	for i, node := range h.nodes {
+		nodeFrame, forecast, err := f.forecastNode(ctx, node, &h.trainers[i])
+		if err != nil {
+			if f.metrics != nil {
+				f.metrics.RecordError("hierarchy", "node_failed")
+			}
+			f.logger.Warn("hierarchy node forecast failed, skipping reconciliation", "node", node.Name, "error", err)
+			return total
+		}
+		frames[node.Name] = nodeFrame
+		base[node.Name] = forecast
+	}
+
+	f.recordResiduals(frames, base)
+
+	opts := reconcile.Options{Method: h.method, Residuals: h.residuals, Variances: make(map[string]float64)}
+	for node, forecast := range base {
+		if v, ok := errorVariance(forecast); ok {
+			opts.Variances[node] = v
+		}
+	}
+	reconciled, err := reconcile.ReconcileForecasts(h.tree, base, opts)
+	if err != nil {
+		if f.metrics != nil {
+			f.metrics.RecordError("hierarchy", "reconcile_failed")
+		}
+		f.logger.Warn("forecast reconciliation failed", "method", h.method, "error", err)
+		return total
+	}
+
+	for _, node := range h.nodes {
+		forecast := reconciled[node.Name]
+		snapshot := storage.Snapshot{
+			Workload:       f.workload + "/" + node.Name,
+			Metric:         forecast.Metric,
+			GeneratedAt:    time.Now(),
+			StepSeconds:    int(f.step.Seconds()),
+			HorizonSeconds: int(f.horizon.Seconds()),
+			Values:         forecast.Values,
+			Quantiles:      forecast.Quantiles,
+			Model:          node.Model.Name(),
+		}
+		if err := f.store.Put(snapshot); err != nil {
+			if f.metrics != nil {
+				f.metrics.RecordError("store", "put_failed")
+			}
+			f.logger.Warn("failed to store hierarchy node snapshot", "node", node.Name, "error", err)
+		}
+	}
+
+	result := reconciled[f.workload]
+	if len(total.Values) > 0 && len(result.Values) > 0 {
+		f.logger.Debug("reconciled forecast",
+			"method", h.method,
+			"nodes", len(h.nodes),
+			"residuals", len(h.residuals),
+			"base_first", total.Values[0],
+			"reconciled_first", result.Values[0],
+		)
+	}
+	return result
+}
+
+// forecastNode collects, trains through trainer and predicts one hierarchy
+// node. Training failures are ignored as for the workload's own model.
+func (f *Forecaster) forecastNode(ctx context.Context, node HierarchyNode, trainer *models.Trainer) (models.FeatureFrame, models.Forecast, error) {
+	df, err := node.Adapter.Collect(ctx, int(f.window.Seconds()))
+	if err != nil {
+		return models.FeatureFrame{}, models.Forecast{}, fmt.Errorf("collect: %w", err)
+	}
+	frame, err := f.builder.BuildFeatures(*df)
+	if err != nil {
+		return models.FeatureFrame{}, models.Forecast{}, fmt.Errorf("build features: %w", err)
+	}
+	result, err := trainer.Train(ctx, node.Model, frame, time.Now())
+	if err != nil {
+		f.logger.Debug("hierarchy node training skipped or failed", "node", node.Name, "error", err)
+	}
+	if cp := result.ChangePoint; cp != nil && result.NewChangePoint {
+		f.logger.Info("hierarchy node change point detected",
+			"node", node.Name,
+			"time", time.Unix(int64(cp.Timestamp), 0).UTC(),
+			"kind", cp.Kind,
+		)
+	}
+	forecast, err := node.Model.Predict(ctx, frame)
+	if err != nil {
+		return models.FeatureFrame{}, models.Forecast{}, fmt.Errorf("predict: %w", err)
+	}
+	return frame, forecast, nil
+}
+
+// recordResiduals compares the first forecast step of the previous tick with
+// the values that have been collected since, appending one row of errors when
+// every node has a value for it, and remembers this tick's first steps.
+func (f *Forecaster) recordResiduals(frames map[string]models.FeatureFrame, base map[string]models.Forecast) {
+	h := f.hierarchy
+	nodes := h.tree.Nodes()
+
+	if h.pending != nil {
+		row := make([]float64, len(nodes))
+		complete := true
+		for i, node := range nodes {
+			actual, ok := valueAt(frames[node], h.pendingTs, f.step.Seconds()/2)
+			if !ok {
+				complete = false
+				break
+			}
+			row[i] = actual - h.pending[i]
+		}
+		if complete {
+			h.residuals = append(h.residuals, row)
+			if len(h.residuals) > maxHierarchyResiduals {
+				h.residuals = h.residuals[len(h.residuals)-maxHierarchyResiduals:]
+			}
+		}
+	}
+
+	h.pending = nil
+	rows := frames[f.workload].Rows
+	if len(rows) == 0 {
+		return
+	}
+	last, ok := rows[len(rows)-1]["timestamp"]
+	if !ok {
+		return
+	}
+	pending := make([]float64, len(nodes))
+	for i, node := range nodes {
+		if len(base[node].Values) == 0 {
+			return
+		}
+		pending[i] = base[node].Values[0]
+	}
+	h.pending, h.pendingTs = pending, last+f.step.Seconds()
+}
+
+// valueAt returns the value of the row of frame within tolerance seconds of ts.
+func valueAt(frame models.FeatureFrame, ts, tolerance float64) (float64, bool) {
+	for i := len(frame.Rows) - 1; i >= 0; i-- {
+		row := frame.Rows[i]
+		rowTs, ok := row["timestamp"]
+		if !ok || math.Abs(rowTs-ts) > tolerance {
+			continue
+		}
+		v, ok := row["value"]
+		return v, ok
+	}
+	return 0, false
+}
+
+// errorVariance estimates a forecast's error variance from its 80% interval,
+// averaged over the horizon, assuming Gaussian errors.
+func errorVariance(forecast models.Forecast) (float64, bool) {
+	lower, ok := forecast.Quantile(0.1)
+	if !ok {
+		return 0, false
+	}
+	upper, ok := forecast.Quantile(0.9)
+	if !ok || len(upper) == 0 {
+		return 0, false
+	}
+	sum := 0.0
+	for t := range upper {
+		sd := (upper[t] - lower[t]) / 2.5631 // p90 - p10 of a standard normal
+		sum += sd * sd
+	}
+	return sum / float64(len(upper)), true
+}
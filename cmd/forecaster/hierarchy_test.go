@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HatiCode/kedastral/pkg/adapters"
+	"github.com/HatiCode/kedastral/pkg/capacity"
+	"github.com/HatiCode/kedastral/pkg/features"
+	"github.com/HatiCode/kedastral/pkg/models"
+	"github.com/HatiCode/kedastral/pkg/reconcile"
+	"github.com/HatiCode/kedastral/pkg/storage"
+)
+
+// minuteAdapter returns a constant series at 60s spacing that grows by one
+// row per Collect, as if a minute passed between ticks.
+type minuteAdapter struct {
+	value float64
+	calls int
+	err   error
+}
+
+func (a *minuteAdapter) Collect(ctx context.Context, windowSeconds int) (*adapters.DataFrame, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	a.calls++
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	df := &adapters.DataFrame{}
+	for i := range 10 + a.calls {
+		ts := start.Add(time.Duration(i) * time.Minute).Format(time.RFC3339)
+		df.Rows = append(df.Rows, adapters.Row{"ts": ts, "value": a.value})
+	}
+	return df, nil
+}
+
+func (a *minuteAdapter) Name() string { return "minute" }
+
+// constantModel forecasts its value at every step.
+type constantModel struct {
+	value float64
+}
+
+func (c constantModel) Train(ctx context.Context, history models.FeatureFrame) error { return nil }
+
+func (c constantModel) Predict(ctx context.Context, features models.FeatureFrame) (models.Forecast, error) {
+	return models.Forecast{Metric: "rps", StepSec: 60, Horizon: 180, Values: []float64{c.value, c.value, c.value}}, nil
+}
+
+func (c constantModel) Name() string { return "constant" }
+
+func newHierarchyForecaster(t *testing.T, store storage.Store) *Forecaster {
+	t.Helper()
+	policy := &capacity.Policy{TargetPerPod: 1, MinReplicas: 1, MaxReplicas: 100}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return New("api", &minuteAdapter{value: 10}, constantModel{value: 10}, features.NewBuilder(),
+		store, policy, 3*time.Minute, time.Minute, 30*time.Minute, 0, nil, logger, nil)
+}
+
+func TestForecaster_Tick_Hierarchy(t *testing.T) {
+	store := storage.NewMemoryStore()
+	f := newHierarchyForecaster(t, store)
+
+	// The regions' forecasts add up to 8 while the total forecasts 10.
+	err := f.SetHierarchy([]HierarchyNode{
+		{Name: "eu", Adapter: &minuteAdapter{value: 4}, Model: constantModel{value: 4}},
+		{Name: "us", Adapter: &minuteAdapter{value: 4}, Model: constantModel{value: 4}},
+	}, reconcile.BottomUp)
+	if err != nil {
+		t.Fatalf("SetHierarchy() error = %v", err)
+	}
+
+	for range 2 {
+		if err := f.Tick(context.Background()); err != nil {
+			t.Fatalf("Tick() error = %v", err)
+		}
+	}
+
+	total, found, _ := store.GetLatest("api")
+	if !found {
+		t.Fatal("no snapshot for the workload")
+	}
+	if !slices.Equal(total.Values, []float64{8, 8, 8}) {
+		t.Errorf("total Values = %v, want the reconciled [8 8 8]", total.Values)
+	}
+	if n := len(total.DesiredReplicas); n == 0 || total.DesiredReplicas[n-1] != 8 {
+		t.Errorf("DesiredReplicas = %v, want planned from the reconciled total", total.DesiredReplicas)
+	}
+
+	eu, found, _ := store.GetLatest("api/eu")
+	if !found {
+		t.Fatal("no snapshot for node eu")
+	}
+	if !slices.Equal(eu.Values, []float64{4, 4, 4}) || eu.DesiredReplicas != nil {
+		t.Errorf("eu snapshot = %v with replicas %v, want [4 4 4] without replicas", eu.Values, eu.DesiredReplicas)
+	}
+
+	// The second tick observed the first tick's one-step forecasts.
+	if got := len(f.hierarchy.residuals); got != 1 {
+		t.Fatalf("residuals = %d rows, want 1", got)
+	}
+	if got, want := f.hierarchy.residuals[0], []float64{0, 0, 0}; !slices.Equal(got, want) {
+		t.Errorf("residuals[0] = %v, want %v", got, want)
+	}
+}
+
+func TestForecaster_Tick_HierarchyNodeFails(t *testing.T) {
+	store := storage.NewMemoryStore()
+	f := newHierarchyForecaster(t, store)
+	err := f.SetHierarchy([]HierarchyNode{
+		{Name: "eu", Adapter: &minuteAdapter{value: 4}, Model: constantModel{value: 4}},
+		{Name: "us", Adapter: &minuteAdapter{err: errors.New("unavailable")}, Model: constantModel{value: 4}},
+	}, reconcile.MinT)
+	if err != nil {
+		t.Fatalf("SetHierarchy() error = %v", err)
+	}
+
+	if err := f.Tick(context.Background()); err != nil {
+		t.Fatalf("Tick() error = %v", err)
+	}
+	total, _, _ := store.GetLatest("api")
+	if !slices.Equal(total.Values, []float64{10, 10, 10}) {
+		t.Errorf("total Values = %v, want the unreconciled [10 10 10]", total.Values)
+	}
+}
+
+func TestForecaster_Tick_HierarchyNodeTrainer(t *testing.T) {
+	policy := &capacity.Policy{TargetPerPod: 1, MinReplicas: 1, MaxReplicas: 100}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	f := New("api", &minuteAdapter{value: 10}, constantModel{value: 10}, features.NewBuilder(),
+		storage.NewMemoryStore(), policy, 3*time.Minute, time.Minute, 30*time.Minute, time.Hour, nil, logger, nil)
+
+	node := &recordingModel{Model: constantModel{value: 10}}
+	if err := f.SetHierarchy([]HierarchyNode{
+		{Name: "eu", Adapter: &minuteAdapter{value: 10}, Model: node},
+	}, reconcile.BottomUp); err != nil {
+		t.Fatalf("SetHierarchy() error = %v", err)
+	}
+
+	for range 3 {
+		if err := f.Tick(context.Background()); err != nil {
+			t.Fatalf("Tick() error = %v", err)
+		}
+	}
+
+	// Like the workload's model, the node is trained once and then only
+	// updated with the row each tick adds.
+	if node.trains != 1 || len(node.updates) != 2 {
+		t.Errorf("node trained %d times and updated %d times, want 1 and 2", node.trains, len(node.updates))
+	}
+}
+
+func TestForecaster_SetHierarchy_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes []HierarchyNode
+		want  string
+	}{
+		{"duplicate", []HierarchyNode{{Name: "eu"}, {Name: "eu"}}, "duplicate"},
+		{"workload name", []HierarchyNode{{Name: "api"}}, "duplicate"},
+		{"unknown parent", []HierarchyNode{{Name: "eu-checkout", Parent: "eu"}}, "unknown parent"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newHierarchyForecaster(t, storage.NewMemoryStore())
+			err := f.SetHierarchy(tt.nodes, reconcile.MinT)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("SetHierarchy() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadHierarchyFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	specs, err := loadHierarchyFile(write("ok.json", `{"nodes": [
+		{"name": "eu", "query": "sum(eu)"},
+		{"name": "eu-checkout", "parent": "eu", "query": "sum(eu_checkout)"}
+	]}`))
+	if err != nil {
+		t.Fatalf("loadHierarchyFile() error = %v", err)
+	}
+	want := []hierarchySpec{{"eu", "", "sum(eu)"}, {"eu-checkout", "eu", "sum(eu_checkout)"}}
+	if !slices.Equal(specs, want) {
+		t.Errorf("loadHierarchyFile() = %v, want %v", specs, want)
+	}
+
+	for name, content := range map[string]string{
+		"invalid.json": `{"nodes": [`,
+		"noquery.json": `{"nodes": [{"name": "eu"}]}`,
+		"noname.json":  `{"nodes": [{"query": "sum(x)"}]}`,
+	} {
+		if _, err := loadHierarchyFile(write(name, content)); err == nil {
+			t.Errorf("loadHierarchyFile(%s) error = nil, want error", name)
+		}
+	}
+	if _, err := loadHierarchyFile(filepath.Join(dir, "missing.json")); err == nil {
+		t.Errorf("loadHierarchyFile(missing) error = nil, want error")
+	}
+}
+
+func TestErrorVariance(t *testing.T) {
+	forecast := models.Forecast{
+		Values:    []float64{10, 10},
+		Quantiles: map[string][]float64{"p10": {7.4369, 4.8738}, "p90": {12.5631, 15.1262}},
+	}
+	// Standard deviations 2 and 4.
+	got, ok := errorVariance(forecast)
+	if !ok || got < 9.99 || got > 10.01 {
+		t.Errorf("errorVariance() = %v, %v; want 10, true", got, ok)
+	}
+	if _, ok := errorVariance(models.Forecast{Values: []float64{1}}); ok {
+		t.Errorf("errorVariance() without quantiles ok = true, want false")
+	}
+}
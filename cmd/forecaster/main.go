@@ -3,12 +3,16 @@
 // The forecaster runs a continuous forecast loop that:
 //  1. Collects historical metrics from Prometheus
 //  2. Predicts future workload using a forecasting model
-//  3. Calculates desired replica counts using capacity planning policies
-//  4. Stores forecast snapshots for the scaler to consume
-//  5. Exposes snapshots via HTTP API at /forecast/current
+//  3. Optionally reconciles it with forecasts of the series below the workload
+//     total (--hierarchy-file), e.g. per region or endpoint
+//  4. Calculates desired replica counts using capacity planning policies
+//  5. Stores forecast snapshots for the scaler to consume
+//  6. Exposes snapshots via HTTP API at /forecast/current
 //
 // The forecaster serves an HTTP API on port 8081 (configurable) providing:
 //   - GET /forecast/current?workload=<name> - Retrieve latest forecast snapshot
+//   - GET /forecast/accuracy?workload=<name> - Rolling accuracy of past forecasts
+//   - GET /debug/forecast?workload=<name> - Latest forecast explained step by step
 //   - GET /healthz - Health check endpoint
 //   - GET /metrics - Prometheus metrics endpoint
 //
@@ -37,6 +41,8 @@
 //	INTERVAL       - Forecast loop interval (default: 30s)
 //	LOG_LEVEL      - Logging level: debug, info, warn, error (default: info)
 //	LOG_FORMAT     - Logging format: text, json (default: text)
+//	HIERARCHY_FILE - JSON file of series reconciled with the workload total
+//	RECONCILE_METHOD - bottom-up, top-down, ols or mint (default: mint)
 package main
 
 import (
@@ -57,6 +63,7 @@ import (
 	"github.com/HatiCode/kedastral/pkg/capacity"
 	"github.com/HatiCode/kedastral/pkg/features"
 	"github.com/HatiCode/kedastral/pkg/httpx"
+	"github.com/HatiCode/kedastral/pkg/reconcile"
 )
 
 // version is set via ldflags at build time
@@ -101,6 +108,30 @@ func main() {
 		MaxReplicas:           cfg.MaxReplicas,
 		UpMaxFactorPerStep:    cfg.UpMaxFactorPerStep,
 		DownMaxPercentPerStep: cfg.DownMaxPercentPerStep,
+		Quantile:              cfg.PlanQuantile,
+		BurstProbability:      cfg.BurstProbability,
+
+		ScaleDownStabilizationSeconds: int(cfg.DownStabilization.Seconds()),
+		ScaleUpStabilizationSeconds:   int(cfg.UpStabilization.Seconds()),
+		ScaleDownCooldownSeconds:      int(cfg.DownCooldown.Seconds()),
+		ScaleUpCooldownSeconds:        int(cfg.UpCooldown.Seconds()),
+	}
+	if cfg.CapacityCurve != "" {
+		curve, err := capacity.ParseCurve(cfg.CapacityCurve)
+		if err != nil {
+			logger.Error("invalid capacity curve", "error", err)
+			os.Exit(1)
+		}
+		policy.Curve = curve
+	}
+	if cfg.OverridesFile != "" {
+		overrides, err := capacity.LoadOverrides(cfg.OverridesFile)
+		if err != nil {
+			logger.Error("failed to load policy overrides", "error", err)
+			os.Exit(1)
+		}
+		policy.Overrides = overrides
+		logger.Info("policy overrides enabled", "overrides", len(overrides))
 	}
 
 	f := New(
@@ -113,12 +144,45 @@ func main() {
 		cfg.Horizon,
 		cfg.Step,
 		cfg.Window,
+		cfg.FullTrainInterval,
+		models.ChangePoints(cfg),
 		logger,
 		metrics.New(cfg.Workload),
 	)
 
+	if cfg.HierarchyFile != "" {
+		method, err := reconcile.ParseMethod(cfg.ReconcileMethod)
+		if err != nil {
+			logger.Error("invalid reconciliation method", "error", err)
+			os.Exit(1)
+		}
+		specs, err := loadHierarchyFile(cfg.HierarchyFile)
+		if err != nil {
+			logger.Error("failed to load hierarchy", "error", err)
+			os.Exit(1)
+		}
+		nodes := make([]HierarchyNode, 0, len(specs))
+		for _, spec := range specs {
+			nodes = append(nodes, HierarchyNode{
+				Name:   spec.Name,
+				Parent: spec.Parent,
+				Adapter: &adapters.PrometheusAdapter{
+					ServerURL:   cfg.PromURL,
+					Query:       spec.Query,
+					StepSeconds: int(cfg.Step.Seconds()),
+				},
+				Model: models.New(cfg, logger),
+			})
+		}
+		if err := f.SetHierarchy(nodes, method); err != nil {
+			logger.Error("invalid hierarchy", "file", cfg.HierarchyFile, "error", err)
+			os.Exit(1)
+		}
+		logger.Info("hierarchical reconciliation enabled", "nodes", len(nodes), "method", method)
+	}
+
 	staleAfter := 2 * cfg.Interval // Snapshot is stale if older than 2x the interval
-	mux := router.SetupRoutes(store, staleAfter, logger)
+	mux := router.SetupRoutes(store, f.GetAccuracy(), staleAfter, logger)
 	httpServer := httpx.NewServer(cfg.Listen, mux, logger)
 
 	ctx, cancel := context.WithCancel(context.Background())
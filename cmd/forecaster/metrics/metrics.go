@@ -12,13 +12,25 @@
 //   - kedastral_forecast_age_seconds: Gauge of current forecast age
 //   - kedastral_desired_replicas: Gauge of current desired replica count
 //   - kedastral_errors_total: Counter of errors by component and reason
+//   - kedastral_ensemble_weight: Gauge of each ensemble member's weight
+//   - kedastral_model_info: Always 1, labelled with the active model name (e.g. "arima(2,1,0)")
+//   - kedastral_changepoint_timestamp_seconds: Gauge of the start of the regime the model was last trained on
+//   - kedastral_changepoints_total: Counter of detected change points by kind (level, variance)
+//   - kedastral_forecast_mae, kedastral_forecast_mape, kedastral_forecast_smape, kedastral_forecast_bias:
+//     Gauges of rolling forecast errors against realised values, by lead_seconds
+//   - kedastral_forecast_interval_coverage: Gauge of the share of realised values within each
+//     prediction interval, by lead_seconds and interval (nominal coverage, e.g. "80")
 //
 // All metrics include the workload label for multi-workload deployments.
 package metrics
 
 import (
+	"strconv"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/HatiCode/kedastral/pkg/accuracy"
 )
 
 // Metrics holds all Prometheus metrics for the forecaster.
@@ -29,6 +41,15 @@ type Metrics struct {
 	ForecastAgeSeconds     prometheus.Gauge
 	DesiredReplicas        prometheus.Gauge
 	ErrorsTotal            *prometheus.CounterVec
+	EnsembleWeight         *prometheus.GaugeVec
+	ModelInfo              *prometheus.GaugeVec
+	ChangePointTimestamp   prometheus.Gauge
+	ChangePointsTotal      *prometheus.CounterVec
+	ForecastMAE            *prometheus.GaugeVec
+	ForecastMAPE           *prometheus.GaugeVec
+	ForecastSMAPE          *prometheus.GaugeVec
+	ForecastBias           *prometheus.GaugeVec
+	IntervalCoverage       *prometheus.GaugeVec
 }
 
 // New creates and registers all Prometheus metrics.
@@ -86,6 +107,78 @@ func New(workload string) *Metrics {
 				"workload": workload,
 			},
 		}, []string{"component", "reason"}),
+
+		EnsembleWeight: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "kedastral_ensemble_weight",
+			Help: "Current weight of each ensemble member",
+			ConstLabels: prometheus.Labels{
+				"workload": workload,
+			},
+		}, []string{"member"}),
+
+		ModelInfo: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "kedastral_model_info",
+			Help: "Active forecasting model, including its fitted order (always 1)",
+			ConstLabels: prometheus.Labels{
+				"workload": workload,
+			},
+		}, []string{"model"}),
+
+		ChangePointTimestamp: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: "kedastral_changepoint_timestamp_seconds",
+			Help: "Unix time of the most recent change point in the training window",
+			ConstLabels: prometheus.Labels{
+				"workload": workload,
+			},
+		}),
+
+		ChangePointsTotal: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "kedastral_changepoints_total",
+			Help: "Total number of detected change points by kind",
+			ConstLabels: prometheus.Labels{
+				"workload": workload,
+			},
+		}, []string{"kind"}),
+
+		ForecastMAE: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "kedastral_forecast_mae",
+			Help: "Rolling mean absolute error of forecasts against realised values",
+			ConstLabels: prometheus.Labels{
+				"workload": workload,
+			},
+		}, []string{"lead_seconds"}),
+
+		ForecastMAPE: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "kedastral_forecast_mape",
+			Help: "Rolling mean absolute percentage error of forecasts, in percent",
+			ConstLabels: prometheus.Labels{
+				"workload": workload,
+			},
+		}, []string{"lead_seconds"}),
+
+		ForecastSMAPE: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "kedastral_forecast_smape",
+			Help: "Rolling symmetric mean absolute percentage error of forecasts, in percent",
+			ConstLabels: prometheus.Labels{
+				"workload": workload,
+			},
+		}, []string{"lead_seconds"}),
+
+		ForecastBias: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "kedastral_forecast_bias",
+			Help: "Rolling mean of forecast minus realised value (positive = over-forecasting)",
+			ConstLabels: prometheus.Labels{
+				"workload": workload,
+			},
+		}, []string{"lead_seconds"}),
+
+		IntervalCoverage: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "kedastral_forecast_interval_coverage",
+			Help: "Share of realised values within the forecast prediction interval",
+			ConstLabels: prometheus.Labels{
+				"workload": workload,
+			},
+		}, []string{"lead_seconds", "interval"}),
 	}
 }
 
@@ -114,6 +207,41 @@ func (m *Metrics) SetDesiredReplicas(replicas int) {
 	m.DesiredReplicas.Set(float64(replicas))
 }
 
+// SetEnsembleWeight sets the current weight of an ensemble member.
+func (m *Metrics) SetEnsembleWeight(member string, weight float64) {
+	m.EnsembleWeight.WithLabelValues(member).Set(weight)
+}
+
+// SetModel records the active model name, replacing any previous one.
+func (m *Metrics) SetModel(name string) {
+	m.ModelInfo.Reset()
+	m.ModelInfo.WithLabelValues(name).Set(1)
+}
+
+// RecordChangePoint records a newly detected change point.
+func (m *Metrics) RecordChangePoint(kind string, timestamp float64) {
+	m.ChangePointTimestamp.Set(timestamp)
+	m.ChangePointsTotal.WithLabelValues(kind).Inc()
+}
+
+// SetAccuracy publishes the accuracy statistics of every horizon step that
+// has been joined with realised values.
+func (m *Metrics) SetAccuracy(report accuracy.Report) {
+	for _, step := range report.Steps {
+		if step.Samples == 0 {
+			continue
+		}
+		lead := strconv.Itoa(step.LeadSeconds)
+		m.ForecastMAE.WithLabelValues(lead).Set(step.MAE)
+		m.ForecastMAPE.WithLabelValues(lead).Set(step.MAPE)
+		m.ForecastSMAPE.WithLabelValues(lead).Set(step.SMAPE)
+		m.ForecastBias.WithLabelValues(lead).Set(step.Bias)
+		for interval, coverage := range step.Coverage {
+			m.IntervalCoverage.WithLabelValues(lead, interval).Set(coverage)
+		}
+	}
+}
+
 // RecordError increments the error counter.
 func (m *Metrics) RecordError(component, reason string) {
 	m.ErrorsTotal.WithLabelValues(component, reason).Inc()
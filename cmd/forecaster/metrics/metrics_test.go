@@ -5,6 +5,8 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/HatiCode/kedastral/pkg/accuracy"
 )
 
 func TestNew(t *testing.T) {
@@ -192,6 +194,53 @@ func TestRecordError_Increment(t *testing.T) {
 	}
 }
 
+func TestSetModel(t *testing.T) {
+	m := New("test-set-model")
+
+	m.SetModel("arima(1,1,1)")
+	m.SetModel("arima(2,0,1)")
+
+	if count := testutil.CollectAndCount(m.ModelInfo); count != 1 {
+		t.Errorf("expected 1 model info series after a change, got %d", count)
+	}
+	if got := testutil.ToFloat64(m.ModelInfo.WithLabelValues("arima(2,0,1)")); got != 1 {
+		t.Errorf("model info for current model = %v, want 1", got)
+	}
+}
+
+func TestRecordChangePoint(t *testing.T) {
+	m := New("test-record-changepoint")
+
+	m.RecordChangePoint("level", 1000)
+	m.RecordChangePoint("variance", 2000)
+
+	if got := testutil.ToFloat64(m.ChangePointTimestamp); got != 2000 {
+		t.Errorf("change point timestamp = %v, want 2000", got)
+	}
+	if got := testutil.ToFloat64(m.ChangePointsTotal.WithLabelValues("level")); got != 1 {
+		t.Errorf("level change points = %v, want 1", got)
+	}
+}
+
+func TestSetAccuracy(t *testing.T) {
+	m := New("test-set-accuracy")
+
+	m.SetAccuracy(accuracy.Report{Steps: []accuracy.StepAccuracy{
+		{Step: 1, LeadSeconds: 60, Samples: 10, MAE: 4, Bias: -1, Coverage: map[string]float64{"80": 0.7}},
+		{Step: 2, LeadSeconds: 120},
+	}})
+
+	if got := testutil.ToFloat64(m.ForecastMAE.WithLabelValues("60")); got != 4 {
+		t.Errorf("MAE at 60s = %v, want 4", got)
+	}
+	if got := testutil.ToFloat64(m.IntervalCoverage.WithLabelValues("60", "80")); got != 0.7 {
+		t.Errorf("80%% coverage at 60s = %v, want 0.7", got)
+	}
+	if count := testutil.CollectAndCount(m.ForecastMAE); count != 1 {
+		t.Errorf("MAE series = %d, want 1 (steps without samples are skipped)", count)
+	}
+}
+
 func TestMetrics_MultipleObservations(t *testing.T) {
 	m := New("test-metrics-multiple-observations")
 
@@ -1,34 +1,418 @@
 package models
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/HatiCode/kedastral/cmd/forecaster/config"
 	"github.com/HatiCode/kedastral/pkg/models"
 )
 
 func New(cfg *config.Config, logger *slog.Logger) models.Model {
+	return newModel(cfg.Model, cfg, logger)
+}
+
+// ChangePoints returns the change-point detection options, or nil when
+// detection is disabled.
+func ChangePoints(cfg *config.Config) *models.ChangePointOptions {
+	if cfg.ChangePointMinSegment <= 0 {
+		return nil
+	}
+	return &models.ChangePointOptions{
+		MinSegment: max(int(cfg.ChangePointMinSegment/cfg.Step), 2),
+		Penalty:    cfg.ChangePointPenalty,
+	}
+}
+
+func newModel(name string, cfg *config.Config, logger *slog.Logger) models.Model {
 	stepSec := int(cfg.Step.Seconds())
 	horizonSec := int(cfg.Horizon.Seconds())
 
-	switch cfg.Model {
+	switch name {
 	case "arima":
-		logger.Info("initializing ARIMA model",
-			"p", cfg.ARIMA_P,
-			"d", cfg.ARIMA_D,
-			"q", cfg.ARIMA_Q,
+		var seasonal models.SeasonalOrder
+		if cfg.ARIMA_Season > 0 {
+			if cfg.ARIMA_Season%cfg.Step != 0 {
+				logger.Warn("ARIMA season is not a multiple of the step, rounding down",
+					"season", cfg.ARIMA_Season,
+					"step", cfg.Step,
+				)
+			}
+			seasonal = models.SeasonalOrder{
+				P:      cfg.ARIMA_SP,
+				D:      cfg.ARIMA_SD,
+				Q:      cfg.ARIMA_SQ,
+				Period: int(cfg.ARIMA_Season / cfg.Step),
+			}
+		}
+		events, err := models.ParseEvents(cfg.ARIMA_Events)
+		if err != nil {
+			logger.Error("invalid ARIMA events", "error", err)
+			os.Exit(1)
+		}
+		var regressors []string
+		for _, name := range strings.Split(cfg.ARIMA_Regressors, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				regressors = append(regressors, name)
+			}
+		}
+		exog := models.Exogenous{Columns: regressors, Events: events}
+
+		if cfg.ARIMA_Season > 0 {
+			logger.Info("initializing SARIMA model",
+				"p", cfg.ARIMA_P,
+				"d", cfg.ARIMA_D,
+				"q", cfg.ARIMA_Q,
+				"seasonal_p", seasonal.P,
+				"seasonal_d", seasonal.D,
+				"seasonal_q", seasonal.Q,
+				"season", cfg.ARIMA_Season,
+				"period_steps", seasonal.Period,
+				"regressors", regressors,
+				"events", len(events),
+			)
+		} else {
+			logger.Info("initializing ARIMA model",
+				"p", cfg.ARIMA_P,
+				"d", cfg.ARIMA_D,
+				"q", cfg.ARIMA_Q,
+				"regressors", regressors,
+				"events", len(events),
+			)
+		}
+		model := models.NewARIMAXModel(cfg.Metric, stepSec, horizonSec, cfg.ARIMA_P, cfg.ARIMA_D, cfg.ARIMA_Q, seasonal, exog)
+		model.SetOrderSearchInterval(cfg.ARIMA_OrderSearch)
+		return model
+
+	case "prophet":
+		events, err := models.ParseEvents(cfg.ProphetEvents)
+		if err != nil {
+			logger.Error("invalid prophet events", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("initializing prophet model",
+			"changepoints", cfg.ProphetChangepoints,
+			"daily_order", cfg.ProphetDailyOrder,
+			"weekly_order", cfg.ProphetWeeklyOrder,
+			"events", len(events),
 		)
-		return models.NewARIMAModel(cfg.Metric, stepSec, horizonSec, cfg.ARIMA_P, cfg.ARIMA_D, cfg.ARIMA_Q)
+		return models.NewProphetModel(cfg.Metric, stepSec, horizonSec, models.ProphetOptions{
+			Changepoints: cfg.ProphetChangepoints,
+			DailyOrder:   cfg.ProphetDailyOrder,
+			WeeklyOrder:  cfg.ProphetWeeklyOrder,
+			Events:       events,
+		})
+
+	case "regression":
+		strategy := models.RegressionStrategy(cfg.RegressionStrategy)
+		if strategy != models.RegressionDirect && strategy != models.RegressionRecursive {
+			logger.Error("invalid regression strategy", "strategy", cfg.RegressionStrategy)
+			os.Exit(1)
+		}
+		events, err := models.ParseEvents(cfg.RegressionEvents)
+		if err != nil {
+			logger.Error("invalid regression events", "error", err)
+			os.Exit(1)
+		}
+		var features []string
+		for _, name := range strings.Split(cfg.RegressionFeatures, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				features = append(features, name)
+			}
+		}
+		logger.Info("initializing regression model",
+			"lags", cfg.RegressionLags,
+			"features", features,
+			"alpha", cfg.RegressionAlpha,
+			"l1_ratio", cfg.RegressionL1Ratio,
+			"strategy", strategy,
+			"events", len(events),
+		)
+		return models.NewRegressionModel(cfg.Metric, stepSec, horizonSec, models.RegressionOptions{
+			Lags:     cfg.RegressionLags,
+			Features: features,
+			Alpha:    cfg.RegressionAlpha,
+			L1Ratio:  cfg.RegressionL1Ratio,
+			Strategy: strategy,
+			Events:   events,
+		})
+
+	case "remote":
+		var fallback models.Model
+		if fb := strings.TrimSpace(cfg.RemoteFallback); fb != "" {
+			if fb == "remote" {
+				logger.Error("remote model cannot fall back to itself")
+				os.Exit(1)
+			}
+			fallback = newModel(fb, cfg, logger)
+		}
+		logger.Info("initializing remote model",
+			"url", cfg.RemoteURL,
+			"timeout", cfg.RemoteTimeout,
+			"fallback", cfg.RemoteFallback,
+		)
+		return models.NewRemoteModel(cfg.Metric, stepSec, horizonSec, models.RemoteOptions{
+			URL:      cfg.RemoteURL,
+			Timeout:  cfg.RemoteTimeout,
+			Fallback: fallback,
+			OnFallback: func(op string, err error) {
+				logger.Warn("model plugin failed, using fallback model", "op", op, "error", err)
+			},
+		})
+
+	case "wasm":
+		module, err := os.ReadFile(cfg.WASMModule)
+		if err != nil {
+			logger.Error("failed to read wasm plugin", "path", cfg.WASMModule, "error", err)
+			os.Exit(1)
+		}
+		pluginName := strings.TrimSuffix(filepath.Base(cfg.WASMModule), filepath.Ext(cfg.WASMModule))
+		model, err := models.NewWASMModel(context.Background(), cfg.Metric, stepSec, horizonSec, module, models.WASMOptions{
+			Name:           pluginName,
+			MemoryLimitMiB: cfg.WASMMemoryLimit,
+			Timeout:        cfg.WASMTimeout,
+		})
+		if err != nil {
+			logger.Error("invalid wasm plugin", "path", cfg.WASMModule, "error", err)
+			os.Exit(1)
+		}
+		logger.Info("initializing wasm model",
+			"path", cfg.WASMModule,
+			"memory_limit_mib", cfg.WASMMemoryLimit,
+			"timeout", cfg.WASMTimeout,
+		)
+		return model
+
+	case "seasonal-naive":
+		method := models.SeasonalNaiveMethod(cfg.NaiveMethod)
+		if method != models.SeasonalNaiveLast && method != models.SeasonalNaiveAverage && method != models.SeasonalNaiveLevel {
+			logger.Error("invalid seasonal naive method", "method", cfg.NaiveMethod)
+			os.Exit(1)
+		}
+		var periods []int
+		for _, spec := range strings.Split(cfg.NaivePeriods, ",") {
+			if spec = strings.TrimSpace(spec); spec == "" {
+				continue
+			}
+			period, err := time.ParseDuration(spec)
+			if err != nil || period < cfg.Step {
+				logger.Error("invalid seasonal naive period", "period", spec)
+				os.Exit(1)
+			}
+			periods = append(periods, int(period.Seconds()))
+		}
+		logger.Info("initializing seasonal naive model",
+			"method", method,
+			"periods", cfg.NaivePeriods,
+			"seasons", cfg.NaiveSeasons,
+			"level_window", cfg.NaiveLevelWindow,
+		)
+		return models.NewSeasonalNaiveModel(cfg.Metric, stepSec, horizonSec, models.SeasonalNaiveOptions{
+			Method:      method,
+			Periods:     periods,
+			Seasons:     cfg.NaiveSeasons,
+			LevelWindow: int(cfg.NaiveLevelWindow.Seconds()),
+		})
+
+	case "statespace":
+		var period int
+		if cfg.StateSpaceSeason > 0 {
+			if cfg.StateSpaceSeason%cfg.Step != 0 {
+				logger.Warn("state space season is not a multiple of the step, rounding down",
+					"season", cfg.StateSpaceSeason,
+					"step", cfg.Step,
+				)
+			}
+			period = int(cfg.StateSpaceSeason / cfg.Step)
+		}
+		logger.Info("initializing state space model",
+			"trend", cfg.StateSpaceTrend,
+			"season", cfg.StateSpaceSeason,
+			"period_steps", period,
+			"harmonics", cfg.StateSpaceHarmonics,
+		)
+		return models.NewStateSpaceModel(cfg.Metric, stepSec, horizonSec, models.StateSpaceOptions{
+			Trend:     cfg.StateSpaceTrend,
+			Period:    period,
+			Harmonics: cfg.StateSpaceHarmonics,
+		})
+
+	case "intermittent":
+		method := models.IntermittentMethod(cfg.IntermittentMethod)
+		if method != models.IntermittentCroston && method != models.IntermittentSBA && method != models.IntermittentTSB {
+			logger.Error("invalid intermittent method", "method", cfg.IntermittentMethod)
+			os.Exit(1)
+		}
+		logger.Info("initializing intermittent-demand model",
+			"method", method,
+			"alpha", cfg.IntermittentAlpha,
+			"beta", cfg.IntermittentBeta,
+			"threshold", cfg.IntermittentThreshold,
+		)
+		return models.NewIntermittentModel(cfg.Metric, stepSec, horizonSec, models.IntermittentOptions{
+			Method:    method,
+			Alpha:     cfg.IntermittentAlpha,
+			Beta:      cfg.IntermittentBeta,
+			Threshold: cfg.IntermittentThreshold,
+		})
 
 	case "baseline":
-		logger.Info("initializing baseline model")
-		return models.NewBaselineModel(cfg.Metric, stepSec, horizonSec)
+		logger.Info("initializing baseline model",
+			"week_backoff", cfg.BaselineWeekBackoff,
+			"max_blend", cfg.BaselineMaxBlend,
+		)
+		return models.NewBaselineModelWithOptions(cfg.Metric, stepSec, horizonSec, models.BaselineOptions{
+			WeekBackoff:   cfg.BaselineWeekBackoff,
+			MaxBlend:      cfg.BaselineMaxBlend,
+			SpikeWeight:   cfg.BaselineSpikeWeight,
+			RiseWeight:    cfg.BaselineRiseWeight,
+			DipWeight:     cfg.BaselineDipWeight,
+			NeutralWeight: cfg.BaselineNeutralWeight,
+		})
+
+	case "ensemble":
+		method := models.EnsembleMethod(cfg.EnsembleMethod)
+		if method != models.EnsembleInverseError && method != models.EnsembleStacking {
+			logger.Error("invalid ensemble method", "method", cfg.EnsembleMethod)
+			os.Exit(1)
+		}
+
+		var members []models.Model
+		for _, member := range strings.Split(cfg.EnsembleMembers, ",") {
+			member = strings.TrimSpace(member)
+			if member == "" {
+				continue
+			}
+			if member == "ensemble" {
+				logger.Error("ensemble cannot be a member of itself")
+				os.Exit(1)
+			}
+			members = append(members, newModel(member, cfg, logger))
+		}
+		if len(members) == 0 {
+			logger.Error("ensemble needs at least one member")
+			os.Exit(1)
+		}
+
+		logger.Info("initializing ensemble model",
+			"members", cfg.EnsembleMembers,
+			"method", method,
+			"window", cfg.EnsembleWindow,
+		)
+		return models.NewEnsembleModel(cfg.Metric, stepSec, horizonSec, members, models.EnsembleOptions{
+			Method: method,
+			Window: cfg.EnsembleWindow,
+		})
+
+	case "auto":
+		metric, err := models.ParseErrorMetric(cfg.AutoMetric)
+		if err != nil {
+			logger.Error("invalid auto selection metric", "error", err)
+			os.Exit(1)
+		}
+
+		candidates := autoCandidates(cfg, logger)
+		logger.Info("initializing auto model",
+			"candidates", len(candidates),
+			"metric", metric,
+			"folds", cfg.AutoFolds,
+			"reselect", cfg.AutoReselect,
+		)
+		return models.NewAutoModel(cfg.Metric, stepSec, horizonSec, candidates, models.AutoOptions{
+			Metric:   metric,
+			Quantile: cfg.AutoQuantile,
+			Folds:    cfg.AutoFolds,
+			Reselect: cfg.AutoReselect,
+			OnSelect: func(selected string, scores map[string]float64) {
+				attrs := []any{"selected", selected, "metric", metric}
+				for name, score := range scores {
+					attrs = append(attrs, name, score)
+				}
+				logger.Info("auto model selected", attrs...)
+			},
+		})
 
 	default:
-		logger.Error("invalid model type", "model", cfg.Model)
+		logger.Error("invalid model type", "model", name)
 		os.Exit(1)
 	}
 
 	return nil
 }
+
+// autoCandidates parses cfg.AutoCandidates into candidates for the auto model.
+// Entries are model names (built from the regular flags) or "arima(p,d,q)".
+func autoCandidates(cfg *config.Config, logger *slog.Logger) []models.Candidate {
+	stepSec := int(cfg.Step.Seconds())
+	horizonSec := int(cfg.Horizon.Seconds())
+	quiet := slog.New(slog.DiscardHandler)
+
+	var candidates []models.Candidate
+	for _, spec := range splitCandidates(cfg.AutoCandidates) {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		var p, d, q int
+		if n, _ := fmt.Sscanf(spec, "arima(%d,%d,%d)", &p, &d, &q); n == 3 {
+			if p < 0 || d < 0 || d > 2 || q < 0 {
+				logger.Error("invalid auto candidate", "candidate", spec, "reason", "p and q must be >= 0 and d in [0, 2]")
+				os.Exit(1)
+			}
+			name := models.NewARIMAModel(cfg.Metric, stepSec, horizonSec, p, d, q).Name()
+			candidates = append(candidates, models.Candidate{
+				Name: name,
+				New: func() models.Model {
+					return models.NewARIMAModel(cfg.Metric, stepSec, horizonSec, p, d, q)
+				},
+			})
+			continue
+		}
+
+		if spec == "auto" || spec == "ensemble" {
+			logger.Error("invalid auto candidate", "candidate", spec)
+			os.Exit(1)
+		}
+		// Build once with the real logger so that invalid names fail at startup.
+		name := newModel(spec, cfg, logger).Name()
+		candidates = append(candidates, models.Candidate{
+			Name: name,
+			New: func() models.Model {
+				return newModel(spec, cfg, quiet)
+			},
+		})
+	}
+
+	if len(candidates) == 0 {
+		logger.Error("auto model needs at least one candidate")
+		os.Exit(1)
+	}
+	return candidates
+}
+
+// splitCandidates splits a candidate list on the commas outside parentheses,
+// so that "baseline,arima(1,1,1)" yields "baseline" and "arima(1,1,1)".
+func splitCandidates(list string) []string {
+	var specs []string
+	depth, start := 0, 0
+	for i, r := range list {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				specs = append(specs, list[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(specs, list[start:])
+}
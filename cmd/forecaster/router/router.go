@@ -6,6 +6,8 @@
 //
 // Routes configured:
 //   - GET /forecast/current?workload=<name> - Retrieve latest forecast snapshot
+//   - GET /forecast/accuracy?workload=<name> - Rolling accuracy of past forecasts
+//   - GET /debug/forecast?workload=<name> - Latest forecast explained step by step
 //   - GET /healthz - Health check endpoint (returns 200 OK)
 //   - GET /metrics - Prometheus metrics endpoint
 //
@@ -13,22 +15,34 @@
 // specified in SPEC.md §3.1, including forecast values, desired replica counts,
 // and metadata (generated timestamp, step size, horizon). Snapshots older than
 // the stale threshold include an X-Kedastral-Stale header.
+//
+// The /forecast/accuracy endpoint returns the accuracy.Report of a workload:
+// MAE, MAPE, sMAPE, bias and interval coverage per horizon step, computed by
+// joining past forecasts with the values collected since.
+//
+// The /debug/forecast endpoint answers "why this many replicas": for every
+// step of the latest snapshot it lists the time, value, quantiles, desired
+// replicas and the model's components (level, trend, seasonal, event effects,
+// blend weights), as far as the model reports them.
 package router
 
 import (
 	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"github.com/HatiCode/kedastral/pkg/accuracy"
 	"github.com/HatiCode/kedastral/pkg/httpx"
 	"github.com/HatiCode/kedastral/pkg/storage"
 )
 
-// SetupRoutes configures HTTP endpoints for the forecaster.
-func SetupRoutes(store storage.Store, staleAfter time.Duration, logger *slog.Logger) *http.ServeMux {
+// SetupRoutes configures HTTP endpoints for the forecaster. The accuracy
+// endpoint is only registered when tracker is non-nil.
+func SetupRoutes(store storage.Store, tracker *accuracy.Tracker, staleAfter time.Duration, logger *slog.Logger) *http.ServeMux {
 	mux := http.NewServeMux()
 
 	// Health check endpoint
@@ -37,6 +51,14 @@ func SetupRoutes(store storage.Store, staleAfter time.Duration, logger *slog.Log
 	// Forecast snapshot endpoint
 	mux.HandleFunc("/forecast/current", handleGetSnapshot(store, staleAfter, logger))
 
+	// Forecast accuracy endpoint
+	if tracker != nil {
+		mux.HandleFunc("/forecast/accuracy", handleGetAccuracy(tracker, logger))
+	}
+
+	// Forecast explanation endpoint
+	mux.HandleFunc("/debug/forecast", handleDebugForecast(store, logger))
+
 	// Prometheus metrics endpoint
 	mux.Handle("/metrics", promhttp.Handler())
 
@@ -79,9 +101,117 @@ func handleGetSnapshot(store storage.Store, staleAfter time.Duration, logger *sl
 			"values":          snapshot.Values,
 			"desiredReplicas": snapshot.DesiredReplicas,
 		}
+		if len(snapshot.Quantiles) > 0 {
+			resp["quantiles"] = snapshot.Quantiles
+		}
+
+		if err := httpx.WriteJSON(w, http.StatusOK, resp); err != nil {
+			logger.Error("failed to write JSON response", "error", err)
+		}
+	}
+}
+
+// handleGetAccuracy returns a handler for GET /forecast/accuracy?workload=<name>.
+func handleGetAccuracy(tracker *accuracy.Tracker, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		workload := r.URL.Query().Get("workload")
+		if workload == "" {
+			httpx.WriteErrorMessage(w, http.StatusBadRequest, "workload parameter required")
+			return
+		}
+
+		report, found := tracker.Report(workload)
+		if !found {
+			httpx.WriteErrorMessage(w, http.StatusNotFound, fmt.Sprintf("no forecasts recorded for workload %q", workload))
+			return
+		}
+
+		if err := httpx.WriteJSON(w, http.StatusOK, report); err != nil {
+			logger.Error("failed to write JSON response", "error", err)
+		}
+	}
+}
+
+// debugForecast is the response of GET /debug/forecast.
+type debugForecast struct {
+	Workload    string      `json:"workload"`
+	Metric      string      `json:"metric"`
+	Model       string      `json:"model,omitempty"`
+	GeneratedAt string      `json:"generatedAt"`
+	StepSeconds int         `json:"stepSeconds"`
+	Steps       []debugStep `json:"steps"`
+}
+
+// debugStep explains one forecast step.
+type debugStep struct {
+	Time            string             `json:"time"`
+	Value           float64            `json:"value"`
+	DesiredReplicas *int               `json:"desiredReplicas,omitempty"`
+	Quantiles       map[string]float64 `json:"quantiles,omitempty"`
+	Components      map[string]float64 `json:"components,omitempty"`
+}
+
+// handleDebugForecast returns a handler for GET /debug/forecast?workload=<name>.
+// Step i is labelled with the time the scaler applies it: generatedAt + i·step.
+func handleDebugForecast(store storage.Store, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		workload := r.URL.Query().Get("workload")
+		if workload == "" {
+			httpx.WriteErrorMessage(w, http.StatusBadRequest, "workload parameter required")
+			return
+		}
+
+		snapshot, found, err := store.GetLatest(workload)
+		if err != nil {
+			logger.Error("failed to get snapshot", "workload", workload, "error", err)
+			httpx.WriteErrorMessage(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		if !found {
+			httpx.WriteErrorMessage(w, http.StatusNotFound, fmt.Sprintf("snapshot not found for workload %q", workload))
+			return
+		}
+
+		resp := debugForecast{
+			Workload:    snapshot.Workload,
+			Metric:      snapshot.Metric,
+			Model:       snapshot.Model,
+			GeneratedAt: snapshot.GeneratedAt.Format(time.RFC3339),
+			StepSeconds: snapshot.StepSeconds,
+			Steps:       make([]debugStep, len(snapshot.Values)),
+		}
+		step := time.Duration(snapshot.StepSeconds) * time.Second
+		for i, v := range snapshot.Values {
+			s := debugStep{
+				Time:       snapshot.GeneratedAt.Add(time.Duration(i) * step).Format(time.RFC3339),
+				Value:      v,
+				Quantiles:  stepValues(snapshot.Quantiles, i),
+				Components: stepValues(snapshot.Components, i),
+			}
+			if i < len(snapshot.DesiredReplicas) {
+				s.DesiredReplicas = &snapshot.DesiredReplicas[i]
+			}
+			resp.Steps[i] = s
+		}
 
 		if err := httpx.WriteJSON(w, http.StatusOK, resp); err != nil {
 			logger.Error("failed to write JSON response", "error", err)
 		}
 	}
 }
+
+// stepValues returns the value of every series at step i, skipping series
+// that are too short or not finite (which JSON cannot encode).
+func stepValues(series map[string][]float64, i int) map[string]float64 {
+	var out map[string]float64
+	for key, values := range series {
+		if i >= len(values) || math.IsNaN(values[i]) || math.IsInf(values[i], 0) {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]float64, len(series))
+		}
+		out[key] = values[i]
+	}
+	return out
+}
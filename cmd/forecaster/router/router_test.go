@@ -1,6 +1,7 @@
 package router
 
 import (
+	"encoding/json"
 	"io"
 	"log/slog"
 	"net/http"
@@ -8,6 +9,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/HatiCode/kedastral/pkg/accuracy"
+	"github.com/HatiCode/kedastral/pkg/models"
 	"github.com/HatiCode/kedastral/pkg/storage"
 )
 
@@ -15,7 +18,7 @@ func TestSetupRoutes(t *testing.T) {
 	store := storage.NewMemoryStore()
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 
-	mux := SetupRoutes(store, 2*time.Minute, logger)
+	mux := SetupRoutes(store, nil, 2*time.Minute, logger)
 
 	if mux == nil {
 		t.Fatal("SetupRoutes() returned nil")
@@ -25,7 +28,7 @@ func TestSetupRoutes(t *testing.T) {
 func TestHealthEndpoint(t *testing.T) {
 	store := storage.NewMemoryStore()
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
-	mux := SetupRoutes(store, 2*time.Minute, logger)
+	mux := SetupRoutes(store, nil, 2*time.Minute, logger)
 
 	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
 	w := httptest.NewRecorder()
@@ -45,7 +48,7 @@ func TestHealthEndpoint(t *testing.T) {
 func TestMetricsEndpoint(t *testing.T) {
 	store := storage.NewMemoryStore()
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
-	mux := SetupRoutes(store, 2*time.Minute, logger)
+	mux := SetupRoutes(store, nil, 2*time.Minute, logger)
 
 	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
 	w := httptest.NewRecorder()
@@ -66,7 +69,7 @@ func TestMetricsEndpoint(t *testing.T) {
 func TestGetSnapshot_MissingWorkload(t *testing.T) {
 	store := storage.NewMemoryStore()
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
-	mux := SetupRoutes(store, 2*time.Minute, logger)
+	mux := SetupRoutes(store, nil, 2*time.Minute, logger)
 
 	req := httptest.NewRequest(http.MethodGet, "/forecast/current", nil)
 	w := httptest.NewRecorder()
@@ -81,7 +84,7 @@ func TestGetSnapshot_MissingWorkload(t *testing.T) {
 func TestGetSnapshot_NotFound(t *testing.T) {
 	store := storage.NewMemoryStore()
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
-	mux := SetupRoutes(store, 2*time.Minute, logger)
+	mux := SetupRoutes(store, nil, 2*time.Minute, logger)
 
 	req := httptest.NewRequest(http.MethodGet, "/forecast/current?workload=nonexistent", nil)
 	w := httptest.NewRecorder()
@@ -112,7 +115,7 @@ func TestGetSnapshot_Success(t *testing.T) {
 		t.Fatalf("failed to put snapshot: %v", err)
 	}
 
-	mux := SetupRoutes(store, 2*time.Minute, logger)
+	mux := SetupRoutes(store, nil, 2*time.Minute, logger)
 
 	req := httptest.NewRequest(http.MethodGet, "/forecast/current?workload=test-api", nil)
 	w := httptest.NewRecorder()
@@ -155,7 +158,7 @@ func TestGetSnapshot_Stale(t *testing.T) {
 		t.Fatalf("failed to put snapshot: %v", err)
 	}
 
-	mux := SetupRoutes(store, 2*time.Minute, logger) // Stale after 2 minutes
+	mux := SetupRoutes(store, nil, 2*time.Minute, logger) // Stale after 2 minutes
 
 	req := httptest.NewRequest(http.MethodGet, "/forecast/current?workload=test-api", nil)
 	w := httptest.NewRecorder()
@@ -191,7 +194,7 @@ func TestGetSnapshot_JSONResponse(t *testing.T) {
 		t.Fatalf("failed to put snapshot: %v", err)
 	}
 
-	mux := SetupRoutes(store, 2*time.Minute, logger)
+	mux := SetupRoutes(store, nil, 2*time.Minute, logger)
 
 	req := httptest.NewRequest(http.MethodGet, "/forecast/current?workload=test-api", nil)
 	w := httptest.NewRecorder()
@@ -222,6 +225,42 @@ func TestGetSnapshot_JSONResponse(t *testing.T) {
 	}
 }
 
+func TestGetSnapshot_Quantiles(t *testing.T) {
+	store := storage.NewMemoryStore()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	snapshot := storage.Snapshot{
+		Workload:        "test-api",
+		Metric:          "http_rps",
+		GeneratedAt:     time.Now(),
+		StepSeconds:     60,
+		HorizonSeconds:  180,
+		Values:          []float64{100, 110, 120},
+		DesiredReplicas: []int{2, 3, 3},
+		Quantiles: map[string][]float64{
+			"p10": {90, 95, 100},
+			"p90": {110, 125, 140},
+		},
+	}
+	if err := store.Put(snapshot); err != nil {
+		t.Fatalf("failed to put snapshot: %v", err)
+	}
+
+	mux := SetupRoutes(store, nil, 2*time.Minute, logger)
+
+	req := httptest.NewRequest(http.MethodGet, "/forecast/current?workload=test-api", nil)
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	for _, field := range []string{"\"quantiles\"", "\"p10\"", "\"p90\""} {
+		if !contains(body, field) {
+			t.Errorf("response missing field %s", field)
+		}
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && findSubstring(s, substr))
 }
@@ -234,3 +273,110 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestGetAccuracy(t *testing.T) {
+	store := storage.NewMemoryStore()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	tracker := accuracy.NewTracker(0)
+	tracker.Record("test-api", time.Unix(0, 0), models.Forecast{Values: []float64{110}, StepSec: 60})
+	tracker.Observe("test-api", models.FeatureFrame{Rows: []map[string]float64{{"timestamp": 60, "value": 100}}})
+	mux := SetupRoutes(store, tracker, 2*time.Minute, logger)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{name: "missing workload", query: "", wantStatus: http.StatusBadRequest},
+		{name: "unknown workload", query: "?workload=other", wantStatus: http.StatusNotFound},
+		{name: "report", query: "?workload=test-api", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/forecast/accuracy"+tt.query, nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var report accuracy.Report
+			if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(report.Steps) != 1 || report.Steps[0].MAE != 10 || report.Steps[0].LeadSeconds != 60 {
+				t.Errorf("Steps = %+v, want one step with MAE 10 at 60s", report.Steps)
+			}
+		})
+	}
+}
+
+func TestDebugForecast(t *testing.T) {
+	store := storage.NewMemoryStore()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	generated := time.Date(2025, 1, 6, 3, 0, 0, 0, time.UTC)
+	snapshot := storage.Snapshot{
+		Workload:        "test-api",
+		Metric:          "http_rps",
+		Model:           "baseline",
+		GeneratedAt:     generated,
+		StepSeconds:     60,
+		HorizonSeconds:  120,
+		Values:          []float64{100, 400},
+		DesiredReplicas: []int{10, 40},
+		Quantiles:       map[string][]float64{"p90": {110, 450}},
+		Components: map[string][]float64{
+			models.ComponentLevel:    {100, 100},
+			models.ComponentSeasonal: {100, 475},
+		},
+	}
+	if err := store.Put(snapshot); err != nil {
+		t.Fatalf("failed to put snapshot: %v", err)
+	}
+	mux := SetupRoutes(store, nil, 2*time.Minute, logger)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{name: "missing workload", query: "", wantStatus: http.StatusBadRequest},
+		{name: "unknown workload", query: "?workload=other", wantStatus: http.StatusNotFound},
+		{name: "explanation", query: "?workload=test-api", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/debug/forecast"+tt.query, nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp debugForecast
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Model != "baseline" || len(resp.Steps) != 2 {
+				t.Fatalf("response = %+v, want 2 steps of the baseline model", resp)
+			}
+			step := resp.Steps[1]
+			if step.Time != "2025-01-06T03:01:00Z" || step.Value != 400 || *step.DesiredReplicas != 40 {
+				t.Errorf("Steps[1] = %+v, want 400 and 40 replicas at 03:01", step)
+			}
+			if step.Quantiles["p90"] != 450 || step.Components[models.ComponentSeasonal] != 475 || step.Components[models.ComponentLevel] != 100 {
+				t.Errorf("Steps[1] quantiles = %v, components = %v", step.Quantiles, step.Components)
+			}
+		})
+	}
+}
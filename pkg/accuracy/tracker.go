@@ -0,0 +1,341 @@
+// Package accuracy measures how well forecasts matched the values that were
+// observed later.
+//
+// A Tracker keeps the recent forecasts of each workload. As later collections
+// bring in the realised values, every forecast step is joined with the value
+// observed at its target time, and rolling error statistics are kept per
+// horizon step: MAE, MAPE, sMAPE, bias and, when the forecast carried
+// quantiles, the coverage of its central prediction intervals.
+//
+// State is kept in memory only, so statistics start over when the process
+// restarts.
+package accuracy
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/HatiCode/kedastral/pkg/models"
+)
+
+// DefaultWindow is the number of recent forecast/actual pairs per horizon
+// step that statistics are computed over when NewTracker is given zero.
+const DefaultWindow = 500
+
+// maxPending caps the forecasts per workload still waiting for realised
+// values; the oldest are dropped first.
+const maxPending = 1000
+
+// Interval is a central prediction interval bounded by two forecast
+// quantiles.
+type Interval struct {
+	// Name is the nominal coverage in percent, e.g. "80".
+	Name string
+
+	// Lower and Upper are the quantile levels bounding the interval.
+	Lower, Upper float64
+}
+
+// Intervals are the prediction intervals whose coverage is tracked. A
+// well-calibrated 80% interval contains about 80% of the realised values.
+var Intervals = []Interval{
+	{Name: "50", Lower: 0.25, Upper: 0.75},
+	{Name: "80", Lower: 0.1, Upper: 0.9},
+	{Name: "90", Lower: 0.05, Upper: 0.95},
+}
+
+// Tracker joins forecasts with realised values and keeps rolling accuracy
+// statistics per workload and horizon step. It is safe for concurrent use.
+type Tracker struct {
+	window int
+
+	mu        sync.Mutex
+	workloads map[string]*workloadState
+}
+
+type workloadState struct {
+	stepSec int
+	pending []*pendingForecast
+	steps   [][]sample // recent samples per horizon step
+}
+
+// pendingForecast is a recorded forecast whose steps are joined with realised
+// values as they arrive.
+type pendingForecast struct {
+	origin    float64 // Unix time of the last observation the forecast saw
+	stepSec   int
+	values    []float64
+	bounds    map[string][2][]float64 // interval name → lower, upper series
+	resolved  []bool
+	remaining int
+}
+
+// sample is one forecast step joined with its realised value.
+type sample struct {
+	actual    float64
+	predicted float64
+	covered   map[string]bool // interval name → actual within bounds
+}
+
+// StepAccuracy summarises the forecast errors at one horizon step, or over
+// all steps.
+type StepAccuracy struct {
+	// Step is the 1-based horizon step; 0 for the summary over all steps.
+	Step int `json:"step"`
+
+	// LeadSeconds is how far ahead the step forecasts; 0 for the summary.
+	LeadSeconds int `json:"leadSeconds"`
+
+	// Samples is the number of forecast/actual pairs behind the statistics.
+	Samples int `json:"samples"`
+
+	// MAE is the mean absolute error.
+	MAE float64 `json:"mae"`
+
+	// MAPE is the mean absolute percentage error in percent, skipping zero
+	// actuals.
+	MAPE float64 `json:"mape"`
+
+	// SMAPE is the symmetric mean absolute percentage error in percent.
+	SMAPE float64 `json:"smape"`
+
+	// Bias is the mean of forecast minus actual: positive values mean
+	// over-forecasting.
+	Bias float64 `json:"bias"`
+
+	// Coverage maps an interval name (see Intervals) to the share of realised
+	// values that fell within it. Only intervals the forecasts carried are
+	// reported.
+	Coverage map[string]float64 `json:"coverage,omitempty"`
+}
+
+// Report holds the accuracy statistics of one workload.
+type Report struct {
+	Workload string `json:"workload"`
+
+	// Pending is the number of forecasts still waiting for realised values.
+	Pending int `json:"pending"`
+
+	// Overall summarises all steps.
+	Overall StepAccuracy `json:"overall"`
+
+	// Steps holds the statistics per horizon step, nearest first.
+	Steps []StepAccuracy `json:"steps"`
+}
+
+// NewTracker creates a tracker that keeps the last window forecast/actual
+// pairs per horizon step (DefaultWindow when window <= 0).
+func NewTracker(window int) *Tracker {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Tracker{
+		window:    window,
+		workloads: make(map[string]*workloadState),
+	}
+}
+
+// Record stores a forecast made after observing data up to origin. Step h
+// (0-based) of the forecast is later compared with the value observed at
+// origin + (h+1)·StepSec. Forecasts without values or step are ignored.
+func (t *Tracker) Record(workload string, origin time.Time, forecast models.Forecast) {
+	if len(forecast.Values) == 0 || forecast.StepSec <= 0 {
+		return
+	}
+
+	p := &pendingForecast{
+		origin:    float64(origin.Unix()),
+		stepSec:   forecast.StepSec,
+		values:    append([]float64(nil), forecast.Values...),
+		resolved:  make([]bool, len(forecast.Values)),
+		remaining: len(forecast.Values),
+	}
+	for _, iv := range Intervals {
+		lower, okLower := forecast.Quantile(iv.Lower)
+		upper, okUpper := forecast.Quantile(iv.Upper)
+		if okLower && okUpper {
+			if p.bounds == nil {
+				p.bounds = make(map[string][2][]float64, len(Intervals))
+			}
+			p.bounds[iv.Name] = [2][]float64{
+				append([]float64(nil), lower...),
+				append([]float64(nil), upper...),
+			}
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	w := t.workload(workload)
+	w.stepSec = forecast.StepSec
+	w.pending = append(w.pending, p)
+	if len(w.pending) > maxPending {
+		w.pending = w.pending[len(w.pending)-maxPending:]
+	}
+}
+
+// Observe joins the pending forecasts of workload with realised values. Each
+// row needs a "timestamp" (Unix seconds) and a "value"; a forecast step
+// matches the row nearest to its target time within half a step. Forecasts
+// are dropped once all of their steps are joined or lie before the newest
+// row.
+func (t *Tracker) Observe(workload string, actuals models.FeatureFrame) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	w, ok := t.workloads[workload]
+	if !ok || len(w.pending) == 0 {
+		return
+	}
+
+	latest := math.Inf(-1)
+	for _, row := range actuals.Rows {
+		if ts, ok := row["timestamp"]; ok {
+			latest = max(latest, ts)
+		}
+	}
+
+	byStep := make(map[int]map[int64]float64)
+	kept := w.pending[:0]
+	for _, p := range w.pending {
+		index, ok := byStep[p.stepSec]
+		if !ok {
+			index = indexByStep(actuals, p.stepSec)
+			byStep[p.stepSec] = index
+		}
+
+		for h := range p.values {
+			if p.resolved[h] {
+				continue
+			}
+			target := p.origin + float64((h+1)*p.stepSec)
+			actual, ok := index[int64(math.Round(target/float64(p.stepSec)))]
+			if !ok {
+				continue
+			}
+			t.add(w, h, p.sample(h, actual))
+			p.resolved[h] = true
+			p.remaining--
+		}
+
+		lastTarget := p.origin + float64(len(p.values)*p.stepSec)
+		if p.remaining > 0 && lastTarget > latest {
+			kept = append(kept, p)
+		}
+	}
+	clear(w.pending[len(kept):])
+	w.pending = kept
+}
+
+// Report returns the current statistics of workload; found is false when no
+// forecast has been recorded for it.
+func (t *Tracker) Report(workload string) (report Report, found bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	w, ok := t.workloads[workload]
+	if !ok {
+		return Report{}, false
+	}
+
+	report = Report{
+		Workload: workload,
+		Pending:  len(w.pending),
+		Steps:    make([]StepAccuracy, len(w.steps)),
+	}
+	var all []sample
+	for h, samples := range w.steps {
+		report.Steps[h] = summarise(samples)
+		report.Steps[h].Step = h + 1
+		report.Steps[h].LeadSeconds = (h + 1) * w.stepSec
+		all = append(all, samples...)
+	}
+	report.Overall = summarise(all)
+	return report, true
+}
+
+// workload returns the state of a workload, creating it. Callers hold mu.
+func (t *Tracker) workload(name string) *workloadState {
+	w, ok := t.workloads[name]
+	if !ok {
+		w = &workloadState{}
+		t.workloads[name] = w
+	}
+	return w
+}
+
+// add appends a sample to step h, keeping the last window samples. Callers
+// hold mu.
+func (t *Tracker) add(w *workloadState, h int, s sample) {
+	for len(w.steps) <= h {
+		w.steps = append(w.steps, nil)
+	}
+	w.steps[h] = append(w.steps[h], s)
+	if n := len(w.steps[h]); n > t.window {
+		w.steps[h] = append(w.steps[h][:0], w.steps[h][n-t.window:]...)
+	}
+}
+
+// sample joins step h of the forecast with its realised value.
+func (p *pendingForecast) sample(h int, actual float64) sample {
+	s := sample{actual: actual, predicted: p.values[h]}
+	for name, b := range p.bounds {
+		if s.covered == nil {
+			s.covered = make(map[string]bool, len(p.bounds))
+		}
+		s.covered[name] = actual >= b[0][h] && actual <= b[1][h]
+	}
+	return s
+}
+
+// indexByStep maps the rows of frame by their step number,
+// round(timestamp / stepSec). Rows without timestamp or value are skipped.
+func indexByStep(frame models.FeatureFrame, stepSec int) map[int64]float64 {
+	index := make(map[int64]float64, len(frame.Rows))
+	for _, row := range frame.Rows {
+		ts, hasTS := row["timestamp"]
+		v, hasValue := row["value"]
+		if hasTS && hasValue {
+			index[int64(math.Round(ts/float64(stepSec)))] = v
+		}
+	}
+	return index
+}
+
+// summarise computes the statistics of a set of samples.
+func summarise(samples []sample) StepAccuracy {
+	out := StepAccuracy{Samples: len(samples)}
+	if len(samples) == 0 {
+		return out
+	}
+
+	actual := make([]float64, len(samples))
+	predicted := make([]float64, len(samples))
+	bias := 0.0
+	covered := make(map[string]int)
+	counted := make(map[string]int)
+	for i, s := range samples {
+		actual[i], predicted[i] = s.actual, s.predicted
+		bias += s.predicted - s.actual
+		for name, in := range s.covered {
+			counted[name]++
+			if in {
+				covered[name]++
+			}
+		}
+	}
+
+	out.MAE = models.MAE(actual, predicted)
+	out.MAPE = models.MAPE(actual, predicted)
+	out.SMAPE = models.SMAPE(actual, predicted)
+	out.Bias = bias / float64(len(samples))
+	if len(counted) > 0 {
+		out.Coverage = make(map[string]float64, len(counted))
+		for name, n := range counted {
+			out.Coverage[name] = float64(covered[name]) / float64(n)
+		}
+	}
+	return out
+}
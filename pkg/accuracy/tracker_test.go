@@ -0,0 +1,142 @@
+package accuracy
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/HatiCode/kedastral/pkg/models"
+)
+
+// observed returns minute rows with timestamps from..to (in minutes) and
+// values value(minute).
+func observed(from, to int, value func(minute int) float64) models.FeatureFrame {
+	var frame models.FeatureFrame
+	for m := from; m <= to; m++ {
+		frame.Rows = append(frame.Rows, map[string]float64{
+			"timestamp": float64(m * 60),
+			"value":     value(m),
+		})
+	}
+	return frame
+}
+
+func TestTracker_JoinsForecastsWithActuals(t *testing.T) {
+	tracker := NewTracker(0)
+	actual := func(m int) float64 { return float64(100 + m) }
+
+	// Forecasts made at minutes 10 and 11, two steps each, over-forecasting
+	// by 10 at step 1 and under-forecasting by 20 at step 2.
+	for _, origin := range []int{10, 11} {
+		tracker.Record("api", time.Unix(int64(origin*60), 0), models.Forecast{
+			Values:  []float64{actual(origin+1) + 10, actual(origin+2) - 20},
+			StepSec: 60,
+		})
+	}
+
+	// Minute 12 resolves step 2 of the first and step 1 of the second.
+	tracker.Observe("api", observed(0, 12, actual))
+	report, found := tracker.Report("api")
+	if !found {
+		t.Fatal("Report() found = false, want true")
+	}
+	if report.Pending != 1 {
+		t.Errorf("Pending = %d, want 1 (the second forecast's step 2)", report.Pending)
+	}
+
+	tracker.Observe("api", observed(0, 13, actual))
+	report, _ = tracker.Report("api")
+	if report.Pending != 0 {
+		t.Errorf("Pending = %d, want 0", report.Pending)
+	}
+
+	want := []StepAccuracy{
+		{Step: 1, LeadSeconds: 60, Samples: 2, MAE: 10, Bias: 10},
+		{Step: 2, LeadSeconds: 120, Samples: 2, MAE: 20, Bias: -20},
+	}
+	if len(report.Steps) != len(want) {
+		t.Fatalf("Steps = %+v, want %d steps", report.Steps, len(want))
+	}
+	for i, w := range want {
+		got := report.Steps[i]
+		if got.Step != w.Step || got.LeadSeconds != w.LeadSeconds || got.Samples != w.Samples {
+			t.Errorf("Steps[%d] = %+v, want step %d, lead %ds, %d samples", i, got, w.Step, w.LeadSeconds, w.Samples)
+		}
+		if math.Abs(got.MAE-w.MAE) > 1e-9 || math.Abs(got.Bias-w.Bias) > 1e-9 {
+			t.Errorf("Steps[%d] MAE, bias = %v, %v, want %v, %v", i, got.MAE, got.Bias, w.MAE, w.Bias)
+		}
+		if got.MAPE <= 0 || got.SMAPE <= 0 {
+			t.Errorf("Steps[%d] MAPE, sMAPE = %v, %v, want > 0", i, got.MAPE, got.SMAPE)
+		}
+	}
+	if report.Overall.Samples != 4 || math.Abs(report.Overall.MAE-15) > 1e-9 || math.Abs(report.Overall.Bias+5) > 1e-9 {
+		t.Errorf("Overall = %+v, want 4 samples, MAE 15, bias -5", report.Overall)
+	}
+}
+
+func TestTracker_Coverage(t *testing.T) {
+	tracker := NewTracker(0)
+
+	// The realised value is 100 every minute; the 80% interval contains it
+	// for the first forecast only, the 50% interval for neither.
+	for i, upper := range []float64{120, 90} {
+		tracker.Record("api", time.Unix(int64(i*60), 0), models.Forecast{
+			Values:  []float64{80},
+			StepSec: 60,
+			Quantiles: map[string][]float64{
+				"p10": {70}, "p25": {75}, "p75": {85}, "p90": {upper},
+			},
+		})
+	}
+	tracker.Observe("api", observed(0, 5, func(int) float64 { return 100 }))
+
+	report, _ := tracker.Report("api")
+	coverage := report.Steps[0].Coverage
+	if got := coverage["80"]; got != 0.5 {
+		t.Errorf("coverage[80] = %v, want 0.5", got)
+	}
+	if got := coverage["50"]; got != 0 {
+		t.Errorf("coverage[50] = %v, want 0", got)
+	}
+	if _, ok := coverage["90"]; ok {
+		t.Error("coverage[90] reported without p5/p95 quantiles")
+	}
+}
+
+func TestTracker_Window(t *testing.T) {
+	tracker := NewTracker(3)
+	for origin := range 10 {
+		tracker.Record("api", time.Unix(int64(origin*60), 0), models.Forecast{
+			Values:  []float64{float64(origin)},
+			StepSec: 60,
+		})
+	}
+	tracker.Observe("api", observed(0, 20, func(int) float64 { return 0 }))
+
+	report, _ := tracker.Report("api")
+	// Only the last three forecasts (7, 8, 9) count.
+	if got := report.Steps[0]; got.Samples != 3 || got.MAE != 8 {
+		t.Errorf("Steps[0] = %+v, want 3 samples with MAE 8", got)
+	}
+}
+
+func TestTracker_DropsUnmatchedForecasts(t *testing.T) {
+	tracker := NewTracker(0)
+	tracker.Record("api", time.Unix(0, 0), models.Forecast{Values: []float64{1, 2}, StepSec: 60})
+
+	// Minutes 1 and 2 are missing from the data, but newer rows exist.
+	tracker.Observe("api", observed(5, 10, func(int) float64 { return 1 }))
+
+	report, _ := tracker.Report("api")
+	if report.Pending != 0 || len(report.Steps) != 0 {
+		t.Errorf("Report() = %+v, want the forecast dropped without samples", report)
+	}
+}
+
+func TestTracker_UnknownWorkload(t *testing.T) {
+	tracker := NewTracker(0)
+	tracker.Observe("api", observed(0, 5, func(int) float64 { return 1 }))
+	if _, found := tracker.Report("api"); found {
+		t.Error("Report() found = true, want false before any forecast")
+	}
+}
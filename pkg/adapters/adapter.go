@@ -8,6 +8,7 @@
 //   - HTTPAdapter       — calls arbitrary REST endpoints for events or data
 //   - ScheduleAdapter   — provides upcoming time-based events (e.g. matches)
 //   - KafkaAdapter      — reads lag, queue depth, or message rate
+//   - CSVAdapter        — reads a recorded series from a CSV file
 //
 // Adapters are intentionally lightweight. They focus on pulling raw data,
 // shaping it into [DataFrame] objects, and leaving all feature building and
@@ -47,6 +48,17 @@ type Adapter interface {
 	Name() string
 }
 
+// RangeAdapter is implemented by adapters that can fetch an arbitrary past
+// time range rather than only the most recent window. Backtests use it to
+// replay history.
+type RangeAdapter interface {
+	Adapter
+
+	// CollectRange fetches the data between start and end, inclusive, in
+	// time order.
+	CollectRange(ctx context.Context, start, end time.Time) (*DataFrame, error)
+}
+
 // Optional: helper to align timestamps to a consistent step duration.
 func AlignTimestamp(ts time.Time, stepSec int) time.Time {
 	return ts.Truncate(time.Duration(stepSec) * time.Second)
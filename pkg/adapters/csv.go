@@ -0,0 +1,151 @@
+package adapters
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVAdapter reads a recorded time series from a CSV file, for example one
+// exported from Prometheus, so that it can be replayed offline. The file must
+// have a header row with a timestamp column ("ts" or "timestamp") and a
+// "value" column; other columns are ignored. Timestamps are RFC3339 strings
+// or Unix seconds. Rows are returned in the same form as PrometheusAdapter:
+//
+//	{"ts": RFC3339 string, "value": float64}
+//
+// Values with the same timestamp are SUMMED.
+type CSVAdapter struct {
+	// Path is the CSV file to read.
+	Path string
+}
+
+func (c *CSVAdapter) Name() string { return "csv" }
+
+// Collect implements Adapter. The file is a recording, so the window ends at
+// its newest row rather than at the current time.
+func (c *CSVAdapter) Collect(ctx context.Context, windowSeconds int) (*DataFrame, error) {
+	rows, err := c.read(ctx)
+	if err != nil {
+		return &DataFrame{}, err
+	}
+	if len(rows) == 0 {
+		return &DataFrame{}, nil
+	}
+	end := rows[len(rows)-1].ts
+	start := end.Add(-time.Duration(windowSeconds) * time.Second)
+	return &DataFrame{Rows: between(rows, start, end)}, nil
+}
+
+// CollectRange implements RangeAdapter.
+func (c *CSVAdapter) CollectRange(ctx context.Context, start, end time.Time) (*DataFrame, error) {
+	rows, err := c.read(ctx)
+	if err != nil {
+		return &DataFrame{}, err
+	}
+	return &DataFrame{Rows: between(rows, start, end)}, nil
+}
+
+type csvPoint struct {
+	ts    time.Time
+	value float64
+}
+
+// read parses the whole file and returns its points in time order.
+func (c *CSVAdapter) read(ctx context.Context) ([]csvPoint, error) {
+	if c.Path == "" {
+		return nil, errors.New("csv adapter: Path is required")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(c.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("csv adapter: read header: %w", err)
+	}
+	tsCol, valueCol := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "ts", "timestamp":
+			tsCol = i
+		case "value":
+			valueCol = i
+		}
+	}
+	if tsCol < 0 || valueCol < 0 {
+		return nil, errors.New("csv adapter: header must have a ts or timestamp column and a value column")
+	}
+
+	acc := make(map[int64]float64)
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("csv adapter: %w", err)
+		}
+		if len(record) <= max(tsCol, valueCol) {
+			return nil, fmt.Errorf("csv adapter: line %d: too few columns", line)
+		}
+		ts, err := parseCSVTime(strings.TrimSpace(record[tsCol]))
+		if err != nil {
+			return nil, fmt.Errorf("csv adapter: line %d: %w", line, err)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(record[valueCol]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("csv adapter: line %d: parse value: %w", line, err)
+		}
+		acc[ts.Unix()] += v
+	}
+
+	points := make([]csvPoint, 0, len(acc))
+	for ts, v := range acc {
+		points = append(points, csvPoint{ts: time.Unix(ts, 0).UTC(), value: v})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].ts.Before(points[j].ts) })
+	return points, nil
+}
+
+// parseCSVTime accepts RFC3339 timestamps and Unix seconds.
+func parseCSVTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	sec, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse timestamp %q: want RFC3339 or Unix seconds", s)
+	}
+	return time.Unix(int64(sec), 0), nil
+}
+
+// between returns the points within [start, end] as rows.
+func between(points []csvPoint, start, end time.Time) []Row {
+	var rows []Row
+	for _, p := range points {
+		if p.ts.Before(start) || p.ts.After(end) {
+			continue
+		}
+		rows = append(rows, Row{
+			"ts":    p.ts.Format(time.RFC3339),
+			"value": p.value,
+		})
+	}
+	return rows
+}
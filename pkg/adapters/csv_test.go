@@ -0,0 +1,97 @@
+package adapters
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeCSV(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "series.csv")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	return path
+}
+
+func TestCSVAdapter_CollectRange(t *testing.T) {
+	path := writeCSV(t, `timestamp,value,pod
+2023-11-14T22:15:00Z,30,b
+1700000000,10,a
+2023-11-14T22:14:20Z,20,a
+2023-11-14T22:15:00Z,5,a
+`)
+	ad := &CSVAdapter{Path: path}
+
+	df, err := ad.CollectRange(context.Background(), time.Unix(1700000000, 0), time.Unix(1700000100, 0))
+	if err != nil {
+		t.Fatalf("CollectRange error: %v", err)
+	}
+	want := []struct {
+		ts    string
+		value float64
+	}{
+		{"2023-11-14T22:13:20Z", 10},
+		{"2023-11-14T22:14:20Z", 20},
+		{"2023-11-14T22:15:00Z", 35},
+	}
+	if len(df.Rows) != len(want) {
+		t.Fatalf("expected %d rows, got %d", len(want), len(df.Rows))
+	}
+	for i, w := range want {
+		if df.Rows[i]["ts"] != w.ts || df.Rows[i]["value"] != w.value {
+			t.Errorf("row %d = %v, want ts=%s value=%v", i, df.Rows[i], w.ts, w.value)
+		}
+	}
+
+	df, err = ad.CollectRange(context.Background(), time.Unix(1700000030, 0), time.Unix(1700000060, 0))
+	if err != nil {
+		t.Fatalf("CollectRange error: %v", err)
+	}
+	if len(df.Rows) != 1 || df.Rows[0]["value"] != 20.0 {
+		t.Errorf("sub-range rows = %v, want only the 20 at 22:14:20", df.Rows)
+	}
+}
+
+func TestCSVAdapter_CollectEndsAtLastRow(t *testing.T) {
+	path := writeCSV(t, "ts,value\n1700000000,1\n1700000060,2\n1700000120,3\n")
+	ad := &CSVAdapter{Path: path}
+
+	df, err := ad.Collect(context.Background(), 60)
+	if err != nil {
+		t.Fatalf("Collect error: %v", err)
+	}
+	if len(df.Rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(df.Rows))
+	}
+	if df.Rows[1]["value"] != 3.0 {
+		t.Errorf("last value = %v, want 3", df.Rows[1]["value"])
+	}
+}
+
+func TestCSVAdapter_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"missing value column", "ts,count\n1700000000,1\n"},
+		{"bad timestamp", "ts,value\nyesterday,1\n"},
+		{"bad value", "ts,value\n1700000000,lots\n"},
+		{"short row", "value,ts\n1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ad := &CSVAdapter{Path: writeCSV(t, tt.content)}
+			if _, err := ad.Collect(context.Background(), 60); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+
+	if _, err := (&CSVAdapter{}).Collect(context.Background(), 60); err == nil {
+		t.Errorf("expected error for missing path")
+	}
+}
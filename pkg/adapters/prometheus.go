@@ -38,23 +38,65 @@ func (p *PrometheusAdapter) Collect(ctx context.Context, windowSeconds int) (*Da
 	if p.ServerURL == "" || p.Query == "" {
 		return &DataFrame{}, errors.New("prometheus adapter: ServerURL and QueryURL are required")
 	}
-	step := p.StepSeconds
-	if step <= 0 {
-		step = 60
-	}
 	now := time.Now().UTC().Truncate(time.Second)
 	start := now.Add(-time.Duration(windowSeconds) * time.Second)
 
+	rows, err := p.queryRange(ctx, start, now, p.step())
+	if err != nil {
+		return &DataFrame{}, err
+	}
+	return &DataFrame{Rows: rows}, nil
+}
+
+// maxPointsPerQuery keeps range queries below Prometheus' limit of 11,000
+// points per series.
+const maxPointsPerQuery = 10000
+
+// CollectRange implements RangeAdapter. Ranges longer than maxPointsPerQuery
+// steps are split into several queries.
+func (p *PrometheusAdapter) CollectRange(ctx context.Context, start, end time.Time) (*DataFrame, error) {
+	if p.ServerURL == "" || p.Query == "" {
+		return &DataFrame{}, errors.New("prometheus adapter: ServerURL and QueryURL are required")
+	}
+	step := p.step()
+	chunk := time.Duration((maxPointsPerQuery-1)*step) * time.Second
+
+	var rows []Row
+	for from := start.UTC().Truncate(time.Second); !from.After(end); from = from.Add(chunk + time.Duration(step)*time.Second) {
+		to := from.Add(chunk)
+		if to.After(end) {
+			to = end
+		}
+		part, err := p.queryRange(ctx, from, to, step)
+		if err != nil {
+			return &DataFrame{}, err
+		}
+		rows = append(rows, part...)
+	}
+	return &DataFrame{Rows: rows}, nil
+}
+
+// step returns StepSeconds, defaulting to 60.
+func (p *PrometheusAdapter) step() int {
+	if p.StepSeconds <= 0 {
+		return 60
+	}
+	return p.StepSeconds
+}
+
+// queryRange runs one /api/v1/query_range call and returns its rows in time
+// order.
+func (p *PrometheusAdapter) queryRange(ctx context.Context, start, end time.Time, step int) ([]Row, error) {
 	u, err := url.Parse(p.ServerURL)
 	if err != nil {
-		return &DataFrame{}, fmt.Errorf("invalid ServerURL: %w", err)
+		return nil, fmt.Errorf("invalid ServerURL: %w", err)
 	}
 	u.Path = "/api/v1/query_range"
 
 	q := u.Query()
 	q.Set("query", p.Query)
 	q.Set("start", fmt.Sprintf("%d", start.Unix()))
-	q.Set("end", fmt.Sprintf("%d", now.Unix()))
+	q.Set("end", fmt.Sprintf("%d", end.Unix()))
 	q.Set("step", fmt.Sprintf("%d", step))
 	u.RawQuery = q.Encode()
 
@@ -65,31 +107,31 @@ func (p *PrometheusAdapter) Collect(ctx context.Context, windowSeconds int) (*Da
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
-		return &DataFrame{}, err
+		return nil, err
 	}
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := cli.Do(req)
 	if err != nil {
-		return &DataFrame{}, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return &DataFrame{}, fmt.Errorf("prometheus: status %d", resp.StatusCode)
+		return nil, fmt.Errorf("prometheus: status %d", resp.StatusCode)
 	}
 
 	var pr prometheusRangeResponse
 	if err := json.NewDecoder(resp.Body).Decode(&pr); err != nil {
-		return &DataFrame{}, fmt.Errorf("decode prometheus response: %w", err)
+		return nil, fmt.Errorf("decode prometheus response: %w", err)
 	}
 	if pr.Status != "success" {
-		return &DataFrame{}, fmt.Errorf("prometheus status: %s", pr.Status)
+		return nil, fmt.Errorf("prometheus status: %s", pr.Status)
 	}
 
 	rows, err := aggregateRangeResult(pr.Data.Result)
 	if err != nil {
-		return &DataFrame{}, err
+		return nil, err
 	}
 
 	// Ensure sorted by timestamp
@@ -101,7 +143,7 @@ func (p *PrometheusAdapter) Collect(ctx context.Context, windowSeconds int) (*Da
 		rows[i]["ts"] = rows[i]["ts"].(time.Time).UTC().Format(time.RFC3339)
 	}
 
-	return &DataFrame{Rows: rows}, nil
+	return rows, nil
 }
 
 type prometheusRangeResponse struct {
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -114,3 +116,51 @@ func TestPrometheusAdapter_ValidatesConfig(t *testing.T) {
 		t.Fatalf("expected error for missing config")
 	}
 }
+
+func TestPrometheusAdapter_CollectRangeChunks(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		start, _ := strconv.ParseInt(r.URL.Query().Get("start"), 10, 64)
+		end, _ := strconv.ParseInt(r.URL.Query().Get("end"), 10, 64)
+		step, _ := strconv.ParseInt(r.URL.Query().Get("step"), 10, 64)
+		if points := (end-start)/step + 1; points > maxPointsPerQuery {
+			t.Errorf("request covers %d points, want at most %d", points, maxPointsPerQuery)
+		}
+
+		var values []string
+		for ts := start; ts <= end; ts += step {
+			values = append(values, fmt.Sprintf(`[%d, "1"]`, ts))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[%s]}]}}`,
+			strings.Join(values, ","))
+	}))
+	defer server.Close()
+
+	ad := &PrometheusAdapter{ServerURL: server.URL, Query: "q", StepSeconds: 60}
+	start := time.Unix(1700000000, 0)
+	end := start.Add(25000 * time.Minute)
+	df, err := ad.CollectRange(context.Background(), start, end)
+	if err != nil {
+		t.Fatalf("CollectRange error: %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("requests = %d, want 3", requests)
+	}
+	if len(df.Rows) != 25001 {
+		t.Fatalf("expected 25001 rows, got %d", len(df.Rows))
+	}
+
+	prev := time.Time{}
+	for i, row := range df.Rows {
+		ts, err := time.Parse(time.RFC3339, row["ts"].(string))
+		if err != nil {
+			t.Fatalf("row %d ts parse: %v", i, err)
+		}
+		if !prev.IsZero() && !ts.After(prev) {
+			t.Fatalf("row %d: timestamps not strictly increasing at %v", i, ts)
+		}
+		prev = ts
+	}
+}
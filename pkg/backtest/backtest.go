@@ -0,0 +1,316 @@
+// Package backtest replays recorded history through a forecasting model and
+// capacity policy to estimate how predictive scaling would have behaved.
+//
+// A backtest simulates the forecast loop: at every tick it trains the model
+// on the window of history that would have been available at that time,
+// predicts the horizon, and plans replicas with the policy exactly as the
+// forecaster does. It then compares the plan with what actually happened:
+//
+//   - forecast error of every forecast step against the realised value
+//   - under-provisioned pod-minutes: pods that were needed but not running
+//   - over-provisioned pod-minutes: pods that were running but not needed
+//   - scaling churn: how often and by how much the replica count changed
+//
+// The replicas needed at a point in time are ceil(actual / TargetPerPod), or
+// read off the policy's Curve;
+// headroom is deliberately not included, so it shows up as over-provisioning.
+// The simulation starts from the replicas needed at the first tick, so a
+// ramp-up from MinReplicas does not count against a scenario.
+//
+// Several scenarios (model and policy combinations) can be run over the same
+// history to compare them side by side.
+package backtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/HatiCode/kedastral/pkg/accuracy"
+	"github.com/HatiCode/kedastral/pkg/adapters"
+	"github.com/HatiCode/kedastral/pkg/capacity"
+	"github.com/HatiCode/kedastral/pkg/features"
+	"github.com/HatiCode/kedastral/pkg/models"
+)
+
+// Options configures the simulated forecast loop.
+type Options struct {
+	// Start and End bound the simulated ticks. History from Start - Window
+	// on must be present in the frame for the first tick to see a full window.
+	Start, End time.Time
+
+	// Step, Horizon and Window mirror the forecaster settings of the same
+	// name.
+	Step, Horizon, Window time.Duration
+
+	// Interval is the time between simulated ticks (default Step).
+	Interval time.Duration
+
+	// FullTrainInterval is how often models implementing
+	// models.IncrementalModel are trained on the full window; in between
+	// they are only updated with new rows. Zero retrains on every tick.
+	FullTrainInterval time.Duration
+
+	// ChangePoints enables change-point detection on full training runs, as
+	// in the forecaster. Nil trains on the whole window.
+	ChangePoints *models.ChangePointOptions
+
+	// ScalerLeadTime selects which planned step is applied, like the
+	// scaler's lead time (default 0: the first step).
+	ScalerLeadTime time.Duration
+
+	// StartupDelay is how long a planned replica count takes to become
+	// effective, e.g. pod scheduling and readiness (default 0).
+	StartupDelay time.Duration
+}
+
+// Scenario is a model and policy combination to evaluate.
+type Scenario struct {
+	// Name identifies the scenario in results.
+	Name string
+
+	// Model is trained and queried at every tick. Each scenario needs its
+	// own instance because scenarios run concurrently.
+	Model models.Model
+
+	// Policy converts forecasts to replicas.
+	Policy capacity.Policy
+}
+
+// Result summarises one scenario.
+type Result struct {
+	Scenario string `json:"scenario"`
+
+	// Model is the model name after the last training run.
+	Model string `json:"model"`
+
+	// Ticks is the number of simulated ticks; Failed counts ticks whose
+	// prediction failed and which kept the previous replica count.
+	Ticks  int `json:"ticks"`
+	Failed int `json:"failed"`
+
+	// Accuracy holds the forecast errors over all forecast steps.
+	Accuracy accuracy.StepAccuracy `json:"accuracy"`
+
+	// UnderPodMinutes and OverPodMinutes integrate the shortfall and surplus
+	// of running replicas relative to the replicas needed by the actual load.
+	UnderPodMinutes float64 `json:"underPodMinutes"`
+	OverPodMinutes  float64 `json:"overPodMinutes"`
+
+	// UnderProvisioned is the share of evaluated steps with a shortfall.
+	UnderProvisioned float64 `json:"underProvisioned"`
+
+	// MeanReplicas is the average running replica count.
+	MeanReplicas float64 `json:"meanReplicas"`
+
+	// ScaleEvents counts replica count changes; ReplicaChanges sums their
+	// absolute size.
+	ScaleEvents    int `json:"scaleEvents"`
+	ReplicaChanges int `json:"replicaChanges"`
+}
+
+// Load collects the history between start and end from adapter and builds
+// its features. The result can be passed to Run for any number of scenarios.
+func Load(ctx context.Context, adapter adapters.RangeAdapter, builder *features.Builder, start, end time.Time) (models.FeatureFrame, error) {
+	df, err := adapter.CollectRange(ctx, start, end)
+	if err != nil {
+		return models.FeatureFrame{}, fmt.Errorf("collect: %w", err)
+	}
+	frame, err := builder.BuildFeatures(*df)
+	if err != nil {
+		return models.FeatureFrame{}, fmt.Errorf("build features: %w", err)
+	}
+	return frame, nil
+}
+
+// Run replays frame through every scenario and returns their results in the
+// same order. Rows must be in time order and carry "timestamp" and "value".
+// Scenarios run concurrently.
+func Run(ctx context.Context, frame models.FeatureFrame, opts Options, scenarios []Scenario) ([]Result, error) {
+	if opts.Step <= 0 || opts.Horizon < opts.Step || opts.Window <= 0 {
+		return nil, errors.New("step, horizon and window must be positive, with horizon >= step")
+	}
+	if !opts.End.After(opts.Start) {
+		return nil, errors.New("end must be after start")
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = opts.Step
+	}
+	for i, row := range frame.Rows {
+		if _, ok := row["timestamp"]; !ok {
+			return nil, fmt.Errorf("row %d missing 'timestamp' field", i)
+		}
+		if _, ok := row["value"]; !ok {
+			return nil, fmt.Errorf("row %d missing 'value' field", i)
+		}
+	}
+
+	results := make([]Result, len(scenarios))
+	errs := make([]error, len(scenarios))
+	var wg sync.WaitGroup
+	for i, sc := range scenarios {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = run(ctx, frame, opts, sc)
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// decision is the replica count planned at a tick.
+type decision struct {
+	at       float64 // Unix time the count becomes effective
+	replicas int
+}
+
+// run simulates one scenario.
+func run(ctx context.Context, frame models.FeatureFrame, opts Options, sc Scenario) (Result, error) {
+	if sc.Model == nil {
+		return Result{}, fmt.Errorf("scenario %q: model is required", sc.Name)
+	}
+	result := Result{Scenario: sc.Name}
+	// The forecaster's trainer, so that incremental updates, full retrains
+	// and change points are replayed as in production.
+	trainer := models.Trainer{
+		Step:              opts.Step,
+		FullTrainInterval: opts.FullTrainInterval,
+		ChangePoints:      opts.ChangePoints,
+	}
+
+	stepSec := int(opts.Step.Seconds())
+	leadSteps := int(opts.ScalerLeadTime / opts.Step)
+	tracker := accuracy.NewTracker(math.MaxInt)
+
+	var decisions []decision
+	var history capacity.History // stabilisation windows and cooldowns span ticks
+	replicas := -1               // set from the load at the first tick
+	first, next := 0, 0          // window rows are frame.Rows[first:next]
+	for tick := opts.Start; !tick.After(opts.End); tick = tick.Add(opts.Interval) {
+		if err := ctx.Err(); err != nil {
+			return Result{}, err
+		}
+
+		now := float64(tick.Unix())
+		seen := next
+		for next < len(frame.Rows) && frame.Rows[next]["timestamp"] <= now {
+			next++
+		}
+		for first < next && frame.Rows[first]["timestamp"] <= now-opts.Window.Seconds() {
+			first++
+		}
+		if first == next {
+			continue
+		}
+		window := models.FeatureFrame{Rows: frame.Rows[first:next]}
+		if replicas < 0 {
+			replicas = needed(window.Rows[len(window.Rows)-1]["value"], sc.Policy)
+		}
+		tracker.Observe(sc.Name, models.FeatureFrame{Rows: frame.Rows[seen:next]})
+		result.Ticks++
+
+		// As in the forecaster, training failures are tolerated: some models
+		// can still predict from an earlier fit.
+		_, _ = trainer.Train(ctx, sc.Model, window, tick)
+
+		forecast, err := sc.Model.Predict(ctx, window)
+		if err != nil || len(forecast.Values) == 0 {
+			result.Failed++
+			continue
+		}
+		origin := window.Rows[len(window.Rows)-1]["timestamp"]
+		tracker.Record(sc.Name, time.Unix(int64(origin), 0), forecast)
+
+		desired := history.ToReplicas(tick, replicas, planningSeries(forecast, sc.Policy), stepSec, sc.Policy)
+		replicas = desired[0]
+
+		applied := desired[min(max(leadSteps, 0), len(desired)-1)]
+		if n := len(decisions); n > 0 && decisions[n-1].replicas != applied {
+			result.ScaleEvents++
+			result.ReplicaChanges += abs(applied - decisions[n-1].replicas)
+		}
+		decisions = append(decisions, decision{at: now + opts.StartupDelay.Seconds(), replicas: applied})
+	}
+
+	result.Model = sc.Model.Name()
+	if report, ok := tracker.Report(sc.Name); ok {
+		result.Accuracy = report.Overall
+	}
+	provision(&result, frame, decisions, sc.Policy, float64(opts.End.Unix()), opts.Step)
+	return result, nil
+}
+
+// planningSeries mirrors the forecaster: burst sizes when the policy plans on
+// bursts, else the policy's quantile, else the point forecast.
+func planningSeries(forecast models.Forecast, p capacity.Policy) []float64 {
+	if p.BurstProbability > 0 && forecast.Burst != nil {
+		if series, ok := capacity.BurstSeries(forecast.Burst.Probability, forecast.Burst.Size, p); ok {
+			return series
+		}
+	}
+	series, _ := capacity.SelectSeries(forecast.Values, forecast.Quantiles, p)
+	return series
+}
+
+// provision compares the running replicas with the replicas needed by the
+// actual load at every row from the first effective decision up to end.
+func provision(result *Result, frame models.FeatureFrame, decisions []decision, p capacity.Policy, end float64, step time.Duration) {
+	if len(decisions) == 0 {
+		return
+	}
+	stepMinutes := step.Minutes()
+
+	d, evaluated, under, running := 0, 0, 0, 0
+	for _, row := range frame.Rows {
+		ts := row["timestamp"]
+		if ts < decisions[0].at || ts > end {
+			continue
+		}
+		for d+1 < len(decisions) && decisions[d+1].at <= ts {
+			d++
+		}
+		have := decisions[d].replicas
+		need := needed(row["value"], p)
+
+		evaluated++
+		running += have
+		if need > have {
+			under++
+			result.UnderPodMinutes += float64(need-have) * stepMinutes
+		} else {
+			result.OverPodMinutes += float64(have-need) * stepMinutes
+		}
+	}
+	if evaluated > 0 {
+		result.UnderProvisioned = float64(under) / float64(evaluated)
+		result.MeanReplicas = float64(running) / float64(evaluated)
+	}
+}
+
+// needed returns the replicas a load needs: ceil(value / TargetPerPod), or
+// the fewest replicas sustaining it along the policy's Curve.
+func needed(value float64, p capacity.Policy) int {
+	if p.Curve != nil {
+		return int(math.Min(math.Ceil(p.Curve.Replicas(math.Max(value, 0))), math.MaxInt32))
+	}
+	target := p.TargetPerPod
+	if target <= 0 {
+		target = 1
+	}
+	return int(math.Ceil(math.Max(value, 0) / target))
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
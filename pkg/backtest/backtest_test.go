@@ -0,0 +1,206 @@
+package backtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/HatiCode/kedastral/pkg/capacity"
+	"github.com/HatiCode/kedastral/pkg/models"
+)
+
+// naiveModel forecasts the last observed value for every step.
+type naiveModel struct {
+	horizon int
+	last    float64
+}
+
+func (m *naiveModel) Name() string { return "naive" }
+
+func (m *naiveModel) Train(_ context.Context, history models.FeatureFrame) error {
+	if len(history.Rows) == 0 {
+		return errors.New("empty history")
+	}
+	m.last = history.Rows[len(history.Rows)-1]["value"]
+	return nil
+}
+
+func (m *naiveModel) Predict(_ context.Context, _ models.FeatureFrame) (models.Forecast, error) {
+	values := make([]float64, m.horizon)
+	for i := range values {
+		values[i] = m.last
+	}
+	return models.Forecast{Metric: "rps", Values: values, StepSec: 60, Horizon: 60 * m.horizon}, nil
+}
+
+// series returns one row per minute starting at start, with value(i).
+func series(start time.Time, n int, value func(i int) float64) models.FeatureFrame {
+	rows := make([]map[string]float64, n)
+	for i := range rows {
+		rows[i] = map[string]float64{
+			"timestamp": float64(start.Add(time.Duration(i) * time.Minute).Unix()),
+			"value":     value(i),
+		}
+	}
+	return models.FeatureFrame{Rows: rows}
+}
+
+func TestRun(t *testing.T) {
+	base := time.Unix(1700000000, 0).UTC()
+	start := base.Add(time.Hour)
+	end := start.Add(2 * time.Hour)
+	jump := start.Add(time.Hour)
+
+	frame := series(base, 181, func(i int) float64 {
+		if base.Add(time.Duration(i) * time.Minute).Before(jump) {
+			return 100
+		}
+		return 200
+	})
+	policy := capacity.Policy{TargetPerPod: 10, Headroom: 1, MinReplicas: 1, MaxReplicas: 50, DownMaxPercentPerStep: 50}
+
+	tests := []struct {
+		name         string
+		startupDelay time.Duration
+		wantUnder    float64
+	}{
+		// The naive forecast reacts on the tick the load changes.
+		{"immediate", 0, 0},
+		// With a one-minute startup delay the first minute at 200 runs on
+		// 10 pods instead of 20.
+		{"startup delay", time.Minute, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{
+				Start:        start,
+				End:          end,
+				Step:         time.Minute,
+				Horizon:      5 * time.Minute,
+				Window:       time.Hour,
+				StartupDelay: tt.startupDelay,
+			}
+			results, err := Run(context.Background(), frame, opts, []Scenario{
+				{Name: "naive", Model: &naiveModel{horizon: 5}, Policy: policy},
+			})
+			if err != nil {
+				t.Fatalf("Run error: %v", err)
+			}
+			r := results[0]
+
+			if r.Ticks != 121 || r.Failed != 0 {
+				t.Errorf("ticks = %d, failed = %d, want 121 and 0", r.Ticks, r.Failed)
+			}
+			if r.Model != "naive" {
+				t.Errorf("model = %q, want naive", r.Model)
+			}
+			if r.UnderPodMinutes != tt.wantUnder {
+				t.Errorf("under-provisioned = %v pod-minutes, want %v", r.UnderPodMinutes, tt.wantUnder)
+			}
+			if r.OverPodMinutes != 0 {
+				t.Errorf("over-provisioned = %v pod-minutes, want 0", r.OverPodMinutes)
+			}
+			if r.ScaleEvents != 1 || r.ReplicaChanges != 10 {
+				t.Errorf("churn = %d events, %d replicas, want 1 and 10", r.ScaleEvents, r.ReplicaChanges)
+			}
+
+			// Forecasts made in the five minutes before the jump miss it.
+			if r.Accuracy.Samples == 0 || r.Accuracy.MAE <= 0 || r.Accuracy.Bias >= 0 {
+				t.Errorf("accuracy = %+v, want samples, positive MAE and negative bias", r.Accuracy)
+			}
+		})
+	}
+}
+
+func TestRun_ComparesScenarios(t *testing.T) {
+	base := time.Unix(1700000000, 0).UTC()
+	frame := series(base, 120, func(int) float64 { return 100 })
+	opts := Options{
+		Start:    base.Add(time.Hour),
+		End:      base.Add(119 * time.Minute),
+		Step:     time.Minute,
+		Horizon:  5 * time.Minute,
+		Window:   time.Hour,
+		Interval: 5 * time.Minute,
+	}
+	tight := capacity.Policy{TargetPerPod: 10, Headroom: 1, MinReplicas: 1}
+	generous := capacity.Policy{TargetPerPod: 10, Headroom: 1.5, MinReplicas: 1}
+
+	results, err := Run(context.Background(), frame, opts, []Scenario{
+		{Name: "tight", Model: &naiveModel{horizon: 5}, Policy: tight},
+		{Name: "generous", Model: &naiveModel{horizon: 5}, Policy: generous},
+	})
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if len(results) != 2 || results[0].Scenario != "tight" || results[1].Scenario != "generous" {
+		t.Fatalf("results = %+v, want tight then generous", results)
+	}
+	if results[0].Ticks != 12 {
+		t.Errorf("ticks = %d, want 12", results[0].Ticks)
+	}
+	if results[0].Accuracy.MAE != 0 {
+		t.Errorf("MAE = %v, want 0 for a constant series", results[0].Accuracy.MAE)
+	}
+	if results[0].OverPodMinutes != 0 || results[0].MeanReplicas != 10 {
+		t.Errorf("tight: over = %v, mean replicas = %v, want 0 and 10", results[0].OverPodMinutes, results[0].MeanReplicas)
+	}
+	// Headroom shows up as over-provisioning: 15 pods for a need of 10.
+	if results[1].MeanReplicas != 15 || results[1].OverPodMinutes != 5*float64(60) {
+		t.Errorf("generous: mean replicas = %v, over = %v, want 15 and 300", results[1].MeanReplicas, results[1].OverPodMinutes)
+	}
+}
+
+func TestRun_IncrementalTraining(t *testing.T) {
+	base := time.Unix(1700000000, 0).UTC()
+	frame := series(base, 180, func(i int) float64 { return float64(i % 7) })
+	model := models.NewBaselineModel("rps", 60, 300)
+	opts := Options{
+		Start:             base.Add(time.Hour),
+		End:               base.Add(179 * time.Minute),
+		Step:              time.Minute,
+		Horizon:           5 * time.Minute,
+		Window:            time.Hour,
+		FullTrainInterval: 30 * time.Minute,
+	}
+
+	results, err := Run(context.Background(), frame, opts, []Scenario{
+		{Name: "baseline", Model: model, Policy: capacity.Policy{TargetPerPod: 1, MinReplicas: 1}},
+	})
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if results[0].Failed != 0 {
+		t.Errorf("failed ticks = %d, want 0", results[0].Failed)
+	}
+}
+
+func TestRun_Validates(t *testing.T) {
+	base := time.Unix(1700000000, 0).UTC()
+	frame := series(base, 10, func(int) float64 { return 1 })
+	valid := Options{Start: base, End: base.Add(time.Hour), Step: time.Minute, Horizon: 5 * time.Minute, Window: time.Hour}
+	scenario := []Scenario{{Name: "naive", Model: &naiveModel{horizon: 5}}}
+
+	tests := []struct {
+		name      string
+		modify    func(*Options)
+		frame     models.FeatureFrame
+		scenarios []Scenario
+	}{
+		{"zero step", func(o *Options) { o.Step = 0 }, frame, scenario},
+		{"horizon below step", func(o *Options) { o.Horizon = time.Second }, frame, scenario},
+		{"end before start", func(o *Options) { o.End = o.Start }, frame, scenario},
+		{"missing value", func(*Options) {}, models.FeatureFrame{Rows: []map[string]float64{{"timestamp": 1}}}, scenario},
+		{"missing model", func(*Options) {}, frame, []Scenario{{Name: "empty"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := valid
+			tt.modify(&opts)
+			if _, err := Run(context.Background(), tt.frame, opts, tt.scenarios); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
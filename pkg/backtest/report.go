@@ -0,0 +1,123 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+// Format selects how results are written.
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatCSV   Format = "csv"
+)
+
+// ParseFormat parses "table", "json" or "csv".
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatTable, FormatJSON, FormatCSV:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q (want table, json or csv)", s)
+	}
+}
+
+// Write writes results to w in the given format.
+func Write(w io.Writer, format Format, results []Result) error {
+	switch format {
+	case FormatJSON:
+		return WriteJSON(w, results)
+	case FormatCSV:
+		return WriteCSV(w, results)
+	default:
+		return WriteTable(w, results)
+	}
+}
+
+// columns are the table and CSV columns, in order.
+var columns = []string{
+	"scenario", "model", "ticks", "failed",
+	"mae", "mape", "smape", "bias", "coverage80",
+	"under_pod_min", "over_pod_min", "under_pct", "mean_replicas",
+	"scale_events", "replica_changes",
+}
+
+// WriteTable writes results as an aligned text table, one scenario per row.
+func WriteTable(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i, c := range columns {
+		if i > 0 {
+			fmt.Fprint(tw, "\t")
+		}
+		fmt.Fprint(tw, c)
+	}
+	fmt.Fprintln(tw)
+	for _, r := range results {
+		for i, v := range record(r, 2) {
+			if i > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			if v == "" {
+				v = "-"
+			}
+			fmt.Fprint(tw, v)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+// WriteJSON writes results as an indented JSON array.
+func WriteJSON(w io.Writer, results []Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+// WriteCSV writes results as CSV with a header row.
+func WriteCSV(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if err := cw.Write(record(r, -1)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// record formats a result as a row of columns with prec decimals (-1 for
+// full precision). Coverage is empty when the forecasts had no quantiles.
+func record(r Result, prec int) []string {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', prec, 64) }
+	coverage := ""
+	if c, ok := r.Accuracy.Coverage["80"]; ok {
+		coverage = f(c)
+	}
+	return []string{
+		r.Scenario,
+		r.Model,
+		strconv.Itoa(r.Ticks),
+		strconv.Itoa(r.Failed),
+		f(r.Accuracy.MAE),
+		f(r.Accuracy.MAPE),
+		f(r.Accuracy.SMAPE),
+		f(r.Accuracy.Bias),
+		coverage,
+		f(r.UnderPodMinutes),
+		f(r.OverPodMinutes),
+		f(100 * r.UnderProvisioned),
+		f(r.MeanReplicas),
+		strconv.Itoa(r.ScaleEvents),
+		strconv.Itoa(r.ReplicaChanges),
+	}
+}
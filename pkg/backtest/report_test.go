@@ -0,0 +1,98 @@
+package backtest
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/HatiCode/kedastral/pkg/accuracy"
+)
+
+var sampleResults = []Result{
+	{
+		Scenario:         "baseline",
+		Model:            "baseline",
+		Ticks:            100,
+		Accuracy:         accuracy.StepAccuracy{Samples: 500, MAE: 12.5, MAPE: 4.2, SMAPE: 4.1, Bias: -1.5},
+		UnderPodMinutes:  30,
+		OverPodMinutes:   120.5,
+		UnderProvisioned: 0.05,
+		MeanReplicas:     7.25,
+		ScaleEvents:      12,
+		ReplicaChanges:   20,
+	},
+	{
+		Scenario: "arima-p90",
+		Model:    "arima(1,1,1)",
+		Ticks:    100,
+		Accuracy: accuracy.StepAccuracy{Samples: 500, MAE: 10, Coverage: map[string]float64{"80": 0.78}},
+	},
+}
+
+func TestWriteTable(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteTable(&buf, sampleResults); err != nil {
+		t.Fatalf("WriteTable error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header and 2 rows, got %d lines:\n%s", len(lines), buf.String())
+	}
+	if fields := strings.Fields(lines[0]); len(fields) != len(columns) {
+		t.Errorf("header has %d columns, want %d", len(fields), len(columns))
+	}
+	// Missing coverage is shown as "-" so that columns stay aligned.
+	if fields := strings.Fields(lines[1]); len(fields) != len(columns) || fields[8] != "-" {
+		t.Errorf("row = %q, want %d columns with coverage '-'", lines[1], len(columns))
+	}
+	if !strings.Contains(lines[2], "0.78") {
+		t.Errorf("row = %q, want coverage 0.78", lines[2])
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, sampleResults); err != nil {
+		t.Fatalf("WriteCSV error: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	want := []string{"baseline", "baseline", "100", "0", "12.5", "4.2", "4.1", "-1.5", "", "30", "120.5", "5", "7.25", "12", "20"}
+	for i, v := range want {
+		if records[1][i] != v {
+			t.Errorf("column %s = %q, want %q", columns[i], records[1][i], v)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, sampleResults); err != nil {
+		t.Fatalf("WriteJSON error: %v", err)
+	}
+	var decoded []Result
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("decode json: %v", err)
+	}
+	if len(decoded) != 2 || decoded[1].Accuracy.Coverage["80"] != 0.78 || decoded[0].UnderPodMinutes != 30 {
+		t.Errorf("decoded = %+v, want the sample results", decoded)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, s := range []string{"table", "json", "csv"} {
+		if f, err := ParseFormat(s); err != nil || string(f) != s {
+			t.Errorf("ParseFormat(%q) = %q, %v", s, f, err)
+		}
+	}
+	if _, err := ParseFormat("yaml"); err == nil {
+		t.Errorf("expected error for yaml")
+	}
+}
@@ -0,0 +1,205 @@
+package capacity
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Curve is a nonlinear capacity model: the sustainable load of a number of
+// replicas, for services that do not scale linearly with TargetPerPod, e.g.
+// because of contention on a shared database.
+type Curve interface {
+	// Load returns the sustainable load of the given number of replicas.
+	Load(replicas float64) float64
+
+	// Replicas returns the fewest, possibly fractional, replicas sustaining
+	// load. When no count does, it returns the replicas sustaining the most
+	// load, or +Inf when load keeps growing with replicas without reaching it.
+	Replicas(load float64) float64
+}
+
+// CurvePoint is a measured replica count and the load it sustains.
+type CurvePoint struct {
+	Replicas float64
+	Load     float64
+}
+
+// PiecewiseLinear is a Curve interpolating linearly between measured points,
+// sorted by replicas, from (0, 0). Beyond the last point it extends the last
+// segment while that gains load; otherwise the load stays at the last point's.
+type PiecewiseLinear struct {
+	points []CurvePoint
+}
+
+// NewPiecewiseLinear returns the curve through points, which must have
+// strictly increasing positive replicas and non-negative loads.
+func NewPiecewiseLinear(points []CurvePoint) (PiecewiseLinear, error) {
+	if len(points) == 0 {
+		return PiecewiseLinear{}, errors.New("capacity curve needs at least one point")
+	}
+	prev := 0.0
+	for _, pt := range points {
+		if pt.Replicas <= prev {
+			return PiecewiseLinear{}, fmt.Errorf("capacity curve replicas must be positive and increasing, got %g after %g", pt.Replicas, prev)
+		}
+		if pt.Load < 0 || math.IsNaN(pt.Load) || math.IsInf(pt.Load, 0) {
+			return PiecewiseLinear{}, fmt.Errorf("capacity curve load at %g replicas must be finite and non-negative", pt.Replicas)
+		}
+		prev = pt.Replicas
+	}
+	return PiecewiseLinear{points: append([]CurvePoint{{}}, points...)}, nil
+}
+
+// Load implements Curve.
+func (c PiecewiseLinear) Load(replicas float64) float64 {
+	if len(c.points) == 0 || replicas <= 0 {
+		return 0
+	}
+	for i := 1; i < len(c.points); i++ {
+		a, b := c.points[i-1], c.points[i]
+		if replicas <= b.Replicas {
+			return a.Load + (replicas-a.Replicas)/(b.Replicas-a.Replicas)*(b.Load-a.Load)
+		}
+	}
+	a, b := c.points[len(c.points)-2], c.points[len(c.points)-1]
+	if b.Load <= a.Load {
+		return b.Load
+	}
+	return b.Load + (replicas-b.Replicas)/(b.Replicas-a.Replicas)*(b.Load-a.Load)
+}
+
+// Replicas implements Curve.
+func (c PiecewiseLinear) Replicas(load float64) float64 {
+	if load <= 0 || len(c.points) == 0 {
+		return 0
+	}
+	best := c.points[0]
+	for i := 1; i < len(c.points); i++ {
+		a, b := c.points[i-1], c.points[i]
+		if load == b.Load {
+			return b.Replicas
+		}
+		if load < b.Load && load > a.Load {
+			return a.Replicas + (load-a.Load)/(b.Load-a.Load)*(b.Replicas-a.Replicas)
+		}
+		if b.Load > best.Load {
+			best = b
+		}
+	}
+	a, b := c.points[len(c.points)-2], c.points[len(c.points)-1]
+	if b.Load <= a.Load {
+		return best.Replicas
+	}
+	return b.Replicas + (load-b.Load)/(b.Load-a.Load)*(b.Replicas-a.Replicas)
+}
+
+// USL is a Curve following the Universal Scalability Law:
+//
+//	Load(N) = Lambda*N / (1 + Sigma*(N-1) + Kappa*N*(N-1))
+//
+// Lambda is the load of a single replica, Sigma the contention (serialised
+// fraction of work) and Kappa the coherency (crosstalk) penalty. With Kappa
+// > 0, load peaks at sqrt((1-Sigma)/Kappa) replicas and declines beyond.
+type USL struct {
+	Lambda float64
+	Sigma  float64
+	Kappa  float64
+}
+
+// Load implements Curve.
+func (u USL) Load(replicas float64) float64 {
+	if replicas <= 0 {
+		return 0
+	}
+	return u.Lambda * replicas / (1 + u.Sigma*(replicas-1) + u.Kappa*replicas*(replicas-1))
+}
+
+// Replicas implements Curve by solving Load(N) = load, a quadratic in N.
+func (u USL) Replicas(load float64) float64 {
+	if load <= 0 {
+		return 0
+	}
+	// load*Kappa*N² + (load*(Sigma-Kappa) - Lambda)*N + load*(1-Sigma) = 0
+	a := load * u.Kappa
+	b := load*(u.Sigma-u.Kappa) - u.Lambda
+	c := load * (1 - u.Sigma)
+
+	var n float64
+	switch {
+	case a > 0:
+		disc := b*b - 4*a*c
+		if disc < 0 {
+			return math.Sqrt((1 - u.Sigma) / u.Kappa)
+		}
+		// The smaller root, in a form that avoids cancellation.
+		n = 2 * c / (-b + math.Sqrt(disc))
+	case b < 0:
+		n = -c / b
+	default:
+		return math.Inf(1)
+	}
+	// Snap results that are whole numbers up to rounding, so that ceil does
+	// not add a replica.
+	if r := math.Round(n); math.Abs(n-r) < 1e-9*math.Max(1, n) {
+		return r
+	}
+	return n
+}
+
+// ParseCurve parses a capacity curve specification:
+//
+//	table:1=100,5=450,20=1200   piecewise-linear replicas=load points
+//	usl:100,0.05,0.001          Universal Scalability Law lambda,sigma,kappa
+func ParseCurve(spec string) (Curve, error) {
+	kind, args, ok := strings.Cut(strings.TrimSpace(spec), ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid capacity curve %q: want table:replicas=load,... or usl:lambda,sigma,kappa", spec)
+	}
+	switch kind {
+	case "table":
+		var points []CurvePoint
+		for item := range strings.SplitSeq(args, ",") {
+			replicasRaw, loadRaw, ok := strings.Cut(strings.TrimSpace(item), "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid capacity curve point %q: want replicas=load", item)
+			}
+			replicas, err := strconv.ParseFloat(replicasRaw, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid capacity curve point %q: %w", item, err)
+			}
+			load, err := strconv.ParseFloat(loadRaw, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid capacity curve point %q: %w", item, err)
+			}
+			points = append(points, CurvePoint{Replicas: replicas, Load: load})
+		}
+		curve, err := NewPiecewiseLinear(points)
+		if err != nil {
+			return nil, err
+		}
+		return curve, nil
+	case "usl":
+		fields := strings.Split(args, ",")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid USL curve %q: want lambda,sigma,kappa", args)
+		}
+		var coef [3]float64
+		for i, f := range fields {
+			v, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid USL curve %q: %w", args, err)
+			}
+			coef[i] = v
+		}
+		u := USL{Lambda: coef[0], Sigma: coef[1], Kappa: coef[2]}
+		if u.Lambda <= 0 || u.Sigma < 0 || u.Sigma >= 1 || u.Kappa < 0 {
+			return nil, fmt.Errorf("invalid USL curve %q: want lambda > 0, 0 <= sigma < 1 and kappa >= 0", args)
+		}
+		return u, nil
+	default:
+		return nil, fmt.Errorf("unknown capacity curve kind %q: want table or usl", kind)
+	}
+}
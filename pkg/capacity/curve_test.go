@@ -0,0 +1,151 @@
+package capacity
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestPiecewiseLinear(t *testing.T) {
+	curve, err := NewPiecewiseLinear([]CurvePoint{{1, 100}, {5, 400}, {10, 600}, {20, 800}})
+	if err != nil {
+		t.Fatalf("NewPiecewiseLinear() error = %v", err)
+	}
+	tests := []struct {
+		replicas, load float64
+	}{
+		{0, 0},
+		{0.5, 50},
+		{1, 100},
+		{3, 250},
+		{5, 400},
+		{7.5, 500},
+		{20, 800},
+		{25, 900}, // extends the last segment
+	}
+	for _, tt := range tests {
+		if got := curve.Load(tt.replicas); math.Abs(got-tt.load) > 1e-9 {
+			t.Errorf("Load(%v) = %v, want %v", tt.replicas, got, tt.load)
+		}
+		if got := curve.Replicas(tt.load); math.Abs(got-tt.replicas) > 1e-9 {
+			t.Errorf("Replicas(%v) = %v, want %v", tt.load, got, tt.replicas)
+		}
+	}
+
+	// Past the peak, more replicas lose load: plan the peak.
+	peaked, _ := NewPiecewiseLinear([]CurvePoint{{10, 1000}, {20, 900}})
+	if got := peaked.Replicas(950); got != 9.5 {
+		t.Errorf("Replicas(950) = %v, want 9.5 before the peak", got)
+	}
+	if got := peaked.Replicas(2000); got != 10 {
+		t.Errorf("Replicas(2000) = %v, want the peak at 10", got)
+	}
+	if got := peaked.Load(30); got != 900 {
+		t.Errorf("Load(30) = %v, want 900 after the last point", got)
+	}
+
+	for _, points := range [][]CurvePoint{nil, {{0, 10}}, {{2, 10}, {1, 20}}, {{1, -1}}} {
+		if _, err := NewPiecewiseLinear(points); err == nil {
+			t.Errorf("NewPiecewiseLinear(%v) error = nil, want error", points)
+		}
+	}
+}
+
+func TestUSL(t *testing.T) {
+	u := USL{Lambda: 100, Sigma: 0.05, Kappa: 0.001}
+	for _, n := range []float64{1, 2, 5, 10, 20} {
+		if got := u.Replicas(u.Load(n)); math.Abs(got-n) > 1e-6 {
+			t.Errorf("Replicas(Load(%v)) = %v, want %v", n, got, n)
+		}
+	}
+	if got := u.Replicas(100); got != 1 {
+		t.Errorf("Replicas(100) = %v, want exactly 1", got)
+	}
+
+	// Load peaks at sqrt(0.95/0.001) ≈ 30.8 replicas.
+	peak := math.Sqrt(0.95 / 0.001)
+	if got := u.Replicas(1e6); math.Abs(got-peak) > 1e-9 {
+		t.Errorf("Replicas(1e6) = %v, want the peak %v", got, peak)
+	}
+	if u.Load(peak) < u.Load(peak-1) || u.Load(peak) < u.Load(peak+1) {
+		t.Errorf("Load is not highest at the peak %v", peak)
+	}
+
+	// Without coherency cost, load approaches Lambda/Sigma = 2000.
+	amdahl := USL{Lambda: 100, Sigma: 0.05}
+	if got := amdahl.Replicas(1000); math.Abs(got-19) > 1e-9 {
+		t.Errorf("Amdahl Replicas(1000) = %v, want 19", got)
+	}
+	if got := amdahl.Replicas(2000); !math.IsInf(got, 1) {
+		t.Errorf("Amdahl Replicas(2000) = %v, want +Inf", got)
+	}
+}
+
+func TestParseCurve(t *testing.T) {
+	curve, err := ParseCurve("table:1=100, 5=400")
+	if err != nil {
+		t.Fatalf("ParseCurve(table) error = %v", err)
+	}
+	if got := curve.Replicas(250); got != 3 {
+		t.Errorf("table Replicas(250) = %v, want 3", got)
+	}
+
+	curve, err = ParseCurve("usl:100,0.05,0.001")
+	if err != nil {
+		t.Fatalf("ParseCurve(usl) error = %v", err)
+	}
+	if want := (USL{Lambda: 100, Sigma: 0.05, Kappa: 0.001}); curve != want {
+		t.Errorf("ParseCurve(usl) = %+v, want %+v", curve, want)
+	}
+
+	for _, spec := range []string{"", "1=100", "table:", "table:1", "table:x=1", "table:2=10,1=20", "usl:100,0.05", "usl:0,0,0", "usl:100,1,0", "usl:100,0,-1", "linear:100"} {
+		if _, err := ParseCurve(spec); err == nil {
+			t.Errorf("ParseCurve(%q) error = nil, want error", spec)
+		}
+	}
+}
+
+func TestToReplicas_Curve(t *testing.T) {
+	curve, _ := NewPiecewiseLinear([]CurvePoint{{1, 100}, {5, 400}, {10, 600}, {20, 800}})
+	p := Policy{
+		TargetPerPod:          100, // ignored with a curve
+		Headroom:              1.0,
+		MinReplicas:           1,
+		UpMaxFactorPerStep:    100,
+		DownMaxPercentPerStep: 100,
+		Curve:                 curve,
+	}
+	// 400 → 5, 500 → 7.5 → 8, 800 → 20 pods; linear would plan 4, 5, 8.
+	got := ToReplicas(1, []float64{400, 500, 800}, 60, p)
+	if want := []int{5, 8, 20}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ToReplicas() = %v, want %v", got, want)
+	}
+
+	// Headroom applies to the load: 250 * 1.6 = 400 → 5.
+	p.Headroom = 1.6
+	if got := ToReplicas(1, []float64{250}, 60, p); !reflect.DeepEqual(got, []int{5}) {
+		t.Errorf("ToReplicas() with headroom = %v, want [5]", got)
+	}
+
+	// Load no replica count sustains plans up to MaxReplicas.
+	p = Policy{Headroom: 1, MaxReplicas: 50, UpMaxFactorPerStep: 100, DownMaxPercentPerStep: 100, Curve: USL{Lambda: 100, Sigma: 0.05}}
+	if got := ToReplicas(40, []float64{5000}, 60, p); !reflect.DeepEqual(got, []int{50}) {
+		t.Errorf("ToReplicas() beyond the curve = %v, want [50]", got)
+	}
+}
+
+func TestToReplicasMulti_Curve(t *testing.T) {
+	curve, _ := NewPiecewiseLinear([]CurvePoint{{5, 400}, {10, 600}})
+	p := Policy{TargetPerPod: 100, Headroom: 1, UpMaxFactorPerStep: 100, DownMaxPercentPerStep: 100, Curve: curve}
+
+	got := ToReplicasMulti(1, []Signal{
+		{Name: "rps", Forecast: []float64{500, 100}},                        // policy curve: 7.5, 1.25
+		{Name: "backlog", Forecast: []float64{300, 300}, TargetPerPod: 100}, // linear: 3, 3
+	}, 60, p)
+	if want := []int{8, 3}; !reflect.DeepEqual(got.Replicas, want) {
+		t.Errorf("Replicas = %v, want %v", got.Replicas, want)
+	}
+	if want := []string{"rps", "backlog"}; !reflect.DeepEqual(got.Binding, want) {
+		t.Errorf("Binding = %v, want %v", got.Binding, want)
+	}
+}
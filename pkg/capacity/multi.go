@@ -0,0 +1,101 @@
+package capacity
+
+import "time"
+
+// Signal is one forecast load series that bounds a workload's capacity, such
+// as requests per second or queue backlog, with its own per-pod target.
+type Signal struct {
+	// Name identifies the signal in MultiPlan.Binding (e.g., "rps", "backlog").
+	Name string
+
+	// Forecast holds the signal's forecast values for each future step.
+	Forecast []float64
+
+	// TargetPerPod is the signal's sustainable value per pod.
+	// Zero uses the policy's Curve or TargetPerPod.
+	TargetPerPod float64
+
+	// Curve, when set, replaces TargetPerPod with a nonlinear capacity model.
+	Curve Curve
+
+	// Headroom is the signal's multiplicative safety factor.
+	// Zero uses the policy's Headroom; other values below 1 are treated as 1.
+	Headroom float64
+}
+
+// MultiPlan is the result of ToReplicasMulti.
+type MultiPlan struct {
+	// Replicas holds the desired replicas at each step.
+	Replicas []int
+
+	// Binding names the signal needing the most pods at each step, before
+	// bounds and change clamps; the first such signal on ties.
+	Binding []string
+}
+
+// ToReplicasMulti converts several forecast signals into desired replicas:
+// at each step the pods needed by every signal are computed as in ToReplicas,
+// with its own target per pod and headroom, and the workload gets the maximum.
+// Bounds and change clamps of the policy apply once, to the combined series.
+//
+// The plan covers the steps forecast by every signal, i.e. the length of the
+// shortest non-empty Forecast. Signals without values are ignored. Returns an
+// empty plan when no signal has values.
+//
+// Like ToReplicas, it ignores the policy's overrides; History.ToReplicasMulti
+// applies them.
+func ToReplicasMulti(prev int, signals []Signal, stepSec int, p Policy) MultiPlan {
+	p, stepSec = p.sanitize(stepSec)
+	need, binding, steps := combineSignals(time.Time{}, signals, stepSec, p)
+	if need == nil {
+		return MultiPlan{}
+	}
+	return MultiPlan{
+		Replicas: clampSeries(prev, need, stepSec, p, steps, &trail{}),
+		Binding:  binding,
+	}
+}
+
+// combineSignals returns the maximum pods needed by the signals at each step,
+// the signal binding it, and the policy in effect at each step starting at
+// start, or nils when no signal has values. Signals without a headroom use
+// the one in effect at each step. p must be sanitized.
+func combineSignals(start time.Time, signals []Signal, stepSec int, p Policy) ([]float64, []string, []Policy) {
+	steps := 0
+	for _, s := range signals {
+		if n := len(s.Forecast); n > 0 && (steps == 0 || n < steps) {
+			steps = n
+		}
+	}
+	if steps == 0 {
+		return nil, nil, nil
+	}
+	policies := p.stepPolicies(start, stepSec, steps)
+
+	need := make([]float64, steps)
+	binding := make([]string, steps)
+	first := true
+	for _, s := range signals {
+		if len(s.Forecast) == 0 {
+			continue
+		}
+		target, curve := s.TargetPerPod, s.Curve
+		if curve == nil && target <= 0 {
+			target, curve = p.TargetPerPod, p.Curve
+		}
+		headroom := s.Headroom
+		if headroom != 0 {
+			headroom = max(headroom, 1)
+		}
+
+		pods := podsNeeded(s.Forecast[:steps], target, curve, headroom, stepSec, policies)
+		for i, v := range pods {
+			if first || v > need[i] {
+				need[i] = v
+				binding[i] = s.Name
+			}
+		}
+		first = false
+	}
+	return need, binding, policies
+}
@@ -0,0 +1,84 @@
+package capacity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestToReplicasMulti(t *testing.T) {
+	p := Policy{
+		TargetPerPod:          100,
+		Headroom:              1.0,
+		MinReplicas:           1,
+		MaxReplicas:           20,
+		UpMaxFactorPerStep:    10,
+		DownMaxPercentPerStep: 100,
+	}
+	rps := Signal{Name: "rps", Forecast: []float64{200, 800, 300, 100}}
+	// 50 queued jobs per pod, with 20% headroom.
+	backlog := Signal{Name: "backlog", Forecast: []float64{250, 100, 400, 2000}, TargetPerPod: 50, Headroom: 1.2}
+
+	got := ToReplicasMulti(1, []Signal{rps, backlog}, 60, p)
+	// rps:     2, 8, 3, 1
+	// backlog: 6, 2.4→3, 9.6→10, 48
+	// max:     6, 8, 10, 48 → capped at 20
+	if want := []int{6, 8, 10, 20}; !reflect.DeepEqual(got.Replicas, want) {
+		t.Errorf("Replicas = %v, want %v", got.Replicas, want)
+	}
+	if want := []string{"backlog", "rps", "backlog", "backlog"}; !reflect.DeepEqual(got.Binding, want) {
+		t.Errorf("Binding = %v, want %v", got.Binding, want)
+	}
+}
+
+func TestToReplicasMulti_MatchesToReplicas(t *testing.T) {
+	p := Policy{
+		TargetPerPod:          50,
+		Headroom:              1.2,
+		LeadTimeSeconds:       60,
+		MinReplicas:           1,
+		MaxReplicas:           100,
+		UpMaxFactorPerStep:    2.0,
+		DownMaxPercentPerStep: 50,
+	}
+	forecast := []float64{120, 130, 125, 140, 100}
+
+	got := ToReplicasMulti(2, []Signal{{Name: "rps", Forecast: forecast}}, 60, p)
+	if want := ToReplicas(2, forecast, 60, p); !reflect.DeepEqual(got.Replicas, want) {
+		t.Errorf("single signal Replicas = %v, want ToReplicas %v", got.Replicas, want)
+	}
+}
+
+func TestToReplicasMulti_ClampsCombinedOnce(t *testing.T) {
+	p := Policy{
+		TargetPerPod:          1,
+		MinReplicas:           1,
+		UpMaxFactorPerStep:    1.5,
+		DownMaxPercentPerStep: 100,
+	}
+	// Each signal alone would ramp up from 4; the combined series ramps once.
+	a := Signal{Name: "a", Forecast: []float64{4, 9, 2}}
+	b := Signal{Name: "b", Forecast: []float64{2, 3, 9}}
+
+	got := ToReplicasMulti(4, []Signal{a, b}, 60, p)
+	// need 4, 9, 9 → up clamp 6, 9, 9
+	if want := []int{4, 6, 9}; !reflect.DeepEqual(got.Replicas, want) {
+		t.Errorf("Replicas = %v, want %v", got.Replicas, want)
+	}
+}
+
+func TestToReplicasMulti_Lengths(t *testing.T) {
+	p := Policy{TargetPerPod: 10, MinReplicas: 0, UpMaxFactorPerStep: 100, DownMaxPercentPerStep: 100}
+
+	got := ToReplicasMulti(0, []Signal{
+		{Name: "short", Forecast: []float64{10, 20}},
+		{Name: "long", Forecast: []float64{5, 5, 500}},
+		{Name: "empty"},
+	}, 60, p)
+	if want := []int{1, 2}; !reflect.DeepEqual(got.Replicas, want) {
+		t.Errorf("Replicas = %v, want %v over the shortest signal", got.Replicas, want)
+	}
+
+	if got := ToReplicasMulti(3, []Signal{{Name: "empty"}}, 60, p); got.Replicas != nil || got.Binding != nil {
+		t.Errorf("ToReplicasMulti() without values = %+v, want empty plan", got)
+	}
+}
@@ -0,0 +1,185 @@
+package capacity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Override changes the policy during scheduled time windows, e.g. a replica
+// floor for daily morning logins or a fixed count during a sales event.
+// Zero fields leave the policy's value.
+type Override struct {
+	// Name identifies the override in configuration errors.
+	Name string
+
+	// Schedule starts a recurring window at each of its matches, lasting
+	// Duration. When Schedule is zero, the window is [Start, End).
+	Schedule Schedule
+	Duration time.Duration
+	Start    time.Time
+	End      time.Time
+
+	// MinReplicas and MaxReplicas replace the policy's bounds.
+	MinReplicas int
+	MaxReplicas int
+
+	// Headroom replaces the policy's headroom.
+	Headroom float64
+
+	// Replicas fixes the replica count, as both bounds.
+	Replicas int
+}
+
+// Active reports whether t falls in one of the override's windows.
+func (o Override) Active(t time.Time) bool {
+	if !o.Schedule.IsZero() {
+		return o.Schedule.Active(t, o.Duration)
+	}
+	return !t.Before(o.Start) && t.Before(o.End)
+}
+
+// validate checks that the override has a window and changes something.
+func (o Override) validate() error {
+	switch {
+	case !o.Schedule.IsZero() && o.Duration <= 0:
+		return errors.New("scheduled override needs a positive duration")
+	case o.Schedule.IsZero() && !o.End.After(o.Start):
+		return errors.New("needs a schedule, or an end after its start")
+	case o.MinReplicas < 0 || o.MaxReplicas < 0 || o.Replicas < 0:
+		return errors.New("replica counts must not be negative")
+	case o.MaxReplicas > 0 && o.MaxReplicas < o.MinReplicas:
+		return errors.New("max replicas below min replicas")
+	case o.Headroom != 0 && o.Headroom < 1:
+		return errors.New("headroom must be at least 1")
+	case o.MinReplicas == 0 && o.MaxReplicas == 0 && o.Headroom == 0 && o.Replicas == 0:
+		return errors.New("sets none of min, max, headroom or replicas")
+	}
+	return nil
+}
+
+// at returns the policy in effect at t: p with the overrides active at t
+// applied. Several active overrides combine to the highest floor, the
+// lowest cap, and the first headroom and fixed count. p must be sanitized.
+func (p Policy) at(t time.Time) Policy {
+	q := p
+	floor, ceiling, fixed, headroom := 0, 0, 0, 0.0
+	for _, o := range p.Overrides {
+		if !o.Active(t) {
+			continue
+		}
+		floor = max(floor, o.MinReplicas)
+		if o.MaxReplicas > 0 && (ceiling == 0 || o.MaxReplicas < ceiling) {
+			ceiling = o.MaxReplicas
+		}
+		if fixed == 0 {
+			fixed = o.Replicas
+		}
+		if headroom == 0 {
+			headroom = o.Headroom
+		}
+	}
+
+	if floor > 0 {
+		q.MinReplicas = floor
+	}
+	if ceiling > 0 {
+		q.MaxReplicas = ceiling
+	}
+	if fixed > 0 {
+		q.MinReplicas, q.MaxReplicas = fixed, fixed
+	}
+	if headroom >= 1 {
+		q.Headroom = headroom
+	}
+	if q.MaxReplicas > 0 && q.MaxReplicas < q.MinReplicas {
+		q.MaxReplicas = q.MinReplicas
+	}
+	return q
+}
+
+// stepPolicies returns the policy in effect at each of n steps starting at
+// start, or p at every step when start is zero. p must be sanitized.
+func (p Policy) stepPolicies(start time.Time, stepSec, n int) []Policy {
+	steps := make([]Policy, n)
+	for j := range steps {
+		steps[j] = p
+		if !start.IsZero() && len(p.Overrides) > 0 {
+			steps[j] = p.at(start.Add(time.Duration(j*stepSec) * time.Second))
+		}
+	}
+	return steps
+}
+
+// overridesFile is the JSON format read by LoadOverrides.
+type overridesFile struct {
+	Overrides []struct {
+		Name     string    `json:"name"`
+		Cron     string    `json:"cron"`
+		Duration string    `json:"duration"`
+		TimeZone string    `json:"timezone"`
+		Start    time.Time `json:"start"`
+		End      time.Time `json:"end"`
+		Min      int       `json:"min"`
+		Max      int       `json:"max"`
+		Headroom float64   `json:"headroom"`
+		Replicas int       `json:"replicas"`
+	} `json:"overrides"`
+}
+
+// LoadOverrides reads policy overrides from a JSON file of the form:
+//
+//	{"overrides": [
+//	  {"name": "morning-logins", "cron": "45 8 * * 1-5", "duration": "2h", "timezone": "Europe/Paris", "min": 12},
+//	  {"name": "black-friday", "start": "2026-11-27T00:00:00Z", "end": "2026-11-28T06:00:00Z", "min": 40, "headroom": 1.5}
+//	]}
+//
+// Scheduled overrides take a cron expression (see ParseSchedule), a Go
+// duration and an optional IANA time zone; one-off overrides take RFC3339
+// start and end times.
+func LoadOverrides(path string) ([]Override, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read overrides file: %w", err)
+	}
+	var file overridesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse overrides file: %w", err)
+	}
+
+	overrides := make([]Override, 0, len(file.Overrides))
+	for i, spec := range file.Overrides {
+		name := spec.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		o := Override{
+			Name:        name,
+			Start:       spec.Start,
+			End:         spec.End,
+			MinReplicas: spec.Min,
+			MaxReplicas: spec.Max,
+			Headroom:    spec.Headroom,
+			Replicas:    spec.Replicas,
+		}
+		if spec.Cron != "" {
+			loc, err := time.LoadLocation(spec.TimeZone)
+			if err != nil {
+				return nil, fmt.Errorf("override %s: %w", name, err)
+			}
+			if o.Schedule, err = ParseSchedule(spec.Cron, loc); err != nil {
+				return nil, fmt.Errorf("override %s: %w", name, err)
+			}
+			if o.Duration, err = time.ParseDuration(spec.Duration); err != nil {
+				return nil, fmt.Errorf("override %s duration: %w", name, err)
+			}
+		}
+		if err := o.validate(); err != nil {
+			return nil, fmt.Errorf("override %s: %w", name, err)
+		}
+		overrides = append(overrides, o)
+	}
+	return overrides, nil
+}
@@ -0,0 +1,151 @@
+package capacity
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToReplicasAt_Overrides(t *testing.T) {
+	start := time.Date(2024, 1, 1, 8, 40, 0, 0, time.UTC) // Monday
+	morning, err := ParseSchedule("45 8 * * *", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := Policy{TargetPerPod: 10, Headroom: 1, MinReplicas: 1, MaxReplicas: 10, UpMaxFactorPerStep: 100, DownMaxPercentPerStep: 100}
+	window := func(from, to int) (time.Time, time.Time) {
+		return start.Add(time.Duration(from) * time.Minute), start.Add(time.Duration(to) * time.Minute)
+	}
+	oneOff := func(from, to int, o Override) Override {
+		o.Start, o.End = window(from, to)
+		return o
+	}
+
+	tests := []struct {
+		name      string
+		lead      int
+		overrides []Override
+		forecast  []float64
+		want      []int
+	}{
+		{
+			// The floor starts at 08:45, the fifth step; lead time pre-warms for it.
+			name:      "scheduled floor with lead time",
+			lead:      60,
+			overrides: []Override{{Schedule: morning, Duration: 10 * time.Minute, MinReplicas: 5}},
+			forecast:  []float64{10, 10, 10, 10, 10, 10},
+			want:      []int{1, 1, 1, 1, 5, 5},
+		},
+		{
+			name:      "fixed above the policy max",
+			overrides: []Override{oneOff(1, 3, Override{Replicas: 12})},
+			forecast:  []float64{10, 10, 10, 10},
+			want:      []int{1, 12, 12, 1},
+		},
+		{
+			name:      "cap",
+			overrides: []Override{oneOff(0, 2, Override{MaxReplicas: 2})},
+			forecast:  []float64{50, 50, 50},
+			want:      []int{2, 2, 5},
+		},
+		{
+			name:      "headroom",
+			overrides: []Override{oneOff(1, 2, Override{Headroom: 2})},
+			forecast:  []float64{10, 10, 10},
+			want:      []int{1, 2, 1},
+		},
+		{
+			name: "highest floor",
+			overrides: []Override{
+				oneOff(0, 3, Override{MinReplicas: 3}),
+				oneOff(0, 3, Override{MinReplicas: 4}),
+			},
+			forecast: []float64{10, 10},
+			want:     []int{4, 4},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := base
+			p.LeadTimeSeconds = tt.lead
+			p.Overrides = tt.overrides
+			if got := ToReplicasAt(start, 1, tt.forecast, 60, p); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToReplicasAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverrides_NeedTimestamps(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := Policy{TargetPerPod: 10, MinReplicas: 1, Overrides: []Override{{Start: start, End: start.Add(time.Hour), Replicas: 7}}}
+
+	if got := ToReplicas(1, []float64{10}, 60, p); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("ToReplicas() = %v, want overrides ignored", got)
+	}
+	if got := ToReplicasAt(time.Time{}, 1, []float64{10}, 60, p); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("ToReplicasAt(zero) = %v, want overrides ignored", got)
+	}
+	var h History
+	if got := h.ToReplicas(start, 1, []float64{10}, 60, p); !reflect.DeepEqual(got, []int{7}) {
+		t.Errorf("History.ToReplicas() = %v, want [7]", got)
+	}
+	plan := h.ToReplicasMulti(start, 1, []Signal{{Name: "rps", Forecast: []float64{10}}}, 60, p)
+	if !reflect.DeepEqual(plan.Replicas, []int{7}) {
+		t.Errorf("History.ToReplicasMulti() = %v, want [7]", plan.Replicas)
+	}
+}
+
+func TestLoadOverrides(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	overrides, err := LoadOverrides(write("ok.json", `{"overrides": [
+		{"name": "morning", "cron": "45 8 * * 1-5", "duration": "2h", "timezone": "UTC", "min": 12},
+		{"name": "black-friday", "start": "2026-11-27T00:00:00Z", "end": "2026-11-28T06:00:00Z", "replicas": 40}
+	]}`))
+	if err != nil {
+		t.Fatalf("LoadOverrides() error = %v", err)
+	}
+	if len(overrides) != 2 {
+		t.Fatalf("LoadOverrides() = %d overrides, want 2", len(overrides))
+	}
+	morning := overrides[0]
+	if morning.Name != "morning" || morning.Schedule.String() != "45 8 * * 1-5" || morning.Duration != 2*time.Hour || morning.MinReplicas != 12 {
+		t.Errorf("morning = %+v", morning)
+	}
+	if !morning.Active(time.Date(2026, 11, 27, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("morning not active on a Friday at 09:00")
+	}
+	friday := overrides[1]
+	if friday.Replicas != 40 || !friday.Active(time.Date(2026, 11, 27, 12, 0, 0, 0, time.UTC)) || friday.Active(time.Date(2026, 11, 28, 6, 0, 0, 0, time.UTC)) {
+		t.Errorf("black-friday = %+v, want active on the 27th until 06:00 on the 28th", friday)
+	}
+
+	for name, tt := range map[string]struct{ content, want string }{
+		"invalid.json":  {`{"overrides": [`, "parse"},
+		"cron.json":     {`{"overrides": [{"cron": "61 * * * *", "duration": "1h", "min": 1}]}`, "minute"},
+		"duration.json": {`{"overrides": [{"cron": "0 * * * *", "min": 1}]}`, "duration"},
+		"zone.json":     {`{"overrides": [{"cron": "0 * * * *", "duration": "1h", "timezone": "Mars/Olympus", "min": 1}]}`, "Mars"},
+		"window.json":   {`{"overrides": [{"name": "late", "start": "2026-11-28T00:00:00Z", "end": "2026-11-27T00:00:00Z", "min": 1}]}`, "late"},
+		"noop.json":     {`{"overrides": [{"start": "2026-11-27T00:00:00Z", "end": "2026-11-28T00:00:00Z"}]}`, "#1"},
+		"bounds.json":   {`{"overrides": [{"start": "2026-11-27T00:00:00Z", "end": "2026-11-28T00:00:00Z", "min": 5, "max": 2}]}`, "max"},
+		"headroom.json": {`{"overrides": [{"start": "2026-11-27T00:00:00Z", "end": "2026-11-28T00:00:00Z", "headroom": 0.5}]}`, "headroom"},
+	} {
+		if _, err := LoadOverrides(write(name, tt.content)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("LoadOverrides(%s) error = %v, want %q", name, err, tt.want)
+		}
+	}
+	if _, err := LoadOverrides(filepath.Join(dir, "missing.json")); err == nil {
+		t.Errorf("LoadOverrides(missing) error = nil, want error")
+	}
+}
@@ -4,6 +4,8 @@ package capacity
 
 import (
 	"math"
+	"strconv"
+	"time"
 )
 
 // Policy defines how forecasted load is translated into replicas.
@@ -12,6 +14,11 @@ type Policy struct {
 	// Must be > 0.
 	TargetPerPod float64
 
+	// Curve, when set, replaces TargetPerPod for services that do not scale
+	// linearly: each step gets the fewest replicas whose sustainable load
+	// covers the forecast with headroom.
+	Curve Curve
+
 	// Headroom is a multiplicative safety factor (e.g., 1.2 for +20%).
 	// Must be >= 1.0
 	Headroom float64
@@ -39,17 +46,105 @@ type Policy struct {
 	// RoundingMode controls how fractional pods are turned into integers.
 	// "ceil" (default), "round", or "floor".
 	RoundingMode string
+
+	// Quantile selects which forecast quantile to plan against (e.g., 0.9 for p90).
+	// 0 plans against the point forecast. ToReplicas does not read this field;
+	// callers use SelectSeries to pick the series they pass in.
+	Quantile float64
+
+	// BurstProbability plans intermittent-demand forecasts on their bursts:
+	// steps whose burst probability is at least this value get capacity for
+	// the full burst size, the others none (so MinReplicas, possibly zero).
+	// 0 disables it. Like Quantile, ToReplicas does not read this field;
+	// callers use BurstSeries.
+	BurstProbability float64
+
+	// ScaleDownStabilizationSeconds delays scale-downs until they hold: a step
+	// below the previous one gets the highest recommendation of this many
+	// trailing seconds, so brief dips in the forecast keep the replicas.
+	// 0 disables it.
+	ScaleDownStabilizationSeconds int
+
+	// ScaleUpStabilizationSeconds likewise gives a step above the previous one
+	// the lowest recommendation of this many trailing seconds. 0 disables it.
+	ScaleUpStabilizationSeconds int
+
+	// ScaleDownCooldownSeconds is the minimum time after a replica change
+	// before scaling down. 0 disables it.
+	ScaleDownCooldownSeconds int
+
+	// ScaleUpCooldownSeconds is the minimum time after a replica change
+	// before scaling up. 0 disables it.
+	ScaleUpCooldownSeconds int
+
+	// Overrides change the bounds or headroom during scheduled windows. They
+	// are evaluated at the timestamp of each forecast step, so lead time
+	// pre-warms for them. ToReplicas does not know the timestamps and ignores
+	// them; use ToReplicasAt or History.
+	Overrides []Override
+}
+
+// SelectSeries returns the load series to plan against under the policy:
+// the quantiles entry for p.Quantile when set and available, otherwise point.
+// quantiles is keyed like models.Forecast.Quantiles ("p10", "p90", ...).
+// The second return value is false when a quantile was requested but missing.
+func SelectSeries(point []float64, quantiles map[string][]float64, p Policy) ([]float64, bool) {
+	if p.Quantile <= 0 {
+		return point, true
+	}
+	key := "p" + strconv.FormatFloat(math.Round(p.Quantile*1e4)/1e2, 'f', -1, 64)
+	if series, ok := quantiles[key]; ok && len(series) == len(point) {
+		return series, true
+	}
+	return point, false
+}
+
+// BurstSeries returns the load series to plan against for a forecast split
+// into per-step burst probability and size: size where the probability is at
+// least p.BurstProbability, zero elsewhere. The second return value is false
+// when burst planning is disabled or the series lengths differ.
+func BurstSeries(probability, size []float64, p Policy) ([]float64, bool) {
+	if p.BurstProbability <= 0 || len(probability) != len(size) {
+		return nil, false
+	}
+	series := make([]float64, len(size))
+	for i := range size {
+		if probability[i] >= p.BurstProbability {
+			series[i] = size[i]
+		}
+	}
+	return series, true
 }
 
 // ToReplicas converts a forecasted load series into desired replicas, applying the policy.
 // prev is the previously applied desired replica count (from the last control loop tick).
 // forecast contains the metric values for each future step (e.g., RPS).
 // stepSec is the step resolution in seconds.
+//
+// Stabilisation windows and cooldowns only see the steps of this plan; use
+// History.ToReplicas to carry them over between control loop ticks.
 func ToReplicas(prev int, forecast []float64, stepSec int, p Policy) []int {
 	if len(forecast) == 0 {
 		return nil
 	}
-	// ---- sanitize policy ----
+	return ToReplicasAt(time.Time{}, prev, forecast, stepSec, p)
+}
+
+// ToReplicasAt is like ToReplicas for a forecast whose first step is at
+// start, applying the policy's overrides active at each step's timestamp.
+// A zero start ignores them.
+func ToReplicasAt(start time.Time, prev int, forecast []float64, stepSec int, p Policy) []int {
+	if len(forecast) == 0 {
+		return nil
+	}
+	p, stepSec = p.sanitize(stepSec)
+	steps := p.stepPolicies(start, stepSec, len(forecast))
+	return clampSeries(prev, podsNeeded(forecast, p.TargetPerPod, p.Curve, 0, stepSec, steps), stepSec, p, steps, &trail{})
+}
+
+// sanitize returns the policy and step with out-of-range values replaced by
+// their defaults or nearest valid value.
+func (p Policy) sanitize(stepSec int) (Policy, int) {
 	if p.TargetPerPod <= 0 {
 		p.TargetPerPod = 1
 	}
@@ -77,54 +172,101 @@ func ToReplicas(prev int, forecast []float64, stepSec int, p Policy) []int {
 	if p.PrewarmWindowSteps < 0 {
 		p.PrewarmWindowSteps = 0
 	}
+	p.ScaleDownStabilizationSeconds = max(p.ScaleDownStabilizationSeconds, 0)
+	p.ScaleUpStabilizationSeconds = max(p.ScaleUpStabilizationSeconds, 0)
+	p.ScaleDownCooldownSeconds = max(p.ScaleDownCooldownSeconds, 0)
+	p.ScaleUpCooldownSeconds = max(p.ScaleUpCooldownSeconds, 0)
+	return p, stepSec
+}
+
+// podsNeeded returns the fractional pods needed at each step to serve the
+// forecast at targetPerPod, or along curve when it is not nil, with headroom,
+// or with the headroom of the step's policy when headroom is 0, looking ahead
+// by the policy's lead time and prewarm window. steps holds the sanitized
+// policy in effect at each step.
+func podsNeeded(forecast []float64, targetPerPod float64, curve Curve, headroom float64, stepSec int, steps []Policy) []float64 {
 	// ---- precompute adjusted capacity requirement per step (load -> pods before rounding) ----
 	adj := make([]float64, len(forecast))
 	for i, v := range forecast {
 		if v < 0 {
 			v = 0
 		}
-		raw := v / p.TargetPerPod
-		adj[i] = raw * p.Headroom
+		h := headroom
+		if h == 0 {
+			h = steps[i].Headroom
+		}
+		if curve != nil {
+			adj[i] = curve.Replicas(v * h)
+			continue
+		}
+		raw := v / targetPerPod
+		adj[i] = raw * h
 	}
 
-	// lead time offset in steps
-	i0 := max(int(math.Ceil(float64(p.LeadTimeSeconds)/float64(stepSec))), 0)
-
-	res := make([]int, len(forecast))
-	prevOut := clampBounds(prev, p.MinReplicas, p.MaxReplicas)
-
+	p := steps[0]
+	need := make([]float64, len(forecast))
 	for i := range forecast {
 		// Conservative pick: single point at i+i0.
 		// If PrewarmWindowSteps > 0, take the max over [jStart..jEnd].
-		jStart := i + i0
-		if jStart >= len(adj) {
-			jStart = len(adj) - 1
-		}
+		jStart := leadIndex(i, len(adj), stepSec, p)
 		jEnd := jStart + p.PrewarmWindowSteps
 		if jEnd >= len(adj) {
 			jEnd = len(adj) - 1
 		}
-		need := 0.0
 		for j := jStart; j <= jEnd; j++ {
-			if adj[j] > need {
-				need = adj[j]
+			if adj[j] > need[i] {
+				need[i] = adj[j]
 			}
 		}
+	}
+	return need
+}
+
+// leadIndex returns the step of an n-step forecast that step i plans for:
+// i plus the policy's lead time, capped at the last step.
+func leadIndex(i, n, stepSec int, p Policy) int {
+	i0 := max(int(math.Ceil(float64(p.LeadTimeSeconds)/float64(stepSec))), 0)
+	return min(i+i0, n-1)
+}
 
-		desired := roundPods(need, p.RoundingMode)
+// clampSeries rounds the pods needed at each step and applies the policy's
+// bounds, stabilisation, and change clamps, starting from prev. Each step is
+// bounded like the step it plans for in steps, the policy in effect at each
+// step. tr holds what happened before the plan and is extended with its
+// steps. p and steps must be sanitized.
+func clampSeries(prev int, need []float64, stepSec int, p Policy, steps []Policy, tr *trail) []int {
+	res := make([]int, len(need))
+	prevOut := clampBounds(prev, p.MinReplicas, p.MaxReplicas)
+
+	for i := range need {
+		t := float64(i * stepSec)
+		q := steps[leadIndex(i, len(steps), stepSec, p)]
+		desired := roundPods(need[i], p.RoundingMode)
 
-		// Apply bounds, then change clamps, then bounds again.
-		desired = clampBounds(desired, p.MinReplicas, p.MaxReplicas)
+		// Apply bounds, then stabilisation, then change clamps, then bounds again.
+		desired = clampBounds(desired, q.MinReplicas, q.MaxReplicas)
+		tr.observe(t, desired)
+		desired = tr.stabilize(prevOut, desired, t, p)
 		desired = clampChange(prevOut, desired, p.UpMaxFactorPerStep, p.DownMaxPercentPerStep)
-		desired = clampBounds(desired, p.MinReplicas, p.MaxReplicas)
+		desired = clampBounds(desired, q.MinReplicas, q.MaxReplicas)
 
+		if desired != prevOut {
+			tr.change(t)
+		}
 		res[i] = desired
 		prevOut = desired
 	}
 	return res
 }
 
+// maxPods caps rounded pods, e.g. for loads no replica count of a Curve
+// sustains.
+const maxPods = math.MaxInt32
+
 func roundPods(x float64, mode string) int {
+	if x >= maxPods || math.IsNaN(x) {
+		return maxPods
+	}
 	switch mode {
 	case "floor":
 		return int(math.Floor(x))
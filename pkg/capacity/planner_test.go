@@ -102,3 +102,122 @@ func TestToReplicas_LeadTimeWindow_SinglePoint(t *testing.T) {
 		t.Fatalf("got %v, want %v", got, want)
 	}
 }
+
+func TestSelectSeries(t *testing.T) {
+	point := []float64{100, 200}
+	quantiles := map[string][]float64{
+		"p50": {100, 200},
+		"p90": {150, 280},
+	}
+
+	tests := []struct {
+		name     string
+		quantile float64
+		want     []float64
+		wantOK   bool
+	}{
+		{name: "point forecast", quantile: 0, want: point, wantOK: true},
+		{name: "p90", quantile: 0.9, want: []float64{150, 280}, wantOK: true},
+		{name: "missing quantile falls back", quantile: 0.95, want: point, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := SelectSeries(point, quantiles, Policy{Quantile: tt.quantile})
+			if ok != tt.wantOK {
+				t.Errorf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBurstSeries(t *testing.T) {
+	probability := []float64{0.05, 0.3, 0.6}
+	size := []float64{40, 40, 50}
+
+	tests := []struct {
+		name      string
+		threshold float64
+		want      []float64
+		wantOK    bool
+	}{
+		{name: "disabled", threshold: 0, wantOK: false},
+		{name: "pre-warm likely bursts", threshold: 0.25, want: []float64{0, 40, 50}, wantOK: true},
+		{name: "stay idle below threshold", threshold: 0.9, want: []float64{0, 0, 0}, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := BurstSeries(probability, size, Policy{BurstProbability: tt.threshold})
+			if ok != tt.wantOK {
+				t.Errorf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToReplicas_Stabilization(t *testing.T) {
+	base := Policy{TargetPerPod: 1, Headroom: 1, UpMaxFactorPerStep: 100, DownMaxPercentPerStep: 100}
+	tests := []struct {
+		name     string
+		prev     int
+		forecast []float64
+		set      func(*Policy)
+		want     []int
+	}{
+		{
+			// Each step is held at the highest of the last three recommendations.
+			name:     "down window",
+			prev:     10,
+			forecast: []float64{10, 10, 4, 10, 10, 4, 4, 4, 4},
+			set:      func(p *Policy) { p.ScaleDownStabilizationSeconds = 180 },
+			want:     []int{10, 10, 10, 10, 10, 10, 10, 4, 4},
+		},
+		{
+			name:     "up window",
+			prev:     2,
+			forecast: []float64{2, 8, 2, 8, 8, 8},
+			set:      func(p *Policy) { p.ScaleUpStabilizationSeconds = 120 },
+			want:     []int{2, 2, 2, 2, 8, 8},
+		},
+		{
+			name:     "down cooldown",
+			prev:     10,
+			forecast: []float64{8, 6, 4, 2, 2},
+			set:      func(p *Policy) { p.ScaleDownCooldownSeconds = 120 },
+			want:     []int{8, 8, 4, 4, 2},
+		},
+		{
+			name:     "up cooldown",
+			prev:     2,
+			forecast: []float64{4, 8, 8, 8},
+			set:      func(p *Policy) { p.ScaleUpCooldownSeconds = 120 },
+			want:     []int{4, 4, 8, 8},
+		},
+		{
+			name:     "negative disables",
+			prev:     10,
+			forecast: []float64{10, 4},
+			set: func(p *Policy) {
+				p.ScaleDownStabilizationSeconds = -60
+				p.ScaleDownCooldownSeconds = -60
+			},
+			want: []int{10, 4},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := base
+			tt.set(&p)
+			if got := ToReplicas(tt.prev, tt.forecast, 60, p); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToReplicas() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package capacity
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression with the five standard fields:
+// minute, hour, day of month, month and day of week. The zero value never
+// matches.
+type Schedule struct {
+	expr   string
+	minute uint64 // bit i set when minute i matches
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64 // 0 = Sunday
+	// As in cron, when both day fields are restricted a day matches
+	// either of them.
+	domAny, dowAny bool
+	loc            *time.Location
+}
+
+// scheduleLookback bounds how far back Schedule looks for its latest match.
+const scheduleLookback = 366 * 24 * time.Hour
+
+// ParseSchedule parses a five-field cron expression such as "45 8 * * 1-5"
+// (08:45 on weekdays), evaluated in loc (UTC when nil). Fields accept "*",
+// numbers, ranges ("1-5"), lists ("1,15") and steps ("*/15", "0-30/10");
+// day of week 7 is Sunday like 0.
+func ParseSchedule(expr string, loc *time.Location) (Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return Schedule{}, fmt.Errorf("schedule %q: want 5 fields (minute hour day-of-month month day-of-week), got %d", expr, len(fields))
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	s := Schedule{expr: expr, loc: loc}
+	ranges := []struct {
+		name   string
+		lo, hi int
+		bits   *uint64
+	}{
+		{"minute", 0, 59, &s.minute},
+		{"hour", 0, 23, &s.hour},
+		{"day of month", 1, 31, &s.dom},
+		{"month", 1, 12, &s.month},
+		{"day of week", 0, 7, &s.dow},
+	}
+	for i, r := range ranges {
+		bits, err := parseField(fields[i], r.lo, r.hi)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("schedule %q %s: %w", expr, r.name, err)
+		}
+		*r.bits = bits
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	s.domAny = fields[2] == "*"
+	s.dowAny = fields[4] == "*"
+	return s, nil
+}
+
+// parseField returns the bit set of the values lo..hi matched by field.
+func parseField(field string, lo, hi int) (uint64, error) {
+	var bits uint64
+	for part := range strings.SplitSeq(field, ",") {
+		rng, stepRaw, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepRaw)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepRaw)
+			}
+			step = n
+		}
+
+		first, last := lo, hi
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if first, err = strconv.Atoi(a); err != nil {
+				return 0, fmt.Errorf("invalid value %q", a)
+			}
+			last = first
+			if isRange {
+				if last, err = strconv.Atoi(b); err != nil {
+					return 0, fmt.Errorf("invalid value %q", b)
+				}
+			} else if hasStep {
+				last = hi
+			}
+		}
+		if first < lo || last > hi || first > last {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, lo, hi)
+		}
+		for v := first; v <= last; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// IsZero reports whether s is the zero Schedule.
+func (s Schedule) IsZero() bool {
+	return s.expr == ""
+}
+
+// String returns the cron expression s was parsed from.
+func (s Schedule) String() string {
+	return s.expr
+}
+
+// Matches reports whether the minute containing t matches s.
+func (s Schedule) Matches(t time.Time) bool {
+	if s.IsZero() {
+		return false
+	}
+	t = t.In(s.loc)
+	return s.month&(1<<int(t.Month())) != 0 &&
+		s.dayMatches(t) &&
+		s.hour&(1<<t.Hour()) != 0 &&
+		s.minute&(1<<t.Minute()) != 0
+}
+
+func (s Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<t.Day()) != 0
+	dow := s.dow&(1<<int(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Active reports whether t falls in a window of length d starting at a match
+// of s, i.e. s matched a minute in (t-d, t].
+func (s Schedule) Active(t time.Time, d time.Duration) bool {
+	if s.IsZero() || d <= 0 {
+		return false
+	}
+	match, ok := s.latest(t, t.Add(-min(d, scheduleLookback)))
+	return ok && t.Sub(match) < d
+}
+
+// latest returns the start of the latest matching minute at or before t and
+// not before floor, skipping non-matching months, days and hours at once.
+func (s Schedule) latest(t, floor time.Time) (time.Time, bool) {
+	t = t.In(s.loc).Truncate(time.Minute)
+	for !t.Before(floor) {
+		y, mo, d := t.Date()
+		var next time.Time
+		switch {
+		case s.month&(1<<int(mo)) == 0:
+			next = time.Date(y, mo, 1, 0, 0, 0, 0, s.loc)
+		case !s.dayMatches(t):
+			next = time.Date(y, mo, d, 0, 0, 0, 0, s.loc)
+		case s.hour&(1<<t.Hour()) == 0:
+			next = time.Date(y, mo, d, t.Hour(), 0, 0, 0, s.loc)
+		case s.minute&(1<<t.Minute()) == 0:
+			next = t
+		default:
+			return t, true
+		}
+		// Step to the last minute before the skipped period. Around DST
+		// changes time.Date may pick a later instant; always move back.
+		next = next.Add(-time.Minute)
+		if !next.Before(t) {
+			next = t.Add(-time.Minute)
+		}
+		t = next
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,84 @@
+package capacity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule_Errors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "a * * * *", "*/0 * * * *", "5-1 * * * *", "* * 0 * *", "* * * 13 *", "* * * * 8"} {
+		if _, err := ParseSchedule(expr, nil); err == nil {
+			t.Errorf("ParseSchedule(%q) error = nil, want error", expr)
+		}
+	}
+}
+
+func TestSchedule_Matches(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip("time zone database unavailable")
+	}
+	tests := []struct {
+		expr string
+		loc  *time.Location
+		at   string
+		want bool
+	}{
+		{"45 8 * * 1-5", nil, "2024-01-01T08:45:00Z", true}, // Monday
+		{"45 8 * * 1-5", nil, "2024-01-01T08:45:59Z", true},
+		{"45 8 * * 1-5", nil, "2024-01-01T08:46:00Z", false},
+		{"45 8 * * 1-5", nil, "2024-01-06T08:45:00Z", false}, // Saturday
+		{"*/15 * * * *", nil, "2024-01-01T10:30:00Z", true},
+		{"*/15 * * * *", nil, "2024-01-01T10:31:00Z", false},
+		{"0 9-17/4 * * *", nil, "2024-01-01T13:00:00Z", true},
+		{"0 9-17/4 * * *", nil, "2024-01-01T15:00:00Z", false},
+		{"0 0 * * 7", nil, "2024-01-07T00:00:00Z", true}, // Sunday
+		// Both day fields restricted: the 13th or any Friday.
+		{"0 0 13 * 5", nil, "2024-01-05T00:00:00Z", true},
+		{"0 0 13 * 5", nil, "2024-01-13T00:00:00Z", true},
+		{"0 0 13 * 5", nil, "2024-01-14T00:00:00Z", false},
+		{"0 0 13 * *", nil, "2024-01-05T00:00:00Z", false},
+		{"0 9 * * *", paris, "2024-01-01T08:00:00Z", true},
+		{"0 9 * * *", paris, "2024-01-01T09:00:00Z", false},
+	}
+	for _, tt := range tests {
+		s, err := ParseSchedule(tt.expr, tt.loc)
+		if err != nil {
+			t.Fatalf("ParseSchedule(%q) error = %v", tt.expr, err)
+		}
+		at, _ := time.Parse(time.RFC3339, tt.at)
+		if got := s.Matches(at); got != tt.want {
+			t.Errorf("%q Matches(%s) = %v, want %v", tt.expr, tt.at, got, tt.want)
+		}
+	}
+	if (Schedule{}).Matches(time.Now()) {
+		t.Errorf("zero Schedule matches, want never")
+	}
+}
+
+func TestSchedule_Active(t *testing.T) {
+	weekdays, _ := ParseSchedule("45 8 * * 1-5", nil)
+	newYear, _ := ParseSchedule("0 23 31 12 *", nil)
+	tests := []struct {
+		s    Schedule
+		d    time.Duration
+		at   string
+		want bool
+	}{
+		{weekdays, 2 * time.Hour, "2024-01-01T08:45:00Z", true},
+		{weekdays, 2 * time.Hour, "2024-01-01T10:44:00Z", true},
+		{weekdays, 2 * time.Hour, "2024-01-01T10:45:00Z", false},
+		{weekdays, 2 * time.Hour, "2024-01-01T08:44:00Z", false},
+		{weekdays, 2 * time.Hour, "2024-01-06T09:00:00Z", false},
+		{weekdays, 48 * time.Hour, "2024-01-06T09:00:00Z", true}, // Friday's window
+		{weekdays, 0, "2024-01-01T08:45:00Z", false},
+		{newYear, 3 * time.Hour, "2025-01-01T01:00:00Z", true},
+		{newYear, 3 * time.Hour, "2025-01-01T02:00:00Z", false},
+	}
+	for _, tt := range tests {
+		at, _ := time.Parse(time.RFC3339, tt.at)
+		if got := tt.s.Active(at, tt.d); got != tt.want {
+			t.Errorf("%q Active(%s, %v) = %v, want %v", tt.s, tt.at, tt.d, got, tt.want)
+		}
+	}
+}
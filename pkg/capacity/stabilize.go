@@ -0,0 +1,139 @@
+package capacity
+
+import "time"
+
+// History records the replica recommendations and changes of past planning
+// runs of a workload, so that the policy's stabilisation windows and
+// cooldowns span control loop ticks rather than a single plan. The zero
+// value is an empty history. A History is not safe for concurrent use.
+type History struct {
+	recs       []timedReplicas
+	lastChange time.Time
+}
+
+type timedReplicas struct {
+	at       time.Time
+	replicas int
+}
+
+// ToReplicas is like ToReplicasAt for a plan whose first step is applied at
+// now, taking into account the recommendations and the last replica change
+// recorded by earlier calls. It records the first step.
+func (h *History) ToReplicas(now time.Time, prev int, forecast []float64, stepSec int, p Policy) []int {
+	if len(forecast) == 0 {
+		return nil
+	}
+	p, stepSec = p.sanitize(stepSec)
+	steps := p.stepPolicies(now, stepSec, len(forecast))
+	return h.plan(now, prev, podsNeeded(forecast, p.TargetPerPod, p.Curve, 0, stepSec, steps), stepSec, p, steps)
+}
+
+// ToReplicasMulti is like the package-level ToReplicasMulti for a plan whose
+// first step is applied at now, as History.ToReplicas.
+func (h *History) ToReplicasMulti(now time.Time, prev int, signals []Signal, stepSec int, p Policy) MultiPlan {
+	p, stepSec = p.sanitize(stepSec)
+	need, binding, steps := combineSignals(now, signals, stepSec, p)
+	if need == nil {
+		return MultiPlan{}
+	}
+	return MultiPlan{Replicas: h.plan(now, prev, need, stepSec, p, steps), Binding: binding}
+}
+
+// plan clamps need with the history's trail and records the first step.
+// p and steps must be sanitized and need non-empty.
+func (h *History) plan(now time.Time, prev int, need []float64, stepSec int, p Policy, steps []Policy) []int {
+	// Only recommendations inside the longest window can affect a plan.
+	window := time.Duration(max(p.ScaleDownStabilizationSeconds, p.ScaleUpStabilizationSeconds)) * time.Second
+	kept := h.recs[:0]
+	for _, r := range h.recs {
+		if now.Sub(r.at) < window {
+			kept = append(kept, r)
+		}
+	}
+	h.recs = kept
+
+	tr := &trail{}
+	for _, r := range h.recs {
+		tr.observe(r.at.Sub(now).Seconds(), r.replicas)
+	}
+	if !h.lastChange.IsZero() {
+		tr.change(h.lastChange.Sub(now).Seconds())
+	}
+
+	res := clampSeries(prev, need, stepSec, p, steps, tr)
+
+	if window > 0 {
+		q := steps[leadIndex(0, len(steps), stepSec, p)]
+		rec := clampBounds(roundPods(need[0], p.RoundingMode), q.MinReplicas, q.MaxReplicas)
+		h.recs = append(h.recs, timedReplicas{at: now, replicas: rec})
+	}
+	if res[0] != clampBounds(prev, p.MinReplicas, p.MaxReplicas) {
+		h.lastChange = now
+	}
+	return res
+}
+
+// trail holds the replica recommendations and the last replica change up to
+// a step, timed in seconds relative to the first step of a plan.
+type trail struct {
+	recs       []trailReplicas // oldest first
+	lastChange float64
+	changed    bool
+}
+
+type trailReplicas struct {
+	t        float64
+	replicas int
+}
+
+// observe appends the recommendation for time t.
+func (tr *trail) observe(t float64, replicas int) {
+	tr.recs = append(tr.recs, trailReplicas{t: t, replicas: replicas})
+}
+
+// change records that the replicas changed at time t.
+func (tr *trail) change(t float64) {
+	tr.lastChange = t
+	tr.changed = true
+}
+
+// stabilize applies the policy's cooldowns and stabilisation windows to the
+// recommendation rec at time t, which follows prev replicas. It keeps prev
+// during a cooldown, and otherwise moves towards rec at most as far as the
+// recommendations in the trailing window agree.
+func (tr *trail) stabilize(prev, rec int, t float64, p Policy) int {
+	switch {
+	case rec < prev:
+		if tr.cooling(t, p.ScaleDownCooldownSeconds) {
+			return prev
+		}
+		for _, r := range tr.window(t, p.ScaleDownStabilizationSeconds) {
+			rec = max(rec, r.replicas)
+		}
+		return min(rec, prev)
+	case rec > prev:
+		if tr.cooling(t, p.ScaleUpCooldownSeconds) {
+			return prev
+		}
+		for _, r := range tr.window(t, p.ScaleUpStabilizationSeconds) {
+			rec = min(rec, r.replicas)
+		}
+		return max(rec, prev)
+	}
+	return rec
+}
+
+// cooling reports whether fewer than cooldown seconds passed between the last
+// change and t.
+func (tr *trail) cooling(t float64, cooldown int) bool {
+	return cooldown > 0 && tr.changed && t-tr.lastChange < float64(cooldown)
+}
+
+// window returns the recommendations of the trailing seconds up to t.
+func (tr *trail) window(t float64, seconds int) []trailReplicas {
+	i := len(tr.recs)
+	for i > 0 && t-tr.recs[i-1].t < float64(seconds) {
+		i--
+	}
+	return tr.recs[i:]
+}
@@ -0,0 +1,72 @@
+package capacity
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHistory_ToReplicas_StabilizationWindow(t *testing.T) {
+	p := Policy{TargetPerPod: 1, Headroom: 1, UpMaxFactorPerStep: 100, DownMaxPercentPerStep: 100, ScaleDownStabilizationSeconds: 300}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var h History
+
+	h.ToReplicas(start, 10, []float64{10, 10, 10}, 60, p)
+
+	// The dip is only forecast a minute after recommending 10, which is
+	// still inside the window of every step.
+	got := h.ToReplicas(start.Add(time.Minute), 10, []float64{4, 4, 4}, 60, p)
+	if want := []int{10, 10, 10}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ToReplicas() after 1m = %v, want %v", got, want)
+	}
+	if got := ToReplicas(10, []float64{4, 4, 4}, 60, p); !reflect.DeepEqual(got, []int{4, 4, 4}) {
+		t.Errorf("package ToReplicas() = %v, want the plan alone [4 4 4]", got)
+	}
+
+	// Once the dip held for the window, the workload scales in.
+	got = h.ToReplicas(start.Add(6*time.Minute), 10, []float64{4, 4, 4}, 60, p)
+	if want := []int{4, 4, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ToReplicas() after 6m = %v, want %v", got, want)
+	}
+	if len(h.recs) != 1 {
+		t.Errorf("history keeps %d recommendations, want only the latest inside the window", len(h.recs))
+	}
+}
+
+func TestHistory_ToReplicas_Cooldown(t *testing.T) {
+	p := Policy{TargetPerPod: 1, Headroom: 1, UpMaxFactorPerStep: 100, DownMaxPercentPerStep: 100, ScaleUpCooldownSeconds: 300}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var h History
+
+	ticks := []struct {
+		after time.Duration
+		prev  int
+		load  float64
+		want  int
+	}{
+		{0, 2, 4, 4},
+		{time.Minute, 4, 8, 4},     // changed a minute ago
+		{5 * time.Minute, 4, 8, 8}, // cooldown over
+	}
+	for _, tick := range ticks {
+		got := h.ToReplicas(start.Add(tick.after), tick.prev, []float64{tick.load}, 60, p)
+		if len(got) != 1 || got[0] != tick.want {
+			t.Errorf("ToReplicas() after %v = %v, want [%d]", tick.after, got, tick.want)
+		}
+	}
+}
+
+func TestHistory_ToReplicasMulti(t *testing.T) {
+	p := Policy{TargetPerPod: 1, Headroom: 1, UpMaxFactorPerStep: 100, DownMaxPercentPerStep: 100, ScaleDownStabilizationSeconds: 300}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var h History
+
+	h.ToReplicasMulti(start, 10, []Signal{{Name: "rps", Forecast: []float64{10}}}, 60, p)
+	got := h.ToReplicasMulti(start.Add(time.Minute), 10, []Signal{{Name: "rps", Forecast: []float64{4}}}, 60, p)
+	if !reflect.DeepEqual(got.Replicas, []int{10}) || !reflect.DeepEqual(got.Binding, []string{"rps"}) {
+		t.Errorf("ToReplicasMulti() = %+v, want [10] bound by rps", got)
+	}
+	if got := h.ToReplicasMulti(start, 10, nil, 60, p); got.Replicas != nil {
+		t.Errorf("ToReplicasMulti() without signals = %+v, want empty plan", got)
+	}
+}
@@ -44,13 +44,14 @@ func NewForecasterClientWithTimeout(baseURL string, timeout time.Duration) *Fore
 // SnapshotResponse represents the JSON response from GET /forecast/current.
 // This matches the structure defined in SPEC.md §3.1.
 type SnapshotResponse struct {
-	Workload        string    `json:"workload"`
-	Metric          string    `json:"metric"`
-	GeneratedAt     time.Time `json:"generatedAt"`
-	StepSeconds     int       `json:"stepSeconds"`
-	HorizonSeconds  int       `json:"horizonSeconds"`
-	Values          []float64 `json:"values"`
-	DesiredReplicas []int     `json:"desiredReplicas"`
+	Workload        string               `json:"workload"`
+	Metric          string               `json:"metric"`
+	GeneratedAt     time.Time            `json:"generatedAt"`
+	StepSeconds     int                  `json:"stepSeconds"`
+	HorizonSeconds  int                  `json:"horizonSeconds"`
+	Values          []float64            `json:"values"`
+	DesiredReplicas []int                `json:"desiredReplicas"`
+	Quantiles       map[string][]float64 `json:"quantiles,omitempty"`
 }
 
 // SnapshotResult contains the snapshot and metadata about staleness.
@@ -111,6 +112,7 @@ func (c *ForecasterClient) GetSnapshot(ctx context.Context, workload string) (*S
 		HorizonSeconds:  snapshotResp.HorizonSeconds,
 		Values:          snapshotResp.Values,
 		DesiredReplicas: snapshotResp.DesiredReplicas,
+		Quantiles:       snapshotResp.Quantiles,
 	}
 
 	return &SnapshotResult{
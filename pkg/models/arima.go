@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math"
 	"sync"
+	"time"
 )
 
 // ARIMAModel implements the Model interface using AutoRegressive Integrated Moving Average.
@@ -16,6 +17,11 @@ import (
 //   - d: Differencing order (trend removal: 0=none, 1=linear, 2=quadratic)
 //   - q: Moving Average order (how many past errors to use)
 //
+// When a SeasonalOrder is configured the model becomes SARIMA(p,d,q)(P,D,Q)[s],
+// adding seasonal differencing and seasonal AR/MA terms at multiples of the
+// season length s (see NewSARIMAModel). With exogenous regressors it becomes
+// a regression with ARIMA errors, ARIMAX (see NewARIMAXModel).
+//
 // The model requires training on historical data before making predictions.
 // It is thread-safe for concurrent Predict calls after training.
 type ARIMAModel struct {
@@ -23,13 +29,59 @@ type ARIMAModel struct {
 	stepSec    int
 	horizonSec int
 	p, d, q    int
+	seasonal   SeasonalOrder
+	autoP      bool // p was 0 at construction: chosen by order search
+	autoD      bool // d was 0 at construction: chosen by KPSS tests
+	autoQ      bool // q was 0 at construction: chosen by order search
 	mu         sync.RWMutex
 	trained    bool
 	arCoeffs   []float64 // AR coefficients (length p)
 	maCoeffs   []float64 // MA coefficients (length q)
+	sarCoeffs  []float64 // Seasonal AR coefficients (length P)
+	smaCoeffs  []float64 // Seasonal MA coefficients (length Q)
 	mean       float64   // Mean of stationary series
+	sigma2     float64   // Innovation variance, used for prediction intervals
 	lastValues []float64 // Last p values for AR predictions
 	lastErrors []float64 // Last q errors for MA predictions
+
+	// Seasonal state: expanded multiplicative polynomials and the recent
+	// history needed to run the recursion and undo differencing.
+	arPoly       []float64 // Expanded AR coefficients for lags 1..p+P*s
+	maPoly       []float64 // Expanded MA coefficients for lags 1..q+Q*s
+	lastCentered []float64 // Last len(arPoly) (or p) centered stationary values
+	tail         []float64 // Last D*s+d+1 raw values for (inverse) differencing
+
+	// Exogenous regressors: the ARIMA part models value - β·x.
+	exog    Exogenous
+	exogFit exogFit
+
+	// Order search cache: searched orders are reused by Train until
+	// orderInterval of data time has passed since orderSearchedAt (the last
+	// row's timestamp at the search), or ResetOrderSearch is called.
+	orderInterval   time.Duration
+	orderSearched   bool
+	orderSearchedAt float64
+}
+
+// SeasonalOrder describes the seasonal (P,D,Q)[s] part of a SARIMA model.
+// The zero value disables seasonality.
+type SeasonalOrder struct {
+	// P is the seasonal AutoRegressive order (lags s, 2s, ...).
+	P int
+
+	// D is the seasonal differencing order (0 or 1).
+	D int
+
+	// Q is the seasonal Moving Average order (errors at lags s, 2s, ...).
+	Q int
+
+	// Period is the season length s in steps (e.g., 1440 for a daily cycle at 1m steps).
+	Period int
+}
+
+// enabled reports whether the seasonal part contributes to the model.
+func (s SeasonalOrder) enabled() bool {
+	return s.Period > 0 && (s.P > 0 || s.D > 0 || s.Q > 0)
 }
 
 // NewARIMAModel creates a new ARIMA model with the specified parameters.
@@ -38,14 +90,33 @@ type ARIMAModel struct {
 //   - metric: Metric name to forecast
 //   - stepSec: Step size in seconds between predictions (must be > 0)
 //   - horizonSec: Forecast horizon in seconds (must be >= stepSec)
-//   - p: AR order (0 = auto-detect, defaults to 1)
-//   - d: Differencing order (0 = auto-detect, defaults to 1; max 2)
-//   - q: MA order (0 = auto-detect, defaults to 1)
+//   - p: AR order (0 = auto-detect, 0-5)
+//   - d: Differencing order (0 = auto-detect, 0-2)
+//   - q: MA order (0 = auto-detect, 0-5)
 //
-// Auto-detection (p=0, d=0, or q=0) uses sensible defaults for general-purpose forecasting.
+// Auto-detected orders start at 1 and are chosen by the first Train call: d
+// by repeated KPSS stationarity tests, then p and q by a stepwise search that
+// minimises AICc (see Train). Later calls reuse them until the order search
+// interval passes (see SetOrderSearchInterval) or ResetOrderSearch is called.
+// Name reports the chosen order.
 //
 // Panics if metric is empty, stepSec <= 0, horizonSec < stepSec, or d > 2.
 func NewARIMAModel(metric string, stepSec, horizonSec int, p, d, q int) *ARIMAModel {
+	return NewSARIMAModel(metric, stepSec, horizonSec, p, d, q, SeasonalOrder{})
+}
+
+// NewSARIMAModel creates a seasonal ARIMA model SARIMA(p,d,q)(P,D,Q)[s].
+//
+// The non-seasonal parameters behave exactly as in NewARIMAModel. The seasonal
+// orders are taken as given (0 means "no seasonal term", not auto-detect):
+//   - seasonal.P: seasonal AR order (0-2 typical)
+//   - seasonal.D: seasonal differencing order (0 or 1)
+//   - seasonal.Q: seasonal MA order (0-2 typical)
+//   - seasonal.Period: season length in steps (must be >= 2 when any seasonal order is set)
+//
+// Panics on the same conditions as NewARIMAModel, or if any seasonal order is
+// negative, seasonal.D > 1, or seasonal.Period < 2 while seasonal orders are set.
+func NewSARIMAModel(metric string, stepSec, horizonSec int, p, d, q int, seasonal SeasonalOrder) *ARIMAModel {
 	if metric == "" {
 		panic("metric cannot be empty")
 	}
@@ -64,43 +135,89 @@ func NewARIMAModel(metric string, stepSec, horizonSec int, p, d, q int) *ARIMAMo
 	if q < 0 {
 		panic("q must be >= 0")
 	}
-
-	if p == 0 {
-		p = 1
+	if seasonal.P < 0 || seasonal.Q < 0 || seasonal.Period < 0 {
+		panic("seasonal orders must be >= 0")
 	}
-	if d == 0 {
-		d = 1
+	if seasonal.D < 0 || seasonal.D > 1 {
+		panic("seasonal D must be in range [0, 1]")
 	}
-	if q == 0 {
-		q = 1
+	if (seasonal.P > 0 || seasonal.D > 0 || seasonal.Q > 0) && seasonal.Period < 2 {
+		panic("seasonal period must be >= 2 when seasonal orders are set")
 	}
 
-	return &ARIMAModel{
+	m := &ARIMAModel{
 		metric:     metric,
 		stepSec:    stepSec,
 		horizonSec: horizonSec,
 		p:          p,
 		d:          d,
 		q:          q,
+		seasonal:   seasonal,
+		autoP:      p == 0,
+		autoD:      d == 0,
+		autoQ:      q == 0,
+	}
+	if m.autoP {
+		m.p = 1
+	}
+	if m.autoD {
+		m.d = 1
+	}
+	if m.autoQ {
+		m.q = 1
 	}
+	return m
 }
 
-// Name returns the model name with ARIMA parameters.
+// Name returns the model name with ARIMA parameters, e.g. "arima(1,1,1)" or
+// "sarima(1,1,1)(1,1,0)[24]" when a seasonal order is configured. Models with
+// exogenous regressors are named "arimax(...)" and "sarimax(...)".
 func (m *ARIMAModel) Name() string {
-	return fmt.Sprintf("arima(%d,%d,%d)", m.p, m.d, m.q)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	x := ""
+	if m.exog.enabled() {
+		x = "x"
+	}
+	if m.seasonal.enabled() {
+		return fmt.Sprintf("sarima%s(%d,%d,%d)(%d,%d,%d)[%d]",
+			x, m.p, m.d, m.q, m.seasonal.P, m.seasonal.D, m.seasonal.Q, m.seasonal.Period)
+	}
+	return fmt.Sprintf("arima%s(%d,%d,%d)", x, m.p, m.d, m.q)
 }
 
 // Train fits the ARIMA model to historical data.
 //
 // The training process:
 //  1. Extracts metric values from feature rows
-//  2. Applies differencing (d times) to achieve stationarity
-//  3. Computes mean of stationary series
-//  4. Fits AR coefficients using Yule-Walker equations
-//  5. Fits MA coefficients using innovations algorithm
-//  6. Stores last p values and q errors for prediction
+//  2. Chooses any auto-detected orders (see below)
+//  3. With exogenous regressors, fits their coefficients and continues on
+//     value - β·x (see NewARIMAXModel)
+//  4. Applies differencing (d times) to achieve stationarity
+//  5. Computes mean of stationary series
+//  6. Fits AR coefficients using Yule-Walker equations
+//  7. Fits MA coefficients using innovations algorithm
+//  8. Stores last p values and q errors for prediction
+//
+// Order search: an auto d is the smallest d in [0, 2] for which the KPSS test
+// does not reject level stationarity at 5%. Auto p and q are then chosen in
+// [0, 5] by a stepwise search from (2,2), (0,0), (1,0), (0,1) over neighbouring
+// orders, scoring each by AICc of its conditional-sum-of-squares likelihood
+// with Hannan-Rissanen estimates. The search stops at the context deadline
+// (or after 2s without one) and keeps the best order found so far. It only
+// runs again once the order search interval has passed in the rows'
+// timestamps, or after ResetOrderSearch.
+//
+// When p or q of a non-seasonal model is searched, steps 6 and 7 use the
+// search's Hannan-Rissanen estimator instead (falling back to Yule-Walker if
+// it fails), and Predict forecasts with the ARMA recursion, so that
+// predictions come from the model the AICc scored. Seasonal models are always
+// fitted as SARIMA (see trainSeasonal); for them the search only picks p and q.
 //
 // Minimum data requirements: max(p+d, q+d, 10) points needed for stable training.
+// Seasonal models additionally need D*s + max(p+P*s, q+Q*s) + d + 10 points, i.e.
+// at least one full season per seasonal order plus the non-seasonal requirement.
 //
 // Returns error if:
 //   - Context is cancelled
@@ -120,10 +237,63 @@ func (m *ARIMAModel) Train(ctx context.Context, history FeatureFrame) error {
 		values[i] = val
 	}
 
-	minPoints := max(max(m.p+m.d, m.q+m.d), 10)
+	var x [][]float64
+	var fit exogFit
+	if m.exog.enabled() {
+		var err error
+		if x, fit.lastTs, err = m.exogMatrix(history); err != nil {
+			return err
+		}
+		fit.last = x[len(x)-1]
+	}
+
+	minPoints := m.minPoints()
 	if len(values) < minPoints {
-		return fmt.Errorf("need at least %d points for ARIMA(%d,%d,%d), got %d",
-			minPoints, m.p, m.d, m.q, len(values))
+		return fmt.Errorf("need at least %d points for %s, got %d",
+			minPoints, m.Name(), len(values))
+	}
+
+	if (m.autoP || m.autoD || m.autoQ) && m.orderSearchDue(history) {
+		search := values
+		if x != nil {
+			beta, err := fitExogenous(values, x, 0, SeasonalOrder{})
+			if err != nil {
+				return err
+			}
+			search = removeExogenous(values, x, beta)
+		}
+		p, d, q := m.searchOrder(ctx, search)
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return ctx.Err()
+		}
+
+		m.mu.Lock()
+		prevP, prevD, prevQ := m.p, m.d, m.q
+		m.p, m.d, m.q = p, d, q
+		if len(values) < m.minPoints() {
+			m.p, m.d, m.q = prevP, prevD, prevQ
+		}
+		m.orderSearched = true
+		m.orderSearchedAt, _ = lastTimestamp(history)
+		m.mu.Unlock()
+	}
+
+	if x != nil {
+		var err error
+		if fit.beta, err = fitExogenous(values, x, m.d, m.seasonal); err != nil {
+			return err
+		}
+		values = removeExogenous(values, x, fit.beta)
+	}
+
+	if m.seasonal.enabled() {
+		if err := m.trainSeasonal(values); err != nil {
+			return err
+		}
+		m.mu.Lock()
+		m.exogFit = fit
+		m.mu.Unlock()
+		return nil
 	}
 
 	stationary := difference(values, m.d)
@@ -135,28 +305,47 @@ func (m *ARIMAModel) Train(ctx context.Context, history FeatureFrame) error {
 		centered[i] = v - mean
 	}
 
-	arCoeffs, err := fitAR(centered, m.p)
-	if err != nil {
-		return fmt.Errorf("failed to fit AR coefficients: %w", err)
+	var arCoeffs, maCoeffs, residuals []float64
+	fitted := false
+	if m.searchedARMA() {
+		arCoeffs, maCoeffs, fitted = fitSearchedARMA(centered, m.p, m.q)
 	}
+	if !fitted {
+		var err error
+		if arCoeffs, err = fitAR(centered, m.p); err != nil {
+			return fmt.Errorf("failed to fit AR coefficients: %w", err)
+		}
 
-	residuals := computeResiduals(centered, arCoeffs, m.p)
+		residuals = computeResiduals(centered, arCoeffs, m.p)
 
-	maCoeffs, err := fitMA(residuals, m.q)
-	if err != nil {
-		return fmt.Errorf("failed to fit MA coefficients: %w", err)
+		if maCoeffs, err = fitMA(residuals, m.q); err != nil {
+			return fmt.Errorf("failed to fit MA coefficients: %w", err)
+		}
 	}
 
-	lastValues := make([]float64, m.p)
-	if m.p > 0 {
-		copy(lastValues, values[len(values)-m.p:])
+	errs := replayErrors(centered, arCoeffs, maCoeffs)
+	// The ARMA recursion continues from its own one-step errors.
+	if m.searchedARMA() {
+		residuals = errs
 	}
 
+	// Keep at least the last observation: it anchors the forecast even for p=0.
+	lastValues := make([]float64, max(m.p, 1))
+	copy(lastValues, values[len(values)-len(lastValues):])
+
 	lastErrors := make([]float64, m.q)
 	if m.q > 0 && len(residuals) >= m.q {
 		copy(lastErrors, residuals[len(residuals)-m.q:])
 	}
 
+	// Kept so that Update can extend the stationary series.
+	lastCentered := make([]float64, min(m.p, len(centered)))
+	copy(lastCentered, centered[len(centered)-len(lastCentered):])
+	tail := make([]float64, m.d+1)
+	copy(tail, values[len(values)-len(tail):])
+
+	sigma2 := innovationVariance(errs, max(m.p, m.q))
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -164,8 +353,12 @@ func (m *ARIMAModel) Train(ctx context.Context, history FeatureFrame) error {
 	m.arCoeffs = arCoeffs
 	m.maCoeffs = maCoeffs
 	m.mean = mean
+	m.sigma2 = sigma2
 	m.lastValues = lastValues
 	m.lastErrors = lastErrors
+	m.lastCentered = lastCentered
+	m.tail = tail
+	m.exogFit = fit
 
 	return nil
 }
@@ -176,9 +369,17 @@ func (m *ARIMAModel) Train(ctx context.Context, history FeatureFrame) error {
 //  1. Uses trained AR and MA coefficients
 //  2. Generates predictions step-by-step using ARIMA equations
 //  3. Applies inverse differencing to restore trend
-//  4. Enforces non-negativity constraint
+//  4. Adds the regression part β·x of an ARIMAX model
+//  5. Enforces non-negativity constraint
+//  6. Adds quantiles from the analytic forecast error variance σ²·Σψ², where
+//     ψ are the MA(∞) weights of the fitted (integrated) process
+//
+// Seasonal models, and models whose p or q is searched, instead run the ARMA
+// recursion on the stationary series and undo the differencing.
 //
-// The features parameter is ignored - ARIMA uses stored model state.
+// Features are only read for the future regressor values of an ARIMAX model
+// (see NewARIMAXModel); otherwise ARIMA uses stored model state. An ARIMAX
+// forecast reports the regression part of every step as ComponentRegressors.
 //
 // Returns error if:
 //   - Context is cancelled
@@ -194,6 +395,19 @@ func (m *ARIMAModel) Predict(ctx context.Context, features FeatureFrame) (Foreca
 		return Forecast{}, errors.New("model not trained, call Train() first")
 	}
 
+	nSteps := m.horizonSec / m.stepSec
+	if nSteps <= 0 {
+		nSteps = 1
+	}
+	offsets := m.exogOffsets(features, nSteps)
+
+	if m.seasonal.enabled() || m.searchedARMA() {
+		defer m.mu.RUnlock()
+		forecast := m.predictRecursive(offsets)
+		forecast.Components = exogComponents(offsets)
+		return forecast, nil
+	}
+
 	arCoeffs := make([]float64, len(m.arCoeffs))
 	copy(arCoeffs, m.arCoeffs)
 	maCoeffs := make([]float64, len(m.maCoeffs))
@@ -202,19 +416,24 @@ func (m *ARIMAModel) Predict(ctx context.Context, features FeatureFrame) (Foreca
 	copy(lastValues, m.lastValues)
 	lastErrors := make([]float64, len(m.lastErrors))
 	copy(lastErrors, m.lastErrors)
+	sigma2 := m.sigma2
+	lastOffset := m.lastOffset()
 	m.mu.RUnlock()
 
-	nSteps := m.horizonSec / m.stepSec
-	if nSteps <= 0 {
-		nSteps = 1
-	}
-
 	predictions := make([]float64, nSteps)
 
 	baseValue := 0.0
 	if len(lastValues) > 0 {
 		baseValue = lastValues[len(lastValues)-1]
 	}
+	// The recursion runs on η = value - β·x, but bounds apply to value.
+	level := baseValue + lastOffset
+	offset := func(t int) float64 {
+		if offsets == nil {
+			return 0
+		}
+		return offsets[t]
+	}
 
 	for t := 0; t < nSteps; t++ {
 		var pred float64
@@ -237,25 +456,33 @@ func (m *ARIMAModel) Predict(ctx context.Context, features FeatureFrame) (Foreca
 			pred = pred*dampingFactor + baseValue*(1-dampingFactor)
 		}
 
-		if pred < 0 {
-			pred = 0
+		value := pred + offset(t)
+		if value < 0 {
+			value = 0
 		}
 
-		if pred > baseValue*2+100 {
-			pred = baseValue*2 + 100
+		if value > level*2+100 {
+			value = level*2 + 100
 		}
-		if pred > 1e9 {
-			pred = 1e9
+		if value > 1e9 {
+			value = 1e9
 		}
 
-		predictions[t] = pred
+		predictions[t] = value - offset(t)
+	}
+	for t := range predictions {
+		predictions[t] += offset(t)
 	}
 
+	psi := psiWeights(arCoeffs, maCoeffs, m.d, 0, 0, nSteps)
+
 	return Forecast{
-		Metric:  m.metric,
-		Values:  predictions,
-		StepSec: m.stepSec,
-		Horizon: m.horizonSec,
+		Metric:     m.metric,
+		Values:     predictions,
+		StepSec:    m.stepSec,
+		Horizon:    m.horizonSec,
+		Quantiles:  normalQuantiles(predictions, psiStddev(psi, sigma2)),
+		Components: exogComponents(offsets),
 	}, nil
 }
 
@@ -292,6 +519,22 @@ func computeMean(series []float64) float64 {
 	return sum / float64(len(series))
 }
 
+// innovationVariance returns the mean squared one-step error, ignoring the
+// first skip errors that are distorted by the zero start-up values.
+func innovationVariance(errs []float64, skip int) float64 {
+	if len(errs) == 0 {
+		return 0
+	}
+	if skip >= len(errs) {
+		skip = 0
+	}
+	var sumSq float64
+	for _, e := range errs[skip:] {
+		sumSq += e * e
+	}
+	return sumSq / float64(len(errs)-skip)
+}
+
 // computeVariance calculates the variance of a series
 func computeVariance(series []float64) float64 {
 	if len(series) == 0 {
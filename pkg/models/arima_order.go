@@ -0,0 +1,333 @@
+package models
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+const (
+	// arimaMaxOrder bounds p and q in the automatic order search.
+	arimaMaxOrder = 5
+
+	// defaultOrderSearchBudget caps the order search when the training
+	// context has no deadline.
+	defaultOrderSearchBudget = 2 * time.Second
+
+	// defaultOrderSearchInterval is how long, in data time, searched orders
+	// are reused by Train before the search runs again.
+	defaultOrderSearchInterval = 6 * time.Hour
+
+	// kpssCritical5 is the 5% critical value of the KPSS level-stationarity test.
+	kpssCritical5 = 0.463
+)
+
+// arimaOrder is a candidate (p, q) pair in the order search.
+type arimaOrder struct{ p, q int }
+
+// SetOrderSearchInterval sets how long, measured in the timestamps of the
+// training rows, Train reuses searched orders before searching again. Zero
+// uses the default of 6h; a negative interval searches on every Train call,
+// as do rows without timestamps.
+func (m *ARIMAModel) SetOrderSearchInterval(interval time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.orderInterval = interval
+}
+
+// ResetOrderSearch makes the next Train call search the auto orders again,
+// e.g. after a change point in the series. It implements OrderSearcher.
+func (m *ARIMAModel) ResetOrderSearch() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.orderSearched = false
+}
+
+// orderSearchDue reports whether Train must search the auto orders for
+// history: on the first call, after ResetOrderSearch, once the search
+// interval has passed since the last search, or when the rows have no
+// timestamps or go back before the last search.
+func (m *ARIMAModel) orderSearchDue(history FeatureFrame) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.orderSearched || m.orderInterval < 0 {
+		return true
+	}
+	ts, ok := lastTimestamp(history)
+	if !ok || ts < m.orderSearchedAt {
+		return true
+	}
+	interval := m.orderInterval
+	if interval == 0 {
+		interval = defaultOrderSearchInterval
+	}
+	return ts-m.orderSearchedAt >= interval.Seconds()
+}
+
+// lastTimestamp returns the timestamp of the last row of frame.
+func lastTimestamp(frame FeatureFrame) (float64, bool) {
+	if len(frame.Rows) == 0 {
+		return 0, false
+	}
+	ts, ok := frame.Rows[len(frame.Rows)-1]["timestamp"]
+	return ts, ok
+}
+
+// searchedARMA reports whether p or q is chosen by the AICc search. Such
+// non-seasonal models are fitted with the search's estimator and forecast by
+// the ARMA recursion, so that the scored model is the one that predicts.
+func (m *ARIMAModel) searchedARMA() bool {
+	return m.autoP || m.autoQ
+}
+
+// searchOrder picks the orders left on auto (0 at construction) for values:
+// d by repeated KPSS tests, then p and q by a stepwise AICc search
+// (Hyndman-Khandakar). Fixed orders are kept as configured. Seasonal
+// differencing, when configured, is applied before the search.
+//
+// The search stops early when the context is done or its deadline passes
+// (defaultOrderSearchBudget when it has none) and returns the best order
+// found so far.
+func (m *ARIMAModel) searchOrder(ctx context.Context, values []float64) (p, d, q int) {
+	p, d, q = m.p, m.d, m.q
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultOrderSearchBudget)
+	}
+
+	series := values
+	if m.seasonal.enabled() {
+		series = seasonalDifference(values, m.seasonal.Period, m.seasonal.D)
+	}
+
+	if m.autoD {
+		d = chooseDifferencing(series, 2)
+	}
+
+	w := difference(series, d)
+	mean := computeMean(w)
+	for i := range w {
+		w[i] -= mean
+	}
+
+	// Keep enough points for Train's minimum once the order is fixed.
+	maxOrder := min(arimaMaxOrder, len(values)-d-10)
+	if maxOrder < 0 {
+		return p, d, q
+	}
+
+	allowed := func(o arimaOrder) bool {
+		if o.p < 0 || o.q < 0 || o.p > maxOrder || o.q > maxOrder {
+			return false
+		}
+		return (m.autoP || o.p == m.p) && (m.autoQ || o.q == m.q)
+	}
+	// clamp replaces fixed orders by their configured value.
+	clamp := func(o arimaOrder) arimaOrder {
+		if !m.autoP {
+			o.p = m.p
+		}
+		if !m.autoQ {
+			o.q = m.q
+		}
+		return o
+	}
+
+	scores := make(map[arimaOrder]float64)
+	best, bestScore := arimaOrder{p, q}, math.Inf(1)
+	try := func(o arimaOrder) bool {
+		o = clamp(o)
+		if _, seen := scores[o]; seen || !allowed(o) {
+			return false
+		}
+		if ctx.Err() != nil || time.Now().After(deadline) {
+			return false
+		}
+		score, ok := arimaAICc(w, o.p, o.q)
+		if !ok {
+			score = math.Inf(1)
+		}
+		scores[o] = score
+		if score < bestScore {
+			best, bestScore = o, score
+			return true
+		}
+		return false
+	}
+
+	for _, o := range []arimaOrder{{2, 2}, {0, 0}, {1, 0}, {0, 1}} {
+		try(o)
+	}
+	for improved := true; improved; {
+		improved = false
+		center := best
+		for _, delta := range []arimaOrder{{-1, 0}, {1, 0}, {0, -1}, {0, 1}, {-1, -1}, {1, 1}, {-1, 1}, {1, -1}} {
+			if try(arimaOrder{center.p + delta.p, center.q + delta.q}) {
+				improved = true
+			}
+		}
+	}
+
+	if math.IsInf(bestScore, 1) {
+		return p, d, q
+	}
+	return best.p, d, best.q
+}
+
+// chooseDifferencing returns the smallest d <= maxD for which the KPSS test
+// does not reject level stationarity of the d-times differenced series.
+func chooseDifferencing(series []float64, maxD int) int {
+	for d := 0; d < maxD; d++ {
+		if kpssStatistic(difference(series, d)) < kpssCritical5 {
+			return d
+		}
+	}
+	return maxD
+}
+
+// kpssStatistic computes the KPSS level-stationarity statistic with a
+// Newey-West long-run variance (Bartlett kernel). Large values reject
+// stationarity. Series that are too short or constant are reported stationary.
+func kpssStatistic(series []float64) float64 {
+	n := len(series)
+	if n < 10 {
+		return 0
+	}
+
+	mean := computeMean(series)
+	resid := make([]float64, n)
+	for i, v := range series {
+		resid[i] = v - mean
+	}
+
+	var partial, sumSq float64
+	for _, e := range resid {
+		partial += e
+		sumSq += partial * partial
+	}
+
+	lags := int(math.Trunc(4 * math.Pow(float64(n)/100, 0.25)))
+	lrv := 0.0
+	for _, e := range resid {
+		lrv += e * e
+	}
+	for l := 1; l <= lags && l < n; l++ {
+		cov := 0.0
+		for t := l; t < n; t++ {
+			cov += resid[t] * resid[t-l]
+		}
+		lrv += 2 * (1 - float64(l)/float64(lags+1)) * cov
+	}
+	lrv /= float64(n)
+	if lrv <= 1e-12 {
+		return 0
+	}
+
+	return sumSq / (float64(n) * float64(n) * lrv)
+}
+
+// arimaAICc scores an ARMA(p,q) fit of the centered stationary series w by the
+// corrected Akaike information criterion of its conditional-sum-of-squares
+// Gaussian likelihood. Coefficients are estimated by Hannan-Rissanen.
+func arimaAICc(w []float64, p, q int) (float64, bool) {
+	ar, ma, ok := hannanRissanen(w, p, q)
+	if !ok {
+		return 0, false
+	}
+
+	skip := max(p, q)
+	errs := replayErrors(w, ar, ma)[skip:]
+	n := float64(len(errs))
+	k := float64(p + q + 2) // coefficients, mean and innovation variance
+	if n-k-1 <= 0 {
+		return 0, false
+	}
+
+	ssr := 0.0
+	for _, e := range errs {
+		ssr += e * e
+	}
+	sigma2 := math.Max(ssr/n, 1e-12*(computeVariance(w)+1))
+
+	logLik := -0.5 * n * (math.Log(2*math.Pi*sigma2) + 1)
+	aicc := -2*logLik + 2*k + 2*k*(k+1)/(n-k-1)
+	if math.IsNaN(aicc) || math.IsInf(aicc, 0) {
+		return 0, false
+	}
+	return aicc, true
+}
+
+// fitSearchedARMA fits ARMA(p,q) to the centered stationary series w with
+// Hannan-Rissanen, as arimaAICc scores it. ok is false when the estimate
+// fails or its one-step errors diverge (a non-invertible MA part), in which
+// case the caller falls back to Yule-Walker.
+func fitSearchedARMA(w []float64, p, q int) (ar, ma []float64, ok bool) {
+	ar, ma, ok = hannanRissanen(w, p, q)
+	if !ok {
+		return nil, nil, false
+	}
+	ar = append(make([]float64, 0, p), ar...)
+	ma = append(make([]float64, 0, q), ma...)
+
+	sigma2 := innovationVariance(replayErrors(w, ar, ma), max(p, q))
+	if math.IsNaN(sigma2) || math.IsInf(sigma2, 0) || sigma2 > 2*computeVariance(w)+1e-12 {
+		return nil, nil, false
+	}
+	return ar, ma, true
+}
+
+// hannanRissanen estimates ARMA(p,q) coefficients for a centered series:
+// innovations are first approximated by the residuals of a long AR fit, then
+// w(t) is regressed on its own lags and the lagged innovations.
+func hannanRissanen(w []float64, p, q int) (ar, ma []float64, ok bool) {
+	if p == 0 && q == 0 {
+		return nil, nil, true
+	}
+
+	n := len(w)
+	longOrder := 0
+	innov := make([]float64, n)
+	if q > 0 {
+		longOrder = min(max(p+q+2, 8), n/5)
+		if longOrder < q {
+			return nil, nil, false
+		}
+		longAR, err := fitAR(w, longOrder)
+		if err != nil {
+			return nil, nil, false
+		}
+		copy(innov[longOrder:], computeResiduals(w, longAR, longOrder))
+	}
+
+	start := max(p, longOrder+q)
+	if n-start <= p+q+5 {
+		return nil, nil, false
+	}
+
+	X := make([][]float64, 0, n-start)
+	y := make([]float64, 0, n-start)
+	for t := start; t < n; t++ {
+		row := make([]float64, 0, p+q)
+		for i := 1; i <= p; i++ {
+			row = append(row, w[t-i])
+		}
+		for j := 1; j <= q; j++ {
+			row = append(row, innov[t-j])
+		}
+		X = append(X, row)
+		y = append(y, w[t])
+	}
+
+	beta, err := ridgeSolve(X, y, make([]float64, p+q))
+	if err != nil {
+		return nil, nil, false
+	}
+	for _, b := range beta {
+		if math.IsNaN(b) || math.IsInf(b, 0) {
+			return nil, nil, false
+		}
+	}
+	return beta[:p], beta[p:], true
+}
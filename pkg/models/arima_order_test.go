@@ -0,0 +1,236 @@
+package models
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+// simulateAR1 returns n points of x(t) = level + phi*(x(t-1)-level) + noise.
+func simulateAR1(n int, phi, level float64, seed uint64) []float64 {
+	rng := rand.New(rand.NewPCG(seed, seed+1))
+	values := make([]float64, n)
+	x := 0.0
+	for i := range values {
+		x = phi*x + rng.NormFloat64()
+		values[i] = level + x
+	}
+	return values
+}
+
+// randomWalk returns n points of a Gaussian random walk starting at level.
+func randomWalk(n int, level float64, seed uint64) []float64 {
+	rng := rand.New(rand.NewPCG(seed, seed+1))
+	values := make([]float64, n)
+	x := level
+	for i := range values {
+		x += rng.NormFloat64()
+		values[i] = x
+	}
+	return values
+}
+
+func TestKPSSStatistic(t *testing.T) {
+	if stat := kpssStatistic(simulateAR1(400, 0, 100, 1)); stat >= kpssCritical5 {
+		t.Errorf("KPSS(white noise) = %.3f, want < %.3f", stat, kpssCritical5)
+	}
+	if stat := kpssStatistic(randomWalk(400, 100, 2)); stat <= kpssCritical5 {
+		t.Errorf("KPSS(random walk) = %.3f, want > %.3f", stat, kpssCritical5)
+	}
+}
+
+func TestChooseDifferencing(t *testing.T) {
+	tests := []struct {
+		name   string
+		series []float64
+		want   int
+	}{
+		{"stationary", simulateAR1(400, 0.5, 100, 3), 0},
+		{"random walk", randomWalk(400, 100, 4), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chooseDifferencing(tt.series, 2); got != tt.want {
+				t.Errorf("chooseDifferencing() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestARIMAAICc_PrefersTrueOrder(t *testing.T) {
+	w := simulateAR1(500, 0.7, 0, 5)
+
+	ar1, ok := arimaAICc(w, 1, 0)
+	if !ok {
+		t.Fatal("arimaAICc(1,0) not ok")
+	}
+	white, ok := arimaAICc(w, 0, 0)
+	if !ok {
+		t.Fatal("arimaAICc(0,0) not ok")
+	}
+	if ar1 >= white {
+		t.Errorf("AICc AR(1) = %.1f, white noise = %.1f; want AR(1) lower", ar1, white)
+	}
+}
+
+func TestARIMAModel_Train_OrderSearch(t *testing.T) {
+	model := NewARIMAModel("test_metric", 60, 600, 0, 0, 0)
+	if err := model.Train(context.Background(), makeFeatureFrame(simulateAR1(500, 0.7, 100, 5))); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+
+	if model.d != 0 {
+		t.Errorf("d = %d, want 0 for a stationary series", model.d)
+	}
+	if model.p < 1 {
+		t.Errorf("p = %d, want >= 1 for an AR(1) series", model.p)
+	}
+	if model.Name() == "arima(1,1,1)" {
+		t.Errorf("Name() = %q, want the searched order", model.Name())
+	}
+
+	forecast, err := model.Predict(context.Background(), FeatureFrame{})
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	if forecast.Values[0] < 90 || forecast.Values[0] > 110 {
+		t.Errorf("Values[0] = %.2f, want near the series level 100", forecast.Values[0])
+	}
+}
+
+func TestARIMAModel_Train_OrderSearchKeepsFixedOrders(t *testing.T) {
+	model := NewARIMAModel("test_metric", 60, 600, 2, 0, 0)
+	if err := model.Train(context.Background(), makeFeatureFrame(simulateAR1(300, 0.5, 100, 7))); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	if model.p != 2 {
+		t.Errorf("p = %d, want fixed order 2", model.p)
+	}
+}
+
+func TestARIMAModel_Train_OrderSearchBudget(t *testing.T) {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	model := NewARIMAModel("test_metric", 60, 600, 0, 1, 0)
+
+	p, _, q := model.searchOrder(ctx, simulateAR1(300, 0.5, 100, 8))
+	if p != 1 || q != 1 {
+		t.Errorf("searchOrder() with expired budget = (%d,%d), want defaults (1,1)", p, q)
+	}
+}
+
+func TestARIMAModel_Train_ReusesSearchedOrder(t *testing.T) {
+	ctx := context.Background()
+	model := NewARIMAModel("test_metric", 60, 600, 0, 0, 0)
+	model.SetOrderSearchInterval(time.Hour)
+
+	ar := makeFeatureFrame(simulateAR1(300, 0.7, 100, 11))
+	if err := model.Train(ctx, ar); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	searched := model.Name()
+
+	// A random walk would be differenced, but the order is cached while the
+	// rows' timestamps stay within the interval.
+	walk := makeFeatureFrame(randomWalk(300, 100, 12))
+	if err := model.Train(ctx, walk); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	if got := model.Name(); got != searched {
+		t.Errorf("Name() after retrain within interval = %q, want cached %q", got, searched)
+	}
+
+	model.ResetOrderSearch()
+	if err := model.Train(ctx, walk); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	if model.d != 1 {
+		t.Errorf("d after ResetOrderSearch = %d, want 1 for a random walk", model.d)
+	}
+}
+
+func TestARIMAModel_OrderSearchDue(t *testing.T) {
+	frame := func(ts float64) FeatureFrame {
+		return FeatureFrame{Rows: []map[string]float64{{"value": 1, "timestamp": ts}}}
+	}
+
+	tests := []struct {
+		name     string
+		interval time.Duration
+		searched bool
+		frame    FeatureFrame
+		want     bool
+	}{
+		{name: "never searched", searched: false, frame: frame(1000), want: true},
+		{name: "within default interval", searched: true, frame: frame(1000 + 3600), want: false},
+		{name: "default interval passed", searched: true, frame: frame(1000 + 6*3600), want: true},
+		{name: "custom interval passed", interval: time.Minute, searched: true, frame: frame(1060), want: true},
+		{name: "negative interval", interval: -1, searched: true, frame: frame(1000), want: true},
+		{name: "time went back", searched: true, frame: frame(10), want: true},
+		{name: "no timestamps", searched: true, frame: FeatureFrame{Rows: []map[string]float64{{"value": 1}}}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := NewARIMAModel("test_metric", 60, 600, 0, 0, 0)
+			model.SetOrderSearchInterval(tt.interval)
+			model.orderSearched = tt.searched
+			model.orderSearchedAt = 1000
+
+			if got := model.orderSearchDue(tt.frame); got != tt.want {
+				t.Errorf("orderSearchDue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestARIMAModel_SearchedOrderForecastsByRecursion(t *testing.T) {
+	const level, phi = 100.0, 0.8
+	values := simulateAR1(500, phi, level, 13)
+	// End far above the level: an AR(1) forecast decays back geometrically.
+	values = append(values, level+20)
+
+	model := NewARIMAModel("test_metric", 60, 600, 0, 0, 1)
+	if err := model.Train(context.Background(), makeFeatureFrame(values)); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	if model.d != 0 || model.p == 0 {
+		t.Fatalf("searched order = %s, want d=0 and p>0", model.Name())
+	}
+
+	forecast, err := model.Predict(context.Background(), FeatureFrame{})
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+
+	// The recursion must use the Hannan-Rissanen coefficients the search scored.
+	centered := make([]float64, len(values))
+	mean := computeMean(values)
+	for i, v := range values {
+		centered[i] = v - mean
+	}
+	ar, ma, ok := fitSearchedARMA(centered, model.p, model.q)
+	if !ok {
+		t.Fatal("fitSearchedARMA() not ok")
+	}
+	errs := replayErrors(centered, ar, ma)
+	want := mean
+	for i, a := range ar {
+		want += a * centered[len(centered)-1-i]
+	}
+	for j, b := range ma {
+		want += b * errs[len(errs)-1-j]
+	}
+	if math.Abs(forecast.Values[0]-want) > 1e-6 {
+		t.Errorf("Values[0] = %.4f, want one-step ARMA forecast %.4f", forecast.Values[0], want)
+	}
+
+	last := forecast.Values[len(forecast.Values)-1]
+	if last >= forecast.Values[0] || last < level-5 {
+		t.Errorf("forecast %.2f → %.2f, want decay towards the level %.0f", forecast.Values[0], last, level)
+	}
+}
@@ -0,0 +1,238 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// Exogenous configures the regressors of an ARIMAX model (see
+// NewARIMAXModel). The zero value disables them.
+type Exogenous struct {
+	// Columns names feature columns used as regressors. Every training row
+	// must carry them.
+	Columns []string
+
+	// Events adds one indicator regressor per event name, 1 while the step
+	// falls inside one of its windows.
+	Events []Event
+}
+
+// enabled reports whether any regressor is configured.
+func (e Exogenous) enabled() bool {
+	return len(e.Columns) > 0 || len(e.Events) > 0
+}
+
+// names returns the regressor names: the columns, then each event name once.
+func (e Exogenous) names() []string {
+	names := slices.Clone(e.Columns)
+	for _, ev := range e.Events {
+		if !slices.Contains(names[len(e.Columns):], ev.Name) {
+			names = append(names, ev.Name)
+		}
+	}
+	return names
+}
+
+// calendarColumns are the time features of features.Builder, which Predict
+// computes for future steps that no feature row covers.
+var calendarColumns = map[string]func(time.Time) float64{
+	"hour":   func(t time.Time) float64 { return float64(t.Hour()) },
+	"minute": func(t time.Time) float64 { return float64(t.Minute()) },
+	"day":    func(t time.Time) float64 { return float64(t.Weekday()) },
+}
+
+// exogFit is the fitted regression part of an ARIMAX model.
+type exogFit struct {
+	beta   []float64 // one coefficient per regressor
+	last   []float64 // regressor values of the last observed row
+	lastTs float64   // Unix time of the last observed row
+}
+
+// NewARIMAXModel creates a regression model with (S)ARIMA errors:
+//
+//	value(t) = Σ βⱼ·xⱼ(t) + η(t),   η ~ SARIMA(p,d,q)(P,D,Q)[s]
+//
+// The regressors xⱼ are the feature columns and event indicators in exog.
+// Train estimates β by least squares on the differenced series (so that
+// trending regressors do not produce spurious fits), then fits the ARIMA part
+// to value - β·x exactly as NewSARIMAModel would. Orders left at 0 are
+// searched on the residuals of a first regression on the undifferenced series.
+//
+// Predict adds β·x over the horizon to the ARIMA forecast, which needs future
+// regressor values. For the step at time t they are taken from, in order:
+//   - a row of the features passed to Predict whose timestamp matches t, so
+//     a caller can append rows for planned events beyond the history
+//     ("value" is not read from them)
+//   - for the calendar columns "hour", "minute" and "day", t itself (UTC)
+//   - for events, their windows
+//   - otherwise the value of the last observed row
+//
+// Event and column effects can only be learned if they vary in the training
+// window; a campaign unlike anything in the history gets a zero coefficient.
+// Rows need a "timestamp" feature.
+//
+// Panics on the same conditions as NewSARIMAModel.
+func NewARIMAXModel(metric string, stepSec, horizonSec int, p, d, q int, seasonal SeasonalOrder, exog Exogenous) *ARIMAModel {
+	m := NewSARIMAModel(metric, stepSec, horizonSec, p, d, q, seasonal)
+	m.exog = Exogenous{
+		Columns: slices.Clone(exog.Columns),
+		Events:  slices.Clone(exog.Events),
+	}
+	return m
+}
+
+// exogMatrix returns the regressors of every row in frame, one row per
+// observation, and the last row's timestamp.
+func (m *ARIMAModel) exogMatrix(frame FeatureFrame) ([][]float64, float64, error) {
+	names := m.exog.names()
+	x := make([][]float64, len(frame.Rows))
+	lastTs := 0.0
+	for i, row := range frame.Rows {
+		ts, ok := row["timestamp"]
+		if !ok {
+			return nil, 0, fmt.Errorf("row %d missing 'timestamp' field", i)
+		}
+		x[i] = make([]float64, len(names))
+		for j, name := range names {
+			if j < len(m.exog.Columns) {
+				v, ok := row[name]
+				if !ok {
+					return nil, 0, fmt.Errorf("row %d missing regressor %q", i, name)
+				}
+				x[i][j] = v
+			} else {
+				x[i][j] = m.eventIndicator(name, ts)
+			}
+		}
+		lastTs = ts
+	}
+	return x, lastTs, nil
+}
+
+// eventIndicator returns 1 if an event window named name contains ts.
+func (m *ARIMAModel) eventIndicator(name string, ts float64) float64 {
+	for _, e := range m.exog.Events {
+		if e.Name == name && e.active(ts) {
+			return 1
+		}
+	}
+	return 0
+}
+
+// fitExogenous estimates β by least squares of the differenced values on the
+// equally differenced regressors. Without differencing an intercept is fitted
+// alongside and dropped; the ARIMA mean absorbs it.
+func fitExogenous(values []float64, x [][]float64, d int, seasonal SeasonalOrder) ([]float64, error) {
+	k := len(x[0])
+	diff := func(series []float64) []float64 {
+		return difference(seasonalDifference(series, seasonal.Period, seasonal.D), d)
+	}
+
+	y := diff(values)
+	columns := make([][]float64, k)
+	column := make([]float64, len(values))
+	for j := range k {
+		for i := range x {
+			column[i] = x[i][j]
+		}
+		columns[j] = diff(column)
+	}
+
+	intercept := d == 0 && seasonal.D == 0
+	width := k
+	if intercept {
+		width++
+	}
+	X := make([][]float64, len(y))
+	for i := range y {
+		X[i] = make([]float64, width)
+		for j := range k {
+			X[i][j] = columns[j][i]
+		}
+		if intercept {
+			X[i][k] = 1
+		}
+	}
+
+	beta, err := ridgeSolve(X, y, make([]float64, width))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fit regressors: %w", err)
+	}
+	return beta[:k], nil
+}
+
+// removeExogenous returns values - β·x.
+func removeExogenous(values []float64, x [][]float64, beta []float64) []float64 {
+	out := make([]float64, len(values))
+	for i, v := range values {
+		out[i] = v - dot(beta, x[i])
+	}
+	return out
+}
+
+// exogOffsets returns β·x for every forecast step, or nil without
+// regressors. Callers must hold at least a read lock.
+func (m *ARIMAModel) exogOffsets(features FeatureFrame, nSteps int) []float64 {
+	if !m.exog.enabled() || len(m.exogFit.beta) == 0 {
+		return nil
+	}
+
+	step := float64(m.stepSec)
+	future := make(map[int64]map[string]float64)
+	for _, row := range features.Rows {
+		if ts, ok := row["timestamp"]; ok && ts > m.exogFit.lastTs {
+			future[int64(math.Round(ts/step))] = row
+		}
+	}
+
+	names := m.exog.names()
+	offsets := make([]float64, nSteps)
+	x := make([]float64, len(names))
+	for h := range offsets {
+		ts := m.exogFit.lastTs + float64(h+1)*step
+		row := future[int64(math.Round(ts/step))]
+		for j, name := range names {
+			if j >= len(m.exog.Columns) {
+				x[j] = m.eventIndicator(name, ts)
+				continue
+			}
+			if v, ok := row[name]; ok {
+				x[j] = v
+			} else if calendar, ok := calendarColumns[name]; ok {
+				x[j] = calendar(time.Unix(int64(ts), 0).UTC())
+			} else {
+				x[j] = m.exogFit.last[j]
+			}
+		}
+		offsets[h] = dot(m.exogFit.beta, x)
+	}
+	return offsets
+}
+
+// exogComponents returns the regressor effects as Forecast.Components, or
+// nil without regressors.
+func exogComponents(offsets []float64) map[string][]float64 {
+	if offsets == nil {
+		return nil
+	}
+	return map[string][]float64{ComponentRegressors: offsets}
+}
+
+// lastOffset returns β·x of the last observed row, 0 without regressors.
+// Callers must hold at least a read lock.
+func (m *ARIMAModel) lastOffset() float64 {
+	if len(m.exogFit.beta) == 0 {
+		return 0
+	}
+	return dot(m.exogFit.beta, m.exogFit.last)
+}
+
+func dot(a, b []float64) float64 {
+	s := 0.0
+	for i := range a {
+		s += a[i] * b[i]
+	}
+	return s
+}
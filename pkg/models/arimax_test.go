@@ -0,0 +1,231 @@
+package models
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+// campaignFrame returns n one-minute rows of AR(1) noise around 100, raised
+// by lift while a promo window is active. Rows carry a 0/1 "promo" column.
+func campaignFrame(n int, start time.Time, promo []Event, lift float64) FeatureFrame {
+	rng := rand.New(rand.NewSource(7))
+	rows := make([]map[string]float64, n)
+	noise := 0.0
+	for i := range rows {
+		ts := float64(start.Add(time.Duration(i) * time.Minute).Unix())
+		active := 0.0
+		for _, e := range promo {
+			if e.active(ts) {
+				active = 1
+			}
+		}
+		noise = 0.5*noise + rng.NormFloat64()*2
+		rows[i] = map[string]float64{
+			"timestamp": ts,
+			"value":     100 + lift*active + noise,
+			"promo":     active,
+		}
+	}
+	return FeatureFrame{Rows: rows}
+}
+
+func promoWindows(start time.Time, offsetsMin ...int) []Event {
+	events := make([]Event, len(offsetsMin))
+	for i, off := range offsetsMin {
+		from := start.Add(time.Duration(off) * time.Minute)
+		events[i] = Event{Name: "promo", Start: from, End: from.Add(30 * time.Minute)}
+	}
+	return events
+}
+
+// liftDuring returns the mean forecast over steps [from, to) minus the mean
+// of the first steps before them.
+func liftDuring(values []float64, from, to int) float64 {
+	before := computeMean(values[:from])
+	during := computeMean(values[from:to])
+	return during - before
+}
+
+func TestARIMAXModel_Name(t *testing.T) {
+	exog := Exogenous{Columns: []string{"promo"}}
+	if got, want := NewARIMAXModel("rps", 60, 1800, 1, 1, 1, SeasonalOrder{}, exog).Name(), "arimax(1,1,1)"; got != want {
+		t.Errorf("Name() = %q, want %q", got, want)
+	}
+	seasonal := SeasonalOrder{P: 1, D: 1, Period: 24}
+	if got, want := NewARIMAXModel("rps", 60, 1800, 1, 1, 1, seasonal, exog).Name(), "sarimax(1,1,1)(1,1,0)[24]"; got != want {
+		t.Errorf("Name() = %q, want %q", got, want)
+	}
+	if got, want := NewARIMAXModel("rps", 60, 1800, 1, 1, 1, SeasonalOrder{}, Exogenous{}).Name(), "arima(1,1,1)"; got != want {
+		t.Errorf("Name() without regressors = %q, want %q", got, want)
+	}
+}
+
+func TestARIMAXModel_EventMovesForecast(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	// Three past campaigns and one starting 10 minutes after the history.
+	events := promoWindows(start, 100, 300, 500, 610)
+	history := campaignFrame(600, start, events, 50)
+
+	plain := NewARIMAModel("rps", 60, 1800, 1, 1, 1)
+	arimax := NewARIMAXModel("rps", 60, 1800, 1, 1, 1, SeasonalOrder{}, Exogenous{Events: events})
+	for _, m := range []*ARIMAModel{plain, arimax} {
+		if err := m.Train(context.Background(), history); err != nil {
+			t.Fatalf("%s: Train() error = %v", m.Name(), err)
+		}
+	}
+
+	if beta := arimax.exogFit.beta[0]; math.Abs(beta-50) > 5 {
+		t.Errorf("promo coefficient = %.2f, want about 50", beta)
+	}
+
+	forecast, err := arimax.Predict(context.Background(), history)
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	// Step h forecasts minute 600+h; the campaign covers minutes 610-639.
+	if lift := liftDuring(forecast.Values, 9, 30); math.Abs(lift-50) > 8 {
+		t.Errorf("forecast lift during campaign = %.2f, want about 50", lift)
+	}
+	if effect := forecast.Components[ComponentRegressors]; len(effect) != len(forecast.Values) || effect[0] != 0 || math.Abs(effect[20]-50) > 5 {
+		t.Errorf("regressor component = %v, want 0 before and about 50 during the campaign", effect)
+	}
+	lower, _ := forecast.Quantile(0.1)
+	upper, _ := forecast.Quantile(0.9)
+	if lower == nil || upper == nil || lower[20] >= forecast.Values[20] || upper[20] <= forecast.Values[20] {
+		t.Errorf("quantiles do not bracket the forecast at step 20")
+	}
+
+	base, err := plain.Predict(context.Background(), history)
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	if lift := liftDuring(base.Values, 9, 30); math.Abs(lift) > 8 {
+		t.Errorf("plain ARIMA lift = %.2f, want none", lift)
+	}
+}
+
+func TestARIMAXModel_FutureRows(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	history := campaignFrame(600, start, promoWindows(start, 100, 300, 500), 50)
+	model := NewARIMAXModel("rps", 60, 1800, 1, 1, 1, SeasonalOrder{}, Exogenous{Columns: []string{"promo"}})
+	if err := model.Train(context.Background(), history); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+
+	// Without future rows the last promo value (0) is held.
+	held, err := model.Predict(context.Background(), history)
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	if lift := liftDuring(held.Values, 9, 30); math.Abs(lift) > 8 {
+		t.Errorf("lift without future rows = %.2f, want none", lift)
+	}
+
+	// A schedule announces a campaign for minutes 610-629.
+	features := FeatureFrame{Rows: append([]map[string]float64(nil), history.Rows...)}
+	for i := 600; i < 630; i++ {
+		promo := 0.0
+		if i >= 610 {
+			promo = 1
+		}
+		features.Rows = append(features.Rows, map[string]float64{
+			"timestamp": float64(start.Add(time.Duration(i) * time.Minute).Unix()),
+			"promo":     promo,
+		})
+	}
+	planned, err := model.Predict(context.Background(), features)
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	if lift := liftDuring(planned.Values, 9, 29); math.Abs(lift-50) > 8 {
+		t.Errorf("lift with planned campaign = %.2f, want about 50", lift)
+	}
+}
+
+func TestARIMAXModel_CalendarColumn(t *testing.T) {
+	// Two days of load stepping up by 10 every hour, ending at 10:50 UTC.
+	end := time.Date(2025, 3, 4, 10, 50, 0, 0, time.UTC)
+	n := 2 * 24 * 60
+	rows := make([]map[string]float64, n)
+	for i := range rows {
+		ts := end.Add(-time.Duration(n-1-i) * time.Minute)
+		rows[i] = map[string]float64{
+			"timestamp": float64(ts.Unix()),
+			"hour":      float64(ts.Hour()),
+			"value":     100 + 10*float64(ts.Hour()),
+		}
+	}
+	history := FeatureFrame{Rows: rows}
+
+	model := NewARIMAXModel("rps", 60, 1800, 1, 1, 1, SeasonalOrder{}, Exogenous{Columns: []string{"hour"}})
+	if err := model.Train(context.Background(), history); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	forecast, err := model.Predict(context.Background(), history)
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	// Step 9 forecasts 11:00, when the hour feature computed for the future
+	// step moves the forecast up by 10.
+	if jump := forecast.Values[9] - forecast.Values[8]; math.Abs(jump-10) > 1 {
+		t.Errorf("forecast change at 11:00 = %.2f, want about 10", jump)
+	}
+}
+
+func TestARIMAXModel_Train_MissingRegressor(t *testing.T) {
+	history := campaignFrame(100, time.Unix(1700000000, 0), nil, 0)
+	model := NewARIMAXModel("rps", 60, 1800, 1, 1, 1, SeasonalOrder{}, Exogenous{Columns: []string{"queue_depth"}})
+	err := model.Train(context.Background(), history)
+	if err == nil || !strings.Contains(err.Error(), "queue_depth") {
+		t.Errorf("Train() error = %v, want missing regressor", err)
+	}
+}
+
+func TestARIMAXModel_UpdateAndState(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	events := promoWindows(start, 100, 300, 500)
+	frame := campaignFrame(700, start, events, 50)
+	exog := Exogenous{Columns: []string{"promo"}}
+
+	model := NewARIMAXModel("rps", 60, 1800, 1, 1, 1, SeasonalOrder{}, exog)
+	if err := model.Train(context.Background(), FeatureFrame{Rows: frame.Rows[:650]}); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	if err := model.Update(context.Background(), FeatureFrame{Rows: frame.Rows[650:]}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got, want := model.exogFit.lastTs, frame.Rows[699]["timestamp"]; got != want {
+		t.Errorf("last timestamp after Update = %v, want %v", got, want)
+	}
+
+	data, err := model.MarshalState()
+	if err != nil {
+		t.Fatalf("MarshalState() error = %v", err)
+	}
+	restored := NewARIMAXModel("rps", 60, 1800, 1, 1, 1, SeasonalOrder{}, exog)
+	if err := restored.UnmarshalState(data); err != nil {
+		t.Fatalf("UnmarshalState() error = %v", err)
+	}
+	want, _ := model.Predict(context.Background(), frame)
+	got, err := restored.Predict(context.Background(), frame)
+	if err != nil {
+		t.Fatalf("Predict() after restore error = %v", err)
+	}
+	for i := range want.Values {
+		if got.Values[i] != want.Values[i] {
+			t.Fatalf("restored forecast[%d] = %v, want %v", i, got.Values[i], want.Values[i])
+		}
+	}
+
+	other := NewARIMAXModel("rps", 60, 1800, 1, 1, 1, SeasonalOrder{}, Exogenous{Columns: []string{"hour"}})
+	if err := other.UnmarshalState(data); err == nil {
+		t.Errorf("UnmarshalState() with other regressors: expected error")
+	}
+	if err := NewARIMAModel("rps", 60, 1800, 1, 1, 1).UnmarshalState(data); err == nil {
+		t.Errorf("UnmarshalState() into plain ARIMA: expected error")
+	}
+}
@@ -0,0 +1,258 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Candidate is a model configuration considered by AutoModel.
+type Candidate struct {
+	// Name identifies the candidate in logs and scores (e.g., "arima(2,1,1)").
+	Name string
+
+	// New returns a fresh, untrained model for this candidate.
+	New func() Model
+}
+
+// AutoOptions configures an AutoModel.
+type AutoOptions struct {
+	// Metric is the error metric used to rank candidates. Defaults to MetricMAE.
+	Metric ErrorMetric
+
+	// Quantile is the quantile scored by MetricPinball. Defaults to 0.9.
+	Quantile float64
+
+	// Folds is the number of rolling origins evaluated per candidate.
+	// Defaults to 5.
+	Folds int
+
+	// Reselect is how often selection is re-run, measured in data time (the
+	// "timestamp" of the last training row) so that backtests reselect as
+	// production would. Defaults to 6h.
+	Reselect time.Duration
+
+	// OnSelect, if set, is called after every selection with the chosen
+	// candidate and the score of every candidate that could be evaluated.
+	OnSelect func(selected string, scores map[string]float64)
+}
+
+// AutoModel picks the most accurate of several candidate models by
+// rolling-origin cross-validation on the training history.
+//
+// On the first Train call, and again whenever Reselect has elapsed, each
+// candidate is backtested: for each of Folds origins at the end of the
+// history (one horizon apart), a fresh candidate is trained on the data before
+// the origin and its forecast is scored against the following horizon. The
+// candidate with the lowest mean score is trained on the full history and
+// serves Predict until the next selection. Between selections Train only
+// retrains the selected model. Selection is also re-run when the history's
+// last timestamp goes back; without timestamps, Reselect is measured on the
+// clock.
+type AutoModel struct {
+	metric     string
+	stepSec    int
+	horizon    int
+	candidates []Candidate
+	opts       AutoOptions
+	now        func() time.Time
+
+	mu         sync.RWMutex
+	current    Model
+	selected   string
+	scores     map[string]float64
+	lastSelect time.Time
+}
+
+// NewAutoModel creates a model that selects among candidates.
+//
+// Panics if candidates is empty.
+func NewAutoModel(metric string, stepSec, horizon int, candidates []Candidate, opts AutoOptions) *AutoModel {
+	if len(candidates) == 0 {
+		panic("auto model needs at least one candidate")
+	}
+	if opts.Metric == "" {
+		opts.Metric = MetricMAE
+	}
+	if opts.Quantile <= 0 || opts.Quantile >= 1 {
+		opts.Quantile = 0.9
+	}
+	if opts.Folds <= 0 {
+		opts.Folds = 5
+	}
+	if opts.Reselect <= 0 {
+		opts.Reselect = 6 * time.Hour
+	}
+
+	return &AutoModel{
+		metric:     metric,
+		stepSec:    stepSec,
+		horizon:    horizon,
+		candidates: candidates,
+		opts:       opts,
+		now:        time.Now,
+	}
+}
+
+// Name returns "auto(<selected>)", or "auto" before the first selection.
+func (m *AutoModel) Name() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.selected == "" {
+		return "auto"
+	}
+	return "auto(" + m.selected + ")"
+}
+
+// Selected returns the currently selected candidate and the scores from the
+// last selection. The name is empty before the first selection.
+func (m *AutoModel) Selected() (string, map[string]float64) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	scores := make(map[string]float64, len(m.scores))
+	for k, v := range m.scores {
+		scores[k] = v
+	}
+	return m.selected, scores
+}
+
+// Train runs selection when due and trains the selected model on history.
+//
+// Returns an error if no candidate could be evaluated before any model has
+// been selected, or if training the selected model fails.
+func (m *AutoModel) Train(ctx context.Context, history FeatureFrame) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	m.mu.RLock()
+	current := m.current
+	at := m.dataTime(history)
+	due := current == nil || at.Before(m.lastSelect) || at.Sub(m.lastSelect) >= m.opts.Reselect
+	m.mu.RUnlock()
+
+	if due {
+		err := m.selectModel(ctx, history, at)
+		if err == nil || current == nil {
+			return err
+		}
+		// Keep serving the previous choice and retry selection next time.
+	}
+
+	return current.Train(ctx, history)
+}
+
+// Predict forecasts with the selected model.
+//
+// Returns an error if no model has been selected yet.
+func (m *AutoModel) Predict(ctx context.Context, features FeatureFrame) (Forecast, error) {
+	m.mu.RLock()
+	current := m.current
+	m.mu.RUnlock()
+
+	if current == nil {
+		return Forecast{}, errors.New("model not trained, call Train() first")
+	}
+	return current.Predict(ctx, features)
+}
+
+// dataTime returns the time of the last row of history, or the clock when it
+// has no timestamp.
+func (m *AutoModel) dataTime(history FeatureFrame) time.Time {
+	if ts, ok := lastTimestamp(history); ok {
+		return time.Unix(0, int64(ts*float64(time.Second)))
+	}
+	return m.now()
+}
+
+// selectModel backtests every candidate, trains the best one on the full
+// history and installs it, recording at as the time of selection.
+func (m *AutoModel) selectModel(ctx context.Context, history FeatureFrame, at time.Time) error {
+	rows := make([]map[string]float64, 0, len(history.Rows))
+	for _, row := range history.Rows {
+		if _, ok := row["value"]; ok {
+			rows = append(rows, row)
+		}
+	}
+
+	scores := make(map[string]float64, len(m.candidates))
+	best := -1
+	for i, c := range m.candidates {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		score, ok := m.evaluate(ctx, c, rows)
+		if !ok {
+			continue
+		}
+		scores[c.Name] = score
+		if best < 0 || score < scores[m.candidates[best].Name] {
+			best = i
+		}
+	}
+	if best < 0 {
+		return fmt.Errorf("no candidate could be evaluated on %d points", len(rows))
+	}
+
+	chosen := m.candidates[best]
+	model := chosen.New()
+	if err := model.Train(ctx, history); err != nil {
+		return fmt.Errorf("train selected model %s: %w", chosen.Name, err)
+	}
+
+	m.mu.Lock()
+	m.current = model
+	m.selected = chosen.Name
+	m.scores = scores
+	m.lastSelect = at
+	m.mu.Unlock()
+
+	if m.opts.OnSelect != nil {
+		m.opts.OnSelect(chosen.Name, scores)
+	}
+	return nil
+}
+
+// evaluate returns a candidate's mean score over the rolling origins.
+// The second return value is false if no fold could be evaluated.
+func (m *AutoModel) evaluate(ctx context.Context, c Candidate, rows []map[string]float64) (float64, bool) {
+	nSteps := max(m.horizon/m.stepSec, 1)
+
+	total, folds := 0.0, 0
+	for k := m.opts.Folds; k >= 1; k-- {
+		cut := len(rows) - k*nSteps
+		if cut < 1 {
+			continue
+		}
+
+		train := FeatureFrame{Rows: rows[:cut]}
+		model := c.New()
+		if err := model.Train(ctx, train); err != nil {
+			continue
+		}
+		forecast, err := model.Predict(ctx, train)
+		if err != nil || len(forecast.Values) < nSteps {
+			continue
+		}
+
+		actual := make([]float64, nSteps)
+		for h := range actual {
+			actual[h] = rows[cut+h]["value"]
+		}
+		score := m.opts.Metric.Score(actual, forecast, m.opts.Quantile)
+		if math.IsNaN(score) || math.IsInf(score, 0) {
+			continue
+		}
+		total += score
+		folds++
+	}
+	if folds == 0 {
+		return 0, false
+	}
+	return total / float64(folds), true
+}
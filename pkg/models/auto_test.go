@@ -0,0 +1,133 @@
+package models
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func constantCandidate(name string, value float64) Candidate {
+	return Candidate{Name: name, New: func() Model { return &constantModel{name: name, value: value} }}
+}
+
+func flatHistory(n int, value float64) FeatureFrame {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = value
+	}
+	return timedFrame(0, values...)
+}
+
+func TestAutoModel_SelectsLowestError(t *testing.T) {
+	var selected string
+	var scores map[string]float64
+	model := NewAutoModel("m", 60, 180, []Candidate{
+		constantCandidate("far", 150),
+		constantCandidate("close", 105),
+	}, AutoOptions{OnSelect: func(name string, s map[string]float64) {
+		selected, scores = name, s
+	}})
+
+	if model.Name() != "auto" {
+		t.Errorf("Name() before selection = %q, want %q", model.Name(), "auto")
+	}
+	if _, err := model.Predict(context.Background(), FeatureFrame{}); err == nil {
+		t.Error("Predict() before Train() error = nil, want error")
+	}
+
+	if err := model.Train(context.Background(), flatHistory(30, 100)); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+
+	if selected != "close" {
+		t.Errorf("OnSelect selected = %q, want %q", selected, "close")
+	}
+	if scores["close"] != 5 || scores["far"] != 50 {
+		t.Errorf("scores = %v, want close=5 far=50", scores)
+	}
+	if model.Name() != "auto(close)" {
+		t.Errorf("Name() = %q, want %q", model.Name(), "auto(close)")
+	}
+
+	forecast, err := model.Predict(context.Background(), FeatureFrame{})
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	if forecast.Values[0] != 105 {
+		t.Errorf("Values[0] = %v, want 105", forecast.Values[0])
+	}
+}
+
+func TestAutoModel_PinballPrefersOverForecast(t *testing.T) {
+	model := NewAutoModel("m", 60, 180, []Candidate{
+		constantCandidate("under", 90),
+		constantCandidate("over", 110),
+	}, AutoOptions{Metric: MetricPinball, Quantile: 0.9})
+
+	if err := model.Train(context.Background(), flatHistory(30, 100)); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	if name, _ := model.Selected(); name != "over" {
+		t.Errorf("Selected() = %q, want %q", name, "over")
+	}
+}
+
+func TestAutoModel_ReselectCadence(t *testing.T) {
+	// history returns 30 flat rows starting at minute start.
+	history := func(start int, timed bool) FeatureFrame {
+		frame := timedFrame(start, slices.Repeat([]float64{100}, 30)...)
+		for _, row := range frame.Rows {
+			if !timed {
+				delete(row, "timestamp")
+			}
+		}
+		return frame
+	}
+
+	tests := []struct {
+		name  string
+		timed bool
+		data  []int // minutes the history starts at, per Train call
+		clock []int // minutes on the clock, per Train call
+		want  int
+	}{
+		{name: "data time", timed: true, data: []int{0, 30, 61}, clock: []int{0, 0, 0}, want: 2},
+		{name: "clock ignored with timestamps", timed: true, data: []int{0, 0, 0}, clock: []int{0, 30, 61}, want: 1},
+		{name: "data going back", timed: true, data: []int{0, -30, -61}, clock: []int{0, 0, 0}, want: 3},
+		{name: "clock without timestamps", data: []int{0, 0, 0}, clock: []int{0, 30, 61}, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selections := 0
+			model := NewAutoModel("m", 60, 180, []Candidate{
+				constantCandidate("a", 100),
+			}, AutoOptions{
+				Reselect: time.Hour,
+				OnSelect: func(string, map[string]float64) { selections++ },
+			})
+			start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+			for i := range tt.data {
+				model.now = func() time.Time { return start.Add(time.Duration(tt.clock[i]) * time.Minute) }
+				if err := model.Train(context.Background(), history(tt.data[i], tt.timed)); err != nil {
+					t.Fatalf("Train() error = %v", err)
+				}
+			}
+			if selections != tt.want {
+				t.Errorf("selections = %d, want %d", selections, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoModel_NoCandidateEvaluable(t *testing.T) {
+	model := NewAutoModel("m", 60, 180, []Candidate{
+		{Name: "broken", New: func() Model { return &constantModel{name: "broken", fail: true} }},
+	}, AutoOptions{})
+
+	if err := model.Train(context.Background(), flatHistory(30, 100)); err == nil {
+		t.Error("Train() error = nil, want error when no candidate can be evaluated")
+	}
+}
@@ -3,12 +3,13 @@ package models
 import (
 	"context"
 	"fmt"
+	"math"
 )
 
 // BaselineModel implements a predictive forecasting model combining:
 //   - Linear trend detection (slope from recent history)
 //   - Momentum detection (acceleration/deceleration)
-//   - Multi-level seasonality (minute-of-hour and hour-of-day patterns)
+//   - Multi-level seasonality (minute-of-hour, hour-of-day and hour-of-week patterns)
 //
 // **Recommended Usage:**
 //   - Training data: 3-24 hours of historical metrics (optimal: 6-12 hours)
@@ -16,18 +17,20 @@ import (
 //   - Works best with: recurring patterns (e.g., traffic spikes, daily cycles)
 //
 // **Limitations:**
-//   - For patterns > 24 hours (weekly cycles), consider ARIMA model
+//   - Weekly cycles need the "day" feature and several days of history;
+//     hours of the week with few samples back off to the hour of day
 //   - Requires consistent step size in training data
 //   - Pattern detection needs at least 2-3 occurrences to learn reliably
 //
 // Algorithm:
-//  1. Training: Learn minute-of-hour and hour-of-day statistical patterns
+//  1. Training: Learn minute-of-hour, hour-of-day and hour-of-week statistical patterns
 //  2. Trend: Compute recent slope via linear regression over trailing window
 //  3. Momentum: Detect acceleration by comparing recent vs older slopes
 //  4. Forecast: For each future step t:
 //     a. Base = current + slope*t + 0.5*acceleration*t²
 //     b. Seasonal adjustment from learned patterns
-//     c. Combine base trend with seasonal component (adaptive weighting)
+//     c. Combine base trend with seasonal component (adaptive weighting,
+//        configurable through BaselineOptions)
 //  5. Clamp to non-negative values
 //
 type BaselineModel struct {
@@ -47,8 +50,23 @@ type BaselineModel struct {
 	// hourSeasonality stores hour-of-day patterns (0-23)
 	// Captures daily patterns like business hours vs night
 	hourSeasonality map[int]*seasonalPattern
+
+	// weekSeasonality stores hour-of-week patterns (0-167, day*24+hour)
+	// Separates e.g. Monday mornings from Sunday mornings
+	weekSeasonality map[int]*seasonalPattern
+
+	// opts holds the backoff and blending parameters
+	opts BaselineOptions
+
+	// residuals stores backtested forecast errors per horizon step
+	// Used to derive empirical prediction quantiles
+	residuals [][]float64
 }
 
+// baselineBacktestOrigins is the number of rolling origins per horizon step
+// used to estimate forecast errors during training.
+const baselineBacktestOrigins = 24
+
 // seasonalPattern holds statistical summary for a recurring pattern
 type seasonalPattern struct {
 	mean  float64 // average value at this time point
@@ -57,14 +75,76 @@ type seasonalPattern struct {
 	count int     // number of observations
 }
 
-// NewBaselineModel creates a new baseline forecasting model.
+// BaselineOptions configures the seasonal backoff and blending of a
+// BaselineModel. Zero values select the defaults documented on each field; a
+// negative value sets the parameter to 0. Weights are capped at 1.
+type BaselineOptions struct {
+	// WeekBackoff is the sample count at which an hour-of-week bucket and its
+	// hour-of-day bucket are weighted equally (default 60). A bucket with n
+	// samples gets weight n/(n+WeekBackoff), so thinly covered hours of the
+	// week back off to the hour of day. A negative value uses hour-of-week
+	// buckets as-is.
+	WeekBackoff float64
+
+	// MaxBlend is the share of a bucket's maximum blended into its mean while
+	// the series accelerates upwards (default 0.3).
+	MaxBlend float64
+
+	// SpikeWeight is the weight of the seasonal value when it is more than
+	// 1.5x the trend forecast (default 0.8).
+	SpikeWeight float64
+
+	// RiseWeight is the weight of the seasonal value when it is 1.2-1.5x the
+	// trend forecast (default 0.7).
+	RiseWeight float64
+
+	// DipWeight is the weight of the seasonal value when it is below 0.8x the
+	// trend forecast (default 0.6).
+	DipWeight float64
+
+	// NeutralWeight is the weight of the seasonal value when it roughly
+	// agrees with the trend forecast (default 0.5).
+	NeutralWeight float64
+}
+
+// withDefaults resolves zero and negative values as documented on BaselineOptions.
+func (o BaselineOptions) withDefaults() BaselineOptions {
+	resolve := func(v, def, limit float64) float64 {
+		switch {
+		case v == 0:
+			return def
+		case v < 0:
+			return 0
+		default:
+			return min(v, limit)
+		}
+	}
+	return BaselineOptions{
+		WeekBackoff:   resolve(o.WeekBackoff, 60, math.Inf(1)),
+		MaxBlend:      resolve(o.MaxBlend, 0.3, 1),
+		SpikeWeight:   resolve(o.SpikeWeight, 0.8, 1),
+		RiseWeight:    resolve(o.RiseWeight, 0.7, 1),
+		DipWeight:     resolve(o.DipWeight, 0.6, 1),
+		NeutralWeight: resolve(o.NeutralWeight, 0.5, 1),
+	}
+}
+
+// NewBaselineModel creates a new baseline forecasting model with default options.
 func NewBaselineModel(metric string, stepSec, horizon int) *BaselineModel {
+	return NewBaselineModelWithOptions(metric, stepSec, horizon, BaselineOptions{})
+}
+
+// NewBaselineModelWithOptions creates a new baseline forecasting model with
+// custom backoff and blending parameters.
+func NewBaselineModelWithOptions(metric string, stepSec, horizon int, opts BaselineOptions) *BaselineModel {
 	return &BaselineModel{
 		metric:            metric,
 		stepSec:           stepSec,
 		horizon:           horizon,
 		minuteSeasonality: make(map[int]*seasonalPattern),
 		hourSeasonality:   make(map[int]*seasonalPattern),
+		weekSeasonality:   make(map[int]*seasonalPattern),
+		opts:              opts.withDefaults(),
 	}
 }
 
@@ -78,9 +158,16 @@ func (m *BaselineModel) Name() string {
 // The model extracts:
 //  - Minute-of-hour patterns (0-59): for intra-hour cycles
 //  - Hour-of-day patterns (0-23): for daily cycles
+//  - Hour-of-week patterns (0-167): for weekly cycles, when rows carry "day"
 //
 // For each time bucket, computes: mean, min, max, count
 // Requires at least 2 observations per bucket to establish a pattern.
+// Patterns learned by earlier calls are discarded, so buckets the history
+// does not cover fall back as if the model were new.
+//
+// Afterwards the model is backtested from rolling origins over the end of the
+// history to collect forecast errors per horizon step, from which Predict
+// derives empirical quantiles.
 func (m *BaselineModel) Train(ctx context.Context, history FeatureFrame) error {
 	if len(history.Rows) == 0 {
 		return nil
@@ -92,6 +179,9 @@ func (m *BaselineModel) Train(ctx context.Context, history FeatureFrame) error {
 	// Accumulators for hour-of-day patterns
 	hourValues := make(map[int][]float64)
 
+	// Accumulators for hour-of-week patterns
+	weekValues := make(map[int][]float64)
+
 	// Collect all values by their time buckets
 	for _, row := range history.Rows {
 		value, hasValue := row["value"]
@@ -114,8 +204,18 @@ func (m *BaselineModel) Train(ctx context.Context, history FeatureFrame) error {
 				hourValues[h] = append(hourValues[h], value)
 			}
 		}
+
+		// Collect hour-of-week data
+		if w, ok := hourOfWeek(row); ok {
+			weekValues[w] = append(weekValues[w], value)
+		}
 	}
 
+	// Rebuild the patterns from this history only
+	m.minuteSeasonality = make(map[int]*seasonalPattern)
+	m.hourSeasonality = make(map[int]*seasonalPattern)
+	m.weekSeasonality = make(map[int]*seasonalPattern)
+
 	// Compute statistics for each minute-of-hour
 	for minute := 0; minute < 60; minute++ {
 		values := minuteValues[minute]
@@ -132,9 +232,39 @@ func (m *BaselineModel) Train(ctx context.Context, history FeatureFrame) error {
 		}
 	}
 
+	// Compute statistics for each hour-of-week
+	for w := 0; w < 168; w++ {
+		values := weekValues[w]
+		if len(values) >= 2 {
+			m.weekSeasonality[w] = computeSeasonalPattern(values)
+		}
+	}
+
+	m.residuals = backtestResiduals(history, m.numSteps(), 10, baselineBacktestOrigins, m.predictValues)
+
 	return nil
 }
 
+// hourOfWeek returns the hour-of-week bucket (day*24+hour) of a row that
+// carries both "day" and "hour".
+func hourOfWeek(row map[string]float64) (int, bool) {
+	day, hasDay := row["day"]
+	hour, hasHour := row["hour"]
+	if !hasDay || !hasHour {
+		return 0, false
+	}
+	d, h := int(day), int(hour)
+	if d < 0 || d >= 7 || h < 0 || h >= 24 {
+		return 0, false
+	}
+	return d*24 + h, true
+}
+
+// numSteps returns the number of forecast steps over the horizon.
+func (m *BaselineModel) numSteps() int {
+	return max(m.horizon/m.stepSec, 1)
+}
+
 // computeSeasonalPattern calculates statistical summary from a set of values
 func computeSeasonalPattern(values []float64) *seasonalPattern {
 	if len(values) == 0 {
@@ -169,6 +299,7 @@ func computeSeasonalPattern(values []float64) *seasonalPattern {
 //   - "value": the metric value (required)
 //   - "minute": minute of hour 0-59 (recommended for intra-hour patterns)
 //   - "hour": hour of day 0-23 (recommended for daily patterns)
+//   - "day": day of week 0-6 (optional, for weekly patterns)
 //   - "timestamp": Unix timestamp (optional, for ordering)
 //
 // Algorithm:
@@ -176,14 +307,49 @@ func computeSeasonalPattern(values []float64) *seasonalPattern {
 //  2. Detect momentum (acceleration) by comparing recent vs older trend
 //  3. For each forecast step:
 //     - Compute base prediction using trend + momentum
-//     - Look up seasonal pattern for that future time
+//     - Look up seasonal pattern for that future time: the minute-of-hour
+//       bucket, falling back to hour-of-week and then hour-of-day. Where an
+//       hour-of-week bucket (backed off to hour-of-day) exists, it sets the
+//       level and the minute-of-hour bucket adds its deviation from the
+//       average minute
 //     - Combine base and seasonal predictions with adaptive weighting
 //  4. Clamp to non-negative values
 //
-// Returns a Forecast with Values of length horizon/stepSec.
+// Returns a Forecast with Values of length horizon/stepSec. Quantiles are
+// added once training has produced enough backtested errors. Components holds
+// the level, trend, momentum and seasonal terms of every step and the seasonal
+// weight used to blend them.
 func (m *BaselineModel) Predict(ctx context.Context, features FeatureFrame) (Forecast, error) {
+	forecastValues, components, err := m.predict(features)
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	return Forecast{
+		Metric:     m.metric,
+		Values:     forecastValues,
+		StepSec:    m.stepSec,
+		Horizon:    m.horizon,
+		Quantiles:  empiricalQuantiles(forecastValues, m.residuals),
+		Components: components,
+	}, nil
+}
+
+// predictValues computes the point forecast for backtesting.
+func (m *BaselineModel) predictValues(features FeatureFrame) ([]float64, error) {
+	values, _, err := m.predict(features)
+	return values, err
+}
+
+// predict computes the point forecast and its components: before clamping,
+// each value is
+//
+//	(1-seasonal_weight)·(level + trend + momentum) + seasonal_weight·seasonal
+//
+// with seasonal_weight 0 at steps without a usable seasonal pattern.
+func (m *BaselineModel) predict(features FeatureFrame) ([]float64, map[string][]float64, error) {
 	if len(features.Rows) == 0 {
-		return Forecast{}, fmt.Errorf("features cannot be empty")
+		return nil, nil, fmt.Errorf("features cannot be empty")
 	}
 
 	// Extract value series
@@ -195,7 +361,7 @@ func (m *BaselineModel) Predict(ctx context.Context, features FeatureFrame) (For
 	}
 
 	if len(values) == 0 {
-		return Forecast{}, fmt.Errorf("no 'value' field found in features")
+		return nil, nil, fmt.Errorf("no 'value' field found in features")
 	}
 
 	currentValue := values[len(values)-1]
@@ -209,6 +375,7 @@ func (m *BaselineModel) Predict(ctx context.Context, features FeatureFrame) (For
 	// Get current time context
 	currentMinute := -1
 	currentHour := -1
+	currentWeekHour := -1
 	if len(features.Rows) > 0 {
 		lastRow := features.Rows[len(features.Rows)-1]
 		if m, ok := lastRow["minute"]; ok {
@@ -217,15 +384,25 @@ func (m *BaselineModel) Predict(ctx context.Context, features FeatureFrame) (For
 		if h, ok := lastRow["hour"]; ok {
 			currentHour = int(h)
 		}
+		if w, ok := hourOfWeek(lastRow); ok {
+			currentWeekHour = w
+		}
 	}
 
+	// Average minute level, from which minute-of-hour deviations are measured
+	minuteLevel, hasMinuteLevel := m.minuteLevel()
+
 	// Generate forecast
-	numSteps := m.horizon / m.stepSec
-	if numSteps <= 0 {
-		numSteps = 1
-	}
+	numSteps := m.numSteps()
 
 	forecastValues := make([]float64, numSteps)
+	components := map[string][]float64{
+		ComponentLevel:          make([]float64, numSteps),
+		ComponentTrend:          make([]float64, numSteps),
+		ComponentMomentum:       make([]float64, numSteps),
+		ComponentSeasonal:       make([]float64, numSteps),
+		ComponentSeasonalWeight: make([]float64, numSteps),
+	}
 
 	for i := 0; i < numSteps; i++ {
 		// Time offset in seconds
@@ -233,38 +410,66 @@ func (m *BaselineModel) Predict(ctx context.Context, features FeatureFrame) (For
 
 		// Base prediction using trend + momentum (quadratic extrapolation)
 		// Formula: y(t) = y0 + trend*t + 0.5*momentum*t²
-		basePrediction := currentValue + trend*timeOffset + 0.5*momentum*timeOffset*timeOffset/60.0
+		trendEffect := trend * timeOffset
+		momentumEffect := 0.5 * momentum * timeOffset * timeOffset / 60.0
+		basePrediction := currentValue + trendEffect + momentumEffect
+		components[ComponentLevel][i] = currentValue
+		components[ComponentTrend][i] = trendEffect
+		components[ComponentMomentum][i] = momentumEffect
 
 		// Calculate future time bucket for seasonal lookup
 		secondsAhead := (i + 1) * m.stepSec
 		minutesAhead := secondsAhead / 60
 		hoursAhead := secondsAhead / 3600
 
+		rising := momentum > 0
+
+		// Hour-of-week pattern, backed off to hour-of-day
+		var weekValue float64
+		var hasWeekPattern bool
+		if currentWeekHour >= 0 {
+			futureWeekHour := (currentWeekHour + hoursAhead) % 168
+			if pattern := m.weekSeasonality[futureWeekHour]; pattern != nil && pattern.count >= 2 {
+				weekValue = m.patternValue(pattern, rising)
+				if currentHour >= 0 {
+					futureHour := (currentHour + hoursAhead) % 24
+					if hourPattern := m.hourSeasonality[futureHour]; hourPattern != nil && hourPattern.count >= 2 {
+						n := float64(pattern.count)
+						w := n / (n + m.opts.WeekBackoff)
+						weekValue = w*weekValue + (1-w)*m.patternValue(hourPattern, rising)
+					}
+				}
+				hasWeekPattern = true
+			}
+		}
+
 		var seasonalValue float64
-		var hasSeasonalPattern bool
+		hasSeasonalPattern := false
 
-		// Prefer minute-of-hour seasonality (more granular)
+		// Prefer minute-of-hour seasonality (more granular). With an
+		// hour-of-week level, the minute adds its deviation from the
+		// average minute on top of it.
 		if currentMinute >= 0 && len(m.minuteSeasonality) > 0 {
 			futureMinute := (currentMinute + minutesAhead) % 60
-			if pattern, ok := m.minuteSeasonality[futureMinute]; ok && pattern != nil {
-				// Use the mean, but favor max if we're detecting upward momentum
-				seasonalValue = pattern.mean
-				if momentum > 0 && pattern.max > pattern.mean {
-					// Blend mean and max based on momentum strength
-					seasonalValue = 0.7*pattern.mean + 0.3*pattern.max
+			if pattern, ok := m.minuteSeasonality[futureMinute]; ok && pattern != nil && pattern.count >= 2 {
+				seasonalValue = m.patternValue(pattern, rising)
+				if hasWeekPattern && hasMinuteLevel {
+					seasonalValue = weekValue + seasonalValue - minuteLevel
 				}
 				hasSeasonalPattern = true
 			}
 		}
 
-		// Fall back to hour-of-day seasonality if no minute pattern
+		// Fall back to the hour-of-week, then hour-of-day seasonality if no
+		// minute pattern
+		if !hasSeasonalPattern && hasWeekPattern {
+			seasonalValue = weekValue
+			hasSeasonalPattern = true
+		}
 		if !hasSeasonalPattern && currentHour >= 0 && len(m.hourSeasonality) > 0 {
 			futureHour := (currentHour + hoursAhead) % 24
-			if pattern, ok := m.hourSeasonality[futureHour]; ok && pattern != nil {
-				seasonalValue = pattern.mean
-				if momentum > 0 && pattern.max > pattern.mean {
-					seasonalValue = 0.7*pattern.mean + 0.3*pattern.max
-				}
+			if pattern, ok := m.hourSeasonality[futureHour]; ok && pattern != nil && pattern.count >= 2 {
+				seasonalValue = m.patternValue(pattern, rising)
 				hasSeasonalPattern = true
 			}
 		}
@@ -278,19 +483,23 @@ func (m *BaselineModel) Predict(ctx context.Context, features FeatureFrame) (For
 			// If seasonal value is much higher than base, it might indicate an upcoming spike
 			ratio := seasonalValue / (basePrediction + 1.0) // +1 to avoid division by zero
 
+			var weight float64
 			if ratio > 1.5 {
 				// Strong seasonal spike expected - trust it heavily
-				finalValue = 0.2*basePrediction + 0.8*seasonalValue
+				weight = m.opts.SpikeWeight
 			} else if ratio > 1.2 {
 				// Moderate seasonal increase
-				finalValue = 0.3*basePrediction + 0.7*seasonalValue
+				weight = m.opts.RiseWeight
 			} else if ratio < 0.8 {
 				// Seasonal dip expected
-				finalValue = 0.4*basePrediction + 0.6*seasonalValue
+				weight = m.opts.DipWeight
 			} else {
-				// Seasonal and trend agree - blend equally
-				finalValue = 0.5*basePrediction + 0.5*seasonalValue
+				// Seasonal and trend agree
+				weight = m.opts.NeutralWeight
 			}
+			finalValue = (1-weight)*basePrediction + weight*seasonalValue
+			components[ComponentSeasonal][i] = seasonalValue
+			components[ComponentSeasonalWeight][i] = weight
 		} else {
 			// No seasonal pattern - rely on trend + momentum
 			finalValue = basePrediction
@@ -304,12 +513,31 @@ func (m *BaselineModel) Predict(ctx context.Context, features FeatureFrame) (For
 		forecastValues[i] = finalValue
 	}
 
-	return Forecast{
-		Metric:  m.metric,
-		Values:  forecastValues,
-		StepSec: m.stepSec,
-		Horizon: m.horizon,
-	}, nil
+	return forecastValues, components, nil
+}
+
+// patternValue returns the seasonal value of a bucket: its mean, blended
+// towards its max while the series is accelerating upwards.
+func (m *BaselineModel) patternValue(pattern *seasonalPattern, rising bool) float64 {
+	if rising && pattern.max > pattern.mean {
+		return (1-m.opts.MaxBlend)*pattern.mean + m.opts.MaxBlend*pattern.max
+	}
+	return pattern.mean
+}
+
+// minuteLevel returns the average mean of the usable minute-of-hour buckets.
+func (m *BaselineModel) minuteLevel() (float64, bool) {
+	sum, n := 0.0, 0
+	for _, pattern := range m.minuteSeasonality {
+		if pattern != nil && pattern.count >= 2 {
+			sum += pattern.mean
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return sum / float64(n), true
 }
 
 // detectTrend computes the slope (rate of change per second) from recent values.
@@ -2,7 +2,9 @@ package models
 
 import (
 	"context"
+	"math"
 	"testing"
+	"time"
 )
 
 func TestBaselineModel_Name(t *testing.T) {
@@ -254,6 +256,48 @@ func TestBaselineModel_Train_Seasonality(t *testing.T) {
 	}
 }
 
+func TestBaselineModel_Train_ForgetsOldRegime(t *testing.T) {
+	// Quarter-hour rows at ~100 for two days, then at ~500 for hours 0-5 of
+	// the third day.
+	var old, window FeatureFrame
+	for i := range 2*96 + 24 {
+		row := map[string]float64{"value": float64(100 + i%3), "hour": float64(i / 4 % 24)}
+		if i >= 2*96 {
+			row["value"] += 400
+		} else {
+			old.Rows = append(old.Rows, row)
+		}
+		window.Rows = append(window.Rows, row)
+	}
+
+	ctx := context.Background()
+	model := NewBaselineModel("http_rps", 900, 3600)
+	trainer := Trainer{Step: 15 * time.Minute, ChangePoints: &ChangePointOptions{MinSegment: 20}}
+	if _, err := trainer.Train(ctx, model, old, time.Unix(0, 0)); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	result, err := trainer.Train(ctx, model, window, time.Unix(900, 0))
+	if err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	if result.ChangePoint == nil || result.RegimeErr != nil {
+		t.Fatalf("ChangePoint = %+v, RegimeErr = %v, want training since the level shift", result.ChangePoint, result.RegimeErr)
+	}
+
+	if pattern := model.hourSeasonality[6]; pattern != nil {
+		t.Errorf("hourSeasonality[6] = %+v, want no pattern for an hour only seen before the shift", pattern)
+	}
+	forecast, err := model.Predict(ctx, window)
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	for i, v := range forecast.Values {
+		if v < 450 {
+			t.Errorf("Values[%d] = %.2f, want ~500 without the hours before the shift", i, v)
+		}
+	}
+}
+
 func TestBaselineModel_Train_EmptyHistory(t *testing.T) {
 	model := NewBaselineModel("http_rps", 60, 1800)
 
@@ -383,3 +427,148 @@ func makeFeatureFrame(values []float64) FeatureFrame {
 	}
 	return FeatureFrame{Rows: rows}
 }
+
+// weeklyHistory returns two weeks of hourly rows at 100, except Monday 9:00
+// at 500 and Sunday 9:00 at 50.
+func weeklyHistory() FeatureFrame {
+	var history FeatureFrame
+	for i := range 2 * 168 {
+		day, hour := (i/24)%7, i%24
+		value := 100.0
+		switch {
+		case day == 1 && hour == 9:
+			value = 500
+		case day == 0 && hour == 9:
+			value = 50
+		}
+		history.Rows = append(history.Rows, map[string]float64{
+			"value": value,
+			"hour":  float64(hour),
+			"day":   float64(day),
+		})
+	}
+	return history
+}
+
+func TestBaselineModel_Predict_HourOfWeek(t *testing.T) {
+	tests := []struct {
+		name string
+		opts BaselineOptions
+		day  int
+		want float64
+	}{
+		// Hour-of-week used as-is: dip on Sunday, spike on Monday.
+		{name: "sunday without backoff", opts: BaselineOptions{WeekBackoff: -1}, day: 0, want: 0.4*100 + 0.6*50},
+		{name: "monday without backoff", opts: BaselineOptions{WeekBackoff: -1}, day: 1, want: 0.2*100 + 0.8*500},
+		// Two samples against a backoff of 2: half hour-of-week (50), half
+		// hour-of-day ((2*500+2*50+10*100)/14 = 150).
+		{name: "sunday with backoff", opts: BaselineOptions{WeekBackoff: 2}, day: 0, want: 0.5*100 + 0.5*100},
+		// Custom blending weight: trust the seasonal value fully.
+		{name: "monday custom weight", opts: BaselineOptions{WeekBackoff: -1, SpikeWeight: 1}, day: 1, want: 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := NewBaselineModelWithOptions("m", 3600, 3600, tt.opts)
+			if err := model.Train(context.Background(), weeklyHistory()); err != nil {
+				t.Fatalf("Train() error = %v", err)
+			}
+
+			features := FeatureFrame{Rows: []map[string]float64{
+				{"value": 100, "hour": 8, "day": float64(tt.day)},
+				{"value": 100, "hour": 8, "day": float64(tt.day)},
+			}}
+			forecast, err := model.Predict(context.Background(), features)
+			if err != nil {
+				t.Fatalf("Predict() error = %v", err)
+			}
+			if got := forecast.Values[0]; math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Values[0] = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBaselineModel_Predict_MinuteFirstWithoutWeek(t *testing.T) {
+	// Two days of 9:00-10:59 at 1m steps, without "day": hour 9 at 100 and
+	// hour 10 at 300, so every minute-of-hour bucket averages 200.
+	var history FeatureFrame
+	for range 2 {
+		for hour := 9; hour <= 10; hour++ {
+			for minute := range 60 {
+				history.Rows = append(history.Rows, map[string]float64{
+					"value":  float64(100 + 200*(hour-9)),
+					"minute": float64(minute),
+					"hour":   float64(hour),
+				})
+			}
+		}
+	}
+
+	model := NewBaselineModel("m", 60, 60)
+	if err := model.Train(context.Background(), history); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+
+	features := FeatureFrame{Rows: []map[string]float64{
+		{"value": 300, "minute": 0, "hour": 10},
+		{"value": 300, "minute": 0, "hour": 10},
+	}}
+	forecast, err := model.Predict(context.Background(), features)
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+
+	// Without hour-of-week buckets the minute-of-hour bucket is used on its
+	// own; the hour of day is only a fallback.
+	if got := forecast.Components[ComponentSeasonal][0]; got != 200 {
+		t.Errorf("seasonal = %v, want the minute-of-hour mean 200", got)
+	}
+}
+
+func TestBaselineModel_Predict_Components(t *testing.T) {
+	model := NewBaselineModelWithOptions("m", 3600, 3*3600, BaselineOptions{WeekBackoff: -1})
+	if err := model.Train(context.Background(), weeklyHistory()); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+
+	features := FeatureFrame{Rows: []map[string]float64{
+		{"value": 100, "hour": 7, "day": 1},
+		{"value": 100, "hour": 8, "day": 1},
+	}}
+	forecast, err := model.Predict(context.Background(), features)
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+
+	c := forecast.Components
+	for i, v := range forecast.Values {
+		base := c[ComponentLevel][i] + c[ComponentTrend][i] + c[ComponentMomentum][i]
+		w := c[ComponentSeasonalWeight][i]
+		if got := max((1-w)*base+w*c[ComponentSeasonal][i], 0); math.Abs(got-v) > 1e-9 {
+			t.Errorf("components at step %d give %v, forecast is %v", i, got, v)
+		}
+	}
+	if c[ComponentLevel][0] != 100 {
+		t.Errorf("level = %v, want the last value 100", c[ComponentLevel][0])
+	}
+	// Monday 9:00 is the spike hour of the week.
+	if c[ComponentSeasonal][0] != 500 || c[ComponentSeasonalWeight][0] != 0.8 {
+		t.Errorf("step 0 seasonal = %v with weight %v, want 500 with 0.8", c[ComponentSeasonal][0], c[ComponentSeasonalWeight][0])
+	}
+}
+
+func TestBaselineOptions_WithDefaults(t *testing.T) {
+	got := BaselineOptions{MaxBlend: -1, SpikeWeight: 2, DipWeight: 0.9}.withDefaults()
+	want := BaselineOptions{
+		WeekBackoff:   60,
+		MaxBlend:      0,
+		SpikeWeight:   1,
+		RiseWeight:    0.7,
+		DipWeight:     0.9,
+		NeutralWeight: 0.5,
+	}
+	if got != want {
+		t.Errorf("withDefaults() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,232 @@
+package models
+
+import (
+	"math"
+	"slices"
+)
+
+// ChangePointKind classifies a detected change point.
+type ChangePointKind string
+
+const (
+	// ChangePointLevel is a shift of the mean.
+	ChangePointLevel ChangePointKind = "level"
+
+	// ChangePointVariance is a change of the spread without a comparable
+	// shift of the mean.
+	ChangePointVariance ChangePointKind = "variance"
+)
+
+// ChangePoint is the start of a new regime in a series.
+type ChangePoint struct {
+	// Index is the first row of the new regime.
+	Index int
+
+	// Timestamp is the "timestamp" feature of that row (0 when absent).
+	Timestamp float64
+
+	// Kind is level when the mean shifted by more than one pooled standard
+	// deviation, variance otherwise.
+	Kind ChangePointKind
+}
+
+// ChangePointOptions configures DetectChangePoints. Zero values select the
+// defaults documented on each field.
+type ChangePointOptions struct {
+	// MinSegment is the minimum number of rows between change points and at
+	// either end of the series (default 30).
+	MinSegment int
+
+	// Penalty scales the cost of adding a change point, in units of log(n)
+	// for a series of n rows (default 5). Higher values detect fewer,
+	// larger shifts. It is further multiplied by (1+ρ)/(1-ρ) for a lag-1
+	// autocorrelation ρ, as autocorrelated noise otherwise looks like a
+	// sequence of small shifts.
+	Penalty float64
+}
+
+// DetectChangePoints finds shifts in the mean or variance of the "value"
+// feature with PELT (Pruned Exact Linear Time) under a Gaussian likelihood:
+// the segmentation minimising Σ nᵢ·log σᵢ² + penalty·log(n) per change point.
+//
+// When the rows carry "hour" and "timestamp" and span at least two days,
+// each hour-of-day's mean is subtracted first, so that daily cycles are not
+// mistaken for regime shifts.
+//
+// Change points are returned in order. Rows without a "value" are ignored
+// and indices refer to the rows that have one.
+func DetectChangePoints(frame FeatureFrame, opts ChangePointOptions) []ChangePoint {
+	if opts.MinSegment <= 0 {
+		opts.MinSegment = 30
+	}
+	if opts.Penalty <= 0 {
+		opts.Penalty = 5
+	}
+
+	var rows []map[string]float64
+	for _, row := range frame.Rows {
+		if _, ok := row["value"]; ok {
+			rows = append(rows, row)
+		}
+	}
+	n := len(rows)
+	if n < 2*opts.MinSegment {
+		return nil
+	}
+
+	values := deseasonalise(rows)
+	rho := blockAutocorrelation(values, opts.MinSegment)
+	penalty := opts.Penalty * math.Log(float64(n)) * (1 + rho) / (1 - rho)
+	breaks := pelt(values, opts.MinSegment, penalty)
+
+	points := make([]ChangePoint, len(breaks))
+	for i, b := range breaks {
+		start := 0
+		if i > 0 {
+			start = breaks[i-1]
+		}
+		end := n
+		if i+1 < len(breaks) {
+			end = breaks[i+1]
+		}
+		points[i] = ChangePoint{
+			Index:     b,
+			Timestamp: rows[b]["timestamp"],
+			Kind:      classifyChange(values[start:b], values[b:end]),
+		}
+	}
+	return points
+}
+
+// deseasonalise returns the values of rows, minus the mean of their
+// hour-of-day when the rows span at least two days.
+func deseasonalise(rows []map[string]float64) []float64 {
+	values := make([]float64, len(rows))
+	for i, row := range rows {
+		values[i] = row["value"]
+	}
+
+	first, hasFirst := rows[0]["timestamp"]
+	last, hasLast := rows[len(rows)-1]["timestamp"]
+	if !hasFirst || !hasLast || last-first < 2*secondsPerDay {
+		return values
+	}
+
+	var sum, count [24]float64
+	for i, row := range rows {
+		hour, ok := row["hour"]
+		if !ok || hour < 0 || hour >= 24 {
+			return values
+		}
+		sum[int(hour)] += values[i]
+		count[int(hour)]++
+	}
+	for i, row := range rows {
+		h := int(row["hour"])
+		values[i] -= sum[h] / count[h]
+	}
+	return values
+}
+
+// maxChangePointAutocorrelation caps the autocorrelation correction of the
+// penalty at a factor of 19.
+const maxChangePointAutocorrelation = 0.9
+
+// blockAutocorrelation returns the median lag-1 autocorrelation of
+// consecutive blocks of values, clamped to [0, 0.9]. Each block is centred on
+// its own mean, so a level shift affects at most one block.
+func blockAutocorrelation(values []float64, block int) float64 {
+	var estimates []float64
+	for start := 0; start+block <= len(values); start += block {
+		b := values[start : start+block]
+		mean := computeMean(b)
+		var num, den float64
+		for i, v := range b {
+			den += (v - mean) * (v - mean)
+			if i > 0 {
+				num += (v - mean) * (b[i-1] - mean)
+			}
+		}
+		if den > 0 {
+			estimates = append(estimates, num/den)
+		}
+	}
+	if len(estimates) == 0 {
+		return 0
+	}
+	slices.Sort(estimates)
+	return min(max(estimates[len(estimates)/2], 0), maxChangePointAutocorrelation)
+}
+
+// pelt returns the optimal change points of values under the Gaussian
+// mean-and-variance cost with the given per-change penalty.
+func pelt(values []float64, minSegment int, penalty float64) []int {
+	n := len(values)
+	sum := make([]float64, n+1)
+	sumSq := make([]float64, n+1)
+	for i, v := range values {
+		sum[i+1] = sum[i] + v
+		sumSq[i+1] = sumSq[i] + v*v
+	}
+
+	// Floor the variance relative to the whole series so that constant
+	// segments do not have a cost of -Inf.
+	total := sumSq[n]/float64(n) - (sum[n]/float64(n))*(sum[n]/float64(n))
+	floor := math.Max(total*1e-6, 1e-12)
+	cost := func(s, t int) float64 {
+		m := float64(t - s)
+		mean := (sum[t] - sum[s]) / m
+		variance := (sumSq[t]-sumSq[s])/m - mean*mean
+		return m * math.Log(math.Max(variance, floor))
+	}
+
+	best := make([]float64, n+1)
+	prev := make([]int, n+1)
+	best[0] = -penalty
+	candidates := []int{0}
+
+	for t := minSegment; t <= n; t++ {
+		if s := t - minSegment; s >= minSegment {
+			candidates = append(candidates, s)
+		}
+
+		best[t] = math.Inf(1)
+		costs := make([]float64, len(candidates))
+		for i, s := range candidates {
+			costs[i] = best[s] + cost(s, t)
+			if c := costs[i] + penalty; c < best[t] {
+				best[t] = c
+				prev[t] = s
+			}
+		}
+
+		// Prune candidates that can no longer be optimal.
+		kept := candidates[:0]
+		for i, s := range candidates {
+			if costs[i] <= best[t] {
+				kept = append(kept, s)
+			}
+		}
+		candidates = kept
+	}
+
+	var breaks []int
+	for t := prev[n]; t > 0; t = prev[t] {
+		breaks = append(breaks, t)
+	}
+	for i, j := 0, len(breaks)-1; i < j; i, j = i+1, j-1 {
+		breaks[i], breaks[j] = breaks[j], breaks[i]
+	}
+	return breaks
+}
+
+// classifyChange reports whether the mean shifted by more than one pooled
+// standard deviation between two segments.
+func classifyChange(before, after []float64) ChangePointKind {
+	pooled := (computeVariance(before)*float64(len(before)) +
+		computeVariance(after)*float64(len(after))) / float64(len(before)+len(after))
+	if math.Abs(computeMean(after)-computeMean(before)) > math.Sqrt(pooled) {
+		return ChangePointLevel
+	}
+	return ChangePointVariance
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// noisyFrame returns n one-minute rows of AR(1) noise with coefficient phi
+// and unit marginal deviation, scaled by sd(i) and offset by level(i).
+func noisyFrame(n int, phi float64, level, sd func(i int) float64) FeatureFrame {
+	rng := rand.New(rand.NewSource(1))
+	rows := make([]map[string]float64, n)
+	e := 0.0
+	scale := math.Sqrt(1 - phi*phi)
+	for i := range n {
+		e = phi*e + scale*rng.NormFloat64()
+		rows[i] = map[string]float64{
+			"timestamp": float64(i * 60),
+			"value":     level(i) + sd(i)*e,
+		}
+	}
+	return FeatureFrame{Rows: rows}
+}
+
+func constant(v float64) func(int) float64 {
+	return func(int) float64 { return v }
+}
+
+func stepAt(at int, before, after float64) func(int) float64 {
+	return func(i int) float64 {
+		if i >= at {
+			return after
+		}
+		return before
+	}
+}
+
+func TestDetectChangePoints(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame FeatureFrame
+		want  []ChangePoint // Index within ±10 rows
+	}{
+		{
+			name:  "stationary noise",
+			frame: noisyFrame(720, 0, constant(100), constant(5)),
+		},
+		{
+			name:  "autocorrelated noise",
+			frame: noisyFrame(720, 0.8, constant(100), constant(5)),
+		},
+		{
+			name:  "level shift",
+			frame: noisyFrame(720, 0.5, stepAt(500, 100, 115), constant(5)),
+			want:  []ChangePoint{{Index: 500, Timestamp: 500 * 60, Kind: ChangePointLevel}},
+		},
+		{
+			name:  "variance shift",
+			frame: noisyFrame(600, 0, constant(100), stepAt(400, 2, 8)),
+			want:  []ChangePoint{{Index: 400, Timestamp: 400 * 60, Kind: ChangePointVariance}},
+		},
+		{
+			name:  "too short",
+			frame: noisyFrame(50, 0, stepAt(25, 0, 100), constant(1)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectChangePoints(tt.frame, ChangePointOptions{})
+			if len(got) != len(tt.want) {
+				t.Fatalf("DetectChangePoints() = %+v, want %d change points", got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if math.Abs(float64(got[i].Index-want.Index)) > 10 {
+					t.Errorf("Index = %d, want %d ± 10", got[i].Index, want.Index)
+				}
+				if got[i].Timestamp != tt.frame.Rows[got[i].Index]["timestamp"] {
+					t.Errorf("Timestamp = %v, want timestamp of row %d", got[i].Timestamp, got[i].Index)
+				}
+				if got[i].Kind != want.Kind {
+					t.Errorf("Kind = %q, want %q", got[i].Kind, want.Kind)
+				}
+			}
+		})
+	}
+}
+
+func TestDetectChangePoints_IgnoresDailyCycle(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	rows := make([]map[string]float64, 3*288)
+	for i := range rows {
+		rows[i] = map[string]float64{
+			"timestamp": float64(i * 300),
+			"hour":      float64(i / 12 % 24),
+			"value":     100 + 50*math.Sin(2*math.Pi*float64(i)/288) + 5*rng.NormFloat64(),
+		}
+	}
+	if got := DetectChangePoints(FeatureFrame{Rows: rows}, ChangePointOptions{}); len(got) != 0 {
+		t.Errorf("DetectChangePoints() = %+v, want none for a daily cycle", got)
+	}
+
+	for _, row := range rows[2*288:] {
+		row["value"] += 30
+	}
+	got := DetectChangePoints(FeatureFrame{Rows: rows}, ChangePointOptions{})
+	if len(got) != 1 || math.Abs(float64(got[0].Index-2*288)) > 10 || got[0].Kind != ChangePointLevel {
+		t.Errorf("DetectChangePoints() = %+v, want a level shift at row %d", got, 2*288)
+	}
+}
+
+func TestDetectChangePoints_MinSegment(t *testing.T) {
+	frame := noisyFrame(300, 0, stepAt(280, 100, 200), constant(1))
+
+	// The shift is 20 rows before the end, so the last allowed position is
+	// the best fit.
+	if got := DetectChangePoints(frame, ChangePointOptions{MinSegment: 30}); len(got) != 1 || got[0].Index != 270 {
+		t.Errorf("MinSegment 30: got %+v, want a change point at row 270", got)
+	}
+	if got := DetectChangePoints(frame, ChangePointOptions{MinSegment: 10}); len(got) != 1 || got[0].Index != 280 {
+		t.Errorf("MinSegment 10: got %+v, want a change point at row 280", got)
+	}
+}
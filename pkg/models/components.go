@@ -0,0 +1,45 @@
+package models
+
+// Keys of Forecast.Components shared across models. Models document which
+// keys they report and how they combine into Values; keys of additive
+// components are in the metric's units, weights are between 0 and 1.
+const (
+	// ComponentLevel is the level the forecast starts from: the last
+	// observed or filtered value.
+	ComponentLevel = "level"
+
+	// ComponentTrend is the trend at each step: its change from
+	// ComponentLevel when the model reports a level, else the trend itself.
+	ComponentTrend = "trend"
+
+	// ComponentMomentum is the change due to acceleration of the trend.
+	ComponentMomentum = "momentum"
+
+	// ComponentSeasonal is the seasonal value or effect at each step.
+	ComponentSeasonal = "seasonal"
+
+	// ComponentSeasonalWeight is the weight given to ComponentSeasonal when
+	// it is blended with the trend forecast.
+	ComponentSeasonalWeight = "seasonal_weight"
+
+	// ComponentRegressors is the combined effect of regressors and events.
+	ComponentRegressors = "regressors"
+)
+
+// EventComponent returns the Forecast.Components key of the effect of the
+// named event, e.g. "event:black-friday".
+func EventComponent(name string) string {
+	return "event:" + name
+}
+
+// MemberComponent returns the Forecast.Components key of the forecast of the
+// named member of a combined model, e.g. "member:baseline".
+func MemberComponent(name string) string {
+	return "member:" + name
+}
+
+// WeightComponent returns the Forecast.Components key of the weight of the
+// named member of a combined model, e.g. "weight:baseline".
+func WeightComponent(name string) string {
+	return "weight:" + name
+}
@@ -0,0 +1,499 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// EnsembleMethod selects how an EnsembleModel weights its members.
+type EnsembleMethod string
+
+const (
+	// EnsembleInverseError weights members by 1/MAE over the recent window.
+	EnsembleInverseError EnsembleMethod = "inverse-error"
+
+	// EnsembleStacking fits non-negative weights summing to one that best
+	// reproduce the realised values from the members' forecasts.
+	EnsembleStacking EnsembleMethod = "stacking"
+)
+
+// EnsembleOptions configures an EnsembleModel.
+type EnsembleOptions struct {
+	// Method is the weighting method. Defaults to EnsembleInverseError.
+	Method EnsembleMethod
+
+	// Window is the number of most recent (forecast, realised value) pairs
+	// used to score members. Defaults to 500.
+	Window int
+}
+
+// EnsembleModel combines the forecasts of several member models with weights
+// learned from each member's recent accuracy.
+//
+// Every Predict call records the members' forecasts together with the
+// timestamps they refer to. When a later Train call sees realised values for
+// those timestamps, each member's error is added to a rolling window and the
+// weights are recomputed. Until errors are available, members are weighted
+// equally. Forecasts can only be scored when features carry a "timestamp".
+//
+// Members are trained and predicted independently; a member that fails is left
+// out of the combination and the remaining weights are renormalised.
+type EnsembleModel struct {
+	metric  string
+	stepSec int
+	horizon int
+	members []Model
+	opts    EnsembleOptions
+
+	mu      sync.Mutex
+	pending []ensembleForecast
+	obs     []ensembleObservation
+	weights []float64
+}
+
+// ensembleForecast is a recorded member forecast awaiting realised values.
+type ensembleForecast struct {
+	origin int64       // step index of the last observed point
+	preds  [][]float64 // per member, per step; nil for members that failed
+	next   int         // first step not yet scored
+}
+
+// ensembleObservation pairs a realised value with each member's forecast of it.
+type ensembleObservation struct {
+	actual float64
+	preds  []float64 // NaN for members that did not forecast this point
+}
+
+// maxEnsemblePending bounds the number of forecasts awaiting realised values.
+const maxEnsemblePending = 256
+
+// NewEnsembleModel creates an ensemble over the given member models.
+//
+// Panics if members is empty.
+func NewEnsembleModel(metric string, stepSec, horizon int, members []Model, opts EnsembleOptions) *EnsembleModel {
+	if len(members) == 0 {
+		panic("ensemble needs at least one member")
+	}
+	if opts.Method == "" {
+		opts.Method = EnsembleInverseError
+	}
+	if opts.Window <= 0 {
+		opts.Window = 500
+	}
+
+	weights := make([]float64, len(members))
+	for i := range weights {
+		weights[i] = 1 / float64(len(members))
+	}
+
+	return &EnsembleModel{
+		metric:  metric,
+		stepSec: stepSec,
+		horizon: horizon,
+		members: members,
+		opts:    opts,
+		weights: weights,
+	}
+}
+
+// Name returns the model identifier, listing the members,
+// e.g. "ensemble(baseline,arima(1,1,1))".
+func (m *EnsembleModel) Name() string {
+	names := make([]string, len(m.members))
+	for i, member := range m.members {
+		names[i] = member.Name()
+	}
+	return "ensemble(" + strings.Join(names, ",") + ")"
+}
+
+// Weights returns the current weight of each member keyed by member name.
+func (m *EnsembleModel) Weights() map[string]float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make(map[string]float64, len(m.members))
+	for i, member := range m.members {
+		out[member.Name()] += m.weights[i]
+	}
+	return out
+}
+
+// Train scores previously recorded forecasts against the realised values in
+// history, updates the member weights and trains every member.
+//
+// Returns an error only if every member fails to train.
+func (m *EnsembleModel) Train(ctx context.Context, history FeatureFrame) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	m.score(history)
+
+	var errs []error
+	for _, member := range m.members {
+		if err := member.Train(ctx, history); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", member.Name(), err))
+		}
+	}
+	if len(errs) == len(m.members) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// Predict returns the weighted combination of the members' forecasts.
+// Quantiles are combined the same way when every contributing member
+// provides them. Components holds each member's forecast and weight, keyed by
+// MemberComponent and WeightComponent.
+//
+// Returns an error if every member fails to predict.
+func (m *EnsembleModel) Predict(ctx context.Context, features FeatureFrame) (Forecast, error) {
+	if ctx.Err() != nil {
+		return Forecast{}, ctx.Err()
+	}
+
+	nSteps := max(m.horizon/m.stepSec, 1)
+	forecasts := make([]*Forecast, len(m.members))
+	var errs []error
+	for i, member := range m.members {
+		f, err := member.Predict(ctx, features)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", member.Name(), err))
+			continue
+		}
+		if len(f.Values) < nSteps {
+			errs = append(errs, fmt.Errorf("%s: returned %d values, want %d", member.Name(), len(f.Values), nSteps))
+			continue
+		}
+		forecasts[i] = &f
+	}
+	if len(errs) == len(m.members) {
+		return Forecast{}, errors.Join(errs...)
+	}
+
+	m.mu.Lock()
+	weights := make([]float64, len(m.weights))
+	copy(weights, m.weights)
+	m.record(features, forecasts, nSteps)
+	m.mu.Unlock()
+
+	total := 0.0
+	for i, f := range forecasts {
+		if f == nil {
+			weights[i] = 0
+		}
+		total += weights[i]
+	}
+	if total <= 0 {
+		// Every weighted member failed: fall back to equal weights.
+		for i, f := range forecasts {
+			if f != nil {
+				weights[i] = 1
+				total++
+			}
+		}
+	}
+	for i := range weights {
+		weights[i] /= total
+	}
+
+	values := combineSeries(forecasts, weights, nSteps, func(f *Forecast) []float64 { return f.Values })
+
+	var quantiles map[string][]float64
+	for _, q := range QuantileLevels {
+		key := QuantileKey(q)
+		series := combineSeries(forecasts, weights, nSteps, func(f *Forecast) []float64 {
+			if s, ok := f.Quantile(q); ok {
+				return s
+			}
+			return nil
+		})
+		if series == nil {
+			quantiles = nil
+			break
+		}
+		if quantiles == nil {
+			quantiles = make(map[string][]float64, len(QuantileLevels))
+		}
+		quantiles[key] = series
+	}
+
+	components := make(map[string][]float64, 2*len(m.members))
+	for i, f := range forecasts {
+		if f == nil {
+			continue
+		}
+		name := m.members[i].Name()
+		components[MemberComponent(name)] = f.Values[:nSteps]
+		components[WeightComponent(name)] = slices.Repeat([]float64{weights[i]}, nSteps)
+	}
+
+	return Forecast{
+		Metric:     m.metric,
+		Values:     values,
+		StepSec:    m.stepSec,
+		Horizon:    m.horizon,
+		Quantiles:  quantiles,
+		Components: components,
+	}, nil
+}
+
+// combineSeries returns the weighted sum of the series selected from each forecast
+// with a positive weight, or nil if any of them is missing.
+func combineSeries(forecasts []*Forecast, weights []float64, nSteps int, series func(*Forecast) []float64) []float64 {
+	out := make([]float64, nSteps)
+	for i, f := range forecasts {
+		if f == nil || weights[i] == 0 {
+			continue
+		}
+		s := series(f)
+		if len(s) < nSteps {
+			return nil
+		}
+		for h := range out {
+			out[h] += weights[i] * s[h]
+		}
+	}
+	return out
+}
+
+// record stores the members' forecasts so that they can be scored once the
+// realised values arrive. Callers must hold m.mu.
+func (m *EnsembleModel) record(features FeatureFrame, forecasts []*Forecast, nSteps int) {
+	origin, ok := lastStepIndex(features, m.stepSec)
+	if !ok {
+		return
+	}
+
+	preds := make([][]float64, len(forecasts))
+	for i, f := range forecasts {
+		if f != nil {
+			preds[i] = f.Values[:nSteps]
+		}
+	}
+
+	m.pending = append(m.pending, ensembleForecast{origin: origin, preds: preds})
+	if len(m.pending) > maxEnsemblePending {
+		m.pending = m.pending[len(m.pending)-maxEnsemblePending:]
+	}
+}
+
+// score matches recorded forecasts with realised values in history and
+// recomputes the weights.
+func (m *EnsembleModel) score(history FeatureFrame) {
+	actuals := make(map[int64]float64, len(history.Rows))
+	var latest int64
+	found := false
+	for _, row := range history.Rows {
+		ts, hasTs := row["timestamp"]
+		v, hasValue := row["value"]
+		if !hasTs || !hasValue {
+			continue
+		}
+		idx := stepIndex(ts, m.stepSec)
+		actuals[idx] = v
+		if !found || idx > latest {
+			latest = idx
+			found = true
+		}
+	}
+	if !found {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	remaining := m.pending[:0]
+	for _, p := range m.pending {
+		nSteps := 0
+		for _, preds := range p.preds {
+			nSteps = max(nSteps, len(preds))
+		}
+		for ; p.next < nSteps && p.origin+int64(p.next+1) <= latest; p.next++ {
+			actual, ok := actuals[p.origin+int64(p.next+1)]
+			if !ok {
+				continue
+			}
+			obs := ensembleObservation{actual: actual, preds: make([]float64, len(p.preds))}
+			for i, preds := range p.preds {
+				obs.preds[i] = math.NaN()
+				if p.next < len(preds) {
+					obs.preds[i] = preds[p.next]
+				}
+			}
+			m.obs = append(m.obs, obs)
+		}
+		if p.next < nSteps {
+			remaining = append(remaining, p)
+		}
+	}
+	m.pending = remaining
+
+	if len(m.obs) > m.opts.Window {
+		m.obs = m.obs[len(m.obs)-m.opts.Window:]
+	}
+
+	m.weights = m.computeWeights()
+}
+
+// computeWeights derives member weights from the observation window.
+// Callers must hold m.mu.
+func (m *EnsembleModel) computeWeights() []float64 {
+	if m.opts.Method == EnsembleStacking {
+		if w, ok := stackingWeights(m.obs, len(m.members)); ok {
+			return w
+		}
+	}
+	return inverseErrorWeights(m.obs, len(m.members))
+}
+
+// inverseErrorWeights weights members by the reciprocal of their mean absolute
+// error. Members without any scored forecast get the average weight of the
+// others, so a newly working member is not starved; with no scores at all the
+// weights are equal.
+func inverseErrorWeights(obs []ensembleObservation, k int) []float64 {
+	sums := make([]float64, k)
+	counts := make([]int, k)
+	scale := 0.0
+	for _, o := range obs {
+		scale += math.Abs(o.actual)
+		for i, p := range o.preds {
+			if !math.IsNaN(p) {
+				sums[i] += math.Abs(o.actual - p)
+				counts[i]++
+			}
+		}
+	}
+	// eps keeps a perfect member from taking infinite weight.
+	eps := 1e-6 * (scale/float64(max(len(obs), 1)) + 1)
+
+	weights := make([]float64, k)
+	total, scored := 0.0, 0
+	for i := range weights {
+		if counts[i] == 0 {
+			continue
+		}
+		weights[i] = 1 / (sums[i]/float64(counts[i]) + eps)
+		total += weights[i]
+		scored++
+	}
+	for i := range weights {
+		if counts[i] == 0 {
+			if scored == 0 {
+				weights[i] = 1
+			} else {
+				weights[i] = total / float64(scored)
+			}
+		}
+	}
+	return normalizeWeights(weights)
+}
+
+// stackingWeights finds non-negative weights summing to one that minimise the
+// squared error of the combined forecast over observations where every member
+// forecast, using projected gradient descent on the probability simplex.
+// Returns false when there are too few such observations.
+func stackingWeights(obs []ensembleObservation, k int) ([]float64, bool) {
+	var X [][]float64
+	var y []float64
+	for _, o := range obs {
+		if !slices.ContainsFunc(o.preds, math.IsNaN) {
+			X = append(X, o.preds)
+			y = append(y, o.actual)
+		}
+	}
+	if len(y) < max(20, 5*k) {
+		return nil, false
+	}
+
+	// Step size 1/L, with the Lipschitz constant L bounded by the trace of
+	// the Gram matrix.
+	trace := 0.0
+	for _, row := range X {
+		for _, x := range row {
+			trace += x * x
+		}
+	}
+	if trace == 0 {
+		return nil, false
+	}
+	step := float64(len(y)) / (2 * trace)
+
+	w := make([]float64, k)
+	for i := range w {
+		w[i] = 1 / float64(k)
+	}
+	grad := make([]float64, k)
+	for range 1000 {
+		clear(grad)
+		for r, row := range X {
+			resid := -y[r]
+			for i, x := range row {
+				resid += w[i] * x
+			}
+			for i, x := range row {
+				grad[i] += 2 * resid * x / float64(len(y))
+			}
+		}
+		for i := range w {
+			w[i] -= step * grad[i]
+		}
+		w = projectSimplex(w)
+	}
+	return w, true
+}
+
+// projectSimplex returns the Euclidean projection of v onto
+// {w : w >= 0, Σw = 1}.
+func projectSimplex(v []float64) []float64 {
+	sorted := slices.Clone(v)
+	slices.Sort(sorted)
+	slices.Reverse(sorted)
+
+	cumsum, theta := 0.0, 0.0
+	for i, u := range sorted {
+		cumsum += u
+		if t := (cumsum - 1) / float64(i+1); u-t > 0 {
+			theta = t
+		}
+	}
+
+	out := make([]float64, len(v))
+	for i, x := range v {
+		out[i] = math.Max(x-theta, 0)
+	}
+	return out
+}
+
+// normalizeWeights scales weights to sum to one.
+func normalizeWeights(weights []float64) []float64 {
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	for i := range weights {
+		weights[i] /= total
+	}
+	return weights
+}
+
+// stepIndex converts a Unix timestamp to a step index.
+func stepIndex(ts float64, stepSec int) int64 {
+	return int64(math.Round(ts / float64(stepSec)))
+}
+
+// lastStepIndex returns the step index of the last row with a timestamp.
+func lastStepIndex(frame FeatureFrame, stepSec int) (int64, bool) {
+	for i := len(frame.Rows) - 1; i >= 0; i-- {
+		if ts, ok := frame.Rows[i]["timestamp"]; ok {
+			return stepIndex(ts, stepSec), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+// constantModel forecasts a fixed value and can be made to fail.
+type constantModel struct {
+	name  string
+	value float64
+	fail  bool
+}
+
+func (c *constantModel) Name() string { return c.name }
+
+func (c *constantModel) Train(ctx context.Context, history FeatureFrame) error {
+	if c.fail {
+		return errors.New("train failed")
+	}
+	return nil
+}
+
+func (c *constantModel) Predict(ctx context.Context, features FeatureFrame) (Forecast, error) {
+	if c.fail {
+		return Forecast{}, errors.New("predict failed")
+	}
+	return Forecast{Values: []float64{c.value, c.value, c.value}, StepSec: 60, Horizon: 180}, nil
+}
+
+// timedFrame builds rows at 60s spacing starting at step index start.
+func timedFrame(start int, values ...float64) FeatureFrame {
+	rows := make([]map[string]float64, len(values))
+	for i, v := range values {
+		rows[i] = map[string]float64{"timestamp": float64((start + i) * 60), "value": v}
+	}
+	return FeatureFrame{Rows: rows}
+}
+
+func TestEnsembleModel_Name(t *testing.T) {
+	model := NewEnsembleModel("m", 60, 180, []Model{
+		&constantModel{name: "a"}, &constantModel{name: "b"},
+	}, EnsembleOptions{})
+
+	if got := model.Name(); got != "ensemble(a,b)" {
+		t.Errorf("Name() = %q, want %q", got, "ensemble(a,b)")
+	}
+}
+
+func TestEnsembleModel_Predict_EqualWeightsInitially(t *testing.T) {
+	model := NewEnsembleModel("m", 60, 180, []Model{
+		&constantModel{name: "a", value: 100}, &constantModel{name: "b", value: 200},
+	}, EnsembleOptions{})
+
+	forecast, err := model.Predict(context.Background(), timedFrame(0, 150))
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	for i, v := range forecast.Values {
+		if v != 150 {
+			t.Errorf("Values[%d] = %v, want 150", i, v)
+		}
+	}
+	for name, want := range map[string]float64{"a": 100, "b": 200} {
+		if got := forecast.Components[MemberComponent(name)]; len(got) != 3 || got[0] != want {
+			t.Errorf("member %s component = %v, want %v", name, got, want)
+		}
+		if got := forecast.Components[WeightComponent(name)]; len(got) != 3 || got[2] != 0.5 {
+			t.Errorf("weight %s component = %v, want 0.5", name, got)
+		}
+	}
+}
+
+func TestEnsembleModel_LearnsWeights(t *testing.T) {
+	tests := []struct {
+		name   string
+		method EnsembleMethod
+	}{
+		{name: "inverse error", method: EnsembleInverseError},
+		{name: "stacking", method: EnsembleStacking},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			good := &constantModel{name: "good", value: 100}
+			bad := &constantModel{name: "bad", value: 160}
+			model := NewEnsembleModel("m", 60, 180, []Model{good, bad}, EnsembleOptions{Method: tt.method})
+			ctx := context.Background()
+
+			// The realised value hovers around 100, so "good" should dominate.
+			for step := range 40 {
+				actual := 100 + float64(step%3) - 1
+				if err := model.Train(ctx, timedFrame(step, actual)); err != nil {
+					t.Fatalf("Train() error = %v", err)
+				}
+				if _, err := model.Predict(ctx, timedFrame(step, actual)); err != nil {
+					t.Fatalf("Predict() error = %v", err)
+				}
+			}
+
+			weights := model.Weights()
+			if weights["good"] <= 0.8 {
+				t.Errorf("weights = %v, want good > 0.8", weights)
+			}
+			if math.Abs(weights["good"]+weights["bad"]-1) > 1e-9 {
+				t.Errorf("weights = %v, want sum 1", weights)
+			}
+		})
+	}
+}
+
+func TestEnsembleModel_SkipsFailingMember(t *testing.T) {
+	model := NewEnsembleModel("m", 60, 180, []Model{
+		&constantModel{name: "ok", value: 100}, &constantModel{name: "broken", fail: true},
+	}, EnsembleOptions{})
+	ctx := context.Background()
+
+	if err := model.Train(ctx, timedFrame(0, 100)); err != nil {
+		t.Errorf("Train() error = %v, want nil when one member succeeds", err)
+	}
+	forecast, err := model.Predict(ctx, timedFrame(0, 100))
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	if forecast.Values[0] != 100 {
+		t.Errorf("Values[0] = %v, want 100 from the working member", forecast.Values[0])
+	}
+}
+
+func TestEnsembleModel_AllMembersFail(t *testing.T) {
+	model := NewEnsembleModel("m", 60, 180, []Model{&constantModel{name: "x", fail: true}}, EnsembleOptions{})
+
+	if err := model.Train(context.Background(), timedFrame(0, 1)); err == nil {
+		t.Error("Train() error = nil, want error")
+	}
+	if _, err := model.Predict(context.Background(), timedFrame(0, 1)); err == nil {
+		t.Error("Predict() error = nil, want error")
+	}
+}
+
+func TestInverseErrorWeights_UnscoredMember(t *testing.T) {
+	obs := []ensembleObservation{
+		{actual: 10, preds: []float64{11, math.NaN()}},
+		{actual: 10, preds: []float64{9, math.NaN()}},
+	}
+	w := inverseErrorWeights(obs, 2)
+	if math.Abs(w[0]-0.5) > 1e-9 || math.Abs(w[1]-0.5) > 1e-9 {
+		t.Errorf("weights = %v, want [0.5 0.5] when only one member is scored", w)
+	}
+}
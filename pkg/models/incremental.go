@@ -0,0 +1,210 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Update advances the fitted model over newly observed values without
+// re-estimating its coefficients. Each value is differenced against the
+// stored tail, and its one-step error is computed from the current AR and MA
+// terms; the recent values and errors the forecast starts from are shifted
+// accordingly. Coefficients, mean and innovation variance stay as fitted, so
+// Train should still be called periodically (and is required after orders or
+// the series' behaviour change). For an ARIMAX model the regression part β·x
+// of each row is removed first.
+//
+// Returns error if:
+//   - Context is cancelled
+//   - Model has not been trained, or was restored from state without history
+//   - A row is missing the 'value' field, or a regressor or 'timestamp' of
+//     an ARIMAX model
+func (m *ARIMAModel) Update(ctx context.Context, newRows FeatureFrame) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	values := make([]float64, len(newRows.Rows))
+	for i, row := range newRows.Rows {
+		val, ok := row["value"]
+		if !ok {
+			return fmt.Errorf("row %d missing 'value' field", i)
+		}
+		values[i] = val
+	}
+
+	var x [][]float64
+	var lastTs float64
+	if m.exog.enabled() && len(values) > 0 {
+		var err error
+		if x, lastTs, err = m.exogMatrix(newRows); err != nil {
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.trained {
+		return errors.New("model not trained, call Train() first")
+	}
+	if x != nil {
+		values = removeExogenous(values, x, m.exogFit.beta)
+		m.exogFit.last = x[len(x)-1]
+		m.exogFit.lastTs = lastTs
+	}
+	if len(m.tail) != m.seasonal.D*m.seasonal.Period+m.d+1 {
+		return errors.New("model state has no differencing history, call Train() first")
+	}
+
+	ar, ma := m.arCoeffs, m.maCoeffs
+	if m.seasonal.enabled() {
+		ar, ma = m.arPoly, m.maPoly
+	}
+
+	for _, v := range values {
+		shiftIn(m.tail, v)
+		level := difference(seasonalDifference(m.tail, m.seasonal.Period, m.seasonal.D), m.d)
+		w := level[len(level)-1] - m.mean
+
+		pred := 0.0
+		for j, a := range ar {
+			if idx := len(m.lastCentered) - 1 - j; idx >= 0 {
+				pred += a * m.lastCentered[idx]
+			}
+		}
+		for j, b := range ma {
+			if idx := len(m.lastErrors) - 1 - j; idx >= 0 {
+				pred += b * m.lastErrors[idx]
+			}
+		}
+
+		shiftIn(m.lastCentered, w)
+		shiftIn(m.lastErrors, w-pred)
+		shiftIn(m.lastValues, v)
+	}
+
+	return nil
+}
+
+// Update adds the observations in newRows to the minute-of-hour, hour-of-day
+// and hour-of-week buckets: each bucket's mean, min, max and count are updated in
+// place. Buckets are used by Predict once they hold two observations, as with
+// Train. The backtested errors behind the quantiles are only refreshed by Train.
+func (m *BaselineModel) Update(ctx context.Context, newRows FeatureFrame) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	for _, row := range newRows.Rows {
+		value, hasValue := row["value"]
+		if !hasValue {
+			continue
+		}
+
+		if minute, hasMinute := row["minute"]; hasMinute {
+			if b := int(minute); b >= 0 && b < 60 {
+				m.minuteSeasonality[b] = m.minuteSeasonality[b].observe(value)
+			}
+		}
+		if hour, hasHour := row["hour"]; hasHour {
+			if b := int(hour); b >= 0 && b < 24 {
+				m.hourSeasonality[b] = m.hourSeasonality[b].observe(value)
+			}
+		}
+		if b, ok := hourOfWeek(row); ok {
+			m.weekSeasonality[b] = m.weekSeasonality[b].observe(value)
+		}
+	}
+
+	return nil
+}
+
+// Update runs the burst size, interval and probability recursions over the
+// observations in newRows, as Train does over its history. The initial
+// values are only re-estimated by Train.
+//
+// Returns error if:
+//   - Context is cancelled
+//   - Model has not been trained
+//   - A row is missing the 'value' field
+func (m *IntermittentModel) Update(ctx context.Context, newRows FeatureFrame) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	values, err := intermittentValues(newRows)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.trained {
+		return errors.New("model not trained, call Train() first")
+	}
+	for _, v := range values {
+		m.observe(v)
+	}
+	return nil
+}
+
+// Update filters the observations in newRows with the fitted variances,
+// advancing the state the forecast starts from. Rows are placed by timestamp
+// after the last step seen, as in Train, so gaps and rows without a value are
+// missing observations; rows at or before it are ignored. The variances are
+// only re-estimated by Train.
+//
+// Returns error if:
+//   - Context is cancelled
+//   - Model has not been trained
+func (m *StateSpaceModel) Update(ctx context.Context, newRows FeatureFrame) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.trained {
+		return errors.New("model not trained, call Train() first")
+	}
+	fit := &m.fit
+
+	var y []float64
+	if fit.timed {
+		y, fit.lastTs = m.seriesAfter(newRows, fit.lastTs)
+		if len(y) == 0 {
+			return nil
+		}
+	} else {
+		y, _, _ = m.series(newRows)
+	}
+	fit.state, fit.cov = fit.filterFrom(y)
+	return nil
+}
+
+// observe returns the pattern updated with one more observation.
+// A nil pattern starts a new one.
+func (p *seasonalPattern) observe(value float64) *seasonalPattern {
+	if p == nil {
+		return &seasonalPattern{mean: value, min: value, max: value, count: 1}
+	}
+
+	p.count++
+	p.mean += (value - p.mean) / float64(p.count)
+	p.min = min(p.min, value)
+	p.max = max(p.max, value)
+	return p
+}
+
+// shiftIn drops the oldest element of buf and appends v, in place.
+func shiftIn(buf []float64, v float64) {
+	if len(buf) == 0 {
+		return
+	}
+	copy(buf, buf[1:])
+	buf[len(buf)-1] = v
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestARIMAModel_Update_AdvancesState(t *testing.T) {
+	ctx := context.Background()
+	full := syntheticComplex(230)
+
+	model := NewARIMAModel("m", 60, 600, 2, 1, 1)
+	if err := model.Train(ctx, FeatureFrame{Rows: full.Rows[:200]}); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	if err := model.Update(ctx, FeatureFrame{Rows: full.Rows[200:]}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	values := make([]float64, len(full.Rows))
+	for i, row := range full.Rows {
+		values[i] = row["value"]
+	}
+
+	if want := values[len(values)-2:]; !reflect.DeepEqual(model.tail, want) {
+		t.Errorf("tail = %v, want %v", model.tail, want)
+	}
+	if want := values[len(values)-2:]; !reflect.DeepEqual(model.lastValues, want) {
+		t.Errorf("lastValues = %v, want %v", model.lastValues, want)
+	}
+
+	diffs := difference(values, 1)
+	for i, got := range model.lastCentered {
+		want := diffs[len(diffs)-len(model.lastCentered)+i] - model.mean
+		if math.Abs(got-want) > 1e-9 {
+			t.Errorf("lastCentered[%d] = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestSARIMAModel_Update_TracksNewData(t *testing.T) {
+	ctx := context.Background()
+	full := syntheticComplex(260)
+
+	model := NewSARIMAModel("m", 60, 600, 1, 0, 0, SeasonalOrder{P: 1, D: 1, Period: 24})
+	if err := model.Train(ctx, FeatureFrame{Rows: full.Rows[:200]}); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	if err := model.Update(ctx, FeatureFrame{Rows: full.Rows[200:250]}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	forecast, err := model.Predict(ctx, FeatureFrame{})
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	for h, got := range forecast.Values {
+		want := full.Rows[250+h]["value"]
+		if math.Abs(got-want) > 5 {
+			t.Errorf("Values[%d] = %.2f, want ~%.2f after updating to point 250", h, got, want)
+		}
+	}
+}
+
+func TestARIMAModel_Update_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	if err := NewARIMAModel("m", 60, 600, 1, 1, 1).Update(ctx, syntheticConstant(5, 1)); err == nil {
+		t.Error("Update() error = nil, want error for untrained model")
+	}
+
+	model := NewARIMAModel("m", 60, 600, 1, 1, 1)
+	if err := model.Train(ctx, syntheticComplex(100)); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	missing := FeatureFrame{Rows: []map[string]float64{{"timestamp": 1}}}
+	if err := model.Update(ctx, missing); err == nil {
+		t.Error("Update() error = nil, want error for row without value")
+	}
+}
+
+func TestBaselineModel_Update(t *testing.T) {
+	model := NewBaselineModel("m", 60, 600)
+	ctx := context.Background()
+
+	history := FeatureFrame{Rows: []map[string]float64{
+		{"value": 100, "hour": 9},
+		{"value": 200, "hour": 9},
+	}}
+	if err := model.Train(ctx, history); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+
+	update := FeatureFrame{Rows: []map[string]float64{
+		{"value": 300, "hour": 9},
+		{"value": 50, "hour": 10},
+	}}
+	if err := model.Update(ctx, update); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	got := model.hourSeasonality[9]
+	want := &seasonalPattern{mean: 200, min: 100, max: 300, count: 3}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("hourSeasonality[9] = %+v, want %+v", got, want)
+	}
+
+	// A single observation starts a bucket that Predict does not use yet.
+	if p := model.hourSeasonality[10]; p == nil || p.count != 1 {
+		t.Errorf("hourSeasonality[10] = %+v, want a new bucket with count 1", p)
+	}
+	forecast, err := model.Predict(ctx, FeatureFrame{Rows: []map[string]float64{{"value": 80, "hour": 9}}})
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	// 600s ahead is still hour 9, whose bucket has mean 200.
+	if forecast.Values[0] <= 80 {
+		t.Errorf("Values[0] = %v, want pulled up towards the hour-9 mean", forecast.Values[0])
+	}
+}
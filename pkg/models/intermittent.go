@@ -0,0 +1,289 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// IntermittentMethod selects how an IntermittentModel estimates how often
+// bursts occur.
+type IntermittentMethod string
+
+const (
+	// IntermittentCroston smooths burst sizes and the intervals between
+	// bursts separately; the burst probability is one over the interval.
+	IntermittentCroston IntermittentMethod = "croston"
+
+	// IntermittentSBA is Croston's method with the Syntetos-Boylan
+	// correction of its upward bias, applied to the burst size.
+	IntermittentSBA IntermittentMethod = "sba"
+
+	// IntermittentTSB (Teunter-Syntetos-Babai) smooths the burst probability
+	// at every step, so it decays while the workload stays idle.
+	IntermittentTSB IntermittentMethod = "tsb"
+)
+
+// IntermittentOptions configures an IntermittentModel. Zero values select
+// the defaults documented on each field.
+type IntermittentOptions struct {
+	// Method selects croston, sba or tsb (default IntermittentTSB).
+	Method IntermittentMethod
+
+	// Alpha is the smoothing factor of burst sizes, in (0, 1] (default 0.1).
+	Alpha float64
+
+	// Beta is the smoothing factor of the interval between bursts (Croston,
+	// SBA) or of the burst probability (TSB), in (0, 1] (default 0.1).
+	Beta float64
+
+	// Threshold is the value at or below which a step counts as idle
+	// (default 0).
+	Threshold float64
+}
+
+// intermittentSizeSamples is the number of recent burst sizes kept to
+// estimate quantiles.
+const intermittentSizeSamples = 200
+
+// BurstForecast describes intermittent demand per forecast step: the
+// probability that a burst occurs and its expected size if it does.
+type BurstForecast struct {
+	// Probability holds the per-step burst probability, in [0, 1].
+	Probability []float64
+
+	// Size holds the per-step expected value of a burst.
+	Size []float64
+}
+
+// IntermittentModel forecasts workloads that are idle most of the time and
+// then spike, such as batch jobs and queue consumers. Averaging models smear
+// those bursts into a constant small value; this model instead tracks two
+// exponentially smoothed quantities:
+//
+//	z: the size of a burst, updated on steps with demand
+//	p: the probability of a burst at a step
+//
+// Croston and SBA estimate p as one over the smoothed interval between
+// bursts, which only changes when a burst occurs. TSB smooths p at every
+// step, so the forecast decays towards zero during long idle periods.
+//
+// Forecast.Values is the expected demand p·z; Forecast.Burst carries p and z
+// separately, so that the capacity planner can keep zero replicas when a
+// burst is unlikely and pre-warm for its full size when it is likely.
+// Quantiles follow from the mixture of no demand (probability 1-p) and the
+// recent burst sizes. Rows are assumed to be consecutive steps.
+//
+// The model is thread-safe for concurrent Predict calls after training.
+type IntermittentModel struct {
+	metric  string
+	stepSec int
+	horizon int
+	opts    IntermittentOptions
+
+	mu          sync.RWMutex
+	trained     bool
+	size        float64   // smoothed burst size
+	interval    float64   // smoothed steps between bursts (Croston, SBA)
+	probability float64   // smoothed burst probability (TSB)
+	sinceBurst  int       // steps since the last burst
+	sizes       []float64 // recent burst sizes
+}
+
+// NewIntermittentModel creates a new intermittent-demand model.
+func NewIntermittentModel(metric string, stepSec, horizon int, opts IntermittentOptions) *IntermittentModel {
+	if opts.Method == "" {
+		opts.Method = IntermittentTSB
+	}
+	if opts.Alpha <= 0 || opts.Alpha > 1 {
+		opts.Alpha = 0.1
+	}
+	if opts.Beta <= 0 || opts.Beta > 1 {
+		opts.Beta = 0.1
+	}
+
+	return &IntermittentModel{
+		metric:  metric,
+		stepSec: stepSec,
+		horizon: horizon,
+		opts:    opts,
+	}
+}
+
+// Name returns the model identifier including the method,
+// e.g. "intermittent(tsb)".
+func (m *IntermittentModel) Name() string {
+	return "intermittent(" + string(m.opts.Method) + ")"
+}
+
+// Train initialises burst size, interval and probability from their means
+// over history and then runs the smoothing recursions over it. A history
+// without any burst is valid and forecasts zero.
+//
+// Returns error if:
+//   - Context is cancelled
+//   - Method is unknown
+//   - History is empty or a row is missing the 'value' field
+func (m *IntermittentModel) Train(ctx context.Context, history FeatureFrame) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	switch m.opts.Method {
+	case IntermittentCroston, IntermittentSBA, IntermittentTSB:
+	default:
+		return fmt.Errorf("unknown intermittent method %q", m.opts.Method)
+	}
+
+	values, err := intermittentValues(history)
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return errors.New("empty history")
+	}
+
+	var sizeSum, intervalSum float64
+	bursts, last := 0, -1
+	for i, v := range values {
+		if v > m.opts.Threshold {
+			sizeSum += v
+			intervalSum += float64(i - last)
+			bursts++
+			last = i
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.size, m.interval, m.probability = 0, float64(len(values)), 0
+	if bursts > 0 {
+		m.size = sizeSum / float64(bursts)
+		m.interval = intervalSum / float64(bursts)
+		m.probability = float64(bursts) / float64(len(values))
+	}
+	m.sinceBurst = 0
+	m.sizes = nil
+	for _, v := range values {
+		m.observe(v)
+	}
+	m.trained = true
+	return nil
+}
+
+// observe advances the smoothing recursions by one step. Callers hold mu.
+func (m *IntermittentModel) observe(v float64) {
+	m.sinceBurst++
+	burst := 0.0
+	if v > m.opts.Threshold {
+		burst = 1
+		m.size += m.opts.Alpha * (v - m.size)
+		m.interval += m.opts.Beta * (float64(m.sinceBurst) - m.interval)
+		m.sinceBurst = 0
+
+		m.sizes = append(m.sizes, v)
+		if len(m.sizes) > intermittentSizeSamples {
+			m.sizes = m.sizes[len(m.sizes)-intermittentSizeSamples:]
+		}
+	}
+	m.probability += m.opts.Beta * (burst - m.probability)
+}
+
+// Predict returns the expected demand, the burst probability and size, and
+// quantiles of the demand at every step. Intermittent-demand forecasts are
+// flat over the horizon; features are not used.
+//
+// Returns error if:
+//   - Context is cancelled
+//   - Model has not been trained
+func (m *IntermittentModel) Predict(ctx context.Context, features FeatureFrame) (Forecast, error) {
+	if ctx.Err() != nil {
+		return Forecast{}, ctx.Err()
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.trained {
+		return Forecast{}, errors.New("model not trained, call Train() first")
+	}
+
+	p, z := m.burstProbability(), m.burstSize()
+	steps := m.horizon / m.stepSec
+	burst := &BurstForecast{
+		Probability: make([]float64, steps),
+		Size:        make([]float64, steps),
+	}
+	values := make([]float64, steps)
+	for i := range steps {
+		burst.Probability[i] = p
+		burst.Size[i] = z
+		values[i] = p * z
+	}
+
+	return Forecast{
+		Metric:    m.metric,
+		Values:    values,
+		StepSec:   m.stepSec,
+		Horizon:   m.horizon,
+		Quantiles: m.quantiles(p, steps),
+		Burst:     burst,
+	}, nil
+}
+
+// burstProbability returns the probability of a burst at a future step.
+func (m *IntermittentModel) burstProbability() float64 {
+	if m.opts.Method == IntermittentTSB {
+		return m.probability
+	}
+	return min(1/max(m.interval, 1), 1)
+}
+
+// burstSize returns the expected size of a future burst.
+func (m *IntermittentModel) burstSize() float64 {
+	if m.opts.Method == IntermittentSBA {
+		return (1 - m.opts.Beta/2) * m.size
+	}
+	return m.size
+}
+
+// quantiles returns demand quantiles for a step with burst probability p:
+// zero up to level 1-p, recent burst sizes above it. Returns nil when fewer
+// than minResidualSamples bursts have been observed.
+func (m *IntermittentModel) quantiles(p float64, steps int) map[string][]float64 {
+	if len(m.sizes) < minResidualSamples || p <= 0 {
+		return nil
+	}
+	sorted := slices.Clone(m.sizes)
+	slices.Sort(sorted)
+
+	out := make(map[string][]float64, len(QuantileLevels))
+	for _, q := range QuantileLevels {
+		v := 0.0
+		if q > 1-p {
+			v = sampleQuantile(sorted, (q-(1-p))/p)
+		}
+		values := make([]float64, steps)
+		for i := range values {
+			values[i] = v
+		}
+		out[QuantileKey(q)] = values
+	}
+	return sanitizeQuantiles(out, steps)
+}
+
+// intermittentValues extracts the 'value' of every row.
+func intermittentValues(frame FeatureFrame) ([]float64, error) {
+	values := make([]float64, len(frame.Rows))
+	for i, row := range frame.Rows {
+		v, ok := row["value"]
+		if !ok {
+			return nil, fmt.Errorf("row %d missing 'value' field", i)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
@@ -0,0 +1,182 @@
+package models
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// burstFrame returns n rows that are idle except for a burst of size every
+// period steps, the last row being a burst.
+func burstFrame(n, period int, size float64) FeatureFrame {
+	rows := make([]map[string]float64, n)
+	for i := range n {
+		v := 0.0
+		if (n-1-i)%period == 0 {
+			v = size
+		}
+		rows[i] = map[string]float64{"timestamp": float64(i * 60), "value": v}
+	}
+	return FeatureFrame{Rows: rows}
+}
+
+func TestIntermittentModel_Methods(t *testing.T) {
+	// A burst of 50 every 5 steps.
+	history := burstFrame(200, 5, 50)
+
+	// TSB's probability settles into a cycle peaking right after a burst at
+	// β / (1 - (1-β)^5).
+	tsbPeak := 0.1 / (1 - math.Pow(0.9, 5))
+
+	tests := []struct {
+		method      IntermittentMethod
+		probability float64
+		size        float64
+	}{
+		{method: IntermittentCroston, probability: 0.2, size: 50},
+		{method: IntermittentSBA, probability: 0.2, size: 50 * 0.95},
+		{method: IntermittentTSB, probability: tsbPeak, size: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			model := NewIntermittentModel("m", 60, 300, IntermittentOptions{Method: tt.method})
+			ctx := context.Background()
+			if err := model.Train(ctx, history); err != nil {
+				t.Fatalf("Train() error = %v", err)
+			}
+			forecast, err := model.Predict(ctx, history)
+			if err != nil {
+				t.Fatalf("Predict() error = %v", err)
+			}
+
+			if forecast.Burst == nil || len(forecast.Burst.Probability) != 5 || len(forecast.Burst.Size) != 5 {
+				t.Fatalf("Burst = %+v, want 5 steps", forecast.Burst)
+			}
+			for i, v := range forecast.Values {
+				p, z := forecast.Burst.Probability[i], forecast.Burst.Size[i]
+				if math.Abs(p-tt.probability) > 1e-3 {
+					t.Errorf("Probability[%d] = %v, want %v", i, p, tt.probability)
+				}
+				if math.Abs(z-tt.size) > 1e-6 {
+					t.Errorf("Size[%d] = %v, want %v", i, z, tt.size)
+				}
+				if math.Abs(v-p*z) > 1e-9 {
+					t.Errorf("Values[%d] = %v, want probability × size %v", i, v, p*z)
+				}
+			}
+		})
+	}
+}
+
+func TestIntermittentModel_IdleDecay(t *testing.T) {
+	ctx := context.Background()
+	history := burstFrame(200, 5, 50)
+	idle := burstFrame(31, 100, 0) // 31 idle steps after the last burst
+
+	tests := []struct {
+		method IntermittentMethod
+		want   float64
+	}{
+		// Croston only updates its interval on a burst.
+		{method: IntermittentCroston, want: 0.2},
+		// TSB decays the probability at every idle step.
+		{method: IntermittentTSB, want: 0.1 / (1 - math.Pow(0.9, 5)) * math.Pow(0.9, 31)},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			model := NewIntermittentModel("m", 60, 60, IntermittentOptions{Method: tt.method})
+			if err := model.Train(ctx, history); err != nil {
+				t.Fatalf("Train() error = %v", err)
+			}
+			if err := model.Update(ctx, idle); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			forecast, err := model.Predict(ctx, idle)
+			if err != nil {
+				t.Fatalf("Predict() error = %v", err)
+			}
+			if got := forecast.Burst.Probability[0]; math.Abs(got-tt.want) > 1e-3 {
+				t.Errorf("Probability = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIntermittentModel_Quantiles(t *testing.T) {
+	model := NewIntermittentModel("m", 60, 120, IntermittentOptions{Method: IntermittentCroston})
+	ctx := context.Background()
+	history := burstFrame(200, 5, 50)
+	if err := model.Train(ctx, history); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	forecast, err := model.Predict(ctx, history)
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+
+	// No demand with probability 0.8, a burst of 50 otherwise.
+	want := map[float64]float64{0.5: 0, 0.75: 0, 0.9: 50, 0.95: 50}
+	for q, v := range want {
+		got, ok := forecast.Quantile(q)
+		if !ok {
+			t.Fatalf("Quantile(%v) missing", q)
+		}
+		for i := range got {
+			if got[i] != v {
+				t.Errorf("Quantile(%v)[%d] = %v, want %v", q, i, got[i], v)
+			}
+		}
+	}
+}
+
+func TestIntermittentModel_NoBursts(t *testing.T) {
+	model := NewIntermittentModel("m", 60, 120, IntermittentOptions{Threshold: 1})
+	ctx := context.Background()
+	history := burstFrame(50, 10, 0.5) // all values at or below the threshold
+	if err := model.Train(ctx, history); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	forecast, err := model.Predict(ctx, history)
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	for i, v := range forecast.Values {
+		if v != 0 {
+			t.Errorf("Values[%d] = %v, want 0 without bursts", i, v)
+		}
+	}
+	if forecast.Quantiles != nil {
+		t.Errorf("Quantiles = %v, want nil without bursts", forecast.Quantiles)
+	}
+}
+
+func TestIntermittentModel_Errors(t *testing.T) {
+	ctx := context.Background()
+	model := NewIntermittentModel("m", 60, 60, IntermittentOptions{})
+
+	if _, err := model.Predict(ctx, FeatureFrame{}); err == nil {
+		t.Error("Predict() error = nil, want error before training")
+	}
+	if err := model.Update(ctx, burstFrame(5, 5, 1)); err == nil {
+		t.Error("Update() error = nil, want error before training")
+	}
+	if err := model.Train(ctx, FeatureFrame{}); err == nil {
+		t.Error("Train() error = nil, want error for empty history")
+	}
+	if err := model.Train(ctx, FeatureFrame{Rows: []map[string]float64{{"timestamp": 0}}}); err == nil {
+		t.Error("Train() error = nil, want error for missing value")
+	}
+
+	unknown := NewIntermittentModel("m", 60, 60, IntermittentOptions{Method: "holt"})
+	if err := unknown.Train(ctx, burstFrame(10, 5, 1)); err == nil {
+		t.Error("Train() error = nil, want error for unknown method")
+	}
+}
+
+func TestIntermittentModel_Name(t *testing.T) {
+	if got := NewIntermittentModel("m", 60, 600, IntermittentOptions{}).Name(); got != "intermittent(tsb)" {
+		t.Errorf("Name() = %q, want %q", got, "intermittent(tsb)")
+	}
+}
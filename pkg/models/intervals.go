@@ -0,0 +1,197 @@
+package models
+
+import (
+	"math"
+	"slices"
+	"strconv"
+)
+
+// QuantileLevels are the quantiles reported in Forecast.Quantiles by models that
+// support probabilistic forecasts.
+var QuantileLevels = []float64{0.05, 0.1, 0.25, 0.5, 0.75, 0.9, 0.95}
+
+// minResidualSamples is the minimum number of residuals needed at a forecast
+// step before empirical quantiles are computed from them.
+const minResidualSamples = 5
+
+// maxQuantileValue caps quantile values so that diverging variance estimates
+// stay finite (and JSON-encodable).
+const maxQuantileValue = 1e9
+
+// QuantileKey returns the Forecast.Quantiles key for quantile level q,
+// e.g. 0.1 → "p10", 0.975 → "p97.5".
+func QuantileKey(q float64) string {
+	return "p" + strconv.FormatFloat(math.Round(q*1e4)/1e2, 'f', -1, 64)
+}
+
+// IsQuantileLevel reports whether q is one of QuantileLevels.
+func IsQuantileLevel(q float64) bool {
+	return slices.Contains(QuantileLevels, q)
+}
+
+// Quantile returns the forecast series for quantile level q (e.g., 0.9 for p90).
+// The second return value is false if the forecast does not carry that quantile.
+func (f Forecast) Quantile(q float64) ([]float64, bool) {
+	values, ok := f.Quantiles[QuantileKey(q)]
+	return values, ok && len(values) == len(f.Values)
+}
+
+// normalQuantiles builds quantile series assuming Gaussian forecast errors with
+// the given per-step standard deviations around the point forecast.
+func normalQuantiles(point, stddev []float64) map[string][]float64 {
+	out := make(map[string][]float64, len(QuantileLevels))
+	for _, q := range QuantileLevels {
+		z := math.Sqrt2 * math.Erfinv(2*q-1)
+		values := make([]float64, len(point))
+		for i := range point {
+			values[i] = point[i] + z*stddev[i]
+		}
+		out[QuantileKey(q)] = values
+	}
+	return sanitizeQuantiles(out, len(point))
+}
+
+// empiricalQuantiles builds quantile series by adding quantiles of observed
+// forecast errors (actual - forecast) to the point forecast.
+//
+// residuals[h] holds the errors observed h+1 steps ahead. Steps with fewer
+// than minResidualSamples errors reuse the nearest earlier step that has
+// enough, so intervals never shrink with the horizon. Returns nil when point
+// is empty or no step has enough residuals.
+func empiricalQuantiles(point []float64, residuals [][]float64) map[string][]float64 {
+	if len(point) == 0 {
+		return nil
+	}
+	var sorted [][]float64
+	var last []float64
+	for h := range point {
+		if h < len(residuals) && len(residuals[h]) >= minResidualSamples {
+			last = slices.Clone(residuals[h])
+			slices.Sort(last)
+		}
+		sorted = append(sorted, last)
+	}
+	if sorted[0] == nil {
+		// Fill leading gaps with the first step that has enough residuals.
+		first := slices.IndexFunc(sorted, func(r []float64) bool { return r != nil })
+		if first < 0 {
+			return nil
+		}
+		for h := range first {
+			sorted[h] = sorted[first]
+		}
+	}
+
+	out := make(map[string][]float64, len(QuantileLevels))
+	for _, q := range QuantileLevels {
+		values := make([]float64, len(point))
+		for h := range point {
+			values[h] = point[h] + sampleQuantile(sorted[h], q)
+		}
+		out[QuantileKey(q)] = values
+	}
+	return sanitizeQuantiles(out, len(point))
+}
+
+// sanitizeQuantiles clamps quantile values to [0, maxQuantileValue] and makes
+// them non-decreasing across levels at every step.
+func sanitizeQuantiles(quantiles map[string][]float64, n int) map[string][]float64 {
+	for i := range n {
+		floor := 0.0
+		for _, q := range QuantileLevels {
+			values := quantiles[QuantileKey(q)]
+			if math.IsNaN(values[i]) || values[i] < floor {
+				values[i] = floor
+			}
+			if values[i] > maxQuantileValue {
+				values[i] = max(floor, maxQuantileValue)
+			}
+			floor = values[i]
+		}
+	}
+	return quantiles
+}
+
+// sampleQuantile returns the q-quantile of sorted data by linear interpolation.
+func sampleQuantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	frac := pos - float64(lo)
+	return sorted[lo]*(1-frac) + sorted[hi]*frac
+}
+
+// backtestResiduals evaluates predict at rolling origins over the tail of
+// history and returns the forecast errors per horizon step.
+//
+// For an origin o, predict receives rows [0, o) and its forecast is compared
+// with the observed values of rows o, o+1, ... . Origins are placed so that
+// every step gets up to maxOrigins errors. At least minRows rows are always
+// passed to predict. Rows without a "value" are skipped.
+func backtestResiduals(history FeatureFrame, nSteps, minRows, maxOrigins int, predict func(FeatureFrame) ([]float64, error)) [][]float64 {
+	rows := make([]map[string]float64, 0, len(history.Rows))
+	for _, row := range history.Rows {
+		if _, ok := row["value"]; ok {
+			rows = append(rows, row)
+		}
+	}
+
+	residuals := make([][]float64, nSteps)
+	first := max(minRows, len(rows)-nSteps-maxOrigins+1)
+	for o := first; o < len(rows); o++ {
+		forecast, err := predict(FeatureFrame{Rows: rows[:o]})
+		if err != nil {
+			continue
+		}
+		for h := 0; h < nSteps && h < len(forecast) && o+h < len(rows); h++ {
+			residuals[h] = append(residuals[h], rows[o+h]["value"]-forecast[h])
+		}
+	}
+	return residuals
+}
+
+// psiWeights returns the first n weights of the MA(∞) representation of an
+// ARIMA process with expanded AR/MA coefficients, after d regular and
+// seasonalD seasonal integrations. The h-step forecast error variance is
+// σ²·Σ_{j<h} ψ_j².
+func psiWeights(ar, ma []float64, d, seasonalD, period, n int) []float64 {
+	poly := lagPolynomial(ar, 1, -1)
+	for range d {
+		poly = polyMul(poly, []float64{1, -1})
+	}
+	for range seasonalD {
+		poly = polyMul(poly, lagPolynomial([]float64{1}, period, -1))
+	}
+
+	psi := make([]float64, n)
+	if n == 0 {
+		return psi
+	}
+	psi[0] = 1
+	for j := 1; j < n; j++ {
+		v := 0.0
+		if j-1 < len(ma) {
+			v = ma[j-1]
+		}
+		for i := 1; i < len(poly) && i <= j; i++ {
+			v -= poly[i] * psi[j-i]
+		}
+		psi[j] = v
+	}
+	return psi
+}
+
+// psiStddev converts psi weights and the innovation variance into per-step
+// forecast standard deviations.
+func psiStddev(psi []float64, sigma2 float64) []float64 {
+	out := make([]float64, len(psi))
+	sum := 0.0
+	for h, w := range psi {
+		sum += w * w
+		out[h] = math.Sqrt(sigma2 * sum)
+	}
+	return out
+}
@@ -0,0 +1,193 @@
+package models
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestQuantileKey(t *testing.T) {
+	tests := []struct {
+		q    float64
+		want string
+	}{
+		{0.05, "p5"},
+		{0.1, "p10"},
+		{0.5, "p50"},
+		{0.9, "p90"},
+		{0.975, "p97.5"},
+	}
+
+	for _, tt := range tests {
+		if got := QuantileKey(tt.q); got != tt.want {
+			t.Errorf("QuantileKey(%v) = %q, want %q", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestNormalQuantiles(t *testing.T) {
+	point := []float64{100, 100}
+	got := normalQuantiles(point, []float64{10, 20})
+
+	p50 := got[QuantileKey(0.5)]
+	p90 := got[QuantileKey(0.9)]
+	p10 := got[QuantileKey(0.1)]
+	for i := range point {
+		if math.Abs(p50[i]-100) > 1e-9 {
+			t.Errorf("p50[%d] = %.4f, want 100", i, p50[i])
+		}
+		if math.Abs((p90[i]-100)+(p10[i]-100)) > 1e-9 {
+			t.Errorf("p10/p90 at step %d not symmetric: %.4f, %.4f", i, p10[i], p90[i])
+		}
+	}
+	// z(0.9) ≈ 1.2816
+	if math.Abs(p90[0]-112.816) > 0.01 {
+		t.Errorf("p90[0] = %.4f, want ~112.816", p90[0])
+	}
+	if p90[1]-p10[1] <= p90[0]-p10[0] {
+		t.Error("interval should widen with larger stddev")
+	}
+}
+
+func TestNormalQuantiles_ClampsToZero(t *testing.T) {
+	got := normalQuantiles([]float64{1}, []float64{100})
+	for _, q := range QuantileLevels {
+		if v := got[QuantileKey(q)][0]; v < 0 {
+			t.Errorf("%s = %.2f, want >= 0", QuantileKey(q), v)
+		}
+	}
+}
+
+func TestEmpiricalQuantiles(t *testing.T) {
+	residuals := [][]float64{
+		{-2, -1, 0, 1, 2},
+		{-4, -2, 0, 2, 4},
+		{0, 0}, // too few samples: reuse the previous step
+	}
+	got := empiricalQuantiles([]float64{50, 50, 50}, residuals)
+	if got == nil {
+		t.Fatal("empiricalQuantiles() = nil, want quantiles")
+	}
+
+	p95 := got[QuantileKey(0.95)]
+	want := []float64{51.8, 53.6, 53.6}
+	for i := range want {
+		if math.Abs(p95[i]-want[i]) > 1e-9 {
+			t.Errorf("p95[%d] = %.4f, want %.4f", i, p95[i], want[i])
+		}
+	}
+
+	if empiricalQuantiles([]float64{1}, [][]float64{{1, 2}}) != nil {
+		t.Error("empiricalQuantiles() with too few residuals should return nil")
+	}
+	if empiricalQuantiles(nil, residuals) != nil {
+		t.Error("empiricalQuantiles() with no forecast steps should return nil")
+	}
+}
+
+func TestPsiWeights(t *testing.T) {
+	// Random walk: ARIMA(0,1,0) has ψ_j = 1 for all j.
+	psi := psiWeights(nil, nil, 1, 0, 0, 4)
+	for j, w := range psi {
+		if w != 1 {
+			t.Errorf("random walk psi[%d] = %v, want 1", j, w)
+		}
+	}
+
+	// AR(1) with φ=0.5: ψ_j = 0.5^j.
+	psi = psiWeights([]float64{0.5}, nil, 0, 0, 0, 4)
+	for j, w := range psi {
+		if want := math.Pow(0.5, float64(j)); math.Abs(w-want) > 1e-12 {
+			t.Errorf("AR(1) psi[%d] = %v, want %v", j, w, want)
+		}
+	}
+
+	// MA(1) with θ=0.4: ψ = 1, 0.4, 0, 0.
+	psi = psiWeights(nil, []float64{0.4}, 0, 0, 0, 4)
+	want := []float64{1, 0.4, 0, 0}
+	for j := range want {
+		if math.Abs(psi[j]-want[j]) > 1e-12 {
+			t.Errorf("MA(1) psi[%d] = %v, want %v", j, psi[j], want[j])
+		}
+	}
+}
+
+func TestForecast_Quantile(t *testing.T) {
+	f := Forecast{
+		Values:    []float64{1, 2},
+		Quantiles: map[string][]float64{"p90": {2, 3}},
+	}
+	if got, ok := f.Quantile(0.9); !ok || got[1] != 3 {
+		t.Errorf("Quantile(0.9) = %v, %v; want [2 3], true", got, ok)
+	}
+	if _, ok := f.Quantile(0.1); ok {
+		t.Error("Quantile(0.1) ok = true, want false")
+	}
+}
+
+func TestARIMAModel_Predict_Quantiles(t *testing.T) {
+	values := make([]float64, 100)
+	for i := range values {
+		values[i] = 100 + 10*math.Sin(float64(i)*0.7) + float64(i%3)
+	}
+	model := NewARIMAModel("test_metric", 60, 600, 1, 1, 1)
+	if err := model.Train(context.Background(), makeFeatureFrame(values)); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+
+	forecast, err := model.Predict(context.Background(), FeatureFrame{})
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	assertQuantiles(t, forecast)
+
+	p10, _ := forecast.Quantile(0.1)
+	p90, _ := forecast.Quantile(0.9)
+	last := len(forecast.Values) - 1
+	if p90[last]-p10[last] <= p90[0]-p10[0] {
+		t.Errorf("interval should widen over the horizon: step 0 width %.2f, last width %.2f",
+			p90[0]-p10[0], p90[last]-p10[last])
+	}
+}
+
+func TestBaselineModel_Predict_Quantiles(t *testing.T) {
+	rows := make([]map[string]float64, 120)
+	for i := range rows {
+		rows[i] = map[string]float64{
+			"value":  100 + float64(i%7)*5,
+			"minute": float64(i % 60),
+			"hour":   float64(i / 60),
+		}
+	}
+	history := FeatureFrame{Rows: rows}
+
+	model := NewBaselineModel("test_metric", 60, 300)
+	if err := model.Train(context.Background(), history); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+
+	forecast, err := model.Predict(context.Background(), history)
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	assertQuantiles(t, forecast)
+}
+
+// assertQuantiles checks that every quantile level is present, has one value
+// per step and is ordered at each step.
+func assertQuantiles(t *testing.T, forecast Forecast) {
+	t.Helper()
+	for i := range forecast.Values {
+		prev := math.Inf(-1)
+		for _, q := range QuantileLevels {
+			series, ok := forecast.Quantile(q)
+			if !ok {
+				t.Fatalf("forecast missing quantile %s", QuantileKey(q))
+			}
+			if series[i] < prev {
+				t.Errorf("%s[%d] = %.2f below lower quantile %.2f", QuantileKey(q), i, series[i], prev)
+			}
+			prev = series[i]
+		}
+	}
+}
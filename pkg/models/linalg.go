@@ -0,0 +1,126 @@
+package models
+
+import (
+	"errors"
+	"math"
+)
+
+// ridgeSolve fits y ≈ X·β by penalised least squares, minimising
+//
+//	||y - X·β||² + Σ penalty[j]·β[j]²
+//
+// penalty must have one entry per column of X (0 leaves a column unpenalised).
+// The normal equations are solved with a Cholesky factorisation.
+func ridgeSolve(X [][]float64, y []float64, penalty []float64) ([]float64, error) {
+	if len(X) == 0 || len(X) != len(y) {
+		return nil, errors.New("design matrix and target must have the same non-zero length")
+	}
+	k := len(X[0])
+	if len(penalty) != k {
+		return nil, errors.New("penalty must have one entry per column")
+	}
+
+	xtx := make([][]float64, k)
+	for i := range xtx {
+		xtx[i] = make([]float64, k)
+	}
+	xty := make([]float64, k)
+
+	for r, row := range X {
+		for i, xi := range row {
+			if xi == 0 {
+				continue
+			}
+			xty[i] += xi * y[r]
+			for j := i; j < k; j++ {
+				xtx[i][j] += xi * row[j]
+			}
+		}
+	}
+	for i := range k {
+		xtx[i][i] += penalty[i]
+		for j := range i {
+			xtx[i][j] = xtx[j][i]
+		}
+	}
+
+	return solveSymmetric(xtx, xty)
+}
+
+// solveSymmetric solves A·x = b for a symmetric positive (semi-)definite A
+// using a Cholesky factorisation. A tiny jitter is added to the diagonal when
+// A is numerically singular. A and b are not modified.
+func solveSymmetric(A [][]float64, b []float64) ([]float64, error) {
+	n := len(A)
+	if n == 0 || len(b) != n {
+		return nil, errors.New("matrix and vector dimensions do not match")
+	}
+
+	scale := 0.0
+	for i := range n {
+		scale = math.Max(scale, math.Abs(A[i][i]))
+	}
+	if scale == 0 {
+		scale = 1
+	}
+
+	for _, jitter := range []float64{0, 1e-10, 1e-8, 1e-6} {
+		L, ok := cholesky(A, jitter*scale)
+		if !ok {
+			continue
+		}
+
+		// Forward substitution: L·z = b
+		z := make([]float64, n)
+		for i := range n {
+			sum := b[i]
+			for j := range i {
+				sum -= L[i][j] * z[j]
+			}
+			z[i] = sum / L[i][i]
+		}
+
+		// Back substitution: Lᵀ·x = z
+		x := make([]float64, n)
+		for i := n - 1; i >= 0; i-- {
+			sum := z[i]
+			for j := i + 1; j < n; j++ {
+				sum -= L[j][i] * x[j]
+			}
+			x[i] = sum / L[i][i]
+		}
+		return x, nil
+	}
+
+	return nil, errors.New("matrix is not positive definite")
+}
+
+// cholesky returns the lower-triangular factor L of A + jitter·I.
+func cholesky(A [][]float64, jitter float64) ([][]float64, bool) {
+	n := len(A)
+	L := make([][]float64, n)
+	for i := range L {
+		L[i] = make([]float64, n)
+	}
+
+	for i := range n {
+		for j := 0; j <= i; j++ {
+			sum := A[i][j]
+			if i == j {
+				sum += jitter
+			}
+			for k := range j {
+				sum -= L[i][k] * L[j][k]
+			}
+			if i == j {
+				if sum <= 0 || math.IsNaN(sum) {
+					return nil, false
+				}
+				L[i][i] = math.Sqrt(sum)
+			} else {
+				L[i][j] = sum / L[j][j]
+			}
+		}
+	}
+	return L, true
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRidgeSolve_ExactFit(t *testing.T) {
+	// y = 3 + 2x, no penalty
+	X := [][]float64{{1, 0}, {1, 1}, {1, 2}, {1, 3}}
+	y := []float64{3, 5, 7, 9}
+
+	beta, err := ridgeSolve(X, y, []float64{0, 0})
+	if err != nil {
+		t.Fatalf("ridgeSolve() error = %v", err)
+	}
+	if math.Abs(beta[0]-3) > 1e-9 || math.Abs(beta[1]-2) > 1e-9 {
+		t.Errorf("ridgeSolve() = %v, want [3 2]", beta)
+	}
+}
+
+func TestRidgeSolve_PenaltyShrinks(t *testing.T) {
+	X := [][]float64{{1}, {2}, {3}}
+	y := []float64{2, 4, 6}
+
+	free, err := ridgeSolve(X, y, []float64{0})
+	if err != nil {
+		t.Fatalf("ridgeSolve() error = %v", err)
+	}
+	shrunk, err := ridgeSolve(X, y, []float64{14})
+	if err != nil {
+		t.Fatalf("ridgeSolve() error = %v", err)
+	}
+
+	// X'X = 14, X'y = 28: β = 28/(14+λ)
+	if math.Abs(free[0]-2) > 1e-9 || math.Abs(shrunk[0]-1) > 1e-9 {
+		t.Errorf("ridgeSolve() = %v / %v, want 2 / 1", free, shrunk)
+	}
+}
+
+func TestRidgeSolve_Errors(t *testing.T) {
+	if _, err := ridgeSolve(nil, nil, nil); err == nil {
+		t.Error("ridgeSolve(empty) error = nil, want error")
+	}
+	if _, err := ridgeSolve([][]float64{{1, 2}}, []float64{1}, []float64{0}); err == nil {
+		t.Error("ridgeSolve(bad penalty) error = nil, want error")
+	}
+}
+
+func TestSolveSymmetric_SingularUsesJitter(t *testing.T) {
+	// Rank-deficient: second column duplicates the first.
+	A := [][]float64{{2, 2}, {2, 2}}
+	b := []float64{4, 4}
+
+	x, err := solveSymmetric(A, b)
+	if err != nil {
+		t.Fatalf("solveSymmetric() error = %v", err)
+	}
+	if got := 2*x[0] + 2*x[1]; math.Abs(got-4) > 1e-3 {
+		t.Errorf("A·x = %v, want 4", got)
+	}
+}
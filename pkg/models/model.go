@@ -43,6 +43,22 @@ type Forecast struct {
 
 	// Horizon is the total forecast window in seconds
 	Horizon int
+
+	// Quantiles holds per-step forecast quantiles keyed by QuantileKey
+	// (e.g., "p10", "p50", "p90"). Each series has the same length as Values.
+	// Nil when the model cannot estimate its uncertainty yet.
+	Quantiles map[string][]float64
+
+	// Burst splits the forecast of intermittent-demand models into the
+	// per-step probability and size of a burst; Values is their product.
+	// Nil for other models.
+	Burst *BurstForecast
+
+	// Components explains Values: per-step series keyed by component name,
+	// such as ComponentLevel or ComponentSeasonal, each with the same length
+	// as Values. How they combine into Values is documented by each model.
+	// Nil when the model does not decompose its forecasts.
+	Components map[string][]float64
 }
 
 // Model defines the interface for forecasting models.
@@ -70,3 +86,42 @@ type Model interface {
 	// Used for logging, metrics, and model selection.
 	Name() string
 }
+
+// Stateful is implemented by models whose trained state can be persisted and
+// restored, so that a restarted forecaster can predict before retraining.
+//
+// The state format is private to each model. UnmarshalState returns an error
+// for state that is corrupt or was produced by an incompatible configuration
+// (e.g., different fixed orders or step); the model is left unchanged then.
+type Stateful interface {
+	// MarshalState encodes the trained state.
+	// Returns an error if the model has not been trained.
+	MarshalState() ([]byte, error)
+
+	// UnmarshalState restores state produced by MarshalState.
+	UnmarshalState(data []byte) error
+}
+
+// IncrementalModel is implemented by models that can absorb new observations
+// without retraining on the full history.
+//
+// Update must only be called after a successful Train (or a restore through
+// Stateful) with rows that directly follow the data the model has already
+// seen, in time order. Callers should still retrain periodically: Update
+// advances the model's state but does not re-estimate all of its parameters.
+type IncrementalModel interface {
+	Model
+
+	// Update incorporates newRows into the trained state in place.
+	// Returns an error if the model cannot be updated, in which case the
+	// caller should fall back to Train.
+	Update(ctx context.Context, newRows FeatureFrame) error
+}
+
+// OrderSearcher is implemented by models that choose their structure, such as
+// ARIMA orders, from the data and reuse it across Train calls.
+type OrderSearcher interface {
+	// ResetOrderSearch makes the next Train call choose the structure again,
+	// e.g. after a change point in the series.
+	ResetOrderSearch()
+}
@@ -0,0 +1,501 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	secondsPerDay  = 86400.0
+	secondsPerWeek = 7 * secondsPerDay
+)
+
+// ProphetModel implements an additive decomposition model in the style of Prophet:
+//
+//	y(t) = trend(t) + daily(t) + weekly(t) + events(t) + ε
+//
+// Components:
+//   - Trend: piecewise-linear with automatic changepoints spread uniformly over
+//     the first ChangepointRange of the history. Slope changes are L2-penalised,
+//     so changepoints that are not supported by the data shrink towards zero.
+//   - Daily and weekly seasonality: Fourier series of configurable order,
+//     aligned to wall-clock time (UTC) through the "timestamp" feature.
+//   - Events: one indicator regressor per event name (holidays, launches,
+//     campaigns), active while a timestamp falls inside one of its windows.
+//
+// All components are fitted jointly by regularised (ridge) least squares on a
+// scaled copy of the series. Forecasts extrapolate the final trend slope and
+// evaluate the seasonal and event terms at the future timestamps.
+//
+// Decompose exposes the per-component contribution of each forecast step so
+// operators can see why a spike is predicted.
+//
+// The model is thread-safe for concurrent Predict calls after training.
+type ProphetModel struct {
+	metric  string
+	stepSec int
+	horizon int
+	opts    ProphetOptions
+
+	mu      sync.RWMutex
+	trained bool
+	fit     prophetFit
+}
+
+// ProphetOptions configures a ProphetModel. Zero values select the defaults
+// documented on each field.
+type ProphetOptions struct {
+	// Changepoints is the number of potential trend changepoints (default 10).
+	// Negative disables changepoints (single linear trend).
+	Changepoints int
+
+	// ChangepointRange is the fraction of the history in which changepoints are
+	// placed (default 0.8), leaving the most recent data to define the final slope.
+	ChangepointRange float64
+
+	// ChangepointPenalty is the L2 penalty on slope changes (default 0.001).
+	// Higher values give a stiffer trend.
+	ChangepointPenalty float64
+
+	// SeasonalityPenalty is the L2 penalty on Fourier coefficients (default 0.001).
+	SeasonalityPenalty float64
+
+	// EventPenalty is the L2 penalty on event effects (default 0.001).
+	EventPenalty float64
+
+	// DailyOrder is the Fourier order of the daily seasonality. 0 selects 4 when
+	// the history spans at least two days, negative disables it.
+	DailyOrder int
+
+	// WeeklyOrder is the Fourier order of the weekly seasonality. 0 selects 3
+	// when the history spans at least two weeks, negative disables it.
+	WeeklyOrder int
+
+	// Events lists holiday and event windows. Events sharing a Name share one
+	// regressor, so recurring events (every Black Friday) learn a single effect.
+	Events []Event
+}
+
+// Event marks a time window with a known effect on the metric, such as a
+// holiday, product launch or marketing campaign.
+type Event struct {
+	// Name identifies the regressor; windows with the same name share an effect.
+	Name string
+
+	// Start is the inclusive start of the window.
+	Start time.Time
+
+	// End is the exclusive end of the window.
+	End time.Time
+}
+
+// active reports whether the Unix timestamp ts falls inside the event window.
+func (e Event) active(ts float64) bool {
+	return ts >= float64(e.Start.Unix()) && ts < float64(e.End.Unix())
+}
+
+// Decomposition breaks a forecast into additive components. Each slice has one
+// entry per forecast step; summing the components (before clamping to zero)
+// yields Forecast.Values.
+type Decomposition struct {
+	// Timestamps holds the Unix timestamp (seconds) of each forecast step.
+	Timestamps []int64
+
+	// Trend is the piecewise-linear trend component.
+	Trend []float64
+
+	// Daily is the daily seasonal component (zero when disabled).
+	Daily []float64
+
+	// Weekly is the weekly seasonal component (zero when disabled).
+	Weekly []float64
+
+	// Events holds the contribution of each event regressor, keyed by event name.
+	Events map[string][]float64
+}
+
+// prophetFit holds the fitted state of a ProphetModel.
+type prophetFit struct {
+	t0           float64   // first training timestamp (seconds)
+	span         float64   // training time span (seconds), used to scale time to [0,1]
+	scale        float64   // divisor applied to the target
+	changepoints []float64 // changepoint locations in scaled time
+	dailyOrder   int
+	weeklyOrder  int
+	eventNames   []string
+	coeffs       []float64
+	lastTs       float64   // last training timestamp (seconds)
+	residuals    []float64 // in-sample errors (original units), for quantiles
+}
+
+// NewProphetModel creates a new Prophet-style model.
+func NewProphetModel(metric string, stepSec, horizon int, opts ProphetOptions) *ProphetModel {
+	if opts.Changepoints == 0 {
+		opts.Changepoints = 10
+	}
+	if opts.ChangepointRange <= 0 || opts.ChangepointRange > 1 {
+		opts.ChangepointRange = 0.8
+	}
+	if opts.ChangepointPenalty <= 0 {
+		opts.ChangepointPenalty = 0.001
+	}
+	if opts.SeasonalityPenalty <= 0 {
+		opts.SeasonalityPenalty = 0.001
+	}
+	if opts.EventPenalty <= 0 {
+		opts.EventPenalty = 0.001
+	}
+
+	return &ProphetModel{
+		metric:  metric,
+		stepSec: stepSec,
+		horizon: horizon,
+		opts:    opts,
+	}
+}
+
+// Name returns the model identifier.
+func (m *ProphetModel) Name() string {
+	return "prophet"
+}
+
+// Train fits trend, seasonality and event coefficients to the history.
+//
+// Required features:
+//   - "value": the metric value
+//   - "timestamp": Unix timestamp in seconds (recommended; when missing, rows
+//     are assumed to be stepSec apart and seasonality is not clock-aligned)
+//
+// Returns an error if fewer than 10 rows carry a value or if the fit fails.
+func (m *ProphetModel) Train(ctx context.Context, history FeatureFrame) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	ts, values := m.series(history)
+	if len(values) < 10 {
+		return fmt.Errorf("need at least 10 points for prophet, got %d", len(values))
+	}
+
+	fit := prophetFit{
+		t0:     ts[0],
+		span:   ts[len(ts)-1] - ts[0],
+		lastTs: ts[len(ts)-1],
+	}
+	if fit.span <= 0 {
+		fit.span = 1
+	}
+
+	for _, v := range values {
+		fit.scale = math.Max(fit.scale, math.Abs(v))
+	}
+	if fit.scale == 0 {
+		fit.scale = 1
+	}
+
+	if m.opts.Changepoints > 0 {
+		n := m.opts.Changepoints
+		fit.changepoints = make([]float64, n)
+		for j := range n {
+			fit.changepoints[j] = m.opts.ChangepointRange * float64(j+1) / float64(n+1)
+		}
+	}
+
+	fit.dailyOrder = seasonalOrder(m.opts.DailyOrder, 4, fit.span, 2*secondsPerDay)
+	fit.weeklyOrder = seasonalOrder(m.opts.WeeklyOrder, 3, fit.span, 2*secondsPerWeek)
+
+	for _, e := range m.opts.Events {
+		if !slices.Contains(fit.eventNames, e.Name) {
+			fit.eventNames = append(fit.eventNames, e.Name)
+		}
+	}
+
+	X := make([][]float64, len(values))
+	y := make([]float64, len(values))
+	for i := range values {
+		X[i] = m.designRow(&fit, ts[i])
+		y[i] = values[i] / fit.scale
+	}
+
+	coeffs, err := ridgeSolve(X, y, m.penalties(&fit, len(values)))
+	if err != nil {
+		return fmt.Errorf("failed to fit prophet components: %w", err)
+	}
+	fit.coeffs = coeffs
+
+	fit.residuals = make([]float64, len(values))
+	for i, row := range X {
+		fitted := 0.0
+		for j, x := range row {
+			fitted += x * coeffs[j]
+		}
+		fit.residuals[i] = values[i] - fitted*fit.scale
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.fit = fit
+	m.trained = true
+
+	return nil
+}
+
+// Predict forecasts the configured horizon from the end of the training data,
+// or from the last timestamp in features when it is later.
+//
+// Quantiles are derived from the in-sample residuals and are the same width
+// at every step; they do not account for trend uncertainty. Components holds
+// the trend, the seasonal effect and each event's effect, which add up to
+// Values before clamping.
+//
+// Returns an error if the model has not been trained.
+func (m *ProphetModel) Predict(ctx context.Context, features FeatureFrame) (Forecast, error) {
+	dec, err := m.Decompose(ctx, features)
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	values := make([]float64, len(dec.Trend))
+	for i := range values {
+		v := dec.Trend[i] + dec.Daily[i] + dec.Weekly[i]
+		for _, effect := range dec.Events {
+			v += effect[i]
+		}
+		if v < 0 || math.IsNaN(v) {
+			v = 0
+		}
+		values[i] = v
+	}
+
+	m.mu.RLock()
+	residuals := [][]float64{m.fit.residuals}
+	m.mu.RUnlock()
+
+	return Forecast{
+		Metric:     m.metric,
+		Values:     values,
+		StepSec:    m.stepSec,
+		Horizon:    m.horizon,
+		Quantiles:  empiricalQuantiles(values, residuals),
+		Components: dec.components(),
+	}, nil
+}
+
+// components returns the decomposition as Forecast.Components: the trend, the
+// daily and weekly seasonality combined, and the effect of each event.
+func (d Decomposition) components() map[string][]float64 {
+	seasonal := make([]float64, len(d.Daily))
+	for i := range seasonal {
+		seasonal[i] = d.Daily[i] + d.Weekly[i]
+	}
+	out := map[string][]float64{
+		ComponentTrend:    d.Trend,
+		ComponentSeasonal: seasonal,
+	}
+	for name, effect := range d.Events {
+		out[EventComponent(name)] = effect
+	}
+	return out
+}
+
+// Decompose returns the additive components of the forecast for each future
+// step, in the metric's original units.
+//
+// Returns an error if the context is cancelled or the model has not been trained.
+func (m *ProphetModel) Decompose(ctx context.Context, features FeatureFrame) (Decomposition, error) {
+	if ctx.Err() != nil {
+		return Decomposition{}, ctx.Err()
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.trained {
+		return Decomposition{}, errors.New("model not trained, call Train() first")
+	}
+	fit := &m.fit
+
+	origin := fit.lastTs
+	if len(features.Rows) > 0 {
+		if ts, ok := features.Rows[len(features.Rows)-1]["timestamp"]; ok && ts > origin {
+			origin = ts
+		}
+	}
+
+	nSteps := m.horizon / m.stepSec
+	if nSteps <= 0 {
+		nSteps = 1
+	}
+
+	dec := Decomposition{
+		Timestamps: make([]int64, nSteps),
+		Trend:      make([]float64, nSteps),
+		Daily:      make([]float64, nSteps),
+		Weekly:     make([]float64, nSteps),
+		Events:     make(map[string][]float64, len(fit.eventNames)),
+	}
+	for _, name := range fit.eventNames {
+		dec.Events[name] = make([]float64, nSteps)
+	}
+
+	nTrend := 2 + len(fit.changepoints)
+	dailyEnd := nTrend + 2*fit.dailyOrder
+	weeklyEnd := dailyEnd + 2*fit.weeklyOrder
+
+	for i := range nSteps {
+		ts := origin + float64((i+1)*m.stepSec)
+		row := m.designRow(fit, ts)
+		dec.Timestamps[i] = int64(ts)
+
+		for j, x := range row {
+			contrib := x * fit.coeffs[j] * fit.scale
+			switch {
+			case j < nTrend:
+				dec.Trend[i] += contrib
+			case j < dailyEnd:
+				dec.Daily[i] += contrib
+			case j < weeklyEnd:
+				dec.Weekly[i] += contrib
+			default:
+				dec.Events[fit.eventNames[j-weeklyEnd]][i] += contrib
+			}
+		}
+	}
+
+	return dec, nil
+}
+
+// series extracts timestamps and values from the frame. Rows without a value
+// are skipped; missing timestamps are synthesised stepSec apart.
+func (m *ProphetModel) series(frame FeatureFrame) ([]float64, []float64) {
+	ts := make([]float64, 0, len(frame.Rows))
+	values := make([]float64, 0, len(frame.Rows))
+	for i, row := range frame.Rows {
+		v, ok := row["value"]
+		if !ok {
+			continue
+		}
+		t, ok := row["timestamp"]
+		if !ok {
+			t = float64(i * m.stepSec)
+		}
+		ts = append(ts, t)
+		values = append(values, v)
+	}
+	return ts, values
+}
+
+// designRow builds the regression features for one timestamp:
+// [1, t, (t-c1)+, ..., (t-cJ)+, daily sin/cos..., weekly sin/cos..., events...]
+func (m *ProphetModel) designRow(fit *prophetFit, ts float64) []float64 {
+	t := (ts - fit.t0) / fit.span
+
+	row := make([]float64, 0, 2+len(fit.changepoints)+2*fit.dailyOrder+2*fit.weeklyOrder+len(fit.eventNames))
+	row = append(row, 1, t)
+	for _, c := range fit.changepoints {
+		row = append(row, math.Max(0, t-c))
+	}
+	row = appendFourier(row, ts, secondsPerDay, fit.dailyOrder)
+	row = appendFourier(row, ts, secondsPerWeek, fit.weeklyOrder)
+	for _, name := range fit.eventNames {
+		active := 0.0
+		for _, e := range m.opts.Events {
+			if e.Name == name && e.active(ts) {
+				active = 1
+				break
+			}
+		}
+		row = append(row, active)
+	}
+	return row
+}
+
+// penalties returns the per-column ridge penalties matching designRow.
+// Penalties are scaled by n so that defaults do not depend on the window size.
+func (m *ProphetModel) penalties(fit *prophetFit, n int) []float64 {
+	scale := float64(n)
+	p := make([]float64, 0, 2+len(fit.changepoints)+2*fit.dailyOrder+2*fit.weeklyOrder+len(fit.eventNames))
+	p = append(p, 0, 0)
+	for range fit.changepoints {
+		p = append(p, m.opts.ChangepointPenalty*scale)
+	}
+	for range 2 * (fit.dailyOrder + fit.weeklyOrder) {
+		p = append(p, m.opts.SeasonalityPenalty*scale)
+	}
+	for range fit.eventNames {
+		p = append(p, m.opts.EventPenalty*scale)
+	}
+	return p
+}
+
+// appendFourier appends sin/cos pairs of the given order for a period in seconds.
+func appendFourier(row []float64, ts, period float64, order int) []float64 {
+	for k := 1; k <= order; k++ {
+		angle := 2 * math.Pi * float64(k) * math.Mod(ts, period) / period
+		row = append(row, math.Sin(angle), math.Cos(angle))
+	}
+	return row
+}
+
+// seasonalOrder resolves a configured Fourier order: negative disables the
+// component, positive forces it, 0 enables the default when the history
+// covers at least minSpan seconds.
+func seasonalOrder(configured, def int, span, minSpan float64) int {
+	switch {
+	case configured < 0:
+		return 0
+	case configured > 0:
+		return configured
+	case span >= minSpan:
+		return def
+	default:
+		return 0
+	}
+}
+
+// ParseEvents parses a comma-separated list of events in the form
+// "name=start/duration", where start is RFC3339 and duration is a Go duration.
+//
+// Example:
+//
+//	black-friday=2025-11-28T00:00:00Z/24h,launch=2026-01-10T09:00:00Z/2h
+func ParseEvents(spec string) ([]Event, error) {
+	var events []Event
+	for item := range strings.SplitSeq(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, window, ok := strings.Cut(item, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid event %q: want name=start/duration", item)
+		}
+		startRaw, durRaw, ok := strings.Cut(window, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid event %q: want name=start/duration", item)
+		}
+
+		start, err := time.Parse(time.RFC3339, startRaw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid event %q start: %w", name, err)
+		}
+		dur, err := time.ParseDuration(durRaw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid event %q duration: %w", name, err)
+		}
+		if dur <= 0 {
+			return nil, fmt.Errorf("invalid event %q duration: must be positive", name)
+		}
+
+		events = append(events, Event{Name: name, Start: start, End: start.Add(dur)})
+	}
+	return events, nil
+}
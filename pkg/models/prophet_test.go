@@ -0,0 +1,202 @@
+package models
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+// syntheticDaily generates n rows at stepSec spacing starting at start with a
+// daily sine cycle of the given amplitude on top of a linear trend.
+func syntheticDaily(start time.Time, n, stepSec int, base, slopePerStep, amplitude float64) FeatureFrame {
+	rows := make([]map[string]float64, n)
+	for i := range n {
+		ts := start.Add(time.Duration(i*stepSec) * time.Second)
+		rows[i] = map[string]float64{
+			"timestamp": float64(ts.Unix()),
+			"value":     base + slopePerStep*float64(i) + amplitude*dailyShape(ts),
+		}
+	}
+	return FeatureFrame{Rows: rows}
+}
+
+func dailyShape(ts time.Time) float64 {
+	secs := float64(ts.Unix() % 86400)
+	return math.Sin(2 * math.Pi * secs / 86400)
+}
+
+func TestProphetModel_Name(t *testing.T) {
+	model := NewProphetModel("test_metric", 300, 3600, ProphetOptions{})
+	if model.Name() != "prophet" {
+		t.Errorf("Name() = %q, want %q", model.Name(), "prophet")
+	}
+}
+
+func TestProphetModel_Predict_NotTrained(t *testing.T) {
+	model := NewProphetModel("test_metric", 300, 3600, ProphetOptions{})
+
+	_, err := model.Predict(context.Background(), FeatureFrame{})
+	if err == nil || !contains(err.Error(), "not trained") {
+		t.Errorf("Predict() error = %v, want 'not trained'", err)
+	}
+}
+
+func TestProphetModel_Train_InsufficientData(t *testing.T) {
+	model := NewProphetModel("test_metric", 300, 3600, ProphetOptions{})
+	history := syntheticDaily(time.Unix(0, 0).UTC(), 5, 300, 100, 0, 10)
+
+	if err := model.Train(context.Background(), history); err == nil {
+		t.Error("Train() error = nil, want error for insufficient data")
+	}
+}
+
+func TestProphetModel_Predict_DailySeasonality(t *testing.T) {
+	const stepSec = 600
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	n := 4 * 86400 / stepSec // four days
+	history := syntheticDaily(start, n, stepSec, 200, 0, 50)
+
+	model := NewProphetModel("test_metric", stepSec, 6*3600, ProphetOptions{})
+	if err := model.Train(context.Background(), history); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+
+	forecast, err := model.Predict(context.Background(), history)
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+
+	if len(forecast.Values) != 36 {
+		t.Fatalf("len(forecast.Values) = %d, want 36", len(forecast.Values))
+	}
+
+	lastTs := start.Add(time.Duration((n-1)*stepSec) * time.Second)
+	for h, got := range forecast.Values {
+		ts := lastTs.Add(time.Duration((h+1)*stepSec) * time.Second)
+		want := 200 + 50*dailyShape(ts)
+		if math.Abs(got-want) > 5 {
+			t.Errorf("forecast.Values[%d] = %.2f, want ~%.2f", h, got, want)
+		}
+	}
+}
+
+func TestProphetModel_Predict_TrendChangepoint(t *testing.T) {
+	const stepSec = 60
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	rows := make([]map[string]float64, 300)
+	for i := range rows {
+		v := 100.0
+		if i >= 150 {
+			v += 2 * float64(i-150) // slope changes half way through
+		}
+		rows[i] = map[string]float64{
+			"timestamp": float64(start.Add(time.Duration(i*stepSec) * time.Second).Unix()),
+			"value":     v,
+		}
+	}
+
+	model := NewProphetModel("test_metric", stepSec, 600, ProphetOptions{})
+	if err := model.Train(context.Background(), FeatureFrame{Rows: rows}); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+
+	forecast, err := model.Predict(context.Background(), FeatureFrame{})
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+
+	// The final slope (2 per step) should be extrapolated.
+	for h, got := range forecast.Values {
+		want := 100 + 2*float64(299-150+h+1)
+		if math.Abs(got-want) > 0.05*want {
+			t.Errorf("forecast.Values[%d] = %.2f, want ~%.2f", h, got, want)
+		}
+	}
+}
+
+func TestProphetModel_Decompose_Events(t *testing.T) {
+	const stepSec = 300
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	n := 2 * 86400 / stepSec
+
+	// Campaign windows: two past occurrences and one upcoming.
+	events := []Event{
+		{Name: "campaign", Start: start.Add(6 * time.Hour), End: start.Add(8 * time.Hour)},
+		{Name: "campaign", Start: start.Add(30 * time.Hour), End: start.Add(32 * time.Hour)},
+		{Name: "campaign", Start: start.Add(48*time.Hour + 30*time.Minute), End: start.Add(49 * time.Hour)},
+	}
+
+	rows := make([]map[string]float64, n)
+	for i := range rows {
+		ts := start.Add(time.Duration(i*stepSec) * time.Second)
+		v := 100.0
+		for _, e := range events {
+			if e.active(float64(ts.Unix())) {
+				v += 80
+			}
+		}
+		rows[i] = map[string]float64{"timestamp": float64(ts.Unix()), "value": v}
+	}
+
+	model := NewProphetModel("test_metric", stepSec, 3600, ProphetOptions{DailyOrder: -1, Events: events})
+	if err := model.Train(context.Background(), FeatureFrame{Rows: rows}); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+
+	dec, err := model.Decompose(context.Background(), FeatureFrame{})
+	if err != nil {
+		t.Fatalf("Decompose() error = %v", err)
+	}
+	forecast, err := model.Predict(context.Background(), FeatureFrame{})
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+
+	effect, ok := dec.Events["campaign"]
+	if !ok {
+		t.Fatal("Decompose() missing campaign component")
+	}
+
+	for i, ts := range dec.Timestamps {
+		active := events[2].active(float64(ts))
+		if active && math.Abs(effect[i]-80) > 10 {
+			t.Errorf("campaign effect at step %d = %.2f, want ~80", i, effect[i])
+		}
+		if !active && effect[i] != 0 {
+			t.Errorf("campaign effect at step %d = %.2f, want 0 outside window", i, effect[i])
+		}
+
+		sum := dec.Trend[i] + dec.Daily[i] + dec.Weekly[i] + effect[i]
+		if math.Abs(math.Max(sum, 0)-forecast.Values[i]) > 1e-9 {
+			t.Errorf("components at step %d sum to %.4f, forecast is %.4f", i, sum, forecast.Values[i])
+		}
+
+		c := forecast.Components
+		if got := c[ComponentTrend][i] + c[ComponentSeasonal][i] + c[EventComponent("campaign")][i]; math.Abs(got-sum) > 1e-9 {
+			t.Errorf("Components at step %d sum to %.4f, want %.4f", i, got, sum)
+		}
+	}
+}
+
+func TestParseEvents(t *testing.T) {
+	events, err := ParseEvents("black-friday=2025-11-28T00:00:00Z/24h, launch=2026-01-10T09:00:00Z/2h")
+	if err != nil {
+		t.Fatalf("ParseEvents() error = %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("len(events) = %d, want 2", len(events))
+	}
+	if events[0].Name != "black-friday" || events[0].End.Sub(events[0].Start) != 24*time.Hour {
+		t.Errorf("events[0] = %+v, want black-friday lasting 24h", events[0])
+	}
+	if events[1].Name != "launch" || events[1].Start.Hour() != 9 {
+		t.Errorf("events[1] = %+v, want launch at 09:00", events[1])
+	}
+
+	for _, bad := range []string{"nameonly", "x=2025-01-01T00:00:00Z", "x=yesterday/1h", "x=2025-01-01T00:00:00Z/-1h"} {
+		if _, err := ParseEvents(bad); err == nil {
+			t.Errorf("ParseEvents(%q) error = nil, want error", bad)
+		}
+	}
+}
@@ -0,0 +1,528 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sync"
+)
+
+// RegressionStrategy selects how a RegressionModel produces multi-step forecasts.
+type RegressionStrategy string
+
+const (
+	// RegressionDirect fits one model per horizon step, each predicting the
+	// value h steps ahead from what is known at the forecast origin.
+	RegressionDirect RegressionStrategy = "direct"
+
+	// RegressionRecursive fits a single one-step model and feeds its own
+	// predictions back as lags to reach the horizon.
+	RegressionRecursive RegressionStrategy = "recursive"
+)
+
+// RegressionOptions configures a RegressionModel. Zero values select the
+// defaults documented on each field.
+type RegressionOptions struct {
+	// Lags is the number of lagged values used as regressors (default 6).
+	// Negative disables lags.
+	Lags int
+
+	// Features lists the feature columns used as regressors, read at the
+	// forecast origin. Empty selects every numeric column present in all
+	// training rows except "value" and "timestamp".
+	Features []string
+
+	// DailyOrder is the Fourier order of the daily terms evaluated at the
+	// target time. 0 selects 3 when the history spans at least two days,
+	// negative disables them.
+	DailyOrder int
+
+	// WeeklyOrder is the Fourier order of the weekly terms evaluated at the
+	// target time. 0 selects 2 when the history spans at least two weeks,
+	// negative disables them.
+	WeeklyOrder int
+
+	// Events adds one indicator per event name, active when the target time
+	// falls inside one of its windows.
+	Events []Event
+
+	// Alpha is the regularisation strength on standardised regressors
+	// (default 0.1).
+	Alpha float64
+
+	// L1Ratio mixes L1 into the penalty: 0 is ridge, 1 is lasso and values in
+	// between are elastic net. Clamped to [0, 1].
+	L1Ratio float64
+
+	// Strategy selects direct (default) or recursive multi-step forecasting.
+	Strategy RegressionStrategy
+}
+
+// RegressionModel forecasts with regularised linear regression over lagged
+// values, arbitrary feature columns and calendar terms:
+//
+//	y(t+h) = β₀ + Σ βᵢ·y(t-i) + Σ γⱼ·xⱼ(t) + Σ δₖ·calendarₖ(t+h) + ε
+//
+// Regressors:
+//   - Lags: the last Lags values before the forecast origin.
+//   - Features: any numeric feature column (e.g. derived metrics or flags),
+//     taken at the origin because future values are not known.
+//   - Calendar terms at the target time: daily and weekly Fourier series, a
+//     weekend flag (UTC) and one indicator per event, all derived from the
+//     "timestamp" feature.
+//
+// Every regressor is standardised inside the model and the coefficients are
+// estimated with an elastic-net penalty (ridge by default), so features on very
+// different scales can be mixed freely.
+//
+// With the direct strategy one model is fitted per horizon step; with the
+// recursive strategy a one-step model is iterated, holding feature columns at
+// their last observed value. Quantiles come from the training residuals of
+// each step (direct) or a rolling-origin backtest (recursive).
+//
+// The model is thread-safe for concurrent Predict calls after training.
+type RegressionModel struct {
+	metric  string
+	stepSec int
+	horizon int
+	opts    RegressionOptions
+
+	mu      sync.RWMutex
+	trained bool
+	fit     regressionFit
+}
+
+// regressionFit holds the fitted state of a RegressionModel.
+type regressionFit struct {
+	features    []string
+	hasTime     bool // training rows carried timestamps
+	dailyOrder  int
+	weeklyOrder int
+	eventNames  []string
+	steps       []linearFit // one per horizon step (direct) or a single one-step fit
+	residuals   [][]float64 // per-step errors for quantiles
+}
+
+// linearFit is a standardised linear model: ŷ = intercept + Σ coef·(x-mean)/scale.
+type linearFit struct {
+	mean      []float64
+	scale     []float64
+	coef      []float64
+	intercept float64
+}
+
+// predict evaluates the fit on one regressor row.
+func (f linearFit) predict(x []float64) float64 {
+	y := f.intercept
+	for j, v := range x {
+		y += f.coef[j] * (v - f.mean[j]) / f.scale[j]
+	}
+	return y
+}
+
+// NewRegressionModel creates a new regularised regression model.
+func NewRegressionModel(metric string, stepSec, horizon int, opts RegressionOptions) *RegressionModel {
+	if opts.Lags == 0 {
+		opts.Lags = 6
+	}
+	if opts.Lags < 0 {
+		opts.Lags = 0
+	}
+	if opts.Alpha <= 0 {
+		opts.Alpha = 0.1
+	}
+	opts.L1Ratio = math.Min(math.Max(opts.L1Ratio, 0), 1)
+	if opts.Strategy == "" {
+		opts.Strategy = RegressionDirect
+	}
+
+	return &RegressionModel{
+		metric:  metric,
+		stepSec: stepSec,
+		horizon: horizon,
+		opts:    opts,
+	}
+}
+
+// Name returns the model identifier.
+func (m *RegressionModel) Name() string {
+	return "regression"
+}
+
+// numSteps returns the number of forecast steps over the horizon.
+func (m *RegressionModel) numSteps() int {
+	return max(m.horizon/m.stepSec, 1)
+}
+
+// Train fits the regression coefficients on the history.
+//
+// Required features:
+//   - "value": the metric value
+//   - "timestamp": Unix timestamp in seconds (needed for calendar terms and events)
+//   - any columns named in Features
+//
+// Returns an error if the history is too short for the lags and horizon, if a
+// configured feature is missing, or if the fit fails.
+func (m *RegressionModel) Train(ctx context.Context, history FeatureFrame) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	rows := make([]map[string]float64, 0, len(history.Rows))
+	for _, row := range history.Rows {
+		if _, ok := row["value"]; ok {
+			rows = append(rows, row)
+		}
+	}
+
+	nSteps := m.numSteps()
+	horizonFits := nSteps
+	if m.opts.Strategy == RegressionRecursive {
+		horizonFits = 1
+	}
+	if minRows := m.opts.Lags + horizonFits + 10; len(rows) < minRows {
+		return fmt.Errorf("need at least %d points for regression, got %d", minRows, len(rows))
+	}
+
+	fit := regressionFit{features: m.opts.Features, hasTime: true}
+	if len(fit.features) == 0 {
+		fit.features = discoverFeatures(rows)
+	}
+	for i, row := range rows {
+		if _, ok := row["timestamp"]; !ok {
+			fit.hasTime = false
+		}
+		for _, name := range fit.features {
+			if _, ok := row[name]; !ok {
+				return fmt.Errorf("row %d missing feature %q", i, name)
+			}
+		}
+	}
+	if fit.hasTime {
+		span := rows[len(rows)-1]["timestamp"] - rows[0]["timestamp"]
+		fit.dailyOrder = seasonalOrder(m.opts.DailyOrder, 3, span, 2*secondsPerDay)
+		fit.weeklyOrder = seasonalOrder(m.opts.WeeklyOrder, 2, span, 2*secondsPerWeek)
+		for _, e := range m.opts.Events {
+			if !slices.Contains(fit.eventNames, e.Name) {
+				fit.eventNames = append(fit.eventNames, e.Name)
+			}
+		}
+	}
+
+	values := make([]float64, len(rows))
+	for i, row := range rows {
+		values[i] = row["value"]
+	}
+
+	fit.steps = make([]linearFit, horizonFits)
+	fit.residuals = make([][]float64, horizonFits)
+	for h := 1; h <= horizonFits; h++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var X [][]float64
+		var y []float64
+		for t := max(m.opts.Lags-1, 0); t+h < len(rows); t++ {
+			X = append(X, m.regressors(&fit, values[:t+1], rows[t], h))
+			y = append(y, values[t+h])
+		}
+
+		lf, err := fitElasticNet(X, y, m.opts.Alpha, m.opts.L1Ratio)
+		if err != nil {
+			return fmt.Errorf("failed to fit regression for step %d: %w", h, err)
+		}
+		fit.steps[h-1] = lf
+
+		residuals := make([]float64, len(y))
+		for i, x := range X {
+			residuals[i] = y[i] - lf.predict(x)
+		}
+		fit.residuals[h-1] = residuals
+	}
+
+	m.mu.Lock()
+	m.fit = fit
+	m.trained = true
+	m.mu.Unlock()
+
+	if m.opts.Strategy == RegressionRecursive {
+		residuals := backtestResiduals(history, nSteps, m.opts.Lags+1, regressionBacktestOrigins, m.predictValues)
+		m.mu.Lock()
+		m.fit.residuals = residuals
+		m.mu.Unlock()
+	}
+
+	return nil
+}
+
+// regressionBacktestOrigins is the number of rolling origins per horizon step
+// used to estimate recursive forecast errors.
+const regressionBacktestOrigins = 24
+
+// Predict forecasts the horizon from the last row of features, which must
+// contain at least Lags values and the trained feature columns.
+//
+// Returns an error if the model has not been trained or features are incomplete.
+func (m *RegressionModel) Predict(ctx context.Context, features FeatureFrame) (Forecast, error) {
+	if ctx.Err() != nil {
+		return Forecast{}, ctx.Err()
+	}
+
+	values, err := m.predictValues(features)
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	m.mu.RLock()
+	residuals := m.fit.residuals
+	m.mu.RUnlock()
+
+	return Forecast{
+		Metric:    m.metric,
+		Values:    values,
+		StepSec:   m.stepSec,
+		Horizon:   m.horizon,
+		Quantiles: empiricalQuantiles(values, residuals),
+	}, nil
+}
+
+// predictValues computes the point forecast for Predict.
+func (m *RegressionModel) predictValues(features FeatureFrame) ([]float64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.trained {
+		return nil, errors.New("model not trained, call Train() first")
+	}
+
+	var values []float64
+	var origin map[string]float64
+	for _, row := range features.Rows {
+		if v, ok := row["value"]; ok {
+			values = append(values, v)
+			origin = row
+		}
+	}
+	if len(values) < max(m.opts.Lags, 1) {
+		return nil, fmt.Errorf("need at least %d values in features, got %d", max(m.opts.Lags, 1), len(values))
+	}
+	for _, name := range m.fit.features {
+		if _, ok := origin[name]; !ok {
+			return nil, fmt.Errorf("features missing %q", name)
+		}
+	}
+	if _, ok := origin["timestamp"]; !ok && m.fit.hasTime {
+		return nil, errors.New("features missing \"timestamp\"")
+	}
+
+	nSteps := m.numSteps()
+	out := make([]float64, nSteps)
+
+	if m.opts.Strategy == RegressionRecursive {
+		history := slices.Clone(values)
+		row := maps.Clone(origin)
+		for h := range nSteps {
+			y := m.fit.steps[0].predict(m.regressors(&m.fit, history, row, 1))
+			history = append(history, y)
+			row["timestamp"] += float64(m.stepSec)
+			out[h] = y
+		}
+	} else {
+		for h := range nSteps {
+			out[h] = m.fit.steps[h].predict(m.regressors(&m.fit, values, origin, h+1))
+		}
+	}
+
+	for i, v := range out {
+		if v < 0 || math.IsNaN(v) {
+			out[i] = 0
+		} else if v > maxQuantileValue {
+			out[i] = maxQuantileValue
+		}
+	}
+	return out, nil
+}
+
+// regressors builds the regressor row for predicting h steps after the last
+// of values, where origin is the feature row at that point:
+// [lags..., features..., daily sin/cos..., weekly sin/cos..., weekend, events...]
+func (m *RegressionModel) regressors(fit *regressionFit, values []float64, origin map[string]float64, h int) []float64 {
+	row := make([]float64, 0, m.opts.Lags+len(fit.features)+2*fit.dailyOrder+2*fit.weeklyOrder+1+len(fit.eventNames))
+	for i := range m.opts.Lags {
+		row = append(row, values[len(values)-1-i])
+	}
+	for _, name := range fit.features {
+		row = append(row, origin[name])
+	}
+	if !fit.hasTime {
+		return row
+	}
+
+	ts := origin["timestamp"] + float64(h*m.stepSec)
+	row = appendFourier(row, ts, secondsPerDay, fit.dailyOrder)
+	row = appendFourier(row, ts, secondsPerWeek, fit.weeklyOrder)
+
+	// The Unix epoch was a Thursday: days 2 and 3 of each week are Sat and Sun.
+	weekend := 0.0
+	if day := int(math.Mod(ts, secondsPerWeek) / secondsPerDay); day == 2 || day == 3 {
+		weekend = 1
+	}
+	row = append(row, weekend)
+
+	for _, name := range fit.eventNames {
+		active := 0.0
+		for _, e := range m.opts.Events {
+			if e.Name == name && e.active(ts) {
+				active = 1
+				break
+			}
+		}
+		row = append(row, active)
+	}
+	return row
+}
+
+// discoverFeatures returns, sorted, the columns present in every row other
+// than "value" and "timestamp".
+func discoverFeatures(rows []map[string]float64) []string {
+	if len(rows) == 0 {
+		return nil
+	}
+	var names []string
+	for name := range rows[0] {
+		if name == "value" || name == "timestamp" {
+			continue
+		}
+		inAll := true
+		for _, row := range rows[1:] {
+			if _, ok := row[name]; !ok {
+				inAll = false
+				break
+			}
+		}
+		if inAll {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// fitElasticNet fits y ≈ β₀ + Z·β on standardised regressors Z, minimising
+//
+//	(1/2n)·||y - β₀ - Z·β||² + alpha·(l1Ratio·||β||₁ + (1-l1Ratio)/2·||β||²)
+//
+// Pure ridge is solved in closed form; otherwise by cyclic coordinate descent.
+// Constant columns get a zero coefficient.
+func fitElasticNet(X [][]float64, y []float64, alpha, l1Ratio float64) (linearFit, error) {
+	n := len(X)
+	if n == 0 || n != len(y) {
+		return linearFit{}, errors.New("design matrix and target must have the same non-zero length")
+	}
+	k := len(X[0])
+
+	fit := linearFit{
+		mean:      make([]float64, k),
+		scale:     make([]float64, k),
+		coef:      make([]float64, k),
+		intercept: computeMean(y),
+	}
+	if k == 0 {
+		return fit, nil
+	}
+
+	for j := range k {
+		for _, row := range X {
+			fit.mean[j] += row[j]
+		}
+		fit.mean[j] /= float64(n)
+		for _, row := range X {
+			d := row[j] - fit.mean[j]
+			fit.scale[j] += d * d
+		}
+		fit.scale[j] = math.Sqrt(fit.scale[j] / float64(n))
+		if fit.scale[j] < 1e-12 {
+			fit.scale[j] = 1 // constant column: standardises to zero
+		}
+	}
+
+	Z := make([][]float64, n)
+	r := make([]float64, n)
+	for i, row := range X {
+		Z[i] = make([]float64, k)
+		for j, v := range row {
+			Z[i][j] = (v - fit.mean[j]) / fit.scale[j]
+		}
+		r[i] = y[i] - fit.intercept
+	}
+
+	if l1Ratio == 0 {
+		penalty := make([]float64, k)
+		for j := range penalty {
+			penalty[j] = float64(n) * alpha
+		}
+		coef, err := ridgeSolve(Z, r, penalty)
+		if err != nil {
+			return linearFit{}, err
+		}
+		fit.coef = coef
+		return fit, nil
+	}
+
+	// Coordinate descent: r holds the current residuals y - β₀ - Z·β.
+	sumSq := make([]float64, k)
+	for j := range k {
+		for i := range n {
+			sumSq[j] += Z[i][j] * Z[i][j]
+		}
+		sumSq[j] /= float64(n)
+	}
+	l1 := alpha * l1Ratio
+	l2 := alpha * (1 - l1Ratio)
+	for range elasticNetMaxSweeps {
+		maxDelta := 0.0
+		for j := range k {
+			if sumSq[j] == 0 {
+				continue
+			}
+			old := fit.coef[j]
+			rho := 0.0
+			for i := range n {
+				rho += Z[i][j] * (r[i] + Z[i][j]*old)
+			}
+			rho /= float64(n)
+
+			b := softThreshold(rho, l1) / (sumSq[j] + l2)
+			if delta := b - old; delta != 0 {
+				for i := range n {
+					r[i] -= Z[i][j] * delta
+				}
+				fit.coef[j] = b
+				maxDelta = math.Max(maxDelta, math.Abs(delta))
+			}
+		}
+		if maxDelta < 1e-8 {
+			break
+		}
+	}
+	return fit, nil
+}
+
+// elasticNetMaxSweeps bounds the coordinate-descent passes over all coefficients.
+const elasticNetMaxSweeps = 1000
+
+// softThreshold returns sign(x)·max(|x|-t, 0).
+func softThreshold(x, t float64) float64 {
+	switch {
+	case x > t:
+		return x - t
+	case x < -t:
+		return x + t
+	default:
+		return 0
+	}
+}
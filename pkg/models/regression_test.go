@@ -0,0 +1,168 @@
+package models
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+)
+
+// blockFrame returns rows where "value" is 100·scale plus noise and the
+// "scale" feature is constant over blocks of 40 rows.
+func blockFrame(n int, featureScale float64) FeatureFrame {
+	rng := rand.New(rand.NewPCG(7, 8))
+	rows := make([]map[string]float64, n)
+	for i := range n {
+		scale := float64(1 + (i/40)%3)
+		rows[i] = map[string]float64{
+			"timestamp": float64(i * 60),
+			"value":     100*scale + rng.NormFloat64(),
+			"scale":     scale * featureScale,
+		}
+	}
+	return FeatureFrame{Rows: rows}
+}
+
+func TestRegressionModel_LearnsSeasonalLags(t *testing.T) {
+	history := syntheticSeasonal(300, 24, 30, 0)
+	model := NewRegressionModel("m", 60, 600, RegressionOptions{Lags: 24})
+
+	ctx := context.Background()
+	if err := model.Train(ctx, FeatureFrame{Rows: history.Rows[:290]}); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	forecast, err := model.Predict(ctx, FeatureFrame{Rows: history.Rows[:290]})
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+
+	actual := make([]float64, 10)
+	for i := range actual {
+		actual[i] = history.Rows[290+i]["value"]
+	}
+	if mae := MAE(actual, forecast.Values); mae > 2 {
+		t.Errorf("MAE = %.2f, want < 2 on a noiseless sine", mae)
+	}
+}
+
+func TestRegressionModel_UsesFeatures(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy RegressionStrategy
+	}{
+		{name: "direct", strategy: RegressionDirect},
+		{name: "recursive", strategy: RegressionRecursive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := blockFrame(240, 1)
+			model := NewRegressionModel("m", 60, 180, RegressionOptions{Lags: -1, Strategy: tt.strategy})
+
+			ctx := context.Background()
+			if err := model.Train(ctx, history); err != nil {
+				t.Fatalf("Train() error = %v", err)
+			}
+
+			// The last block (rows 200-239) has scale 3.
+			forecast, err := model.Predict(ctx, FeatureFrame{Rows: history.Rows[:220]})
+			if err != nil {
+				t.Fatalf("Predict() error = %v", err)
+			}
+			for i, v := range forecast.Values {
+				if math.Abs(v-300) > 15 {
+					t.Errorf("Values[%d] = %.1f, want ~300 from the scale feature", i, v)
+				}
+			}
+			if len(forecast.Quantiles) == 0 {
+				t.Error("Quantiles empty, want residual-based quantiles")
+			}
+		})
+	}
+}
+
+func TestRegressionModel_StandardisesFeatures(t *testing.T) {
+	ctx := context.Background()
+	var forecasts [][]float64
+	for _, featureScale := range []float64{1, 1e6} {
+		history := blockFrame(240, featureScale)
+		model := NewRegressionModel("m", 60, 180, RegressionOptions{})
+		if err := model.Train(ctx, history); err != nil {
+			t.Fatalf("Train() error = %v", err)
+		}
+		forecast, err := model.Predict(ctx, history)
+		if err != nil {
+			t.Fatalf("Predict() error = %v", err)
+		}
+		forecasts = append(forecasts, forecast.Values)
+	}
+
+	for i := range forecasts[0] {
+		if math.Abs(forecasts[0][i]-forecasts[1][i]) > 1e-6 {
+			t.Errorf("Values[%d] = %v with scaled feature, want %v", i, forecasts[1][i], forecasts[0][i])
+		}
+	}
+}
+
+func TestRegressionModel_Errors(t *testing.T) {
+	ctx := context.Background()
+	model := NewRegressionModel("m", 60, 180, RegressionOptions{Features: []string{"scale"}})
+
+	if _, err := model.Predict(ctx, blockFrame(20, 1)); err == nil {
+		t.Error("Predict() error = nil, want error before training")
+	}
+	if err := model.Train(ctx, blockFrame(10, 1)); err == nil {
+		t.Error("Train() error = nil, want error for short history")
+	}
+	if err := model.Train(ctx, syntheticConstant(50, 1)); err == nil {
+		t.Error("Train() error = nil, want error for missing feature")
+	}
+
+	if err := model.Train(ctx, blockFrame(100, 1)); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	if _, err := model.Predict(ctx, syntheticConstant(10, 1)); err == nil {
+		t.Error("Predict() error = nil, want error for missing feature")
+	}
+}
+
+func TestFitElasticNet_LassoDropsIrrelevantFeature(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	X := make([][]float64, 200)
+	y := make([]float64, 200)
+	for i := range X {
+		x1, x2 := rng.NormFloat64(), rng.NormFloat64()
+		X[i] = []float64{x1, x2}
+		y[i] = 5 + 3*x1
+	}
+
+	fit, err := fitElasticNet(X, y, 0.1, 1)
+	if err != nil {
+		t.Fatalf("fitElasticNet() error = %v", err)
+	}
+	if fit.coef[1] != 0 {
+		t.Errorf("coef[1] = %v, want exactly 0 for the irrelevant feature", fit.coef[1])
+	}
+	if got := fit.predict([]float64{1, 0}); math.Abs(got-8) > 0.5 {
+		t.Errorf("predict(1, 0) = %.2f, want ~8 (lasso shrinks slightly)", got)
+	}
+
+	ridge, err := fitElasticNet(X, y, 1e-6, 0)
+	if err != nil {
+		t.Fatalf("fitElasticNet() error = %v", err)
+	}
+	if got := ridge.predict([]float64{1, 0}); math.Abs(got-8) > 1e-3 {
+		t.Errorf("ridge predict(1, 0) = %.4f, want 8", got)
+	}
+}
+
+func TestDiscoverFeatures(t *testing.T) {
+	rows := []map[string]float64{
+		{"value": 1, "timestamp": 0, "hour": 1, "cpu": 2, "sometimes": 1},
+		{"value": 1, "timestamp": 60, "hour": 1, "cpu": 2},
+	}
+	if got, want := discoverFeatures(rows), []string{"cpu", "hour"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("discoverFeatures() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,395 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RemoteProtocolVersion is the version of the model plugin protocol spoken by
+// RemoteModel and RemoteHandler. It is sent in every request and response body
+// and is part of the endpoint paths ("/v1/train", "/v1/predict"). A sidecar
+// must reject requests carrying a version it does not implement.
+const RemoteProtocolVersion = 1
+
+// maxRemoteResponseBytes bounds the size of a plugin response body.
+const maxRemoteResponseBytes = 16 << 20
+
+// RemoteRequest is the body of POST /v1/train and POST /v1/predict.
+// Rows carries the FeatureFrame: the full training window for train, the
+// recent features for predict.
+type RemoteRequest struct {
+	Version        int                  `json:"version"`
+	Metric         string               `json:"metric"`
+	StepSeconds    int                  `json:"stepSeconds"`
+	HorizonSeconds int                  `json:"horizonSeconds"`
+	Rows           []map[string]float64 `json:"rows"`
+}
+
+// RemoteForecast is the body of a successful POST /v1/predict response.
+// Values must hold HorizonSeconds/StepSeconds points, as must every quantile
+// series. Quantile keys follow QuantileKey ("p10", "p50", "p90"). Components
+// optionally explains Values as in Forecast.Components.
+type RemoteForecast struct {
+	Version    int                  `json:"version"`
+	Values     []float64            `json:"values"`
+	Quantiles  map[string][]float64 `json:"quantiles,omitempty"`
+	Components map[string][]float64 `json:"components,omitempty"`
+}
+
+// RemoteStatus is the body of a successful POST /v1/train response and of any
+// error response. Error is empty on success.
+type RemoteStatus struct {
+	Version int    `json:"version"`
+	Error   string `json:"error,omitempty"`
+}
+
+// RemoteOptions configures a RemoteModel.
+type RemoteOptions struct {
+	// URL is the base URL of the plugin sidecar, e.g. "http://localhost:9000".
+	URL string
+
+	// Timeout bounds each train or predict call. Defaults to 10 seconds.
+	Timeout time.Duration
+
+	// Fallback, if set, is trained alongside the sidecar and used when a
+	// call fails or times out.
+	Fallback Model
+
+	// OnFallback, if set, is called whenever the fallback is used instead of
+	// the sidecar, with the operation ("train" or "predict") and the cause.
+	OnFallback func(op string, err error)
+
+	// Client is the HTTP client used for calls. Defaults to a client
+	// without its own timeout; Timeout is applied per call.
+	Client *http.Client
+}
+
+// RemoteModel delegates training and prediction to an out-of-process model
+// speaking the plugin protocol over HTTP/JSON, so that models written in
+// other languages can run as a sidecar of the forecaster.
+//
+// Each call is bounded by the configured timeout. When a fallback model is
+// configured it is trained on the same history as the sidecar, and its
+// forecast is returned whenever the sidecar cannot be reached, answers with
+// an error, or returns a malformed forecast. Without a fallback these failures
+// are returned as errors.
+//
+// The sidecar owns its own fitted state; RemoteModel keeps none besides the
+// fallback.
+type RemoteModel struct {
+	metric  string
+	stepSec int
+	horizon int
+	opts    RemoteOptions
+
+	// fallbackMu serialises fallback calls for models that are not safe for
+	// concurrent use.
+	fallbackMu sync.Mutex
+}
+
+// NewRemoteModel creates a model backed by the plugin sidecar at opts.URL.
+//
+// Panics if opts.URL is empty.
+func NewRemoteModel(metric string, stepSec, horizon int, opts RemoteOptions) *RemoteModel {
+	if opts.URL == "" {
+		panic("remote model needs a URL")
+	}
+	opts.URL = strings.TrimRight(opts.URL, "/")
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{}
+	}
+
+	return &RemoteModel{
+		metric:  metric,
+		stepSec: stepSec,
+		horizon: horizon,
+		opts:    opts,
+	}
+}
+
+// Name returns "remote", or "remote(<fallback>)" when a fallback is configured,
+// e.g. "remote(baseline)".
+func (m *RemoteModel) Name() string {
+	if m.opts.Fallback == nil {
+		return "remote"
+	}
+	return "remote(" + m.opts.Fallback.Name() + ")"
+}
+
+// Train sends the history to the sidecar and trains the fallback, if any.
+//
+// Returns error if:
+//   - Context is cancelled
+//   - The sidecar call fails and there is no fallback
+//   - Both the sidecar call and the fallback fail
+func (m *RemoteModel) Train(ctx context.Context, history FeatureFrame) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	remoteErr := m.call(ctx, "train", history, &RemoteStatus{})
+
+	if m.opts.Fallback == nil {
+		return remoteErr
+	}
+
+	m.fallbackMu.Lock()
+	fallbackErr := m.opts.Fallback.Train(ctx, history)
+	m.fallbackMu.Unlock()
+
+	if remoteErr == nil {
+		// The fallback is a safety net; failing to train it must not fail a
+		// healthy sidecar.
+		return nil
+	}
+	if fallbackErr != nil {
+		return errors.Join(remoteErr, fmt.Errorf("fallback: %w", fallbackErr))
+	}
+	m.notifyFallback("train", remoteErr)
+	return nil
+}
+
+// Predict asks the sidecar for a forecast, falling back to the fallback model
+// if the call fails. Negative values and quantiles returned by the sidecar are
+// clamped to zero.
+//
+// Returns error if:
+//   - Context is cancelled
+//   - The sidecar call fails and there is no fallback
+//   - Both the sidecar call and the fallback fail
+func (m *RemoteModel) Predict(ctx context.Context, features FeatureFrame) (Forecast, error) {
+	if ctx.Err() != nil {
+		return Forecast{}, ctx.Err()
+	}
+
+	var resp RemoteForecast
+	remoteErr := m.call(ctx, "predict", features, &resp)
+	if remoteErr == nil {
+		remoteErr = m.checkForecast(resp)
+	}
+	if remoteErr == nil {
+		return m.forecast(resp), nil
+	}
+
+	if m.opts.Fallback == nil {
+		return Forecast{}, remoteErr
+	}
+
+	m.fallbackMu.Lock()
+	forecast, err := m.opts.Fallback.Predict(ctx, features)
+	m.fallbackMu.Unlock()
+	if err != nil {
+		return Forecast{}, errors.Join(remoteErr, fmt.Errorf("fallback: %w", err))
+	}
+	m.notifyFallback("predict", remoteErr)
+	return forecast, nil
+}
+
+// call POSTs frame to /v1/{op} and decodes the response into out.
+func (m *RemoteModel) call(ctx context.Context, op string, frame FeatureFrame, out any) error {
+	body, err := json.Marshal(RemoteRequest{
+		Version:        RemoteProtocolVersion,
+		Metric:         m.metric,
+		StepSeconds:    m.stepSec,
+		HorizonSeconds: m.horizon,
+		Rows:           frame.Rows,
+	})
+	if err != nil {
+		return fmt.Errorf("remote %s: failed to encode request: %w", op, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.opts.Timeout)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/v%d/%s", m.opts.URL, RemoteProtocolVersion, op)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("remote %s: failed to create request: %w", op, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.opts.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("remote %s: request failed: %w", op, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteResponseBytes))
+	if err != nil {
+		return fmt.Errorf("remote %s: failed to read response: %w", op, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var status RemoteStatus
+		if json.Unmarshal(data, &status) == nil && status.Error != "" {
+			return fmt.Errorf("remote %s: status %d: %s", op, resp.StatusCode, status.Error)
+		}
+		return fmt.Errorf("remote %s: unexpected status code: %d", op, resp.StatusCode)
+	}
+
+	var version struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &version); err != nil {
+		return fmt.Errorf("remote %s: failed to decode response: %w", op, err)
+	}
+	if version.Version != RemoteProtocolVersion {
+		return fmt.Errorf("remote %s: protocol version %d, want %d", op, version.Version, RemoteProtocolVersion)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("remote %s: failed to decode response: %w", op, err)
+	}
+	return nil
+}
+
+// checkForecast validates the shape of a sidecar forecast.
+func (m *RemoteModel) checkForecast(resp RemoteForecast) error {
+	steps := m.horizon / m.stepSec
+	if len(resp.Values) != steps {
+		return fmt.Errorf("remote predict: got %d values, want %d", len(resp.Values), steps)
+	}
+	for key, series := range resp.Quantiles {
+		if len(series) != steps {
+			return fmt.Errorf("remote predict: quantile %q has %d values, want %d", key, len(series), steps)
+		}
+	}
+	for key, series := range resp.Components {
+		if len(series) != steps {
+			return fmt.Errorf("remote predict: component %q has %d values, want %d", key, len(series), steps)
+		}
+	}
+	return nil
+}
+
+// forecast converts a validated sidecar forecast, clamping negative values.
+func (m *RemoteModel) forecast(resp RemoteForecast) Forecast {
+	values := make([]float64, len(resp.Values))
+	for i, v := range resp.Values {
+		values[i] = max(v, 0)
+	}
+
+	var quantiles map[string][]float64
+	if len(resp.Quantiles) > 0 {
+		quantiles = make(map[string][]float64, len(resp.Quantiles))
+		for key, series := range resp.Quantiles {
+			clamped := make([]float64, len(series))
+			for i, v := range series {
+				clamped[i] = max(v, 0)
+			}
+			quantiles[key] = clamped
+		}
+	}
+
+	return Forecast{
+		Metric:     m.metric,
+		Values:     values,
+		StepSec:    m.stepSec,
+		Horizon:    m.horizon,
+		Quantiles:  quantiles,
+		Components: resp.Components,
+	}
+}
+
+func (m *RemoteModel) notifyFallback(op string, err error) {
+	if m.opts.OnFallback != nil {
+		m.opts.OnFallback(op, err)
+	}
+}
+
+// RemoteHandler serves the plugin protocol on behalf of a Go model. It is the
+// reference implementation of the sidecar side of the protocol: CI uses it to
+// exercise RemoteModel end to end, and plugin authors can use it to check
+// their own server against the same requests.
+//
+// Requests are passed to model in turn; the request's metric, step and
+// horizon are not checked against the model's configuration.
+type RemoteHandler struct {
+	model Model
+	mu    sync.Mutex
+	mux   *http.ServeMux
+}
+
+// NewRemoteHandler creates a handler serving model over the plugin protocol.
+func NewRemoteHandler(model Model) *RemoteHandler {
+	h := &RemoteHandler{model: model, mux: http.NewServeMux()}
+	h.mux.HandleFunc(fmt.Sprintf("POST /v%d/train", RemoteProtocolVersion), h.handleTrain)
+	h.mux.HandleFunc(fmt.Sprintf("POST /v%d/predict", RemoteProtocolVersion), h.handlePredict)
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *RemoteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *RemoteHandler) handleTrain(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeRemoteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	h.mu.Lock()
+	err := h.model.Train(r.Context(), FeatureFrame{Rows: req.Rows})
+	h.mu.Unlock()
+	if err != nil {
+		writeRemoteJSON(w, http.StatusUnprocessableEntity, RemoteStatus{Version: RemoteProtocolVersion, Error: err.Error()})
+		return
+	}
+	writeRemoteJSON(w, http.StatusOK, RemoteStatus{Version: RemoteProtocolVersion})
+}
+
+func (h *RemoteHandler) handlePredict(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeRemoteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	h.mu.Lock()
+	forecast, err := h.model.Predict(r.Context(), FeatureFrame{Rows: req.Rows})
+	h.mu.Unlock()
+	if err != nil {
+		writeRemoteJSON(w, http.StatusUnprocessableEntity, RemoteStatus{Version: RemoteProtocolVersion, Error: err.Error()})
+		return
+	}
+	writeRemoteJSON(w, http.StatusOK, RemoteForecast{
+		Version:    RemoteProtocolVersion,
+		Values:     forecast.Values,
+		Quantiles:  forecast.Quantiles,
+		Components: forecast.Components,
+	})
+}
+
+// decodeRemoteRequest decodes and version-checks a request body, writing a
+// 400 response on failure.
+func decodeRemoteRequest(w http.ResponseWriter, r *http.Request) (RemoteRequest, bool) {
+	var req RemoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeRemoteJSON(w, http.StatusBadRequest, RemoteStatus{Version: RemoteProtocolVersion, Error: "invalid request body: " + err.Error()})
+		return RemoteRequest{}, false
+	}
+	if req.Version != RemoteProtocolVersion {
+		writeRemoteJSON(w, http.StatusBadRequest, RemoteStatus{
+			Version: RemoteProtocolVersion,
+			Error:   fmt.Sprintf("unsupported protocol version %d", req.Version),
+		})
+		return RemoteRequest{}, false
+	}
+	return req, true
+}
+
+func writeRemoteJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
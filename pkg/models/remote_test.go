@@ -0,0 +1,212 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRemoteModel_RoundTrip(t *testing.T) {
+	history := syntheticComplex(200)
+	ctx := context.Background()
+
+	local := NewARIMAModel("m", 60, 600, 1, 1, 1)
+	server := httptest.NewServer(NewRemoteHandler(NewARIMAModel("m", 60, 600, 1, 1, 1)))
+	defer server.Close()
+
+	remote := NewRemoteModel("m", 60, 600, RemoteOptions{URL: server.URL + "/"})
+	if got := remote.Name(); got != "remote" {
+		t.Errorf("Name() = %q, want %q", got, "remote")
+	}
+
+	if err := remote.Train(ctx, history); err != nil {
+		t.Fatalf("remote Train() error = %v", err)
+	}
+	if err := local.Train(ctx, history); err != nil {
+		t.Fatalf("local Train() error = %v", err)
+	}
+
+	got, err := remote.Predict(ctx, history)
+	if err != nil {
+		t.Fatalf("remote Predict() error = %v", err)
+	}
+	want, err := local.Predict(ctx, history)
+	if err != nil {
+		t.Fatalf("local Predict() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got.Values, want.Values) {
+		t.Errorf("Values = %v, want %v", got.Values, want.Values)
+	}
+	if !reflect.DeepEqual(got.Quantiles, want.Quantiles) {
+		t.Errorf("Quantiles = %v, want %v", got.Quantiles, want.Quantiles)
+	}
+	if got.Metric != "m" || got.StepSec != 60 || got.Horizon != 600 {
+		t.Errorf("forecast = %s/%d/%d, want m/60/600", got.Metric, got.StepSec, got.Horizon)
+	}
+}
+
+func TestRemoteModel_Fallback(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		timeout time.Duration
+	}{
+		{
+			name: "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeRemoteJSON(w, http.StatusInternalServerError, RemoteStatus{Version: RemoteProtocolVersion, Error: "boom"})
+			},
+		},
+		{
+			name: "timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+			},
+			timeout: 20 * time.Millisecond,
+		},
+		{
+			name: "wrong version",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeRemoteJSON(w, http.StatusOK, RemoteForecast{Version: 2, Values: make([]float64, 10)})
+			},
+		},
+		{
+			name: "wrong length",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeRemoteJSON(w, http.StatusOK, RemoteForecast{Version: RemoteProtocolVersion, Values: []float64{1}})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			var ops []string
+			remote := NewRemoteModel("m", 60, 600, RemoteOptions{
+				URL:        server.URL,
+				Timeout:    tt.timeout,
+				Fallback:   NewBaselineModel("m", 60, 600),
+				OnFallback: func(op string, err error) { ops = append(ops, op) },
+			})
+			if got := remote.Name(); got != "remote(baseline)" {
+				t.Errorf("Name() = %q, want %q", got, "remote(baseline)")
+			}
+
+			ctx := context.Background()
+			history := syntheticConstant(50, 100)
+			if err := remote.Train(ctx, history); err != nil {
+				t.Fatalf("Train() error = %v", err)
+			}
+			forecast, err := remote.Predict(ctx, history)
+			if err != nil {
+				t.Fatalf("Predict() error = %v", err)
+			}
+			if len(forecast.Values) != 10 {
+				t.Errorf("len(Values) = %d, want 10 from the fallback", len(forecast.Values))
+			}
+			if len(ops) == 0 || ops[len(ops)-1] != "predict" {
+				t.Errorf("OnFallback ops = %v, want a predict fallback", ops)
+			}
+		})
+	}
+}
+
+func TestRemoteModel_ErrorsWithoutFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeRemoteJSON(w, http.StatusUnprocessableEntity, RemoteStatus{Version: RemoteProtocolVersion, Error: "not trained"})
+	}))
+	defer server.Close()
+
+	remote := NewRemoteModel("m", 60, 600, RemoteOptions{URL: server.URL})
+	ctx := context.Background()
+
+	if err := remote.Train(ctx, syntheticConstant(10, 1)); err == nil {
+		t.Error("Train() error = nil, want sidecar error")
+	}
+	_, err := remote.Predict(ctx, syntheticConstant(10, 1))
+	if err == nil || !strings.Contains(err.Error(), "not trained") {
+		t.Errorf("Predict() error = %v, want it to carry the sidecar message", err)
+	}
+}
+
+func TestRemoteModel_ClampsNegativeValues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeRemoteJSON(w, http.StatusOK, RemoteForecast{
+			Version:    RemoteProtocolVersion,
+			Values:     []float64{-1, 2},
+			Quantiles:  map[string][]float64{"p10": {-3, 1}},
+			Components: map[string][]float64{ComponentTrend: {-5, 0}},
+		})
+	}))
+	defer server.Close()
+
+	forecast, err := NewRemoteModel("m", 60, 120, RemoteOptions{URL: server.URL}).Predict(context.Background(), FeatureFrame{})
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	if want := []float64{0, 2}; !reflect.DeepEqual(forecast.Values, want) {
+		t.Errorf("Values = %v, want %v", forecast.Values, want)
+	}
+	if want := []float64{0, 1}; !reflect.DeepEqual(forecast.Quantiles["p10"], want) {
+		t.Errorf("Quantiles[p10] = %v, want %v", forecast.Quantiles["p10"], want)
+	}
+	// Components are effects, which may be negative.
+	if want := []float64{-5, 0}; !reflect.DeepEqual(forecast.Components[ComponentTrend], want) {
+		t.Errorf("Components[trend] = %v, want %v", forecast.Components[ComponentTrend], want)
+	}
+}
+
+func TestRemoteHandler_Protocol(t *testing.T) {
+	handler := NewRemoteHandler(NewBaselineModel("m", 60, 600))
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{name: "train", method: http.MethodPost, path: "/v1/train", body: `{"version":1,"rows":[{"value":1}]}`, wantStatus: http.StatusOK},
+		{name: "predict", method: http.MethodPost, path: "/v1/predict", body: `{"version":1,"rows":[{"value":1}]}`, wantStatus: http.StatusOK},
+		{name: "unsupported version", method: http.MethodPost, path: "/v1/predict", body: `{"version":2,"rows":[]}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid body", method: http.MethodPost, path: "/v1/train", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "model error", method: http.MethodPost, path: "/v1/predict", body: `{"version":1,"rows":[]}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "wrong method", method: http.MethodGet, path: "/v1/predict", wantStatus: http.StatusMethodNotAllowed},
+		{name: "unknown path", method: http.MethodPost, path: "/v2/predict", body: `{"version":2}`, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK || tt.wantStatus == http.StatusBadRequest || tt.wantStatus == http.StatusUnprocessableEntity {
+				var status RemoteStatus
+				if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+					t.Fatalf("response is not JSON: %v", err)
+				}
+				if status.Version != RemoteProtocolVersion {
+					t.Errorf("version = %d, want %d", status.Version, RemoteProtocolVersion)
+				}
+				if (status.Error == "") != (tt.wantStatus == http.StatusOK) {
+					t.Errorf("error = %q with status %d", status.Error, rec.Code)
+				}
+			}
+		})
+	}
+}
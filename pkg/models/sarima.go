@@ -0,0 +1,328 @@
+package models
+
+import (
+	"fmt"
+	"math"
+)
+
+// minPoints returns the minimum number of observations Train needs.
+func (m *ARIMAModel) minPoints() int {
+	if !m.seasonal.enabled() {
+		return max(max(m.p+m.d, m.q+m.d), 10)
+	}
+	s := m.seasonal.Period
+	longestLag := max(m.p+m.seasonal.P*s, m.q+m.seasonal.Q*s)
+	return m.seasonal.D*s + m.d + longestLag + 10
+}
+
+// trainSeasonal fits SARIMA(p,d,q)(P,D,Q)[s] to values.
+//
+// The fitting process:
+//  1. Applies seasonal differencing (D times at lag s), then regular differencing (d times)
+//  2. Centers the stationary series on its mean
+//  3. Fits seasonal AR coefficients from the autocorrelations at lags s, 2s, ..., P*s
+//  4. Filters out the seasonal AR part and fits non-seasonal AR coefficients (Yule-Walker)
+//  5. Fits non-seasonal and seasonal MA coefficients from residual autocorrelations
+//  6. Expands the multiplicative polynomials and replays the series to obtain
+//     the last errors needed for recursive forecasting
+func (m *ARIMAModel) trainSeasonal(values []float64) error {
+	s := m.seasonal.Period
+
+	stationary := difference(seasonalDifference(values, s, m.seasonal.D), m.d)
+	mean := computeMean(stationary)
+
+	centered := make([]float64, len(stationary))
+	for i, v := range stationary {
+		centered[i] = v - mean
+	}
+
+	sarCoeffs := fitSeasonalAR(centered, m.seasonal.P, s)
+
+	// Remove the seasonal AR component before fitting the short-memory AR terms.
+	filtered := make([]float64, len(centered))
+	for t := range centered {
+		filtered[t] = centered[t]
+		for k, c := range sarCoeffs {
+			if lag := t - (k+1)*s; lag >= 0 {
+				filtered[t] -= c * centered[lag]
+			}
+		}
+	}
+
+	arCoeffs, err := fitAR(filtered, m.p)
+	if err != nil {
+		return fmt.Errorf("failed to fit AR coefficients: %w", err)
+	}
+
+	residuals := computeResiduals(filtered, arCoeffs, m.p)
+
+	maCoeffs, err := fitMA(residuals, m.q)
+	if err != nil {
+		return fmt.Errorf("failed to fit MA coefficients: %w", err)
+	}
+	smaCoeffs := fitSeasonalMA(residuals, m.seasonal.Q, s)
+
+	arPoly := expandAR(arCoeffs, sarCoeffs, s)
+	maPoly := expandMA(maCoeffs, smaCoeffs, s)
+	errs := replayErrors(centered, arPoly, maPoly)
+
+	lastCentered := make([]float64, min(len(arPoly), len(centered)))
+	copy(lastCentered, centered[len(centered)-len(lastCentered):])
+
+	lastErrors := make([]float64, min(len(maPoly), len(errs)))
+	copy(lastErrors, errs[len(errs)-len(lastErrors):])
+
+	sigma2 := innovationVariance(errs, len(arPoly)+len(maPoly))
+
+	tailLen := min(m.seasonal.D*s+m.d+1, len(values))
+	tail := make([]float64, tailLen)
+	copy(tail, values[len(values)-tailLen:])
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.trained = true
+	m.arCoeffs = arCoeffs
+	m.maCoeffs = maCoeffs
+	m.sarCoeffs = sarCoeffs
+	m.smaCoeffs = smaCoeffs
+	m.mean = mean
+	m.sigma2 = sigma2
+	m.arPoly = arPoly
+	m.maPoly = maPoly
+	m.lastCentered = lastCentered
+	m.lastErrors = lastErrors
+	m.tail = tail
+
+	return nil
+}
+
+// predictRecursive runs the (S)ARIMA recursion over the horizon and undoes the
+// differencing, adding offsets (the regression part of an ARIMAX model, nil
+// without) and analytic quantiles. Callers must hold at least a read lock.
+func (m *ARIMAModel) predictRecursive(offsets []float64) Forecast {
+	nSteps := m.horizonSec / m.stepSec
+	if nSteps <= 0 {
+		nSteps = 1
+	}
+
+	ar, ma := m.arCoeffs, m.maCoeffs
+	if m.seasonal.enabled() {
+		ar, ma = m.arPoly, m.maPoly
+	}
+
+	stationary := forecastARMA(m.lastCentered, m.lastErrors, ar, ma, nSteps)
+	for i := range stationary {
+		stationary[i] += m.mean
+	}
+
+	predictions := integrate(m.tail, stationary, m.d, m.seasonal.D, m.seasonal.Period)
+	for i, v := range predictions {
+		if offsets != nil {
+			v += offsets[i]
+		}
+		if v < 0 || math.IsNaN(v) {
+			v = 0
+		}
+		if v > 1e9 {
+			v = 1e9
+		}
+		predictions[i] = v
+	}
+
+	psi := psiWeights(ar, ma, m.d, m.seasonal.D, m.seasonal.Period, nSteps)
+
+	return Forecast{
+		Metric:    m.metric,
+		Values:    predictions,
+		StepSec:   m.stepSec,
+		Horizon:   m.horizonSec,
+		Quantiles: normalQuantiles(predictions, psiStddev(psi, m.sigma2)),
+	}
+}
+
+// seasonalDifference applies D-order seasonal differencing at the given lag:
+// y'(t) = y(t) - y(t-lag).
+func seasonalDifference(series []float64, lag, times int) []float64 {
+	if times == 0 || len(series) == 0 {
+		result := make([]float64, len(series))
+		copy(result, series)
+		return result
+	}
+	if len(series) <= lag {
+		return []float64{}
+	}
+
+	result := make([]float64, len(series)-lag)
+	for i := range result {
+		result[i] = series[i+lag] - series[i]
+	}
+
+	return seasonalDifference(result, lag, times-1)
+}
+
+// integrate undoes seasonal and regular differencing for forecasts made on the
+// fully differenced series, anchored on tail (the most recent raw observations).
+//
+// The differencing stack is rebuilt from tail; each level is then extended
+// with the forecasts from the level below, from the deepest level upwards.
+func integrate(tail, forecasts []float64, d, seasonalD, period int) []float64 {
+	levels := [][]float64{tail}
+	for range seasonalD {
+		levels = append(levels, seasonalDifference(levels[len(levels)-1], period, 1))
+	}
+	for range d {
+		levels = append(levels, difference(levels[len(levels)-1], 1))
+	}
+
+	out := forecasts
+	for k := len(levels) - 2; k >= 0; k-- {
+		lag := 1
+		if k < seasonalD {
+			lag = period
+		}
+
+		base := levels[k]
+		ext := make([]float64, len(base), len(base)+len(out))
+		copy(ext, base)
+		for h, v := range out {
+			prev := 0.0
+			if idx := len(base) + h - lag; idx >= 0 {
+				prev = ext[idx]
+			}
+			ext = append(ext, prev+v)
+		}
+		out = ext[len(base):]
+	}
+
+	result := make([]float64, len(out))
+	copy(result, out)
+	return result
+}
+
+// fitSeasonalAR estimates seasonal AR coefficients by solving the Yule-Walker
+// equations on the autocorrelations at lags period, 2*period, ..., P*period.
+func fitSeasonalAR(centered []float64, P, period int) []float64 {
+	if P == 0 {
+		return []float64{}
+	}
+	if computeVariance(centered) < 1e-10 {
+		return make([]float64, P)
+	}
+
+	acf := make([]float64, P+1)
+	for k := 0; k <= P; k++ {
+		acf[k] = autocorr(centered, k*period)
+	}
+
+	coeffs, err := levinsonDurbin(acf, P)
+	if err != nil {
+		return make([]float64, P)
+	}
+	return coeffs
+}
+
+// fitSeasonalMA estimates seasonal MA coefficients from residual
+// autocorrelations at seasonal lags, clamped to keep the model invertible.
+func fitSeasonalMA(residuals []float64, Q, period int) []float64 {
+	coeffs := make([]float64, Q)
+	for k := range coeffs {
+		c := autocorr(residuals, (k+1)*period)
+		if math.Abs(c) > 0.9 {
+			c = c / math.Abs(c) * 0.9
+		}
+		coeffs[k] = c
+	}
+	return coeffs
+}
+
+// expandAR multiplies (1 - φ(B))(1 - Φ(B^s)) and returns the coefficients a
+// of the expanded form x(t) = Σ a[j-1]·x(t-j) + ...
+func expandAR(ar, sar []float64, period int) []float64 {
+	prod := polyMul(lagPolynomial(ar, 1, -1), lagPolynomial(sar, period, -1))
+	coeffs := make([]float64, len(prod)-1)
+	for j := range coeffs {
+		coeffs[j] = -prod[j+1]
+	}
+	return coeffs
+}
+
+// expandMA multiplies (1 + θ(B))(1 + Θ(B^s)) and returns the coefficients b
+// of the expanded form x(t) = ... + Σ b[j-1]·ε(t-j) + ε(t).
+func expandMA(ma, sma []float64, period int) []float64 {
+	prod := polyMul(lagPolynomial(ma, 1, 1), lagPolynomial(sma, period, 1))
+	return prod[1:]
+}
+
+// lagPolynomial builds 1 + sign·(c1·B^lag + c2·B^(2·lag) + ...).
+func lagPolynomial(coeffs []float64, lag int, sign float64) []float64 {
+	poly := make([]float64, len(coeffs)*lag+1)
+	poly[0] = 1
+	for k, c := range coeffs {
+		poly[(k+1)*lag] = sign * c
+	}
+	return poly
+}
+
+// polyMul multiplies two polynomials given by their coefficients in increasing degree.
+func polyMul(a, b []float64) []float64 {
+	out := make([]float64, len(a)+len(b)-1)
+	for i, x := range a {
+		if x == 0 {
+			continue
+		}
+		for j, y := range b {
+			out[i+j] += x * y
+		}
+	}
+	return out
+}
+
+// replayErrors computes one-step-ahead errors of the ARMA recursion over the
+// series, treating values and errors before the start as zero.
+func replayErrors(series, ar, ma []float64) []float64 {
+	errs := make([]float64, len(series))
+	for t := range series {
+		pred := 0.0
+		for j, a := range ar {
+			if idx := t - 1 - j; idx >= 0 {
+				pred += a * series[idx]
+			}
+		}
+		for j, b := range ma {
+			if idx := t - 1 - j; idx >= 0 {
+				pred += b * errs[idx]
+			}
+		}
+		errs[t] = series[t] - pred
+	}
+	return errs
+}
+
+// forecastARMA extends a centered stationary series nSteps ahead using the
+// given AR and MA coefficients. Future errors are taken as zero.
+func forecastARMA(lastValues, lastErrors, ar, ma []float64, nSteps int) []float64 {
+	values := make([]float64, len(lastValues), len(lastValues)+nSteps)
+	copy(values, lastValues)
+	errs := make([]float64, len(lastErrors), len(lastErrors)+nSteps)
+	copy(errs, lastErrors)
+
+	out := make([]float64, nSteps)
+	for h := range nSteps {
+		pred := 0.0
+		for j, a := range ar {
+			if idx := len(values) - 1 - j; idx >= 0 {
+				pred += a * values[idx]
+			}
+		}
+		for j, b := range ma {
+			if idx := len(errs) - 1 - j; idx >= 0 {
+				pred += b * errs[idx]
+			}
+		}
+		values = append(values, pred)
+		errs = append(errs, 0)
+		out[h] = pred
+	}
+	return out
+}
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestSARIMAModel_Name(t *testing.T) {
+	model := NewSARIMAModel("test_metric", 60, 1800, 1, 1, 1, SeasonalOrder{P: 1, D: 1, Q: 0, Period: 24})
+
+	if got, want := model.Name(), "sarima(1,1,1)(1,1,0)[24]"; got != want {
+		t.Errorf("Name() = %q, want %q", got, want)
+	}
+}
+
+func TestSARIMAModel_ZeroSeasonalIsARIMA(t *testing.T) {
+	model := NewSARIMAModel("test_metric", 60, 1800, 2, 1, 1, SeasonalOrder{Period: 24})
+
+	if got, want := model.Name(), "arima(2,1,1)"; got != want {
+		t.Errorf("Name() = %q, want %q", got, want)
+	}
+}
+
+func TestSARIMAModel_NewSARIMAModel_Panics(t *testing.T) {
+	tests := []struct {
+		name     string
+		seasonal SeasonalOrder
+	}{
+		{"negative P", SeasonalOrder{P: -1, Period: 24}},
+		{"negative Q", SeasonalOrder{Q: -1, Period: 24}},
+		{"D > 1", SeasonalOrder{D: 2, Period: 24}},
+		{"missing period", SeasonalOrder{P: 1}},
+		{"period of 1", SeasonalOrder{D: 1, Period: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("expected panic for %s", tt.name)
+				}
+			}()
+			NewSARIMAModel("test", 60, 1800, 1, 1, 1, tt.seasonal)
+		})
+	}
+}
+
+func TestSARIMAModel_Train_InsufficientData(t *testing.T) {
+	model := NewSARIMAModel("test_metric", 60, 1800, 1, 1, 1, SeasonalOrder{P: 1, D: 1, Period: 24})
+	history := syntheticSeasonal(40, 24, 20, 0) // Less than two seasons
+
+	err := model.Train(context.Background(), history)
+	if err == nil {
+		t.Fatal("Train() error = nil, want error for insufficient data")
+	}
+	if !contains(err.Error(), "need at least") {
+		t.Errorf("error message = %q, want to contain 'need at least'", err.Error())
+	}
+}
+
+func TestSARIMAModel_Predict_FollowsSeasonalCycle(t *testing.T) {
+	const period = 24
+	model := NewSARIMAModel("test_metric", 60, 1800, 1, 1, 1, SeasonalOrder{D: 1, Period: period})
+	n := 10 * period
+	history := syntheticSeasonal(n, period, 20, 0)
+
+	if err := model.Train(context.Background(), history); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+
+	forecast, err := model.Predict(context.Background(), FeatureFrame{})
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+
+	if len(forecast.Values) != 30 {
+		t.Fatalf("len(forecast.Values) = %d, want 30", len(forecast.Values))
+	}
+
+	// The continuation of a pure sine wave is known exactly.
+	for h, got := range forecast.Values {
+		want := 100 + 20*math.Sin(2*math.Pi*float64(n+h)/period)
+		if math.Abs(got-want) > 1.0 {
+			t.Errorf("forecast.Values[%d] = %.2f, want ~%.2f", h, got, want)
+		}
+	}
+}
+
+func TestSARIMAModel_Predict_SeasonalWithTrend(t *testing.T) {
+	const period = 24
+	model := NewSARIMAModel("test_metric", 60, 1440*60, 1, 0, 1, SeasonalOrder{P: 1, D: 1, Q: 1, Period: period})
+	history := syntheticComplex(10 * period)
+
+	if err := model.Train(context.Background(), history); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	if len(model.sarCoeffs) != 1 || len(model.smaCoeffs) != 1 {
+		t.Errorf("seasonal coefficients = %v/%v, want one each", model.sarCoeffs, model.smaCoeffs)
+	}
+
+	forecast, err := model.Predict(context.Background(), FeatureFrame{})
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+
+	// Peak and trough of the next cycle should be roughly a season apart in
+	// value (40 peak-to-trough) rather than damped towards a flat line.
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range forecast.Values[:period] {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	if hi-lo < 30 {
+		t.Errorf("forecast amplitude = %.2f, want seasonal swing of ~40", hi-lo)
+	}
+}
+
+func TestIntegrate_RoundTrip(t *testing.T) {
+	series := []float64{1, 4, 9, 16, 25, 36, 49, 64, 81, 100, 121, 144}
+	const period = 4
+
+	diffed := difference(seasonalDifference(series, period, 1), 1)
+	split := len(diffed) - 3
+	tailLen := period + 1 + 1
+	anchor := len(series) - 3
+	got := integrate(series[anchor-tailLen:anchor], diffed[split:], 1, 1, period)
+
+	for i, v := range got {
+		if math.Abs(v-series[anchor+i]) > 1e-9 {
+			t.Errorf("integrate()[%d] = %v, want %v", i, v, series[anchor+i])
+		}
+	}
+}
+
+func TestExpandAR_Multiplicative(t *testing.T) {
+	// (1 - 0.5B)(1 - 0.4B^3) = 1 - 0.5B - 0.4B^3 + 0.2B^4
+	got := expandAR([]float64{0.5}, []float64{0.4}, 3)
+	want := []float64{0.5, 0, 0.4, -0.2}
+
+	if len(got) != len(want) {
+		t.Fatalf("len(expandAR()) = %d, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-12 {
+			t.Errorf("expandAR()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
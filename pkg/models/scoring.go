@@ -0,0 +1,117 @@
+package models
+
+import (
+	"fmt"
+	"math"
+)
+
+// ErrorMetric names a forecast error measure. Lower is better for all metrics.
+type ErrorMetric string
+
+const (
+	// MetricMAE is the mean absolute error.
+	MetricMAE ErrorMetric = "mae"
+
+	// MetricMAPE is the mean absolute percentage error (in percent).
+	MetricMAPE ErrorMetric = "mape"
+
+	// MetricPinball is the pinball (quantile) loss for a chosen quantile.
+	MetricPinball ErrorMetric = "pinball"
+)
+
+// ParseErrorMetric validates an error metric name.
+func ParseErrorMetric(name string) (ErrorMetric, error) {
+	switch m := ErrorMetric(name); m {
+	case MetricMAE, MetricMAPE, MetricPinball:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown error metric %q (want mae, mape or pinball)", name)
+	}
+}
+
+// MAE returns the mean absolute error over the common length of both series.
+func MAE(actual, predicted []float64) float64 {
+	n := min(len(actual), len(predicted))
+	if n == 0 {
+		return 0
+	}
+	total := 0.0
+	for i := range n {
+		total += math.Abs(actual[i] - predicted[i])
+	}
+	return total / float64(n)
+}
+
+// MAPE returns the mean absolute percentage error, in percent, over the common
+// length of both series. Points where the actual value is zero are skipped.
+func MAPE(actual, predicted []float64) float64 {
+	n := min(len(actual), len(predicted))
+	total, count := 0.0, 0
+	for i := range n {
+		if actual[i] == 0 {
+			continue
+		}
+		total += math.Abs((actual[i] - predicted[i]) / actual[i])
+		count++
+	}
+	if count == 0 {
+		return 0
+	}
+	return 100 * total / float64(count)
+}
+
+// SMAPE returns the symmetric mean absolute percentage error, in percent
+// (0-200), over the common length of both series. Unlike MAPE it is defined
+// for zero actuals; points where both values are zero are skipped.
+func SMAPE(actual, predicted []float64) float64 {
+	n := min(len(actual), len(predicted))
+	total, count := 0.0, 0
+	for i := range n {
+		denom := math.Abs(actual[i]) + math.Abs(predicted[i])
+		if denom == 0 {
+			continue
+		}
+		total += 2 * math.Abs(actual[i]-predicted[i]) / denom
+		count++
+	}
+	if count == 0 {
+		return 0
+	}
+	return 100 * total / float64(count)
+}
+
+// PinballLoss returns the mean pinball loss of predicted as a q-quantile
+// forecast of actual. Under-forecasts cost q per unit, over-forecasts 1-q.
+func PinballLoss(actual, predicted []float64, q float64) float64 {
+	n := min(len(actual), len(predicted))
+	if n == 0 {
+		return 0
+	}
+	total := 0.0
+	for i := range n {
+		diff := actual[i] - predicted[i]
+		if diff >= 0 {
+			total += q * diff
+		} else {
+			total -= (1 - q) * diff
+		}
+	}
+	return total / float64(n)
+}
+
+// Score evaluates forecast against actual with the metric. For pinball loss
+// the forecast's q quantile is scored when available, else its point values.
+func (m ErrorMetric) Score(actual []float64, forecast Forecast, q float64) float64 {
+	switch m {
+	case MetricMAPE:
+		return MAPE(actual, forecast.Values)
+	case MetricPinball:
+		predicted := forecast.Values
+		if series, ok := forecast.Quantile(q); ok {
+			predicted = series
+		}
+		return PinballLoss(actual, predicted, q)
+	default:
+		return MAE(actual, forecast.Values)
+	}
+}
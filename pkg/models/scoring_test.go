@@ -0,0 +1,51 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestErrorMetrics(t *testing.T) {
+	actual := []float64{100, 200, 0}
+	predicted := []float64{110, 180, 5}
+
+	if got := MAE(actual, predicted); math.Abs(got-35.0/3) > 1e-9 {
+		t.Errorf("MAE() = %v, want %v", got, 35.0/3)
+	}
+	// Zero actuals are skipped: (10% + 10%) / 2
+	if got := MAPE(actual, predicted); math.Abs(got-10) > 1e-9 {
+		t.Errorf("MAPE() = %v, want 10", got)
+	}
+	// 2·|a-p|/(|a|+|p|): 20/210 + 40/380 + 10/5 → mean × 100
+	if want := 100 * (20.0/210 + 40.0/380 + 2) / 3; math.Abs(SMAPE(actual, predicted)-want) > 1e-9 {
+		t.Errorf("SMAPE() = %v, want %v", SMAPE(actual, predicted), want)
+	}
+	// q=0.9: over-forecasts cost 0.1, under-forecasts 0.9 → (1 + 18 + 0.5) / 3
+	if got := PinballLoss(actual, predicted, 0.9); math.Abs(got-19.5/3) > 1e-9 {
+		t.Errorf("PinballLoss() = %v, want %v", got, 19.5/3)
+	}
+}
+
+func TestErrorMetric_Score_UsesQuantile(t *testing.T) {
+	forecast := Forecast{
+		Values:    []float64{100},
+		Quantiles: map[string][]float64{"p90": {120}},
+	}
+	if got := MetricPinball.Score([]float64{120}, forecast, 0.9); got != 0 {
+		t.Errorf("Score() = %v, want 0 when the p90 series matches", got)
+	}
+	if got := MetricMAE.Score([]float64{120}, forecast, 0.9); got != 20 {
+		t.Errorf("Score() = %v, want 20 for point MAE", got)
+	}
+}
+
+func TestParseErrorMetric(t *testing.T) {
+	for _, name := range []string{"mae", "mape", "pinball"} {
+		if _, err := ParseErrorMetric(name); err != nil {
+			t.Errorf("ParseErrorMetric(%q) error = %v", name, err)
+		}
+	}
+	if _, err := ParseErrorMetric("rmse"); err == nil {
+		t.Error("ParseErrorMetric(\"rmse\") error = nil, want error")
+	}
+}
@@ -0,0 +1,345 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+)
+
+// SeasonalNaiveMethod selects how a SeasonalNaiveModel combines past seasons.
+type SeasonalNaiveMethod string
+
+const (
+	// SeasonalNaiveLast repeats the value observed one season earlier.
+	SeasonalNaiveLast SeasonalNaiveMethod = "last"
+
+	// SeasonalNaiveAverage averages the values observed at the same point of
+	// the last Seasons seasons.
+	SeasonalNaiveAverage SeasonalNaiveMethod = "average"
+
+	// SeasonalNaiveLevel is the seasonal average shifted by how much the
+	// recent level differs from the level at the same point of those seasons.
+	SeasonalNaiveLevel SeasonalNaiveMethod = "level"
+)
+
+// SeasonalNaiveOptions configures a SeasonalNaiveModel. Zero values select the
+// defaults documented on each field.
+type SeasonalNaiveOptions struct {
+	// Method selects last, average or level (default SeasonalNaiveLast).
+	Method SeasonalNaiveMethod
+
+	// Periods lists the candidate season lengths in seconds (default one week
+	// and one day). The longest period covered by the history is used.
+	Periods []int
+
+	// Seasons is the number of past seasons averaged by the average and
+	// level methods (default 4).
+	Seasons int
+
+	// LevelWindow is the window in seconds over which the level method
+	// compares the recent level with past seasons (default one hour).
+	LevelWindow int
+}
+
+// naiveBacktestOrigins is the number of rolling origins per forecast step
+// used to estimate the seasonal naive model's forecast errors.
+const naiveBacktestOrigins = 24
+
+// SeasonalNaiveModel forecasts each future point from the values observed at
+// the same point of previous seasons, e.g. "same time last week":
+//
+//	last:    ŷ(t) = y(t - k·P)
+//	average: ŷ(t) = mean over the last Seasons seasons of y(t - k·P)
+//	level:   ŷ(t) = average(t) + (recent level - level of those seasons)
+//
+// k is the smallest number of seasons that reaches back into observed data,
+// so horizons longer than a season repeat the last observed season. Several
+// periods can be configured (weekly and daily by default); the longest one
+// the history fully covers is used, so a forecaster with less than a week of
+// data falls back to daily seasonality. Seasons missing from the history are
+// skipped.
+//
+// Rows are aligned on their "timestamp" when every row has one, otherwise
+// they are assumed to be consecutive steps. Quantiles come from a
+// rolling-origin backtest over the training window.
+//
+// The model is thread-safe for concurrent Predict calls after training.
+type SeasonalNaiveModel struct {
+	metric  string
+	stepSec int
+	horizon int
+	opts    SeasonalNaiveOptions
+
+	mu        sync.RWMutex
+	trained   bool
+	period    int
+	residuals [][]float64
+}
+
+// naiveSeries holds observed values indexed by step number.
+type naiveSeries struct {
+	values map[int64]float64
+	first  int64
+	last   int64
+	latest float64 // value at last
+}
+
+// NewSeasonalNaiveModel creates a new seasonal naive model.
+func NewSeasonalNaiveModel(metric string, stepSec, horizon int, opts SeasonalNaiveOptions) *SeasonalNaiveModel {
+	if opts.Method == "" {
+		opts.Method = SeasonalNaiveLast
+	}
+
+	var periods []int
+	for _, p := range opts.Periods {
+		if p > 0 && !slices.Contains(periods, p) {
+			periods = append(periods, p)
+		}
+	}
+	if len(periods) == 0 {
+		periods = []int{secondsPerWeek, secondsPerDay}
+	}
+	slices.Sort(periods)
+	slices.Reverse(periods)
+	opts.Periods = periods
+
+	if opts.Seasons <= 0 {
+		opts.Seasons = 4
+	}
+	if opts.LevelWindow <= 0 {
+		opts.LevelWindow = 3600
+	}
+
+	return &SeasonalNaiveModel{
+		metric:  metric,
+		stepSec: stepSec,
+		horizon: horizon,
+		opts:    opts,
+	}
+}
+
+// Name returns the model identifier including the method,
+// e.g. "seasonal-naive(level)".
+func (m *SeasonalNaiveModel) Name() string {
+	return "seasonal-naive(" + string(m.opts.Method) + ")"
+}
+
+// Period returns the season length in seconds selected by the last Train,
+// or 0 before training.
+func (m *SeasonalNaiveModel) Period() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.period
+}
+
+// Train checks that the history covers at least the shortest period and
+// backtests the model over it to estimate forecast errors.
+//
+// Returns error if:
+//   - Context is cancelled
+//   - History has no values or is shorter than the shortest period
+func (m *SeasonalNaiveModel) Train(ctx context.Context, history FeatureFrame) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	series, err := m.series(history)
+	if err != nil {
+		return err
+	}
+	period, err := m.selectPeriod(series)
+	if err != nil {
+		return err
+	}
+
+	shortest := m.periodSteps(m.opts.Periods[len(m.opts.Periods)-1])
+	residuals := backtestResiduals(history, m.numSteps(), shortest, naiveBacktestOrigins, m.predictValues)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.trained = true
+	m.period = period * m.stepSec
+	m.residuals = residuals
+	return nil
+}
+
+// Predict forecasts from the seasons observed in features, which must cover
+// at least the shortest period.
+//
+// Returns error if:
+//   - Context is cancelled
+//   - Model has not been trained
+//   - Features have no values or are shorter than the shortest period
+func (m *SeasonalNaiveModel) Predict(ctx context.Context, features FeatureFrame) (Forecast, error) {
+	if ctx.Err() != nil {
+		return Forecast{}, ctx.Err()
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.trained {
+		return Forecast{}, errors.New("model not trained, call Train() first")
+	}
+
+	values, err := m.predictValues(features)
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	return Forecast{
+		Metric:    m.metric,
+		Values:    values,
+		StepSec:   m.stepSec,
+		Horizon:   m.horizon,
+		Quantiles: empiricalQuantiles(values, m.residuals),
+	}, nil
+}
+
+// predictValues computes the point forecast from the observed rows.
+func (m *SeasonalNaiveModel) predictValues(frame FeatureFrame) ([]float64, error) {
+	series, err := m.series(frame)
+	if err != nil {
+		return nil, err
+	}
+	period, err := m.selectPeriod(series)
+	if err != nil {
+		return nil, err
+	}
+
+	adjust := 0.0
+	if m.opts.Method == SeasonalNaiveLevel {
+		adjust = m.levelShift(series, period)
+	}
+
+	steps := m.numSteps()
+	values := make([]float64, steps)
+	for h := 1; h <= steps; h++ {
+		target := series.last + int64(h)
+		k0 := int64((h + period - 1) / period)
+
+		sum, n := 0.0, 0
+		for k := k0; k < k0+int64(m.opts.Seasons); k++ {
+			v, ok := series.values[target-k*int64(period)]
+			if !ok {
+				continue
+			}
+			sum += v
+			n++
+			if m.opts.Method == SeasonalNaiveLast {
+				break
+			}
+		}
+
+		v := series.latest
+		if n > 0 {
+			v = sum / float64(n)
+		}
+		values[h-1] = max(v+adjust, 0)
+	}
+	return values, nil
+}
+
+// levelShift returns the recent level minus the mean level over the same
+// window of the previous Seasons seasons.
+func (m *SeasonalNaiveModel) levelShift(series naiveSeries, period int) float64 {
+	window := int64(max(m.opts.LevelWindow/m.stepSec, 1))
+
+	recent, ok := series.mean(series.last-window+1, series.last)
+	if !ok {
+		return 0
+	}
+
+	sum, n := 0.0, 0
+	for k := int64(1); k <= int64(m.opts.Seasons); k++ {
+		end := series.last - k*int64(period)
+		if level, ok := series.mean(end-window+1, end); ok {
+			sum += level
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return recent - sum/float64(n)
+}
+
+// mean returns the mean of the observed values with step index in [from, to].
+func (s naiveSeries) mean(from, to int64) (float64, bool) {
+	sum, n := 0.0, 0
+	for i := from; i <= to; i++ {
+		if v, ok := s.values[i]; ok {
+			sum += v
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return sum / float64(n), true
+}
+
+// selectPeriod returns the longest configured period, in steps, that the
+// series covers in full.
+func (m *SeasonalNaiveModel) selectPeriod(series naiveSeries) (int, error) {
+	span := series.last - series.first + 1
+	for _, p := range m.opts.Periods {
+		steps := m.periodSteps(p)
+		if steps >= 1 && span >= int64(steps) {
+			return steps, nil
+		}
+	}
+	shortest := m.opts.Periods[len(m.opts.Periods)-1]
+	return 0, fmt.Errorf("history of %d steps is shorter than the shortest period (%ds)", span, shortest)
+}
+
+// periodSteps converts a period in seconds to steps, rounding down.
+func (m *SeasonalNaiveModel) periodSteps(periodSec int) int {
+	return periodSec / m.stepSec
+}
+
+func (m *SeasonalNaiveModel) numSteps() int {
+	return max(m.horizon/m.stepSec, 1)
+}
+
+// series indexes the values of frame by step number: timestamp/stepSec when
+// every row has a timestamp, otherwise the row position.
+func (m *SeasonalNaiveModel) series(frame FeatureFrame) (naiveSeries, error) {
+	hasTime := len(frame.Rows) > 0
+	for _, row := range frame.Rows {
+		if _, ok := row["timestamp"]; !ok {
+			hasTime = false
+			break
+		}
+	}
+
+	s := naiveSeries{values: make(map[int64]float64, len(frame.Rows))}
+	found := false
+	for i, row := range frame.Rows {
+		v, ok := row["value"]
+		if !ok {
+			continue
+		}
+		idx := int64(i)
+		if hasTime {
+			idx = int64(math.Round(row["timestamp"] / float64(m.stepSec)))
+		}
+		s.values[idx] = v
+		if !found || idx < s.first {
+			s.first = idx
+		}
+		if !found || idx >= s.last {
+			s.last = idx
+			s.latest = v
+		}
+		found = true
+	}
+	if !found {
+		return naiveSeries{}, errors.New("no values in frame")
+	}
+	return s, nil
+}
@@ -0,0 +1,190 @@
+package models
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// hourlyWeeks returns hourly rows starting at the epoch where the value is
+// weekShape of the hour of the week plus offset(i).
+func hourlyWeeks(n int, offset func(i int) float64) FeatureFrame {
+	rows := make([]map[string]float64, n)
+	for i := range n {
+		rows[i] = map[string]float64{
+			"timestamp": float64(i * 3600),
+			"value":     weekShape(i%168) + offset(i),
+		}
+	}
+	return FeatureFrame{Rows: rows}
+}
+
+// weekShape is busy during "business hours" on five days of the week.
+func weekShape(hourOfWeek int) float64 {
+	if hourOfWeek/24 < 5 && hourOfWeek%24 >= 9 && hourOfWeek%24 < 17 {
+		return 500
+	}
+	return 100
+}
+
+func TestSeasonalNaiveModel_Methods(t *testing.T) {
+	// Three weeks; each week 10 higher than the previous, and the last 12
+	// hours 40 above the week before.
+	history := hourlyWeeks(3*168, func(i int) float64 {
+		if i >= 3*168-12 {
+			return 10*float64(i/168) + 40
+		}
+		return 10 * float64(i/168)
+	})
+
+	tests := []struct {
+		method SeasonalNaiveMethod
+		want   func(h int) float64 // h-th step ahead, 0-based
+	}{
+		// Same hour last week (week 2, offset 20).
+		{method: SeasonalNaiveLast, want: func(h int) float64 { return weekShape(h) + 20 }},
+		// Mean of weeks 0, 1, 2 (offsets 0, 10, 20).
+		{method: SeasonalNaiveAverage, want: func(h int) float64 { return weekShape(h) + 10 }},
+		// Average plus the recent level (offset 60) minus the level over the
+		// same 12 hours of weeks 1 and 0 (offsets 10 and 0; three weeks back
+		// is before the data).
+		{method: SeasonalNaiveLevel, want: func(h int) float64 { return weekShape(h) + 10 + 55 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			model := NewSeasonalNaiveModel("m", 3600, 24*3600, SeasonalNaiveOptions{
+				Method:      tt.method,
+				Seasons:     3,
+				LevelWindow: 12 * 3600,
+			})
+
+			ctx := context.Background()
+			if err := model.Train(ctx, history); err != nil {
+				t.Fatalf("Train() error = %v", err)
+			}
+			if got := model.Period(); got != secondsPerWeek {
+				t.Errorf("Period() = %d, want one week", got)
+			}
+
+			forecast, err := model.Predict(ctx, history)
+			if err != nil {
+				t.Fatalf("Predict() error = %v", err)
+			}
+			for h, got := range forecast.Values {
+				if want := tt.want(h); math.Abs(got-want) > 1e-9 {
+					t.Errorf("Values[%d] = %v, want %v", h, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSeasonalNaiveModel_FallsBackToShorterPeriod(t *testing.T) {
+	history := hourlyWeeks(72, func(int) float64 { return 0 })
+	model := NewSeasonalNaiveModel("m", 3600, 6*3600, SeasonalNaiveOptions{})
+
+	ctx := context.Background()
+	if err := model.Train(ctx, history); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	if got := model.Period(); got != secondsPerDay {
+		t.Errorf("Period() = %d, want one day with three days of history", got)
+	}
+
+	forecast, err := model.Predict(ctx, history)
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	// Hour 72 is midnight of day 3, same as hour 48.
+	for h, got := range forecast.Values {
+		if want := weekShape(48 + h); got != want {
+			t.Errorf("Values[%d] = %v, want %v from the day before", h, got, want)
+		}
+	}
+}
+
+func TestSeasonalNaiveModel_SkipsMissingSeasons(t *testing.T) {
+	history := hourlyWeeks(3*24, func(i int) float64 { return float64(i / 24 * 10) })
+	// Drop hour 48, which the first forecast step would repeat.
+	rows := append(history.Rows[:48:48], history.Rows[49:]...)
+
+	model := NewSeasonalNaiveModel("m", 3600, 3600, SeasonalNaiveOptions{
+		Method:  SeasonalNaiveLast,
+		Periods: []int{secondsPerDay},
+	})
+	ctx := context.Background()
+	if err := model.Train(ctx, FeatureFrame{Rows: rows}); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	forecast, err := model.Predict(ctx, FeatureFrame{Rows: rows})
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	// Two days back instead: hour 24 with offset 10.
+	if got, want := forecast.Values[0], weekShape(24)+10; got != want {
+		t.Errorf("Values[0] = %v, want %v", got, want)
+	}
+}
+
+func TestSeasonalNaiveModel_Quantiles(t *testing.T) {
+	history := hourlyWeeks(3*168, func(i int) float64 { return 5 * math.Sin(float64(i)) })
+	model := NewSeasonalNaiveModel("m", 3600, 3*3600, SeasonalNaiveOptions{Method: SeasonalNaiveAverage})
+
+	ctx := context.Background()
+	if err := model.Train(ctx, history); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	forecast, err := model.Predict(ctx, history)
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	p10, ok10 := forecast.Quantile(0.1)
+	p90, ok90 := forecast.Quantile(0.9)
+	if !ok10 || !ok90 {
+		t.Fatalf("Quantiles = %v, want p10 and p90", forecast.Quantiles)
+	}
+	for h := range forecast.Values {
+		if p10[h] >= p90[h] {
+			t.Errorf("step %d: p10 %v >= p90 %v", h, p10[h], p90[h])
+		}
+	}
+}
+
+func TestSeasonalNaiveModel_HorizonBelowStep(t *testing.T) {
+	history := hourlyWeeks(3*24, func(int) float64 { return 0 })
+	model := NewSeasonalNaiveModel("m", 3600, 1800, SeasonalNaiveOptions{})
+
+	ctx := context.Background()
+	if err := model.Train(ctx, history); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	forecast, err := model.Predict(ctx, history)
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	if len(forecast.Values) != 1 {
+		t.Errorf("len(Values) = %d, want one step when the horizon is shorter than a step", len(forecast.Values))
+	}
+}
+
+func TestSeasonalNaiveModel_Errors(t *testing.T) {
+	model := NewSeasonalNaiveModel("m", 3600, 3600, SeasonalNaiveOptions{})
+	ctx := context.Background()
+
+	if _, err := model.Predict(ctx, hourlyWeeks(48, func(int) float64 { return 0 })); err == nil {
+		t.Error("Predict() error = nil, want error before training")
+	}
+	if err := model.Train(ctx, hourlyWeeks(12, func(int) float64 { return 0 })); err == nil {
+		t.Error("Train() error = nil, want error for history shorter than a day")
+	}
+	if err := model.Train(ctx, FeatureFrame{}); err == nil {
+		t.Error("Train() error = nil, want error for empty history")
+	}
+}
+
+func TestSeasonalNaiveModel_Name(t *testing.T) {
+	if got := NewSeasonalNaiveModel("m", 60, 600, SeasonalNaiveOptions{}).Name(); got != "seasonal-naive(last)" {
+		t.Errorf("Name() = %q, want %q", got, "seasonal-naive(last)")
+	}
+}
@@ -0,0 +1,252 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// stateVersion is bumped whenever a persisted state layout changes
+// incompatibly. State with another version is rejected on restore.
+const stateVersion = 1
+
+// arimaState is the persisted form of a trained ARIMAModel.
+type arimaState struct {
+	Version  int           `json:"version"`
+	StepSec  int           `json:"step_sec"`
+	P        int           `json:"p"`
+	D        int           `json:"d"`
+	Q        int           `json:"q"`
+	Seasonal SeasonalOrder `json:"seasonal"`
+
+	AR     []float64 `json:"ar"`
+	MA     []float64 `json:"ma"`
+	SAR    []float64 `json:"sar,omitempty"`
+	SMA    []float64 `json:"sma,omitempty"`
+	Mean   float64   `json:"mean"`
+	Sigma2 float64   `json:"sigma2"`
+
+	LastValues   []float64 `json:"last_values,omitempty"`
+	LastErrors   []float64 `json:"last_errors"`
+	ARPoly       []float64 `json:"ar_poly,omitempty"`
+	MAPoly       []float64 `json:"ma_poly,omitempty"`
+	LastCentered []float64 `json:"last_centered,omitempty"`
+	Tail         []float64 `json:"tail,omitempty"`
+
+	Regressors    []string  `json:"regressors,omitempty"`
+	Beta          []float64 `json:"beta,omitempty"`
+	LastExog      []float64 `json:"last_exog,omitempty"`
+	LastTimestamp float64   `json:"last_timestamp,omitempty"`
+}
+
+// MarshalState encodes the fitted orders, coefficients and the recent values
+// and errors the forecast recursion starts from.
+//
+// Returns an error if the model has not been trained.
+func (m *ARIMAModel) MarshalState() ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.trained {
+		return nil, errors.New("model not trained, call Train() first")
+	}
+
+	return json.Marshal(arimaState{
+		Version:      stateVersion,
+		StepSec:      m.stepSec,
+		P:            m.p,
+		D:            m.d,
+		Q:            m.q,
+		Seasonal:     m.seasonal,
+		AR:           m.arCoeffs,
+		MA:           m.maCoeffs,
+		SAR:          m.sarCoeffs,
+		SMA:          m.smaCoeffs,
+		Mean:         m.mean,
+		Sigma2:       m.sigma2,
+		LastValues:   m.lastValues,
+		LastErrors:   m.lastErrors,
+		ARPoly:       m.arPoly,
+		MAPoly:       m.maPoly,
+		LastCentered: m.lastCentered,
+		Tail:         m.tail,
+
+		Regressors:    m.exog.names(),
+		Beta:          m.exogFit.beta,
+		LastExog:      m.exogFit.last,
+		LastTimestamp: m.exogFit.lastTs,
+	})
+}
+
+// UnmarshalState restores state produced by MarshalState, after which Predict
+// works without a Train call. Orders that are searched automatically are taken
+// from the state; fixed orders, the seasonal order and the step must match the
+// model's configuration.
+func (m *ARIMAModel) UnmarshalState(data []byte) error {
+	var s arimaState
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("decode arima state: %w", err)
+	}
+	if err := m.checkState(s); err != nil {
+		return fmt.Errorf("incompatible arima state: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.p, m.d, m.q = s.P, s.D, s.Q
+	m.trained = true
+	m.arCoeffs = s.AR
+	m.maCoeffs = s.MA
+	m.sarCoeffs = s.SAR
+	m.smaCoeffs = s.SMA
+	m.mean = s.Mean
+	m.sigma2 = s.Sigma2
+	m.lastValues = s.LastValues
+	m.lastErrors = s.LastErrors
+	m.arPoly = s.ARPoly
+	m.maPoly = s.MAPoly
+	m.lastCentered = s.LastCentered
+	m.tail = s.Tail
+	m.exogFit = exogFit{beta: s.Beta, last: s.LastExog, lastTs: s.LastTimestamp}
+
+	return nil
+}
+
+// checkState validates decoded state against the model configuration.
+// Only orders that are not searched automatically must match.
+func (m *ARIMAModel) checkState(s arimaState) error {
+	switch {
+	case s.Version != stateVersion:
+		return fmt.Errorf("version %d, want %d", s.Version, stateVersion)
+	case s.StepSec != m.stepSec:
+		return fmt.Errorf("step %ds, want %ds", s.StepSec, m.stepSec)
+	case s.Seasonal != m.seasonal:
+		return fmt.Errorf("seasonal order %+v, want %+v", s.Seasonal, m.seasonal)
+	case s.P < 0 || s.Q < 0 || s.D < 0 || s.D > 2:
+		return fmt.Errorf("invalid order (%d,%d,%d)", s.P, s.D, s.Q)
+	}
+
+	m.mu.RLock()
+	p, d, q := m.p, m.d, m.q
+	m.mu.RUnlock()
+	if (!m.autoP && s.P != p) || (!m.autoD && s.D != d) || (!m.autoQ && s.Q != q) {
+		return fmt.Errorf("order (%d,%d,%d), want (%d,%d,%d)", s.P, s.D, s.Q, p, d, q)
+	}
+
+	if names := m.exog.names(); !slices.Equal(s.Regressors, names) ||
+		len(s.Beta) != len(names) || len(s.LastExog) != len(names) {
+		return fmt.Errorf("regressors %v, want %v", s.Regressors, names)
+	}
+
+	if len(s.AR) != s.P || len(s.MA) != s.Q {
+		return errors.New("coefficient count does not match order")
+	}
+	if m.seasonal.enabled() {
+		if len(s.SAR) != m.seasonal.P || len(s.SMA) != m.seasonal.Q || len(s.Tail) == 0 {
+			return errors.New("seasonal state does not match order")
+		}
+		return nil
+	}
+	if len(s.LastValues) == 0 || len(s.LastErrors) > s.Q {
+		return errors.New("missing recent values")
+	}
+	return nil
+}
+
+// baselineState is the persisted form of a trained BaselineModel.
+type baselineState struct {
+	Version   int                  `json:"version"`
+	StepSec   int                  `json:"step_sec"`
+	Minute    map[int]patternState `json:"minute"`
+	Hour      map[int]patternState `json:"hour"`
+	Week      map[int]patternState `json:"week,omitempty"`
+	Residuals [][]float64          `json:"residuals,omitempty"`
+}
+
+// patternState is the persisted form of a seasonalPattern.
+type patternState struct {
+	Mean  float64 `json:"mean"`
+	Max   float64 `json:"max"`
+	Min   float64 `json:"min"`
+	Count int     `json:"count"`
+}
+
+// MarshalState encodes the learned minute-of-hour, hour-of-day and hour-of-week patterns
+// and the backtested errors used for quantiles.
+//
+// Returns an error if no pattern has been learned yet.
+func (m *BaselineModel) MarshalState() ([]byte, error) {
+	if len(m.minuteSeasonality) == 0 && len(m.hourSeasonality) == 0 {
+		return nil, errors.New("model not trained, call Train() first")
+	}
+
+	return json.Marshal(baselineState{
+		Version:   stateVersion,
+		StepSec:   m.stepSec,
+		Minute:    encodePatterns(m.minuteSeasonality),
+		Hour:      encodePatterns(m.hourSeasonality),
+		Week:      encodePatterns(m.weekSeasonality),
+		Residuals: m.residuals,
+	})
+}
+
+// UnmarshalState restores state produced by MarshalState. Later Train calls
+// refresh the restored buckets as new observations arrive, so patterns from
+// before a restart keep serving buckets the current window does not cover.
+func (m *BaselineModel) UnmarshalState(data []byte) error {
+	var s baselineState
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("decode baseline state: %w", err)
+	}
+	if s.Version != stateVersion {
+		return fmt.Errorf("incompatible baseline state: version %d, want %d", s.Version, stateVersion)
+	}
+	if s.StepSec != m.stepSec {
+		return fmt.Errorf("incompatible baseline state: step %ds, want %ds", s.StepSec, m.stepSec)
+	}
+
+	minute, err := decodePatterns(s.Minute, 60)
+	if err != nil {
+		return fmt.Errorf("incompatible baseline state: minute %w", err)
+	}
+	hour, err := decodePatterns(s.Hour, 24)
+	if err != nil {
+		return fmt.Errorf("incompatible baseline state: hour %w", err)
+	}
+	week, err := decodePatterns(s.Week, 168)
+	if err != nil {
+		return fmt.Errorf("incompatible baseline state: week %w", err)
+	}
+
+	m.minuteSeasonality = minute
+	m.hourSeasonality = hour
+	m.weekSeasonality = week
+	m.residuals = s.Residuals
+	return nil
+}
+
+// encodePatterns converts seasonal patterns to their persisted form.
+func encodePatterns(patterns map[int]*seasonalPattern) map[int]patternState {
+	out := make(map[int]patternState, len(patterns))
+	for k, p := range patterns {
+		if p != nil {
+			out[k] = patternState{Mean: p.mean, Max: p.max, Min: p.min, Count: p.count}
+		}
+	}
+	return out
+}
+
+// decodePatterns converts persisted patterns back, rejecting buckets outside
+// [0, buckets).
+func decodePatterns(states map[int]patternState, buckets int) (map[int]*seasonalPattern, error) {
+	out := make(map[int]*seasonalPattern, len(states))
+	for k, s := range states {
+		if k < 0 || k >= buckets {
+			return nil, fmt.Errorf("bucket %d out of range", k)
+		}
+		out[k] = &seasonalPattern{mean: s.Mean, max: s.Max, min: s.Min, count: s.Count}
+	}
+	return out, nil
+}
@@ -0,0 +1,175 @@
+package models
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestARIMAModel_State_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		model func() *ARIMAModel
+	}{
+		{name: "arima", model: func() *ARIMAModel { return NewARIMAModel("m", 60, 600, 2, 1, 1) }},
+		{name: "auto orders", model: func() *ARIMAModel { return NewARIMAModel("m", 60, 600, 0, 0, 0) }},
+		{name: "sarima", model: func() *ARIMAModel {
+			return NewSARIMAModel("m", 60, 600, 1, 0, 0, SeasonalOrder{P: 1, D: 1, Period: 24})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			history := syntheticComplex(200)
+
+			trained := tt.model()
+			if err := trained.Train(ctx, history); err != nil {
+				t.Fatalf("Train() error = %v", err)
+			}
+			state, err := trained.MarshalState()
+			if err != nil {
+				t.Fatalf("MarshalState() error = %v", err)
+			}
+
+			restored := tt.model()
+			if err := restored.UnmarshalState(state); err != nil {
+				t.Fatalf("UnmarshalState() error = %v", err)
+			}
+			if restored.Name() != trained.Name() {
+				t.Errorf("Name() = %q, want %q", restored.Name(), trained.Name())
+			}
+
+			want, err := trained.Predict(ctx, history)
+			if err != nil {
+				t.Fatalf("Predict() error = %v", err)
+			}
+			got, err := restored.Predict(ctx, FeatureFrame{})
+			if err != nil {
+				t.Fatalf("Predict() after restore error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("restored forecast = %v, want %v", got.Values, want.Values)
+			}
+		})
+	}
+}
+
+func TestARIMAModel_MarshalState_Untrained(t *testing.T) {
+	if _, err := NewARIMAModel("m", 60, 600, 1, 1, 1).MarshalState(); err == nil {
+		t.Error("MarshalState() error = nil, want error for untrained model")
+	}
+}
+
+func TestARIMAModel_UnmarshalState_Incompatible(t *testing.T) {
+	trained := NewARIMAModel("m", 60, 600, 2, 1, 1)
+	if err := trained.Train(context.Background(), syntheticComplex(200)); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	state, err := trained.MarshalState()
+	if err != nil {
+		t.Fatalf("MarshalState() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		model *ARIMAModel
+		data  []byte
+	}{
+		{name: "different fixed order", model: NewARIMAModel("m", 60, 600, 1, 1, 1), data: state},
+		{name: "different step", model: NewARIMAModel("m", 30, 600, 2, 1, 1), data: state},
+		{name: "seasonal", model: NewSARIMAModel("m", 60, 600, 2, 1, 1, SeasonalOrder{D: 1, Period: 24}), data: state},
+		{name: "corrupt", model: NewARIMAModel("m", 60, 600, 2, 1, 1), data: []byte("{")},
+		{name: "wrong version", model: NewARIMAModel("m", 60, 600, 2, 1, 1), data: []byte(`{"version":99}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.model.UnmarshalState(tt.data); err == nil {
+				t.Fatal("UnmarshalState() error = nil, want error")
+			}
+			if _, err := tt.model.Predict(context.Background(), FeatureFrame{}); err == nil {
+				t.Error("Predict() error = nil, want model to stay untrained")
+			}
+		})
+	}
+}
+
+func TestARIMAModel_UnmarshalState_AutoOrderAdopted(t *testing.T) {
+	state := []byte(`{"version":1,"step_sec":60,"p":2,"d":0,"q":0,"seasonal":{},` +
+		`"ar":[0.5,0.2],"ma":[],"mean":0,"sigma2":1,"last_values":[10,11],"last_errors":[]}`)
+
+	model := NewARIMAModel("m", 60, 600, 0, 0, 0)
+	if err := model.UnmarshalState(state); err != nil {
+		t.Fatalf("UnmarshalState() error = %v", err)
+	}
+	if got := model.Name(); got != "arima(2,0,0)" {
+		t.Errorf("Name() = %q, want %q", got, "arima(2,0,0)")
+	}
+}
+
+func TestBaselineModel_State_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	history := FeatureFrame{}
+	for i := range 240 {
+		history.Rows = append(history.Rows, map[string]float64{
+			"value":     100 + float64(i%60),
+			"minute":    float64(i % 60),
+			"hour":      float64(i / 60),
+			"day":       1,
+			"timestamp": float64(i * 60),
+		})
+	}
+
+	trained := NewBaselineModel("m", 60, 600)
+	if err := trained.Train(ctx, history); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	state, err := trained.MarshalState()
+	if err != nil {
+		t.Fatalf("MarshalState() error = %v", err)
+	}
+
+	restored := NewBaselineModel("m", 60, 600)
+	if err := restored.UnmarshalState(state); err != nil {
+		t.Fatalf("UnmarshalState() error = %v", err)
+	}
+
+	want, err := trained.Predict(ctx, history)
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	got, err := restored.Predict(ctx, history)
+	if err != nil {
+		t.Fatalf("Predict() after restore error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("restored forecast = %v, want %v", got.Values, want.Values)
+	}
+}
+
+func TestBaselineModel_UnmarshalState_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "corrupt", data: "not json"},
+		{name: "wrong version", data: `{"version":2,"step_sec":60}`},
+		{name: "different step", data: `{"version":1,"step_sec":30}`},
+		{name: "bucket out of range", data: `{"version":1,"step_sec":60,"hour":{"24":{"mean":1}}}`},
+		{name: "week bucket out of range", data: `{"version":1,"step_sec":60,"week":{"168":{"mean":1}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := NewBaselineModel("m", 60, 600)
+			if err := model.UnmarshalState([]byte(tt.data)); err == nil {
+				t.Error("UnmarshalState() error = nil, want error")
+			}
+		})
+	}
+
+	if _, err := NewBaselineModel("m", 60, 600).MarshalState(); err == nil {
+		t.Error("MarshalState() error = nil, want error for untrained model")
+	}
+}
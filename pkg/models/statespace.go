@@ -0,0 +1,767 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultStateSpaceFitBudget bounds the likelihood optimisation when the
+	// training context has no deadline.
+	defaultStateSpaceFitBudget = 2 * time.Second
+
+	// stateSpaceDiffuse is the initial state variance, in units of the
+	// standardised series, standing in for an unknown initial state.
+	stateSpaceDiffuse = 1e6
+
+	// Bounds of the log variance ratios searched by the optimiser.
+	stateSpaceMinLogRatio = -15
+	stateSpaceMaxLogRatio = 10
+)
+
+// StateSpaceOptions configures a StateSpaceModel. Zero values select the
+// defaults documented on each field.
+type StateSpaceOptions struct {
+	// Trend adds a stochastic slope to the level (local linear trend). The
+	// default is a local level: a random walk observed with noise.
+	Trend bool
+
+	// Period is the season length in steps. 0 disables the seasonal component.
+	Period int
+
+	// Harmonics is the number of trigonometric terms of the seasonal
+	// component (default 3, at most Period/2). More terms follow sharper
+	// seasonal shapes but add two states each.
+	Harmonics int
+
+	// MaxIterations bounds the likelihood optimisation (default 200).
+	MaxIterations int
+}
+
+// StateSpaceComponents holds the smoothed components of a series, one value
+// per step including steps without an observation. Slope and Seasonal are
+// nil when the model has no such component.
+type StateSpaceComponents struct {
+	Level    []float64
+	Slope    []float64
+	Seasonal []float64
+}
+
+// StateSpaceModel is a structural time series model:
+//
+//	value(t)    = level(t) + seasonal(t) + ε(t)
+//	level(t+1)  = level(t) + slope(t) + η(t)
+//	slope(t+1)  = slope(t) + ζ(t)                       (with Trend)
+//	seasonal(t) = Σⱼ γⱼ(t), γⱼ rotating at frequency j/Period (with Period)
+//
+// where ε, η, ζ and the seasonal disturbances are independent Gaussian noise.
+// Their variances are estimated by maximum likelihood, computed exactly by a
+// Kalman filter, so the model adapts how quickly it follows the data: a
+// large level variance relative to the noise makes it track recent values, a
+// small one averages them.
+//
+// Missing observations need no imputation: a "value" that is absent or NaN,
+// and a gap between row timestamps, are steps the filter predicts through
+// without an update. Forecast variances come from the same recursions and
+// give exact Gaussian quantiles, which widen as the level, slope and season
+// drift over the horizon.
+//
+// The model implements IncrementalModel: Update filters new rows with the
+// fitted variances. Smooth returns the components over a history.
+//
+// The model is thread-safe for concurrent Predict calls after training.
+type StateSpaceModel struct {
+	metric  string
+	stepSec int
+	horizon int
+	opts    StateSpaceOptions
+
+	mu      sync.RWMutex
+	trained bool
+	fit     stateSpaceFit
+}
+
+// stateSpaceFit is the fitted model. The system works on the standardised
+// series (value - shift) / scale with an observation variance of 1; sigma2
+// scales every variance back.
+type stateSpaceFit struct {
+	sys    stateSpaceSystem
+	shift  float64
+	scale  float64
+	sigma2 float64
+
+	state  []float64   // predicted state for the step after the last observation
+	cov    [][]float64 // its covariance, relative to sigma2
+	lastTs float64     // timestamp of the last step
+	timed  bool        // whether rows carry timestamps
+}
+
+// stateSpaceSystem holds the system matrices. The state is
+// [level, slope?, γ₁, γ₁*, ..., γₖ, γₖ*].
+type stateSpaceSystem struct {
+	T [][]float64 // transition
+	Z []float64   // observation vector
+	Q []float64   // diagonal state disturbance variances
+	H float64     // observation variance
+}
+
+// NewStateSpaceModel creates a new structural time series model.
+func NewStateSpaceModel(metric string, stepSec, horizon int, opts StateSpaceOptions) *StateSpaceModel {
+	if opts.Period < 2 {
+		opts.Period = 0
+	}
+	if opts.Harmonics <= 0 {
+		opts.Harmonics = 3
+	}
+	opts.Harmonics = min(opts.Harmonics, opts.Period/2)
+	if opts.MaxIterations <= 0 {
+		opts.MaxIterations = 200
+	}
+
+	return &StateSpaceModel{
+		metric:  metric,
+		stepSec: stepSec,
+		horizon: horizon,
+		opts:    opts,
+	}
+}
+
+// Name returns the model identifier, e.g. "statespace(level)" or
+// "statespace(trend,1440)" with a seasonal period of 1440 steps.
+func (m *StateSpaceModel) Name() string {
+	name := "statespace(level"
+	if m.opts.Trend {
+		name = "statespace(trend"
+	}
+	if m.opts.Period > 0 {
+		name += "," + strconv.Itoa(m.opts.Period)
+	}
+	return name + ")"
+}
+
+// Train estimates the disturbance variances by maximising the likelihood
+// with a Nelder-Mead search, then filters the history to obtain the state the
+// forecast starts from.
+//
+// The search stops early when the context's deadline passes
+// (defaultStateSpaceFitBudget when it has none) and keeps the best
+// variances found so far.
+//
+// Returns error if:
+//   - Context is cancelled
+//   - History has too few observations for the number of states
+func (m *StateSpaceModel) Train(ctx context.Context, history FeatureFrame) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	y, lastTs, timed := m.series(history)
+	observed := 0
+	for _, v := range y {
+		if !math.IsNaN(v) {
+			observed++
+		}
+	}
+	dim := m.stateDim()
+	if need := dim + 10; observed < need {
+		return fmt.Errorf("need at least %d observations for %s, got %d", need, m.Name(), observed)
+	}
+
+	shift, scale := nanMeanStddev(y)
+	if scale < 1e-9 {
+		scale = 1
+	}
+	z := make([]float64, len(y))
+	for i, v := range y {
+		z[i] = (v - shift) / scale
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultStateSpaceFitBudget)
+	}
+	objective := func(theta []float64) float64 {
+		f := kalmanFilter(m.system(theta, 1), z, nil)
+		return -f.logLikelihood()
+	}
+	theta0 := make([]float64, m.nParams())
+	for i := range theta0 {
+		theta0[i] = math.Log(0.1)
+	}
+	theta, _ := nelderMead(objective, theta0, 1, m.opts.MaxIterations, func() bool {
+		return ctx.Err() != nil || time.Now().After(deadline)
+	})
+	if ctx.Err() == context.Canceled {
+		return ctx.Err()
+	}
+
+	sys := m.system(theta, 1)
+	f := kalmanFilter(sys, z, nil)
+	if f.observed == 0 || math.IsNaN(f.sigma2()) {
+		return errors.New("state space likelihood could not be evaluated")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.fit = stateSpaceFit{
+		sys:    sys,
+		shift:  shift,
+		scale:  scale,
+		sigma2: f.sigma2(),
+		state:  f.state,
+		cov:    f.cov,
+		lastTs: lastTs,
+		timed:  timed,
+	}
+	m.trained = true
+	return nil
+}
+
+// Predict forecasts the horizon from the end of the training data. Rows of
+// features after it (by timestamp) are filtered first without changing the
+// model, so the forecast starts from the latest observation.
+//
+// Quantiles are Gaussian with the exact forecast variance of the model.
+// Components holds the filtered level at the origin, the trend's change from
+// it (with Trend) and the seasonal effect (with Period), which add up to
+// Values before clamping.
+//
+// Returns error if:
+//   - Context is cancelled
+//   - Model has not been trained
+func (m *StateSpaceModel) Predict(ctx context.Context, features FeatureFrame) (Forecast, error) {
+	if ctx.Err() != nil {
+		return Forecast{}, ctx.Err()
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.trained {
+		return Forecast{}, errors.New("model not trained, call Train() first")
+	}
+	fit := &m.fit
+
+	state, cov := fit.state, fit.cov
+	if fit.timed {
+		if y, _ := m.seriesAfter(features, fit.lastTs); len(y) > 0 {
+			state, cov = fit.filterFrom(y)
+		}
+	}
+
+	steps := max(m.horizon/m.stepSec, 1)
+	values := make([]float64, steps)
+	stddev := make([]float64, steps)
+	components := map[string][]float64{ComponentLevel: make([]float64, steps)}
+	if m.opts.Trend {
+		components[ComponentTrend] = make([]float64, steps)
+	}
+	if m.opts.Period > 0 {
+		components[ComponentSeasonal] = make([]float64, steps)
+	}
+	a := slices.Clone(state)
+	P := cloneMatrix(cov)
+	for h := range steps {
+		mean, variance := fit.sys.observe(a, P)
+		values[h] = max(fit.shift+fit.scale*mean, 0)
+		stddev[h] = fit.scale * math.Sqrt(fit.sigma2*variance)
+
+		components[ComponentLevel][h] = fit.shift + fit.scale*state[0]
+		if trend := components[ComponentTrend]; trend != nil {
+			trend[h] = fit.scale * (a[0] - state[0])
+		}
+		if seasonal := components[ComponentSeasonal]; seasonal != nil {
+			for j := m.seasonalOffset(); j < len(a); j += 2 {
+				seasonal[h] += fit.scale * a[j]
+			}
+		}
+		a, P = fit.sys.advance(a, P)
+	}
+
+	return Forecast{
+		Metric:     m.metric,
+		Values:     values,
+		StepSec:    m.stepSec,
+		Horizon:    m.horizon,
+		Quantiles:  normalQuantiles(values, stddev),
+		Components: components,
+	}, nil
+}
+
+// Smooth returns the level, slope and seasonal components over history as
+// estimated from all of its observations (fixed-interval smoothing), in the
+// metric's units. Steps without an observation get interpolated components.
+//
+// Returns error if the context is cancelled or the model has not been trained.
+func (m *StateSpaceModel) Smooth(ctx context.Context, history FeatureFrame) (StateSpaceComponents, error) {
+	if ctx.Err() != nil {
+		return StateSpaceComponents{}, ctx.Err()
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.trained {
+		return StateSpaceComponents{}, errors.New("model not trained, call Train() first")
+	}
+	fit := &m.fit
+
+	y, _, _ := m.series(history)
+	z := make([]float64, len(y))
+	for i, v := range y {
+		z[i] = (v - fit.shift) / fit.scale
+	}
+	var steps []kalmanStep
+	kalmanFilter(fit.sys, z, &steps)
+	smoothed := fit.sys.smooth(steps)
+
+	out := StateSpaceComponents{Level: make([]float64, len(y))}
+	if m.opts.Trend {
+		out.Slope = make([]float64, len(y))
+	}
+	if m.opts.Period > 0 {
+		out.Seasonal = make([]float64, len(y))
+	}
+	for t, a := range smoothed {
+		out.Level[t] = fit.shift + fit.scale*a[0]
+		if out.Slope != nil {
+			out.Slope[t] = fit.scale * a[1]
+		}
+		if out.Seasonal != nil {
+			for j := m.seasonalOffset(); j < len(a); j += 2 {
+				out.Seasonal[t] += fit.scale * a[j]
+			}
+		}
+	}
+	return out, nil
+}
+
+// stateDim returns the number of states.
+func (m *StateSpaceModel) stateDim() int {
+	return m.seasonalOffset() + 2*m.opts.Harmonics
+}
+
+// seasonalOffset returns the index of the first seasonal state.
+func (m *StateSpaceModel) seasonalOffset() int {
+	if m.opts.Trend {
+		return 2
+	}
+	return 1
+}
+
+// nParams returns the number of estimated variance ratios: level, then
+// slope and seasonal when enabled.
+func (m *StateSpaceModel) nParams() int {
+	n := 1
+	if m.opts.Trend {
+		n++
+	}
+	if m.opts.Harmonics > 0 {
+		n++
+	}
+	return n
+}
+
+// system builds the system matrices for the log variance ratios theta
+// (relative to the observation variance h).
+func (m *StateSpaceModel) system(theta []float64, h float64) stateSpaceSystem {
+	dim := m.stateDim()
+	ratio := func(i int) float64 {
+		return h * math.Exp(min(max(theta[i], stateSpaceMinLogRatio), stateSpaceMaxLogRatio))
+	}
+
+	sys := stateSpaceSystem{
+		T: make([][]float64, dim),
+		Z: make([]float64, dim),
+		Q: make([]float64, dim),
+		H: h,
+	}
+	for i := range sys.T {
+		sys.T[i] = make([]float64, dim)
+	}
+
+	sys.T[0][0], sys.Z[0], sys.Q[0] = 1, 1, ratio(0)
+	param := 1
+	if m.opts.Trend {
+		sys.T[0][1], sys.T[1][1], sys.Q[1] = 1, 1, ratio(param)
+		param++
+	}
+	off := m.seasonalOffset()
+	for j := range m.opts.Harmonics {
+		i := off + 2*j
+		lambda := 2 * math.Pi * float64(j+1) / float64(m.opts.Period)
+		c, s := math.Cos(lambda), math.Sin(lambda)
+		sys.T[i][i], sys.T[i][i+1] = c, s
+		sys.T[i+1][i], sys.T[i+1][i+1] = -s, c
+		sys.Z[i] = 1
+		sys.Q[i], sys.Q[i+1] = ratio(param), ratio(param)
+	}
+	return sys
+}
+
+// series places the values of frame on the step grid. Rows with timestamps
+// are positioned by them, so gaps become missing steps; rows without are
+// consecutive. Missing values are NaN. Also returns the timestamp of the last
+// step and whether rows carry timestamps.
+func (m *StateSpaceModel) series(frame FeatureFrame) ([]float64, float64, bool) {
+	if len(frame.Rows) > 0 {
+		if ts, ok := frame.Rows[0]["timestamp"]; ok {
+			y, lastTs := m.seriesAfter(frame, ts-float64(m.stepSec))
+			return y, lastTs, true
+		}
+	}
+	y := make([]float64, len(frame.Rows))
+	for i, row := range frame.Rows {
+		y[i] = rowValue(row)
+	}
+	return y, 0, false
+}
+
+// seriesAfter places the rows of frame timestamped after origin on the step
+// grid starting one step after origin, and returns the timestamp of the last
+// step. Rows without a timestamp are ignored.
+func (m *StateSpaceModel) seriesAfter(frame FeatureFrame, origin float64) ([]float64, float64) {
+	step := float64(m.stepSec)
+	var y []float64
+	for _, row := range frame.Rows {
+		ts, ok := row["timestamp"]
+		if !ok {
+			continue
+		}
+		i := int(math.Round((ts-origin)/step)) - 1
+		if i < 0 {
+			continue
+		}
+		for len(y) <= i {
+			y = append(y, math.NaN())
+		}
+		y[i] = rowValue(row)
+	}
+	return y, origin + float64(len(y))*step
+}
+
+// rowValue returns the row's value, NaN when it has none.
+func rowValue(row map[string]float64) float64 {
+	if v, ok := row["value"]; ok {
+		return v
+	}
+	return math.NaN()
+}
+
+// filterFrom filters the raw values y (NaN for missing) from the stored
+// state and returns the predicted state and covariance after them.
+func (f *stateSpaceFit) filterFrom(y []float64) ([]float64, [][]float64) {
+	a, P := slices.Clone(f.state), cloneMatrix(f.cov)
+	for _, v := range y {
+		a, P, _ = f.sys.step(a, P, (v-f.shift)/f.scale)
+	}
+	return a, P
+}
+
+// kalmanStep records the filter at one step for the smoother.
+type kalmanStep struct {
+	a    []float64   // predicted state
+	P    [][]float64 // predicted covariance
+	v, F float64     // innovation and its variance; F is 0 when missing
+	K    []float64   // P·Z'/F
+}
+
+// kalmanResult summarises a filter pass.
+type kalmanResult struct {
+	state    []float64
+	cov      [][]float64
+	observed int     // observations counted in the likelihood
+	sumV2F   float64 // Σ v²/F
+	sumLogF  float64 // Σ log F
+}
+
+// sigma2 returns the maximum likelihood observation variance scale.
+func (r kalmanResult) sigma2() float64 {
+	return r.sumV2F / float64(r.observed)
+}
+
+// logLikelihood returns the log-likelihood with the variance scale
+// concentrated out, or -Inf when it cannot be evaluated.
+func (r kalmanResult) logLikelihood() float64 {
+	if r.observed == 0 {
+		return math.Inf(-1)
+	}
+	s2 := r.sigma2()
+	if s2 <= 0 || math.IsNaN(s2) || math.IsInf(s2, 0) {
+		return math.Inf(-1)
+	}
+	n := float64(r.observed)
+	return -0.5 * (n*(math.Log(2*math.Pi*s2)+1) + r.sumLogF)
+}
+
+// kalmanFilter runs the filter over y (NaN for missing) from a diffuse
+// initial state centred on the first observation. The first observations,
+// one per state, only initialise the state and are left out of the
+// likelihood. With steps non-nil, every step is recorded for the smoother.
+func kalmanFilter(sys stateSpaceSystem, y []float64, steps *[]kalmanStep) kalmanResult {
+	dim := len(sys.Z)
+	a := make([]float64, dim)
+	for _, v := range y {
+		if !math.IsNaN(v) {
+			a[0] = v
+			break
+		}
+	}
+	P := make([][]float64, dim)
+	for i := range P {
+		P[i] = make([]float64, dim)
+		P[i][i] = stateSpaceDiffuse
+	}
+
+	var r kalmanResult
+	seen := 0
+	for _, v := range y {
+		if steps != nil {
+			*steps = append(*steps, kalmanStep{a: a, P: P})
+		}
+		var s kalmanStep
+		a, P, s = sys.step(a, P, v)
+		if steps != nil {
+			last := &(*steps)[len(*steps)-1]
+			last.v, last.F, last.K = s.v, s.F, s.K
+		}
+		if s.F == 0 {
+			continue
+		}
+		if seen++; seen <= dim {
+			continue
+		}
+		r.observed++
+		r.sumV2F += s.v * s.v / s.F
+		r.sumLogF += math.Log(s.F)
+	}
+	r.state, r.cov = a, P
+	return r
+}
+
+// step filters one observation v (NaN for missing) given the predicted state
+// a and covariance P, and returns the prediction for the next step together
+// with the innovation.
+func (sys stateSpaceSystem) step(a []float64, P [][]float64, v float64) ([]float64, [][]float64, kalmanStep) {
+	var s kalmanStep
+	if !math.IsNaN(v) {
+		PZ := matVec(P, sys.Z)
+		F := dot(sys.Z, PZ) + sys.H
+		if F > 0 {
+			s.v = v - dot(sys.Z, a)
+			s.F = F
+			s.K = make([]float64, len(PZ))
+			for i := range PZ {
+				s.K[i] = PZ[i] / F
+			}
+			filtered := make([]float64, len(a))
+			for i := range a {
+				filtered[i] = a[i] + s.K[i]*s.v
+			}
+			Pf := make([][]float64, len(P))
+			for i := range P {
+				Pf[i] = make([]float64, len(P))
+				for j := range P {
+					Pf[i][j] = P[i][j] - PZ[i]*PZ[j]/F
+				}
+			}
+			a, P = filtered, Pf
+		}
+	}
+	a, P = sys.advance(a, P)
+	return a, P, s
+}
+
+// advance moves a state and covariance one step ahead: T·a and T·P·T' + Q.
+func (sys stateSpaceSystem) advance(a []float64, P [][]float64) ([]float64, [][]float64) {
+	dim := len(a)
+	next := matVec(sys.T, a)
+	TP := make([][]float64, dim)
+	for i := range TP {
+		TP[i] = matVec(P, sys.T[i]) // row i of T·P, as P is symmetric
+	}
+	Pn := make([][]float64, dim)
+	for i := range Pn {
+		Pn[i] = make([]float64, dim)
+	}
+	for i := range dim {
+		for j := i; j < dim; j++ {
+			v := dot(TP[i], sys.T[j])
+			if i == j {
+				v += sys.Q[i]
+			}
+			Pn[i][j], Pn[j][i] = v, v
+		}
+	}
+	return next, Pn
+}
+
+// observe returns the mean and variance of the observation for a predicted
+// state and covariance.
+func (sys stateSpaceSystem) observe(a []float64, P [][]float64) (float64, float64) {
+	return dot(sys.Z, a), dot(sys.Z, matVec(P, sys.Z)) + sys.H
+}
+
+// smooth runs the backward state smoothing recursion over the recorded
+// filter steps and returns the smoothed state at every step:
+//
+//	r(t-1) = Z'·(v/F - K'·T'·r(t)) + T'·r(t)    (T'·r(t) when missing)
+//	â(t)   = a(t) + P(t)·r(t-1)
+func (sys stateSpaceSystem) smooth(steps []kalmanStep) [][]float64 {
+	dim := len(sys.Z)
+	out := make([][]float64, len(steps))
+	r := make([]float64, dim)
+	for t := len(steps) - 1; t >= 0; t-- {
+		s := steps[t]
+		Tr := make([]float64, dim) // T'·r
+		for i := range dim {
+			for j := range dim {
+				Tr[i] += sys.T[j][i] * r[j]
+			}
+		}
+		if s.F > 0 {
+			u := s.v/s.F - dot(s.K, Tr)
+			for i := range Tr {
+				Tr[i] += sys.Z[i] * u
+			}
+		}
+		r = Tr
+
+		Pr := matVec(s.P, r)
+		out[t] = make([]float64, dim)
+		for i := range dim {
+			out[t][i] = s.a[i] + Pr[i]
+		}
+	}
+	return out
+}
+
+// nelderMead minimises f starting from x0 with an initial simplex of the
+// given size. It stops after maxIter iterations, when the simplex values
+// agree to a relative 1e-8, or when stop returns true, and returns the best
+// point and its value.
+func nelderMead(f func([]float64) float64, x0 []float64, size float64, maxIter int, stop func() bool) ([]float64, float64) {
+	n := len(x0)
+	type vertex struct {
+		x []float64
+		f float64
+	}
+	eval := func(x []float64) vertex {
+		v := f(x)
+		if math.IsNaN(v) {
+			v = math.Inf(1)
+		}
+		return vertex{x, v}
+	}
+
+	simplex := []vertex{eval(slices.Clone(x0))}
+	for i := range n {
+		x := slices.Clone(x0)
+		x[i] += size
+		simplex = append(simplex, eval(x))
+	}
+	// along returns centroid + t·(centroid - worst).
+	along := func(centroid []float64, worst []float64, t float64) []float64 {
+		x := make([]float64, n)
+		for i := range x {
+			x[i] = centroid[i] + t*(centroid[i]-worst[i])
+		}
+		return x
+	}
+
+	for range maxIter {
+		slices.SortFunc(simplex, func(a, b vertex) int {
+			switch {
+			case a.f < b.f:
+				return -1
+			case a.f > b.f:
+				return 1
+			}
+			return 0
+		})
+		best, worst := simplex[0], simplex[n]
+		if math.Abs(worst.f-best.f) <= 1e-8*(math.Abs(best.f)+1e-8) || stop() {
+			break
+		}
+
+		centroid := make([]float64, n)
+		for _, v := range simplex[:n] {
+			for i := range centroid {
+				centroid[i] += v.x[i] / float64(n)
+			}
+		}
+
+		reflected := eval(along(centroid, worst.x, 1))
+		switch {
+		case reflected.f < best.f:
+			if expanded := eval(along(centroid, worst.x, 2)); expanded.f < reflected.f {
+				simplex[n] = expanded
+			} else {
+				simplex[n] = reflected
+			}
+		case reflected.f < simplex[n-1].f:
+			simplex[n] = reflected
+		default:
+			if contracted := eval(along(centroid, worst.x, -0.5)); contracted.f < worst.f {
+				simplex[n] = contracted
+				continue
+			}
+			for k := 1; k <= n; k++ {
+				x := make([]float64, n)
+				for i := range x {
+					x[i] = best.x[i] + 0.5*(simplex[k].x[i]-best.x[i])
+				}
+				simplex[k] = eval(x)
+			}
+		}
+	}
+
+	best := simplex[0]
+	for _, v := range simplex[1:] {
+		if v.f < best.f {
+			best = v
+		}
+	}
+	return best.x, best.f
+}
+
+// nanMeanStddev returns the mean and standard deviation of the non-NaN values.
+func nanMeanStddev(values []float64) (float64, float64) {
+	var sum, sumSq float64
+	n := 0
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			sum += v
+			sumSq += v * v
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	mean := sum / float64(n)
+	return mean, math.Sqrt(max(sumSq/float64(n)-mean*mean, 0))
+}
+
+func matVec(A [][]float64, x []float64) []float64 {
+	out := make([]float64, len(A))
+	for i, row := range A {
+		out[i] = dot(row, x)
+	}
+	return out
+}
+
+func cloneMatrix(A [][]float64) [][]float64 {
+	out := make([][]float64, len(A))
+	for i, row := range A {
+		out[i] = slices.Clone(row)
+	}
+	return out
+}
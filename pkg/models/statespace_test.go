@@ -0,0 +1,260 @@
+package models
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// localLevelFrame returns n one-minute rows of a random walk with level
+// variance q2 observed with noise variance e2.
+func localLevelFrame(n int, q2, e2 float64, seed int64) FeatureFrame {
+	rng := rand.New(rand.NewSource(seed))
+	rows := make([]map[string]float64, n)
+	level := 500.0
+	for i := range rows {
+		level += rng.NormFloat64() * math.Sqrt(q2)
+		rows[i] = map[string]float64{
+			"timestamp": float64(1700000000 + 60*i),
+			"value":     level + rng.NormFloat64()*math.Sqrt(e2),
+		}
+	}
+	return FeatureFrame{Rows: rows}
+}
+
+func TestStateSpaceModel_Name(t *testing.T) {
+	tests := []struct {
+		opts StateSpaceOptions
+		want string
+	}{
+		{StateSpaceOptions{}, "statespace(level)"},
+		{StateSpaceOptions{Trend: true}, "statespace(trend)"},
+		{StateSpaceOptions{Trend: true, Period: 1440}, "statespace(trend,1440)"},
+		{StateSpaceOptions{Period: 1}, "statespace(level)"},
+	}
+	for _, tt := range tests {
+		if got := NewStateSpaceModel("rps", 60, 1800, tt.opts).Name(); got != tt.want {
+			t.Errorf("Name(%+v) = %q, want %q", tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestStateSpaceModel_LocalLevel(t *testing.T) {
+	history := localLevelFrame(2000, 4, 16, 1)
+	model := NewStateSpaceModel("rps", 60, 1800, StateSpaceOptions{})
+	if err := model.Train(context.Background(), history); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+
+	fit := model.fit
+	noise := fit.sigma2 * fit.sys.H * fit.scale * fit.scale
+	level := fit.sigma2 * fit.sys.Q[0] * fit.scale * fit.scale
+	if noise < 8 || noise > 32 {
+		t.Errorf("observation variance = %.2f, want about 16", noise)
+	}
+	if level < 2 || level > 8 {
+		t.Errorf("level variance = %.2f, want about 4", level)
+	}
+
+	forecast, err := model.Predict(context.Background(), history)
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	if len(forecast.Values) != 30 {
+		t.Fatalf("len(Values) = %d, want 30", len(forecast.Values))
+	}
+	last := history.Rows[len(history.Rows)-1]["value"]
+	if math.Abs(forecast.Values[0]-last) > 10 {
+		t.Errorf("forecast[0] = %.2f, want near the last value %.2f", forecast.Values[0], last)
+	}
+	if forecast.Values[29] != forecast.Values[0] {
+		t.Errorf("local level forecast is not flat: %.2f then %.2f", forecast.Values[0], forecast.Values[29])
+	}
+
+	// The level keeps drifting, so the forecast variance grows by exactly
+	// the level variance at every step.
+	lower, _ := forecast.Quantile(0.1)
+	upper, _ := forecast.Quantile(0.9)
+	w0, w29 := (upper[0]-lower[0])/2.563, (upper[29]-lower[29])/2.563
+	if growth := w29*w29 - w0*w0; growth < 29*level*0.9 || growth > 29*level*1.1 {
+		t.Errorf("forecast variance growth = %.2f, want %.2f", growth, 29*level)
+	}
+}
+
+func TestStateSpaceModel_Trend(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	rows := make([]map[string]float64, 600)
+	for i := range rows {
+		rows[i] = map[string]float64{"value": 100 + 0.5*float64(i) + rng.NormFloat64()}
+	}
+	history := FeatureFrame{Rows: rows}
+
+	model := NewStateSpaceModel("rps", 60, 600, StateSpaceOptions{Trend: true})
+	if err := model.Train(context.Background(), history); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	forecast, err := model.Predict(context.Background(), history)
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	for h, v := range forecast.Values {
+		want := 100 + 0.5*float64(600+h)
+		if math.Abs(v-want) > 3 {
+			t.Errorf("forecast[%d] = %.2f, want about %.2f", h, v, want)
+		}
+	}
+}
+
+func TestStateSpaceModel_Seasonal(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	season := func(i int) float64 { return 200 + 50*math.Sin(2*math.Pi*float64(i)/24) }
+	rows := make([]map[string]float64, 24*20)
+	for i := range rows {
+		rows[i] = map[string]float64{
+			"timestamp": float64(3600 * i),
+			"value":     season(i) + rng.NormFloat64()*2,
+		}
+	}
+	history := FeatureFrame{Rows: rows}
+
+	model := NewStateSpaceModel("rps", 3600, 24*3600, StateSpaceOptions{Period: 24, Harmonics: 1})
+	if err := model.Train(context.Background(), history); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	forecast, err := model.Predict(context.Background(), history)
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	for h, v := range forecast.Values {
+		if want := season(len(rows) + h); math.Abs(v-want) > 6 {
+			t.Errorf("forecast[%d] = %.2f, want about %.2f", h, v, want)
+		}
+
+		c := forecast.Components
+		if sum := c[ComponentLevel][h] + c[ComponentSeasonal][h]; math.Abs(sum-v) > 1e-6 {
+			t.Errorf("components at step %d sum to %.4f, forecast is %.4f", h, sum, v)
+		}
+		if want := season(len(rows)+h) - 200; math.Abs(c[ComponentSeasonal][h]-want) > 6 {
+			t.Errorf("seasonal[%d] = %.2f, want about %.2f", h, c[ComponentSeasonal][h], want)
+		}
+	}
+	if _, ok := forecast.Components[ComponentTrend]; ok {
+		t.Errorf("Components has a trend without Trend")
+	}
+}
+
+func TestStateSpaceModel_MissingObservations(t *testing.T) {
+	full := localLevelFrame(400, 1, 1, 4)
+	var rows []map[string]float64
+	for i, row := range full.Rows {
+		switch {
+		case i >= 200 && i < 220:
+			// A 20-minute outage: no rows at all.
+		case i%10 == 0:
+			rows = append(rows, map[string]float64{"timestamp": row["timestamp"], "value": math.NaN()})
+		case i%10 == 5:
+			rows = append(rows, map[string]float64{"timestamp": row["timestamp"]})
+		default:
+			rows = append(rows, row)
+		}
+	}
+	history := FeatureFrame{Rows: rows}
+
+	model := NewStateSpaceModel("rps", 60, 1800, StateSpaceOptions{})
+	if err := model.Train(context.Background(), history); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	if _, err := model.Predict(context.Background(), history); err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+
+	components, err := model.Smooth(context.Background(), history)
+	if err != nil {
+		t.Fatalf("Smooth() error = %v", err)
+	}
+	if len(components.Level) != 400 {
+		t.Fatalf("len(Level) = %d, want 400 steps including the gap", len(components.Level))
+	}
+	if components.Slope != nil || components.Seasonal != nil {
+		t.Errorf("local level model returned slope or seasonal components")
+	}
+	// Across the outage the smoothed level moves from one side to the other.
+	before, after := components.Level[199], components.Level[220]
+	for i := 200; i < 220; i++ {
+		lo, hi := min(before, after)-1, max(before, after)+1
+		if v := components.Level[i]; math.IsNaN(v) || v < lo || v > hi {
+			t.Errorf("Level[%d] = %.2f, want between %.2f and %.2f", i, v, lo, hi)
+		}
+	}
+}
+
+func TestStateSpaceModel_UpdateMatchesPredictWithNewRows(t *testing.T) {
+	frame := localLevelFrame(700, 4, 16, 5)
+	head := FeatureFrame{Rows: frame.Rows[:600]}
+	opts := StateSpaceOptions{Trend: true}
+
+	updated := NewStateSpaceModel("rps", 60, 1800, opts)
+	if err := updated.Train(context.Background(), head); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+
+	// Predict with rows beyond the training data filters them first.
+	want, err := updated.Predict(context.Background(), frame)
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	if err := updated.Update(context.Background(), FeatureFrame{Rows: frame.Rows[600:]}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, err := updated.Predict(context.Background(), head)
+	if err != nil {
+		t.Fatalf("Predict() after Update error = %v", err)
+	}
+	for i := range want.Values {
+		if math.Abs(got.Values[i]-want.Values[i]) > 1e-9 {
+			t.Fatalf("forecast[%d] after Update = %v, want %v", i, got.Values[i], want.Values[i])
+		}
+	}
+	if got, want := updated.fit.lastTs, frame.Rows[699]["timestamp"]; got != want {
+		t.Errorf("last timestamp after Update = %v, want %v", got, want)
+	}
+}
+
+func TestStateSpaceModel_Errors(t *testing.T) {
+	model := NewStateSpaceModel("rps", 60, 1800, StateSpaceOptions{})
+	if _, err := model.Predict(context.Background(), FeatureFrame{}); err == nil {
+		t.Errorf("Predict() before Train: expected error")
+	}
+	if err := model.Update(context.Background(), FeatureFrame{}); err == nil {
+		t.Errorf("Update() before Train: expected error")
+	}
+	if err := model.Train(context.Background(), localLevelFrame(5, 1, 1, 6)); err == nil {
+		t.Errorf("Train() with 5 rows: expected error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := model.Train(ctx, localLevelFrame(200, 1, 1, 6)); err != context.Canceled {
+		t.Errorf("Train() with cancelled context error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestStateSpaceModel_TrainBoundedByDeadline(t *testing.T) {
+	history := localLevelFrame(20000, 4, 16, 7)
+	model := NewStateSpaceModel("rps", 60, 1800, StateSpaceOptions{Trend: true, Period: 1440, MaxIterations: 100000})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := model.Train(ctx, history); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Train() took %v with a 100ms deadline", elapsed)
+	}
+	if _, err := model.Predict(context.Background(), history); err != nil {
+		t.Errorf("Predict() after a bounded Train error = %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+//go:build wasip1
+
+// Command wasmplugin is a sample Kedastral WASM model plugin used by the
+// tests. It forecasts the midpoint between the last observed value and the
+// training mean.
+//
+// Build with TinyGo:
+//
+//	tinygo build -o plugin.wasm -target=wasip1 -buildmode=c-shared .
+//
+// or with the standard toolchain:
+//
+//	GOOS=wasip1 GOARCH=wasm go build -o plugin.wasm -buildmode=c-shared .
+//
+// For tests of the host limits, a frame with a "spin" column makes predict
+// loop forever, one with an "alloc" column makes it allocate without bound,
+// and one with a "nan" column makes it return NaN.
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"unsafe"
+)
+
+var (
+	input   []byte
+	output  []byte
+	lastErr string
+	mean    float64
+	trained bool
+	hog     [][]byte
+)
+
+func main() {}
+
+//go:wasmexport kedastral_abi_version
+func abiVersion() int32 { return 1 }
+
+//go:wasmexport kedastral_alloc
+func alloc(size int32) int32 {
+	input = make([]byte, size)
+	if size == 0 {
+		return 0
+	}
+	return int32(uintptr(unsafe.Pointer(&input[0])))
+}
+
+//go:wasmexport kedastral_train
+func train(ptr, size int32) int32 {
+	cols, rows, err := decode(input[:size])
+	if err != nil {
+		return fail(err)
+	}
+	values, ok := cols["value"]
+	if !ok || rows == 0 {
+		return fail(errors.New("no values"))
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean = sum / float64(rows)
+	trained = true
+	return 0
+}
+
+//go:wasmexport kedastral_predict
+func predict(ptr, size, steps int32) int64 {
+	cols, rows, err := decode(input[:size])
+	if err != nil {
+		return int64(fail(err))
+	}
+	if !trained {
+		return int64(fail(errors.New("not trained")))
+	}
+	if _, ok := cols["spin"]; ok {
+		for {
+		}
+	}
+	if _, ok := cols["alloc"]; ok {
+		for {
+			hog = append(hog, make([]byte, 1<<20))
+		}
+	}
+
+	last := mean
+	if values, ok := cols["value"]; ok && rows > 0 {
+		last = values[rows-1]
+	}
+	if _, ok := cols["nan"]; ok {
+		last = math.NaN()
+	}
+
+	output = make([]byte, 8*steps)
+	for i := range int(steps) {
+		binary.LittleEndian.PutUint64(output[8*i:], math.Float64bits((last+mean)/2))
+	}
+	if steps == 0 {
+		return 0
+	}
+	return int64(uintptr(unsafe.Pointer(&output[0])))<<32 | int64(len(output))
+}
+
+//go:wasmexport kedastral_error
+func lastError() int64 {
+	if lastErr == "" {
+		return 0
+	}
+	output = []byte(lastErr)
+	return int64(uintptr(unsafe.Pointer(&output[0])))<<32 | int64(len(output))
+}
+
+func fail(err error) int32 {
+	lastErr = err.Error()
+	return -1
+}
+
+// decode parses a frame in the Kedastral WASM ABI layout into columns.
+func decode(buf []byte) (map[string][]float64, int, error) {
+	errShort := errors.New("frame truncated")
+	if len(buf) < 4 {
+		return nil, 0, errShort
+	}
+	ncols := int(binary.LittleEndian.Uint32(buf))
+	buf = buf[4:]
+
+	names := make([]string, ncols)
+	for i := range names {
+		if len(buf) < 4 {
+			return nil, 0, errShort
+		}
+		n := int(binary.LittleEndian.Uint32(buf))
+		if len(buf) < 4+n {
+			return nil, 0, errShort
+		}
+		names[i] = string(buf[4 : 4+n])
+		buf = buf[4+n:]
+	}
+
+	if len(buf) < 4 {
+		return nil, 0, errShort
+	}
+	rows := int(binary.LittleEndian.Uint32(buf))
+	buf = buf[4:]
+	if len(buf) < 8*rows*ncols {
+		return nil, 0, errShort
+	}
+
+	cols := make(map[string][]float64, ncols)
+	for c, name := range names {
+		col := make([]float64, rows)
+		for r := range rows {
+			col[r] = math.Float64frombits(binary.LittleEndian.Uint64(buf[8*(r*ncols+c):]))
+		}
+		cols[name] = col
+	}
+	return cols, rows, nil
+}
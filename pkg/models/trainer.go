@@ -0,0 +1,153 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// Trainer fits a model on successive windows of a series the way the forecast
+// loop does, so that the forecaster and a backtest train identically.
+//
+// Models implementing IncrementalModel are only updated with the rows that
+// arrived since the previous call, unless a full retrain is due, the new rows
+// do not directly follow the ones already seen, or the update fails. With
+// change-point detection, full training drops the rows before the most recent
+// change point; if the model cannot train on the shorter history, it is
+// trained on the whole window instead. A change point not seen before makes a
+// model implementing OrderSearcher search its orders again.
+//
+// The zero value fully trains on every call without change-point detection.
+// A Trainer is not safe for concurrent use, and must only be used with one
+// model.
+type Trainer struct {
+	// Step is the spacing of rows. Rows more than 1.5 steps after the last
+	// row trained on are not contiguous and trigger a full retrain.
+	Step time.Duration
+
+	// FullTrainInterval is how often incremental models are trained on the
+	// full window; in between they are only updated with new rows. Zero
+	// retrains on every call.
+	FullTrainInterval time.Duration
+
+	// ChangePoints enables change-point detection on full training runs.
+	// Nil trains on the whole window.
+	ChangePoints *ChangePointOptions
+
+	lastFullTrain   time.Time
+	lastSeen        float64 // newest timestamp trained on
+	lastChangePoint float64
+}
+
+// TrainResult reports what a Trainer.Train call did.
+type TrainResult struct {
+	// Updated is the number of new rows passed to IncrementalModel.Update,
+	// zero when the model was fully trained or no rows were new.
+	Updated int
+
+	// UpdateErr is the error of an incremental update that failed, after
+	// which the model was fully trained.
+	UpdateErr error
+
+	// ChangePoint is the change point full training started at, if any;
+	// NewChangePoint reports whether an earlier call had not seen it.
+	ChangePoint    *ChangePoint
+	NewChangePoint bool
+
+	// RegimeErr is the error of training since ChangePoint, after which the
+	// model was trained on the whole window.
+	RegimeErr error
+}
+
+// Train fits model on frame, whose rows need a "timestamp" feature for
+// incremental updates. now is the time of the call, against which
+// FullTrainInterval is measured: the wall clock in the forecaster, the
+// simulated tick in a backtest.
+func (t *Trainer) Train(ctx context.Context, model Model, frame FeatureFrame, now time.Time) (TrainResult, error) {
+	var result TrainResult
+
+	incremental, ok := model.(IncrementalModel)
+	if ok && t.FullTrainInterval > 0 && now.Sub(t.lastFullTrain) < t.FullTrainInterval {
+		if newRows, contiguous := t.newRows(frame); contiguous {
+			if len(newRows.Rows) == 0 {
+				return result, nil
+			}
+			err := incremental.Update(ctx, newRows)
+			if err == nil {
+				t.lastSeen = newRows.Rows[len(newRows.Rows)-1]["timestamp"]
+				result.Updated = len(newRows.Rows)
+				return result, nil
+			}
+			result.UpdateErr = err
+		}
+	}
+
+	regime := t.sinceChangePoint(model, frame, &result)
+	if err := model.Train(ctx, regime); err != nil {
+		if len(regime.Rows) == len(frame.Rows) {
+			return result, err
+		}
+		result.RegimeErr = err
+		if err := model.Train(ctx, frame); err != nil {
+			return result, err
+		}
+	}
+	t.lastFullTrain = now
+	t.lastSeen = 0
+	if n := len(frame.Rows); n > 0 {
+		t.lastSeen = frame.Rows[n-1]["timestamp"]
+	}
+	return result, nil
+}
+
+// sinceChangePoint returns the rows of frame from the most recent change point
+// on, or frame itself when detection is disabled or finds none, and records
+// the change point in result.
+func (t *Trainer) sinceChangePoint(model Model, frame FeatureFrame, result *TrainResult) FeatureFrame {
+	if t.ChangePoints == nil {
+		return frame
+	}
+	points := DetectChangePoints(frame, *t.ChangePoints)
+	if len(points) == 0 {
+		return frame
+	}
+
+	cp := points[len(points)-1]
+	result.ChangePoint = &cp
+	if cp.Timestamp != t.lastChangePoint {
+		t.lastChangePoint = cp.Timestamp
+		result.NewChangePoint = true
+		if searcher, ok := model.(OrderSearcher); ok {
+			searcher.ResetOrderSearch()
+		}
+	}
+	return FeatureFrame{Rows: frame.Rows[cp.Index:]}
+}
+
+// newRows returns the rows of frame newer than the last row trained on.
+// contiguous is false when that cannot be determined (missing timestamps) or
+// when rows are missing between the two, in which case a full retrain is needed.
+func (t *Trainer) newRows(frame FeatureFrame) (rows FeatureFrame, contiguous bool) {
+	if t.lastSeen <= 0 {
+		return FeatureFrame{}, false
+	}
+
+	start := len(frame.Rows)
+	for i, row := range frame.Rows {
+		ts, ok := row["timestamp"]
+		if !ok {
+			return FeatureFrame{}, false
+		}
+		if ts > t.lastSeen && start == len(frame.Rows) {
+			start = i
+		}
+	}
+	if start == len(frame.Rows) {
+		return FeatureFrame{}, true
+	}
+
+	// Allow for jitter in sample alignment, but not a skipped step.
+	if gap := frame.Rows[start]["timestamp"] - t.lastSeen; gap > 1.5*t.Step.Seconds() {
+		return FeatureFrame{}, false
+	}
+	return FeatureFrame{Rows: frame.Rows[start:]}, true
+}
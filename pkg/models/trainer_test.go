@@ -0,0 +1,172 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// trainerModel records the Train and Update calls made by a Trainer.
+type trainerModel struct {
+	trainedRows []int
+	updates     [][]float64
+	minRows     int
+	updateErr   error
+	resets      int
+}
+
+func (m *trainerModel) Train(ctx context.Context, history FeatureFrame) error {
+	if len(history.Rows) < m.minRows {
+		return errors.New("not enough rows")
+	}
+	m.trainedRows = append(m.trainedRows, len(history.Rows))
+	return nil
+}
+
+func (m *trainerModel) Update(ctx context.Context, newRows FeatureFrame) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	var ts []float64
+	for _, row := range newRows.Rows {
+		ts = append(ts, row["timestamp"])
+	}
+	m.updates = append(m.updates, ts)
+	return nil
+}
+
+func (m *trainerModel) Predict(ctx context.Context, features FeatureFrame) (Forecast, error) {
+	return Forecast{}, nil
+}
+
+func (m *trainerModel) Name() string { return "trainer" }
+
+func (m *trainerModel) ResetOrderSearch() { m.resets++ }
+
+// minuteRows returns rows at 60s spacing with timestamps from..to minutes.
+func minuteRows(from, to int) FeatureFrame {
+	var frame FeatureFrame
+	for i := from; i <= to; i++ {
+		frame.Rows = append(frame.Rows, map[string]float64{"timestamp": float64(i * 60), "value": 1})
+	}
+	return frame
+}
+
+func TestTrainer_Incremental(t *testing.T) {
+	ctx := context.Background()
+	start := time.Unix(0, 0)
+	model := &trainerModel{}
+	trainer := Trainer{Step: time.Minute, FullTrainInterval: time.Hour}
+
+	steps := []struct {
+		name        string
+		frame       FeatureFrame
+		now         time.Time
+		wantTrains  int
+		wantUpdated []float64
+	}{
+		{name: "first call trains fully", frame: minuteRows(1, 30), now: start, wantTrains: 1},
+		{name: "new rows update", frame: minuteRows(3, 32), now: start.Add(2 * time.Minute), wantTrains: 1, wantUpdated: []float64{31 * 60, 32 * 60}},
+		{name: "no new rows", frame: minuteRows(3, 32), now: start.Add(3 * time.Minute), wantTrains: 1},
+		{name: "gap retrains", frame: minuteRows(35, 60), now: start.Add(30 * time.Minute), wantTrains: 2},
+		{name: "update after retrain", frame: minuteRows(36, 61), now: start.Add(31 * time.Minute), wantTrains: 2, wantUpdated: []float64{61 * 60}},
+		{name: "interval passed retrains", frame: minuteRows(37, 62), now: start.Add(91 * time.Minute), wantTrains: 3},
+		{name: "no timestamps retrain", frame: syntheticConstant(30, 1), now: start.Add(92 * time.Minute), wantTrains: 4},
+	}
+
+	for _, step := range steps {
+		updates := len(model.updates)
+		result, err := trainer.Train(ctx, model, step.frame, step.now)
+		if err != nil {
+			t.Fatalf("%s: Train() error = %v", step.name, err)
+		}
+		if got := len(model.trainedRows); got != step.wantTrains {
+			t.Errorf("%s: model trained %d times, want %d", step.name, got, step.wantTrains)
+		}
+		var got []float64
+		if len(model.updates) > updates {
+			got = model.updates[len(model.updates)-1]
+		}
+		if !reflect.DeepEqual(got, step.wantUpdated) {
+			t.Errorf("%s: Update rows = %v, want %v", step.name, got, step.wantUpdated)
+		}
+		if result.Updated != len(step.wantUpdated) {
+			t.Errorf("%s: Updated = %d, want %d", step.name, result.Updated, len(step.wantUpdated))
+		}
+	}
+}
+
+func TestTrainer_UpdateFailureRetrains(t *testing.T) {
+	ctx := context.Background()
+	model := &trainerModel{}
+	trainer := Trainer{Step: time.Minute, FullTrainInterval: time.Hour}
+
+	if _, err := trainer.Train(ctx, model, minuteRows(1, 30), time.Unix(0, 0)); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	model.updateErr = errors.New("boom")
+	result, err := trainer.Train(ctx, model, minuteRows(2, 31), time.Unix(60, 0))
+	if err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	if result.UpdateErr == nil || len(model.trainedRows) != 2 {
+		t.Errorf("UpdateErr = %v after %d trains, want the update error and a full retrain", result.UpdateErr, len(model.trainedRows))
+	}
+}
+
+func TestTrainer_ChangePoint(t *testing.T) {
+	// The level jumps from ~10 to ~50 at row 80 of 120.
+	frame := minuteRows(0, 119)
+	for i, row := range frame.Rows {
+		row["value"] = float64(10 + i%3)
+		if i >= 80 {
+			row["value"] += 40
+		}
+	}
+
+	tests := []struct {
+		name        string
+		minRows     int
+		wantRows    []int
+		wantFailure bool
+	}{
+		{name: "trains since change point", wantRows: []int{40, 40}},
+		{name: "falls back to full window", minRows: 60, wantRows: []int{120, 120}, wantFailure: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			model := &trainerModel{minRows: tt.minRows}
+			trainer := Trainer{Step: time.Minute, ChangePoints: &ChangePointOptions{MinSegment: 20}}
+
+			first, err := trainer.Train(ctx, model, frame, time.Unix(0, 0))
+			if err != nil {
+				t.Fatalf("Train() error = %v", err)
+			}
+			second, err := trainer.Train(ctx, model, frame, time.Unix(60, 0))
+			if err != nil {
+				t.Fatalf("Train() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(model.trainedRows, tt.wantRows) {
+				t.Errorf("trained on %v rows, want %v", model.trainedRows, tt.wantRows)
+			}
+			if first.ChangePoint == nil || first.ChangePoint.Timestamp != 80*60 || !first.NewChangePoint {
+				t.Errorf("first ChangePoint = %+v (new %v), want a new one at %d", first.ChangePoint, first.NewChangePoint, 80*60)
+			}
+			if second.NewChangePoint {
+				t.Error("second NewChangePoint = true, want false for a change point already seen")
+			}
+			if (first.RegimeErr != nil) != tt.wantFailure {
+				t.Errorf("RegimeErr = %v, want failure %v", first.RegimeErr, tt.wantFailure)
+			}
+			// Only a change point not seen before resets the order search.
+			if model.resets != 1 {
+				t.Errorf("ResetOrderSearch called %d times, want 1", model.resets)
+			}
+		})
+	}
+}
@@ -0,0 +1,369 @@
+package models
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// WASMABIVersion is the version of the plugin ABI expected from WASM modules.
+//
+// A plugin is a WASI reactor module (built with -buildmode=c-shared) that
+// exports its linear memory as "memory" and the following functions:
+//
+//	kedastral_abi_version() i32
+//	    Returns the ABI version the plugin implements.
+//	kedastral_alloc(size i32) i32
+//	    Returns a pointer to size bytes the host may write the next frame to.
+//	kedastral_train(ptr, size i32) i32
+//	    Fits the plugin on the frame at ptr. Returns 0 on success.
+//	kedastral_predict(ptr, size, steps i32) i64
+//	    Forecasts steps values from the frame at ptr. Returns the location of
+//	    steps little-endian float64 values as ptr<<32 | size, or a negative
+//	    value on failure.
+//	kedastral_error() i64 (optional)
+//	    Returns the last error message as ptr<<32 | size.
+//
+// Frames are encoded column-wise, all integers little-endian uint32:
+//
+//	ncols, then per column: name length, name bytes (UTF-8)
+//	nrows, then nrows*ncols float64 values in row-major order
+//
+// Columns are sorted by name; a value missing from a row is NaN.
+const WASMABIVersion = 1
+
+// WASMOptions configures a WASMModel.
+type WASMOptions struct {
+	// Name identifies the plugin in the model name, e.g. "wasm(my-plugin)".
+	// Defaults to no suffix.
+	Name string
+
+	// MemoryLimitMiB caps the plugin's linear memory. Defaults to 128 MiB;
+	// at most 4096 MiB, the address space of 32-bit WebAssembly.
+	MemoryLimitMiB int
+
+	// Timeout bounds each call into the plugin, including its start-up on
+	// Train. Defaults to 5 seconds.
+	Timeout time.Duration
+}
+
+// WASMModel runs a forecasting model compiled to WebAssembly, so that custom
+// forecasting logic can be deployed as a file rather than a new forecaster
+// build. Modules are executed by wazero, a pure-Go runtime, in a sandbox
+// without filesystem, network or environment access.
+//
+// Limits are enforced by the host:
+//   - Memory: linear memory cannot grow past MemoryLimitMiB
+//   - Time: a call running past Timeout is interrupted
+//   - CPU: a plugin is single-threaded and calls are serialised, so it uses at
+//     most one core, for at most Timeout per call
+//
+// Each Train call starts a fresh module instance, so state leaked by a
+// previous fit cannot accumulate. An instance that hits a limit is discarded,
+// and Predict fails until the next successful Train.
+//
+// The plugin returns point forecasts only; Forecast.Quantiles is always nil.
+type WASMModel struct {
+	metric  string
+	stepSec int
+	horizon int
+	opts    WASMOptions
+
+	mu       sync.Mutex
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+	instance api.Module
+}
+
+// wasmPageSize is the size of a WebAssembly memory page.
+const wasmPageSize = 64 << 10
+
+// wasmMaxMemoryMiB is the largest linear memory of a 32-bit WebAssembly
+// module: 65536 pages.
+const wasmMaxMemoryMiB = 4096
+
+// NewWASMModel compiles a plugin module. The module is checked for the
+// required exports but not started until the first Train.
+//
+// Returns an error if MemoryLimitMiB exceeds 4096, or if the module cannot be
+// compiled or does not implement the plugin ABI. Call Close to release the
+// runtime.
+func NewWASMModel(ctx context.Context, metric string, stepSec, horizon int, module []byte, opts WASMOptions) (*WASMModel, error) {
+	if opts.MemoryLimitMiB <= 0 {
+		opts.MemoryLimitMiB = 128
+	}
+	if opts.MemoryLimitMiB > wasmMaxMemoryMiB {
+		return nil, fmt.Errorf("memory limit %d MiB exceeds the WebAssembly maximum of %d MiB", opts.MemoryLimitMiB, wasmMaxMemoryMiB)
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+
+	config := wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(uint32(opts.MemoryLimitMiB << 20 / wasmPageSize)).
+		WithCloseOnContextDone(true)
+	runtime := wazero.NewRuntimeWithConfig(ctx, config)
+
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		_ = runtime.Close(ctx)
+		return nil, fmt.Errorf("failed to instantiate WASI: %w", err)
+	}
+
+	compiled, err := runtime.CompileModule(ctx, module)
+	if err != nil {
+		_ = runtime.Close(ctx)
+		return nil, fmt.Errorf("failed to compile plugin: %w", err)
+	}
+
+	exports := compiled.ExportedFunctions()
+	for _, name := range []string{"kedastral_abi_version", "kedastral_alloc", "kedastral_train", "kedastral_predict"} {
+		if _, ok := exports[name]; !ok {
+			_ = runtime.Close(ctx)
+			return nil, fmt.Errorf("plugin does not export %s", name)
+		}
+	}
+	if _, ok := compiled.ExportedMemories()["memory"]; !ok {
+		_ = runtime.Close(ctx)
+		return nil, errors.New("plugin does not export its memory")
+	}
+
+	return &WASMModel{
+		metric:   metric,
+		stepSec:  stepSec,
+		horizon:  horizon,
+		opts:     opts,
+		runtime:  runtime,
+		compiled: compiled,
+	}, nil
+}
+
+// Name returns "wasm", or "wasm(<name>)" when a name is configured.
+func (m *WASMModel) Name() string {
+	if m.opts.Name == "" {
+		return "wasm"
+	}
+	return "wasm(" + m.opts.Name + ")"
+}
+
+// Train starts a fresh plugin instance and fits it on history. When training
+// fails the instance is discarded and the model is untrained until the next
+// successful Train.
+//
+// Returns error if:
+//   - Context is cancelled
+//   - The plugin fails to start or implements another ABI version
+//   - The plugin reports an error or exceeds a limit
+func (m *WASMModel) Train(ctx context.Context, history FeatureFrame) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closeInstance(ctx)
+
+	callCtx, cancel := context.WithTimeout(ctx, m.opts.Timeout)
+	defer cancel()
+
+	instance, err := m.runtime.InstantiateModule(callCtx, m.compiled,
+		wazero.NewModuleConfig().WithName("").WithStartFunctions("_initialize"))
+	if err != nil {
+		return m.callError(callCtx, "start", err)
+	}
+
+	results, err := instance.ExportedFunction("kedastral_abi_version").Call(callCtx)
+	if err != nil {
+		_ = instance.Close(ctx)
+		return m.callError(callCtx, "abi_version", err)
+	}
+	if version := int32(results[0]); version != WASMABIVersion {
+		_ = instance.Close(ctx)
+		return fmt.Errorf("plugin implements ABI version %d, want %d", version, WASMABIVersion)
+	}
+	m.instance = instance
+
+	ptr, size, err := m.writeFrame(callCtx, history)
+	if err != nil {
+		m.closeInstance(context.Background())
+		return err
+	}
+	results, err = instance.ExportedFunction("kedastral_train").Call(callCtx, ptr, size)
+	if err != nil {
+		return m.callError(callCtx, "train", err)
+	}
+	if status := int32(results[0]); status != 0 {
+		err := fmt.Errorf("plugin train failed: %s", m.pluginError(callCtx))
+		m.closeInstance(context.Background())
+		return err
+	}
+
+	return nil
+}
+
+// Predict asks the plugin for horizon/step values (at least one). Negative
+// values are clamped to zero; a NaN or infinite value discards the instance
+// like a failed call.
+//
+// Returns error if:
+//   - Context is cancelled
+//   - Model has not been trained, or its instance was discarded
+//   - The plugin reports an error, exceeds a limit, or returns the wrong
+//     number of values or a non-finite one
+func (m *WASMModel) Predict(ctx context.Context, features FeatureFrame) (Forecast, error) {
+	if ctx.Err() != nil {
+		return Forecast{}, ctx.Err()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.instance == nil {
+		return Forecast{}, errors.New("model not trained, call Train() first")
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, m.opts.Timeout)
+	defer cancel()
+
+	ptr, size, err := m.writeFrame(callCtx, features)
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	steps := max(m.horizon/m.stepSec, 1)
+	results, err := m.instance.ExportedFunction("kedastral_predict").Call(callCtx, ptr, size, uint64(steps))
+	if err != nil {
+		return Forecast{}, m.callError(callCtx, "predict", err)
+	}
+	packed := int64(results[0])
+	if packed < 0 {
+		return Forecast{}, fmt.Errorf("plugin predict failed: %s", m.pluginError(callCtx))
+	}
+
+	outPtr, outSize := uint32(packed>>32), uint32(packed)
+	if int(outSize) != 8*steps {
+		return Forecast{}, fmt.Errorf("plugin returned %d bytes, want %d values", outSize, steps)
+	}
+	data, ok := m.instance.Memory().Read(outPtr, outSize)
+	if !ok {
+		return Forecast{}, errors.New("plugin returned an out-of-range result")
+	}
+
+	values := make([]float64, steps)
+	for i := range values {
+		v := math.Float64frombits(binary.LittleEndian.Uint64(data[8*i:]))
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			// The plugin is untrusted: discard it rather than plan on garbage.
+			m.closeInstance(context.Background())
+			return Forecast{}, fmt.Errorf("plugin returned non-finite value at step %d", i)
+		}
+		values[i] = max(v, 0)
+	}
+
+	return Forecast{
+		Metric:  m.metric,
+		Values:  values,
+		StepSec: m.stepSec,
+		Horizon: m.horizon,
+	}, nil
+}
+
+// Close releases the plugin instance and the runtime.
+func (m *WASMModel) Close(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.instance = nil
+	return m.runtime.Close(ctx)
+}
+
+// writeFrame encodes frame and copies it into plugin memory.
+func (m *WASMModel) writeFrame(ctx context.Context, frame FeatureFrame) (uint64, uint64, error) {
+	data := encodeWASMFrame(frame)
+
+	results, err := m.instance.ExportedFunction("kedastral_alloc").Call(ctx, uint64(len(data)))
+	if err != nil {
+		return 0, 0, m.callError(ctx, "alloc", err)
+	}
+	ptr := uint32(results[0])
+	if !m.instance.Memory().Write(ptr, data) {
+		return 0, 0, errors.New("plugin allocated an out-of-range buffer")
+	}
+	return uint64(ptr), uint64(len(data)), nil
+}
+
+// callError wraps a failed call, discarding the instance: after a trap or an
+// interrupted call its state can no longer be trusted.
+func (m *WASMModel) callError(ctx context.Context, op string, err error) error {
+	m.closeInstance(context.Background())
+	if ctx.Err() != nil {
+		return fmt.Errorf("plugin %s exceeded time limit of %v: %w", op, m.opts.Timeout, err)
+	}
+	return fmt.Errorf("plugin %s failed: %w", op, err)
+}
+
+// pluginError reads the plugin's last error message, if it exports one.
+func (m *WASMModel) pluginError(ctx context.Context) string {
+	fn := m.instance.ExportedFunction("kedastral_error")
+	if fn == nil {
+		return "no error message"
+	}
+	results, err := fn.Call(ctx)
+	if err != nil || results[0] == 0 {
+		return "no error message"
+	}
+	msg, ok := m.instance.Memory().Read(uint32(results[0]>>32), uint32(results[0]))
+	if !ok {
+		return "no error message"
+	}
+	return string(msg)
+}
+
+func (m *WASMModel) closeInstance(ctx context.Context) {
+	if m.instance != nil {
+		_ = m.instance.Close(ctx)
+		m.instance = nil
+	}
+}
+
+// encodeWASMFrame encodes frame in the plugin ABI layout described on
+// WASMABIVersion.
+func encodeWASMFrame(frame FeatureFrame) []byte {
+	seen := make(map[string]bool)
+	var names []string
+	for _, row := range frame.Rows {
+		for name := range row {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+
+	buf := binary.LittleEndian.AppendUint32(nil, uint32(len(names)))
+	for _, name := range names {
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(name)))
+		buf = append(buf, name...)
+	}
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(frame.Rows)))
+	for _, row := range frame.Rows {
+		for _, name := range names {
+			v, ok := row[name]
+			if !ok {
+				v = math.NaN()
+			}
+			buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(v))
+		}
+	}
+	return buf
+}
@@ -0,0 +1,236 @@
+package models
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	wasmPluginOnce sync.Once
+	wasmPlugin     []byte
+	wasmPluginErr  error
+)
+
+// buildWASMPlugin compiles testdata/wasmplugin with TinyGo when it is
+// installed, falling back to the standard toolchain's wasip1 port.
+func buildWASMPlugin(t *testing.T) []byte {
+	t.Helper()
+
+	wasmPluginOnce.Do(func() {
+		dir, err := os.MkdirTemp("", "wasmplugin")
+		if err != nil {
+			wasmPluginErr = err
+			return
+		}
+		defer os.RemoveAll(dir)
+		out := filepath.Join(dir, "plugin.wasm")
+
+		var cmd *exec.Cmd
+		if tinygo, err := exec.LookPath("tinygo"); err == nil {
+			cmd = exec.Command(tinygo, "build", "-o", out, "-target=wasip1", "-buildmode=c-shared", ".")
+		} else {
+			cmd = exec.Command("go", "build", "-o", out, "-buildmode=c-shared", ".")
+			cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+		}
+		cmd.Dir = filepath.Join("testdata", "wasmplugin")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			wasmPluginErr = fmt.Errorf("%v: %s", err, output)
+			return
+		}
+		wasmPlugin, wasmPluginErr = os.ReadFile(out)
+	})
+
+	if wasmPluginErr != nil {
+		t.Skipf("cannot build WASM plugin: %v", wasmPluginErr)
+	}
+	return wasmPlugin
+}
+
+func newTestWASMModel(t *testing.T, opts WASMOptions) *WASMModel {
+	t.Helper()
+
+	model, err := NewWASMModel(context.Background(), "m", 60, 300, buildWASMPlugin(t), opts)
+	if err != nil {
+		t.Fatalf("NewWASMModel() error = %v", err)
+	}
+	t.Cleanup(func() { _ = model.Close(context.Background()) })
+	return model
+}
+
+func TestWASMModel_TrainPredict(t *testing.T) {
+	model := newTestWASMModel(t, WASMOptions{Name: "sample"})
+	if got := model.Name(); got != "wasm(sample)" {
+		t.Errorf("Name() = %q, want %q", got, "wasm(sample)")
+	}
+
+	ctx := context.Background()
+	if _, err := model.Predict(ctx, syntheticConstant(5, 10)); err == nil {
+		t.Error("Predict() error = nil, want error before training")
+	}
+
+	history := FeatureFrame{Rows: []map[string]float64{
+		{"timestamp": 0, "value": 10},
+		{"timestamp": 60, "value": 30},
+	}}
+	if err := model.Train(ctx, history); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+
+	features := FeatureFrame{Rows: []map[string]float64{{"timestamp": 120, "value": 40, "hour": 0}}}
+	forecast, err := model.Predict(ctx, features)
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	if len(forecast.Values) != 5 {
+		t.Fatalf("len(Values) = %d, want 5", len(forecast.Values))
+	}
+	// Midpoint of the last value (40) and the training mean (20).
+	for i, v := range forecast.Values {
+		if v != 30 {
+			t.Errorf("Values[%d] = %v, want 30", i, v)
+		}
+	}
+	if forecast.Quantiles != nil {
+		t.Errorf("Quantiles = %v, want nil", forecast.Quantiles)
+	}
+}
+
+func TestWASMModel_PluginError(t *testing.T) {
+	model := newTestWASMModel(t, WASMOptions{})
+
+	ctx := context.Background()
+	if err := model.Train(ctx, syntheticConstant(10, 5)); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+
+	err := model.Train(ctx, FeatureFrame{})
+	if err == nil || !strings.Contains(err.Error(), "no values") {
+		t.Errorf("Train() error = %v, want the plugin's message", err)
+	}
+
+	// The failed instance is discarded rather than used by Predict.
+	if _, err := model.Predict(ctx, syntheticConstant(10, 5)); err == nil || !strings.Contains(err.Error(), "call Train() first") {
+		t.Errorf("Predict() after failed Train error = %v, want not trained", err)
+	}
+}
+
+func TestWASMModel_Limits(t *testing.T) {
+	tests := []struct {
+		name    string
+		column  string
+		opts    WASMOptions
+		wantErr string
+	}{
+		{name: "time", column: "spin", opts: WASMOptions{Timeout: 200 * time.Millisecond}, wantErr: "time limit"},
+		{name: "memory", column: "alloc", opts: WASMOptions{MemoryLimitMiB: 64}, wantErr: "predict failed"},
+		{name: "non-finite", column: "nan", wantErr: "non-finite value at step 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := newTestWASMModel(t, tt.opts)
+			ctx := context.Background()
+			history := syntheticConstant(10, 5)
+			if err := model.Train(ctx, history); err != nil {
+				t.Fatalf("Train() error = %v", err)
+			}
+
+			bad := FeatureFrame{Rows: []map[string]float64{{"value": 1, tt.column: 1}}}
+			_, err := model.Predict(ctx, bad)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Predict() error = %v, want %q", err, tt.wantErr)
+			}
+
+			// The instance is discarded; a new Train recovers.
+			if _, err := model.Predict(ctx, history); err == nil {
+				t.Error("Predict() error = nil, want error until retrained")
+			}
+			if err := model.Train(ctx, history); err != nil {
+				t.Fatalf("Train() after limit error = %v", err)
+			}
+			if _, err := model.Predict(ctx, history); err != nil {
+				t.Errorf("Predict() after retrain error = %v", err)
+			}
+		})
+	}
+}
+
+func TestWASMModel_HorizonBelowStep(t *testing.T) {
+	ctx := context.Background()
+	model, err := NewWASMModel(ctx, "m", 60, 30, buildWASMPlugin(t), WASMOptions{})
+	if err != nil {
+		t.Fatalf("NewWASMModel() error = %v", err)
+	}
+	defer model.Close(ctx)
+
+	history := syntheticConstant(10, 5)
+	if err := model.Train(ctx, history); err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	forecast, err := model.Predict(ctx, history)
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	if len(forecast.Values) != 1 {
+		t.Errorf("len(Values) = %d, want 1", len(forecast.Values))
+	}
+}
+
+func TestNewWASMModel_MemoryLimitTooLarge(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewWASMModel(ctx, "m", 60, 300, []byte("not wasm"), WASMOptions{MemoryLimitMiB: 8192})
+	if err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("NewWASMModel() error = %v, want memory limit error", err)
+	}
+	if _, err := NewWASMModel(ctx, "m", 60, 300, []byte("not wasm"), WASMOptions{MemoryLimitMiB: 4096}); err == nil || strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("NewWASMModel() at 4096 MiB error = %v, want only the compile error", err)
+	}
+}
+
+func TestNewWASMModel_InvalidModule(t *testing.T) {
+	ctx := context.Background()
+
+	if _, err := NewWASMModel(ctx, "m", 60, 300, []byte("not wasm"), WASMOptions{}); err == nil {
+		t.Error("NewWASMModel() error = nil, want compile error")
+	}
+
+	// The smallest valid module: magic number and version, no exports.
+	empty := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	_, err := NewWASMModel(ctx, "m", 60, 300, empty, WASMOptions{})
+	if err == nil || !strings.Contains(err.Error(), "kedastral_") {
+		t.Errorf("NewWASMModel() error = %v, want missing export error", err)
+	}
+}
+
+func TestEncodeWASMFrame(t *testing.T) {
+	frame := FeatureFrame{Rows: []map[string]float64{
+		{"value": 1, "hour": 2},
+		{"value": 3},
+	}}
+	got := encodeWASMFrame(frame)
+
+	// ncols=2, "hour", "value", nrows=2, then [2 1] [NaN 3].
+	if want := 4 + (4 + 4) + (4 + 5) + 4 + 4*8; len(got) != want {
+		t.Fatalf("len = %d, want %d", len(got), want)
+	}
+	if name := string(got[8:12]); name != "hour" {
+		t.Errorf("first column = %q, want %q (sorted)", name, "hour")
+	}
+	values := got[len(got)-32:]
+	if v := math.Float64frombits(binary.LittleEndian.Uint64(values[16:])); !math.IsNaN(v) {
+		t.Errorf("missing hour = %v, want NaN", v)
+	}
+	if v := math.Float64frombits(binary.LittleEndian.Uint64(values[24:])); v != 3 {
+		t.Errorf("value[1] = %v, want 3", v)
+	}
+}
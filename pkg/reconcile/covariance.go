@@ -0,0 +1,168 @@
+package reconcile
+
+import (
+	"errors"
+	"math"
+)
+
+// shrinkCovariance estimates the covariance of the columns of residuals,
+// shrunk towards its diagonal by the Schäfer-Strimmer intensity
+//
+//	λ = Σᵢ≠ⱼ Var(rᵢⱼ) / Σᵢ≠ⱼ rᵢⱼ²
+//
+// where rᵢⱼ are the sample correlations. Few or noisy residuals give a λ
+// close to 1, so the estimate stays positive definite even with fewer rows
+// than columns. Rows with NaN errors are skipped.
+func shrinkCovariance(residuals [][]float64) [][]float64 {
+	n := len(residuals[0])
+	var rows [][]float64
+	for _, r := range residuals {
+		if len(r) != n {
+			continue
+		}
+		ok := true
+		for _, v := range r {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				ok = false
+			}
+		}
+		if ok {
+			rows = append(rows, r)
+		}
+	}
+	T := float64(len(rows))
+
+	mean := make([]float64, n)
+	for _, r := range rows {
+		for i, v := range r {
+			mean[i] += v / T
+		}
+	}
+	cov := make([][]float64, n)
+	for i := range cov {
+		cov[i] = make([]float64, n)
+	}
+	if T >= 2 {
+		for _, r := range rows {
+			for i := range n {
+				for j := range n {
+					cov[i][j] += (r[i] - mean[i]) * (r[j] - mean[j]) / (T - 1)
+				}
+			}
+		}
+	}
+
+	// Keep the diagonal positive: a node whose errors never varied gets the
+	// smallest variance seen (or 1).
+	floor := math.Inf(1)
+	for i := range n {
+		if cov[i][i] > 0 {
+			floor = min(floor, cov[i][i])
+		}
+	}
+	if math.IsInf(floor, 1) {
+		floor = 1
+	}
+	for i := range n {
+		if !(cov[i][i] > 0) {
+			cov[i][i] = floor
+		}
+	}
+	if T < 3 {
+		return diagonal(cov)
+	}
+
+	// Standardised residuals and the variance of each correlation.
+	sd := make([]float64, n)
+	for i := range n {
+		sd[i] = math.Sqrt(cov[i][i])
+	}
+	var num, den float64
+	w := make([]float64, len(rows))
+	for i := range n {
+		for j := i + 1; j < n; j++ {
+			wMean := 0.0
+			for k, r := range rows {
+				w[k] = (r[i] - mean[i]) / sd[i] * (r[j] - mean[j]) / sd[j]
+				wMean += w[k] / T
+			}
+			varW := 0.0
+			for _, v := range w {
+				varW += (v - wMean) * (v - wMean)
+			}
+			corr := wMean * T / (T - 1)
+			num += varW * T / ((T - 1) * (T - 1) * (T - 1))
+			den += corr * corr
+		}
+	}
+	lambda := 1.0
+	if den > 0 {
+		lambda = min(max(num/den, 0), 1)
+	}
+
+	for i := range n {
+		for j := range n {
+			if i != j {
+				cov[i][j] *= 1 - lambda
+			}
+		}
+	}
+	return cov
+}
+
+// diagonal returns the diagonal part of A.
+func diagonal(A [][]float64) [][]float64 {
+	D := identity(len(A))
+	for i := range A {
+		D[i][i] = A[i][i]
+	}
+	return D
+}
+
+// cholesky returns the lower-triangular L with A = L·L'.
+func cholesky(A [][]float64) ([][]float64, error) {
+	n := len(A)
+	L := make([][]float64, n)
+	for i := range L {
+		L[i] = make([]float64, n)
+	}
+	for i := range n {
+		for j := 0; j <= i; j++ {
+			sum := A[i][j]
+			for k := range j {
+				sum -= L[i][k] * L[j][k]
+			}
+			if i == j {
+				if !(sum > 0) {
+					return nil, errors.New("matrix is not positive definite")
+				}
+				L[i][i] = math.Sqrt(sum)
+			} else {
+				L[i][j] = sum / L[j][j]
+			}
+		}
+	}
+	return L, nil
+}
+
+// cholSolve solves L·L'·x = b.
+func cholSolve(L [][]float64, b []float64) []float64 {
+	n := len(L)
+	y := make([]float64, n)
+	for i := range n {
+		sum := b[i]
+		for k := range i {
+			sum -= L[i][k] * y[k]
+		}
+		y[i] = sum / L[i][i]
+	}
+	x := make([]float64, n)
+	for i := n - 1; i >= 0; i-- {
+		sum := y[i]
+		for k := i + 1; k < n; k++ {
+			sum -= L[k][i] * x[k]
+		}
+		x[i] = sum / L[i][i]
+	}
+	return x
+}
@@ -0,0 +1,36 @@
+package reconcile
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestShrinkCovariance(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+
+	// Strongly correlated errors over many rows keep most of their covariance.
+	rows := make([][]float64, 500)
+	for i := range rows {
+		common := rng.NormFloat64()
+		rows[i] = []float64{common + 0.1*rng.NormFloat64(), common + 0.1*rng.NormFloat64()}
+	}
+	cov := shrinkCovariance(rows)
+	if corr := cov[0][1] / cov[0][0]; corr < 0.9 {
+		t.Errorf("shrunk correlation = %.2f, want above 0.9", corr)
+	}
+
+	// Independent errors are shrunk to (almost) a diagonal matrix.
+	for i := range rows {
+		rows[i] = []float64{rng.NormFloat64(), rng.NormFloat64()}
+	}
+	cov = shrinkCovariance(rows)
+	if cov[0][1] > 0.05 || cov[0][1] < -0.05 {
+		t.Errorf("independent covariance = %.3f, want about 0", cov[0][1])
+	}
+
+	// Two rows and a constant column still give a positive definite matrix.
+	cov = shrinkCovariance([][]float64{{1, 5, 0}, {3, 1, 0}})
+	if _, err := cholesky(cov); err != nil {
+		t.Errorf("cholesky() of a tiny-sample estimate error = %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+// Package reconcile makes forecasts of a hierarchy of related series
+// coherent, so that the forecasts of the children of every node add up to the
+// forecast of the node.
+//
+// A typical hierarchy is a service total split into regions and then into
+// endpoints:
+//
+//	total → groups → leaves
+//
+// Forecasting every series on its own gives totals that differ from the sum of
+// their parts. Reconcile combines the base forecasts of all nodes into
+// coherent ones with one of four methods:
+//
+//   - BottomUp: sum the leaf forecasts
+//   - TopDown: split the total forecast among the leaves
+//   - OLS: the coherent forecasts closest to all base forecasts
+//   - MinT: as OLS, weighting each base forecast by its error (co)variance
+//
+// See Method for details.
+package reconcile
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Hierarchy is a tree of named series in which every node is the sum of its
+// children. It is immutable once created.
+type Hierarchy struct {
+	nodes    []string // root first, parents before their children
+	parent   map[string]string
+	children map[string][]string
+	index    map[string]int
+	leaves   []string // in nodes order
+}
+
+// NewHierarchy creates a hierarchy rooted at root. parents maps every other
+// node to its parent, which must be root or another node in parents. Children
+// are ordered by name.
+//
+// Returns an error if a name is empty, a parent is unknown, or the parents
+// form a cycle.
+func NewHierarchy(root string, parents map[string]string) (*Hierarchy, error) {
+	if root == "" {
+		return nil, errors.New("hierarchy root must have a name")
+	}
+	if _, ok := parents[root]; ok {
+		return nil, fmt.Errorf("root %q cannot have a parent", root)
+	}
+
+	h := &Hierarchy{
+		parent:   make(map[string]string, len(parents)),
+		children: make(map[string][]string),
+		index:    make(map[string]int, len(parents)+1),
+	}
+	for node, parent := range parents {
+		if node == "" {
+			return nil, errors.New("hierarchy node must have a name")
+		}
+		if _, ok := parents[parent]; !ok && parent != root {
+			return nil, fmt.Errorf("node %q has unknown parent %q", node, parent)
+		}
+		h.parent[node] = parent
+		h.children[parent] = append(h.children[parent], node)
+	}
+	for _, c := range h.children {
+		slices.Sort(c)
+	}
+
+	// Breadth-first from the root; nodes on a cycle are never reached.
+	queue := []string{root}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		h.index[node] = len(h.nodes)
+		h.nodes = append(h.nodes, node)
+		queue = append(queue, h.children[node]...)
+	}
+	if len(h.nodes) != len(parents)+1 {
+		return nil, errors.New("hierarchy parents form a cycle")
+	}
+
+	for _, node := range h.nodes {
+		if len(h.children[node]) == 0 {
+			h.leaves = append(h.leaves, node)
+		}
+	}
+	return h, nil
+}
+
+// Root returns the name of the root node.
+func (h *Hierarchy) Root() string {
+	return h.nodes[0]
+}
+
+// Nodes returns all node names, root first and parents before children.
+func (h *Hierarchy) Nodes() []string {
+	return slices.Clone(h.nodes)
+}
+
+// Leaves returns the nodes without children, in Nodes order.
+func (h *Hierarchy) Leaves() []string {
+	return slices.Clone(h.leaves)
+}
+
+// Children returns the children of node, ordered by name.
+func (h *Hierarchy) Children(node string) []string {
+	return slices.Clone(h.children[node])
+}
+
+// Parent returns the parent of node, and false for the root or an unknown node.
+func (h *Hierarchy) Parent(node string) (string, bool) {
+	p, ok := h.parent[node]
+	return p, ok
+}
+
+// summing returns the summing matrix S: S[i][j] is 1 if leaf j is node i or
+// below it, so that S·leaves gives every node.
+func (h *Hierarchy) summing() [][]float64 {
+	S := make([][]float64, len(h.nodes))
+	for i := range S {
+		S[i] = make([]float64, len(h.leaves))
+	}
+	for j, leaf := range h.leaves {
+		for node, ok := leaf, true; ok; node, ok = h.parent[node] {
+			S[h.index[node]][j] = 1
+		}
+	}
+	return S
+}
+
+// aggregate sums leaf series up the tree into a series for every node.
+func (h *Hierarchy) aggregate(leaves map[string][]float64, steps int) map[string][]float64 {
+	out := make(map[string][]float64, len(h.nodes))
+	for _, node := range slices.Backward(h.nodes) {
+		if len(h.children[node]) == 0 {
+			out[node] = slices.Clone(leaves[node])
+			continue
+		}
+		sum := make([]float64, steps)
+		for _, c := range h.children[node] {
+			for t, v := range out[c] {
+				sum[t] += v
+			}
+		}
+		out[node] = sum
+	}
+	return out
+}
@@ -0,0 +1,72 @@
+package reconcile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewHierarchy(t *testing.T) {
+	h, err := NewHierarchy("total", map[string]string{
+		"eu":          "total",
+		"us":          "total",
+		"eu-checkout": "eu",
+		"eu-search":   "eu",
+	})
+	if err != nil {
+		t.Fatalf("NewHierarchy() error = %v", err)
+	}
+
+	if got := h.Root(); got != "total" {
+		t.Errorf("Root() = %q, want total", got)
+	}
+	if got, want := h.Nodes(), []string{"total", "eu", "us", "eu-checkout", "eu-search"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Nodes() = %v, want %v", got, want)
+	}
+	if got, want := h.Leaves(), []string{"us", "eu-checkout", "eu-search"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Leaves() = %v, want %v", got, want)
+	}
+	if got, want := h.Children("eu"), []string{"eu-checkout", "eu-search"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Children(eu) = %v, want %v", got, want)
+	}
+	if p, ok := h.Parent("eu-search"); !ok || p != "eu" {
+		t.Errorf("Parent(eu-search) = %q, %v, want eu, true", p, ok)
+	}
+	if _, ok := h.Parent("total"); ok {
+		t.Errorf("Parent(total) reported a parent")
+	}
+
+	want := [][]float64{
+		{1, 1, 1},
+		{0, 1, 1},
+		{1, 0, 0},
+		{0, 1, 0},
+		{0, 0, 1},
+	}
+	if got := h.summing(); !reflect.DeepEqual(got, want) {
+		t.Errorf("summing() = %v, want %v", got, want)
+	}
+}
+
+func TestNewHierarchy_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		root    string
+		parents map[string]string
+		want    string
+	}{
+		{"empty root", "", map[string]string{"a": ""}, "root"},
+		{"root with parent", "total", map[string]string{"total": "a", "a": "total"}, "cannot have a parent"},
+		{"unknown parent", "total", map[string]string{"a": "b"}, "unknown parent"},
+		{"cycle", "total", map[string]string{"a": "total", "b": "c", "c": "b"}, "cycle"},
+		{"empty name", "total", map[string]string{"": "total"}, "name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewHierarchy(tt.root, tt.parents)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewHierarchy() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
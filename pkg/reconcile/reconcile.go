@@ -0,0 +1,350 @@
+package reconcile
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/HatiCode/kedastral/pkg/models"
+)
+
+// Method selects how base forecasts are reconciled.
+type Method string
+
+const (
+	// BottomUp uses the leaf forecasts and sums them up the tree. The base
+	// forecasts of other nodes are ignored.
+	BottomUp Method = "bottom-up"
+
+	// TopDown splits the root forecast among the leaves, by
+	// Options.Proportions or else by the base forecasts of every node
+	// relative to their siblings at each step.
+	TopDown Method = "top-down"
+
+	// OLS finds the coherent forecasts closest to all base forecasts in the
+	// least squares sense, treating every node's errors as equal.
+	OLS Method = "ols"
+
+	// MinT (minimum trace) is OLS weighted by the covariance W of the base
+	// forecast errors, which minimises the total error variance of the
+	// reconciled forecasts: noisy series are adjusted more than accurate
+	// ones. W is estimated from Options.Residuals with shrinkage towards its
+	// diagonal, or taken as diagonal from Options.Variances, or else
+	// proportional to the number of leaves below each node.
+	MinT Method = "mint"
+)
+
+// Methods lists the supported methods.
+var Methods = []Method{BottomUp, TopDown, OLS, MinT}
+
+// ParseMethod returns the method named s.
+func ParseMethod(s string) (Method, error) {
+	if m := Method(s); slices.Contains(Methods, m) {
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown reconciliation method %q (want one of %v)", s, Methods)
+}
+
+// Options configures Reconcile.
+type Options struct {
+	// Method selects the reconciliation method (default MinT).
+	Method Method
+
+	// Proportions are fixed shares of the root per leaf for TopDown, e.g.
+	// historical averages. They are normalised to sum to 1. Nil splits by
+	// the base forecasts.
+	Proportions map[string]float64
+
+	// Residuals are past base forecast errors for MinT, one row per
+	// observation with one error per node in Hierarchy.Nodes order. At
+	// least two rows are needed.
+	Residuals [][]float64
+
+	// Variances are base forecast error variances per node for MinT when
+	// there are too few Residuals. Used only when every node has one.
+	Variances map[string]float64
+}
+
+// Reconcile returns coherent forecasts for every node of h from the base
+// forecasts in base, keyed by node name. All series must have the same
+// length. BottomUp needs the leaves, TopDown the root (and every node
+// without Proportions), OLS and MinT every node.
+//
+// Reconciled values can be negative where a node's base forecast was far
+// above its children's; such leaves are set to zero and the tree is summed
+// again, so the forecasts stay coherent and non-negative.
+func Reconcile(h *Hierarchy, base map[string][]float64, opts Options) (map[string][]float64, error) {
+	if opts.Method == "" {
+		opts.Method = MinT
+	}
+
+	var required []string
+	switch opts.Method {
+	case BottomUp:
+		required = h.leaves
+	case TopDown:
+		required = []string{h.Root()}
+		if opts.Proportions == nil {
+			required = h.nodes
+		}
+	case OLS, MinT:
+		required = h.nodes
+	default:
+		return nil, fmt.Errorf("unknown reconciliation method %q", opts.Method)
+	}
+	steps := -1
+	for _, node := range required {
+		series, ok := base[node]
+		if !ok {
+			return nil, fmt.Errorf("missing base forecast for %q", node)
+		}
+		if steps >= 0 && len(series) != steps {
+			return nil, fmt.Errorf("base forecast for %q has %d steps, want %d", node, len(series), steps)
+		}
+		steps = len(series)
+	}
+
+	var leaves map[string][]float64
+	var err error
+	switch opts.Method {
+	case BottomUp:
+		leaves = base
+	case TopDown:
+		leaves, err = topDown(h, base, opts.Proportions, steps)
+	case OLS:
+		leaves, err = gls(h, base, identity(len(h.nodes)), steps)
+	case MinT:
+		W := errorCovariance(h, opts)
+		if leaves, err = gls(h, base, W, steps); err != nil {
+			leaves, err = gls(h, base, diagonal(W), steps)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	clamped := make(map[string][]float64, len(h.leaves))
+	for _, leaf := range h.leaves {
+		series := slices.Clone(leaves[leaf])
+		for t, v := range series {
+			if v < 0 || math.IsNaN(v) {
+				series[t] = 0
+			}
+		}
+		clamped[leaf] = series
+	}
+	return h.aggregate(clamped, steps), nil
+}
+
+// ReconcileForecasts reconciles the values of forecasts keyed by node name
+// (see Reconcile). Each node's quantiles are shifted by the change of its
+// values, keeping their width, and clamped at zero. Nodes without a base
+// forecast (internal nodes under BottomUp) take metric, step and horizon from
+// the root or a leaf and get no quantiles. Burst forecasts and components are
+// dropped, as they do not add up and no longer explain the values.
+func ReconcileForecasts(h *Hierarchy, base map[string]models.Forecast, opts Options) (map[string]models.Forecast, error) {
+	values := make(map[string][]float64, len(base))
+	var template models.Forecast
+	for node, f := range base {
+		values[node] = f.Values
+		if template.Values == nil || node == h.Root() {
+			template = f
+		}
+	}
+
+	reconciled, err := Reconcile(h, values, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]models.Forecast, len(reconciled))
+	for node, series := range reconciled {
+		f, ok := base[node]
+		if !ok {
+			out[node] = models.Forecast{Metric: template.Metric, Values: series, StepSec: template.StepSec, Horizon: template.Horizon}
+			continue
+		}
+		var quantiles map[string][]float64
+		if f.Quantiles != nil {
+			quantiles = make(map[string][]float64, len(f.Quantiles))
+			for key, q := range f.Quantiles {
+				shifted := make([]float64, len(q))
+				for t := range q {
+					if t < len(series) && t < len(f.Values) {
+						shifted[t] = max(q[t]+series[t]-f.Values[t], 0)
+					}
+				}
+				quantiles[key] = shifted
+			}
+		}
+		out[node] = models.Forecast{
+			Metric:    f.Metric,
+			Values:    series,
+			StepSec:   f.StepSec,
+			Horizon:   f.Horizon,
+			Quantiles: quantiles,
+		}
+	}
+	return out, nil
+}
+
+// topDown splits the root forecast among the leaves by fixed proportions, or
+// at every step by each node's base forecast relative to its siblings'.
+func topDown(h *Hierarchy, base map[string][]float64, proportions map[string]float64, steps int) (map[string][]float64, error) {
+	root := base[h.Root()]
+	leaves := make(map[string][]float64, len(h.leaves))
+
+	if proportions != nil {
+		total := 0.0
+		for _, leaf := range h.leaves {
+			total += max(proportions[leaf], 0)
+		}
+		if total <= 0 {
+			return nil, errors.New("top-down proportions must have a positive sum")
+		}
+		for _, leaf := range h.leaves {
+			share := max(proportions[leaf], 0) / total
+			series := make([]float64, steps)
+			for t := range series {
+				series[t] = share * root[t]
+			}
+			leaves[leaf] = series
+		}
+		return leaves, nil
+	}
+
+	// share[node][t] is the node's share of the root at step t.
+	share := map[string][]float64{h.Root(): slices.Repeat([]float64{1}, steps)}
+	for _, node := range h.nodes {
+		children := h.children[node]
+		for _, c := range children {
+			share[c] = make([]float64, steps)
+		}
+		for t := range steps {
+			sum := 0.0
+			for _, c := range children {
+				sum += max(base[c][t], 0)
+			}
+			for _, c := range children {
+				part := 1 / float64(len(children))
+				if sum > 0 {
+					part = max(base[c][t], 0) / sum
+				}
+				share[c][t] = share[node][t] * part
+			}
+		}
+	}
+	for _, leaf := range h.leaves {
+		series := make([]float64, steps)
+		for t := range series {
+			series[t] = share[leaf][t] * root[t]
+		}
+		leaves[leaf] = series
+	}
+	return leaves, nil
+}
+
+// gls returns the leaf forecasts β minimising (ŷ - S·β)'·W⁻¹·(ŷ - S·β) at
+// every step, i.e. β = (S'·W⁻¹·S)⁻¹·S'·W⁻¹·ŷ.
+func gls(h *Hierarchy, base map[string][]float64, W [][]float64, steps int) (map[string][]float64, error) {
+	S := h.summing()
+	n, m := len(S), len(h.leaves)
+
+	L, err := cholesky(W)
+	if err != nil {
+		return nil, fmt.Errorf("error covariance: %w", err)
+	}
+	// A = W⁻¹·S, column by column.
+	A := make([][]float64, n)
+	for i := range A {
+		A[i] = make([]float64, m)
+	}
+	col := make([]float64, n)
+	for j := range m {
+		for i := range n {
+			col[i] = S[i][j]
+		}
+		x := cholSolve(L, col)
+		for i := range n {
+			A[i][j] = x[i]
+		}
+	}
+	// M = S'·A, the normal matrix.
+	M := make([][]float64, m)
+	for a := range m {
+		M[a] = make([]float64, m)
+		for b := range m {
+			for i := range n {
+				M[a][b] += S[i][a] * A[i][b]
+			}
+		}
+	}
+	LM, err := cholesky(M)
+	if err != nil {
+		return nil, fmt.Errorf("normal matrix: %w", err)
+	}
+
+	leaves := make(map[string][]float64, m)
+	for _, leaf := range h.leaves {
+		leaves[leaf] = make([]float64, steps)
+	}
+	y := make([]float64, n)
+	rhs := make([]float64, m)
+	for t := range steps {
+		for i, node := range h.nodes {
+			y[i] = base[node][t]
+		}
+		for a := range m {
+			rhs[a] = 0
+			for i := range n {
+				rhs[a] += A[i][a] * y[i]
+			}
+		}
+		beta := cholSolve(LM, rhs)
+		for j, leaf := range h.leaves {
+			leaves[leaf][t] = beta[j]
+		}
+	}
+	return leaves, nil
+}
+
+// errorCovariance returns W for MinT: the shrunk sample covariance of the
+// residuals when there are at least two rows, else the diagonal of the given
+// variances when every node has one, else the number of leaves below each
+// node on the diagonal (structural scaling).
+func errorCovariance(h *Hierarchy, opts Options) [][]float64 {
+	n := len(h.nodes)
+	if len(opts.Residuals) >= 2 && len(opts.Residuals[0]) == n {
+		return shrinkCovariance(opts.Residuals)
+	}
+
+	W := identity(n)
+	complete := len(opts.Variances) > 0
+	for _, node := range h.nodes {
+		if v, ok := opts.Variances[node]; !ok || !(v > 0) {
+			complete = false
+		}
+	}
+	S := h.summing()
+	for i, node := range h.nodes {
+		if complete {
+			W[i][i] = opts.Variances[node]
+			continue
+		}
+		W[i][i] = 0
+		for _, s := range S[i] {
+			W[i][i] += s
+		}
+	}
+	return W
+}
+
+func identity(n int) [][]float64 {
+	I := make([][]float64, n)
+	for i := range I {
+		I[i] = make([]float64, n)
+		I[i][i] = 1
+	}
+	return I
+}
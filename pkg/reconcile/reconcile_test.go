@@ -0,0 +1,228 @@
+package reconcile
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/HatiCode/kedastral/pkg/models"
+)
+
+// twoLevel returns total → {a, b}.
+func twoLevel(t *testing.T) *Hierarchy {
+	t.Helper()
+	h, err := NewHierarchy("total", map[string]string{"a": "total", "b": "total"})
+	if err != nil {
+		t.Fatalf("NewHierarchy() error = %v", err)
+	}
+	return h
+}
+
+// assertCoherent fails unless every node equals the sum of its children.
+func assertCoherent(t *testing.T, h *Hierarchy, got map[string][]float64) {
+	t.Helper()
+	for _, node := range h.Nodes() {
+		children := h.Children(node)
+		if len(children) == 0 {
+			continue
+		}
+		for step, v := range got[node] {
+			sum := 0.0
+			for _, c := range children {
+				sum += got[c][step]
+			}
+			if math.Abs(sum-v) > 1e-9 {
+				t.Errorf("%s[%d] = %v, children sum to %v", node, step, v, sum)
+			}
+		}
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestReconcile_Methods(t *testing.T) {
+	h := twoLevel(t)
+	base := map[string][]float64{
+		"total": {10, 20},
+		"a":     {4, 6},
+		"b":     {4, 10},
+	}
+
+	tests := []struct {
+		name     string
+		opts     Options
+		wantA    []float64
+		wantB    []float64
+		wantRoot []float64
+	}{
+		{
+			name:     "bottom-up",
+			opts:     Options{Method: BottomUp},
+			wantA:    []float64{4, 6},
+			wantB:    []float64{4, 10},
+			wantRoot: []float64{8, 16},
+		},
+		{
+			// Shares by base forecasts: a is 50% then 37.5% of the total.
+			name:     "top-down",
+			opts:     Options{Method: TopDown},
+			wantA:    []float64{5, 7.5},
+			wantB:    []float64{5, 12.5},
+			wantRoot: []float64{10, 20},
+		},
+		{
+			name:     "top-down proportions",
+			opts:     Options{Method: TopDown, Proportions: map[string]float64{"a": 3, "b": 1}},
+			wantA:    []float64{7.5, 15},
+			wantB:    []float64{2.5, 5},
+			wantRoot: []float64{10, 20},
+		},
+		{
+			// The 2 (resp. 4) missing from the total is spread over all
+			// three nodes: each leaf gets a third of it.
+			name:     "ols",
+			opts:     Options{Method: OLS},
+			wantA:    []float64{4 + 2.0/3, 6 + 4.0/3},
+			wantB:    []float64{4 + 2.0/3, 10 + 4.0/3},
+			wantRoot: []float64{28.0 / 3, 56.0 / 3},
+		},
+		{
+			// Structural scaling gives the total twice a leaf's variance, so
+			// it moves more: each leaf gets a quarter of the difference.
+			name:     "mint structural",
+			opts:     Options{Method: MinT},
+			wantA:    []float64{4.5, 7},
+			wantB:    []float64{4.5, 11},
+			wantRoot: []float64{9, 18},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Reconcile(h, base, tt.opts)
+			if err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			assertCoherent(t, h, got)
+			for step := range 2 {
+				if !near(got["a"][step], tt.wantA[step]) || !near(got["b"][step], tt.wantB[step]) || !near(got["total"][step], tt.wantRoot[step]) {
+					t.Errorf("step %d = total %v, a %v, b %v; want %v, %v, %v", step,
+						got["total"][step], got["a"][step], got["b"][step],
+						tt.wantRoot[step], tt.wantA[step], tt.wantB[step])
+				}
+			}
+		})
+	}
+}
+
+func TestReconcile_MinTTrustsAccurateSeries(t *testing.T) {
+	h := twoLevel(t)
+	base := map[string][]float64{"total": {20}, "a": {5}, "b": {5}}
+
+	// The total's forecast has been far noisier than the leaves'.
+	rng := rand.New(rand.NewSource(1))
+	residuals := make([][]float64, 200)
+	for i := range residuals {
+		residuals[i] = []float64{rng.NormFloat64() * 30, rng.NormFloat64(), rng.NormFloat64()}
+	}
+	got, err := Reconcile(h, base, Options{Method: MinT, Residuals: residuals})
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if total := got["total"][0]; total < 10 || total > 10.5 {
+		t.Errorf("total = %.2f, want close to the leaves' sum 10", total)
+	}
+
+	// Variances alone give the same preference.
+	got, err = Reconcile(h, base, Options{Method: MinT, Variances: map[string]float64{"total": 900, "a": 1, "b": 1}})
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if total := got["total"][0]; total < 10 || total > 10.5 {
+		t.Errorf("total with variances = %.2f, want close to 10", total)
+	}
+}
+
+func TestReconcile_NonNegative(t *testing.T) {
+	h := twoLevel(t)
+	base := map[string][]float64{"total": {0}, "a": {0}, "b": {30}}
+
+	got, err := Reconcile(h, base, Options{Method: OLS})
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	assertCoherent(t, h, got)
+	for node, series := range got {
+		if series[0] < 0 {
+			t.Errorf("%s = %v, want non-negative", node, series[0])
+		}
+	}
+}
+
+func TestReconcile_Errors(t *testing.T) {
+	h := twoLevel(t)
+	tests := []struct {
+		name string
+		base map[string][]float64
+		opts Options
+	}{
+		{"missing node", map[string][]float64{"total": {1}, "a": {1}}, Options{Method: OLS}},
+		{"missing leaf", map[string][]float64{"total": {1}, "a": {1}}, Options{Method: BottomUp}},
+		{"length mismatch", map[string][]float64{"total": {1, 2}, "a": {1}, "b": {1}}, Options{Method: MinT}},
+		{"unknown method", map[string][]float64{"total": {1}, "a": {1}, "b": {1}}, Options{Method: "median"}},
+		{"zero proportions", map[string][]float64{"total": {1}}, Options{Method: TopDown, Proportions: map[string]float64{"a": 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Reconcile(h, tt.base, tt.opts); err == nil {
+				t.Errorf("Reconcile() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestParseMethod(t *testing.T) {
+	for _, m := range Methods {
+		if got, err := ParseMethod(string(m)); err != nil || got != m {
+			t.Errorf("ParseMethod(%q) = %q, %v", m, got, err)
+		}
+	}
+	if _, err := ParseMethod("wls"); err == nil {
+		t.Errorf("ParseMethod(wls) error = nil, want error")
+	}
+}
+
+func TestReconcileForecasts(t *testing.T) {
+	h := twoLevel(t)
+	base := map[string]models.Forecast{
+		"total": {
+			Metric: "rps", StepSec: 60, Horizon: 120, Values: []float64{10, 20},
+			Quantiles: map[string][]float64{"p10": {8, 15}, "p90": {12, 25}},
+		},
+		"a": {Metric: "rps", StepSec: 60, Horizon: 120, Values: []float64{4, 6}},
+		"b": {
+			Metric: "rps", StepSec: 60, Horizon: 120, Values: []float64{4, 10},
+			Burst: &models.BurstForecast{Probability: []float64{1, 1}, Size: []float64{4, 10}},
+		},
+	}
+
+	got, err := ReconcileForecasts(h, base, Options{Method: BottomUp})
+	if err != nil {
+		t.Fatalf("ReconcileForecasts() error = %v", err)
+	}
+	total := got["total"]
+	if total.Values[0] != 8 || total.Values[1] != 16 {
+		t.Errorf("total = %v, want [8 16]", total.Values)
+	}
+	// Quantiles move with the values and keep their width.
+	if p10, p90 := total.Quantiles["p10"], total.Quantiles["p90"]; p10[0] != 6 || p90[1] != 21 {
+		t.Errorf("total quantiles = p10 %v, p90 %v; want shifted by -2 and -4", p10, p90)
+	}
+	if got["b"].Burst != nil {
+		t.Errorf("reconciled forecast kept its burst forecast")
+	}
+	if total.StepSec != 60 || total.Metric != "rps" {
+		t.Errorf("total lost its metadata: %+v", total)
+	}
+}
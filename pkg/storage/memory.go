@@ -16,6 +16,7 @@ import (
 type MemoryStore struct {
 	mu            sync.RWMutex
 	snapshots     map[string]Snapshot
+	modelStates   map[string][]byte
 	ttl           time.Duration
 	cleanupTicker *time.Ticker
 	stopCleanup   chan struct{}
@@ -29,7 +30,8 @@ type MemoryStore struct {
 // The store is ready to use immediately with no additional configuration.
 func NewMemoryStore() *MemoryStore {
 	return &MemoryStore{
-		snapshots: make(map[string]Snapshot),
+		snapshots:   make(map[string]Snapshot),
+		modelStates: make(map[string][]byte),
 	}
 }
 
@@ -51,6 +53,7 @@ func NewMemoryStoreWithTTL(ttl, cleanupInterval time.Duration) *MemoryStore {
 
 	store := &MemoryStore{
 		snapshots:     make(map[string]Snapshot),
+		modelStates:   make(map[string][]byte),
 		ttl:           ttl,
 		cleanupTicker: time.NewTicker(cleanupInterval),
 		stopCleanup:   make(chan struct{}),
@@ -172,3 +175,40 @@ func (s *MemoryStore) Delete(workload string) bool {
 	delete(s.snapshots, workload)
 	return existed
 }
+
+// PutModelState stores a copy of a model's trained state for a workload.
+// Model state is not subject to the snapshot TTL.
+//
+// Returns an error if workload or model is empty.
+// This operation is safe for concurrent use.
+func (s *MemoryStore) PutModelState(workload, model string, state []byte) error {
+	if workload == "" || model == "" {
+		return fmt.Errorf("workload and model cannot be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.modelStates[modelStateKey(workload, model)] = append([]byte(nil), state...)
+	return nil
+}
+
+// GetModelState retrieves a copy of the stored model state for a workload.
+// The error is always nil for MemoryStore.
+//
+// This operation is safe for concurrent use.
+func (s *MemoryStore) GetModelState(workload, model string) ([]byte, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, found := s.modelStates[modelStateKey(workload, model)]
+	if !found {
+		return nil, false, nil
+	}
+	return append([]byte(nil), state...), true, nil
+}
+
+// modelStateKey joins workload and model into a map key.
+func modelStateKey(workload, model string) string {
+	return workload + "/" + model
+}
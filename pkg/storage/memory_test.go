@@ -362,6 +362,38 @@ func TestMemoryStore_Len(t *testing.T) {
 	}
 }
 
+func TestMemoryStore_ModelState(t *testing.T) {
+	store := NewMemoryStore()
+
+	if _, found, err := store.GetModelState("api", "arima"); found || err != nil {
+		t.Fatalf("GetModelState() = found %v, err %v; want not found", found, err)
+	}
+
+	state := []byte(`{"version":1}`)
+	if err := store.PutModelState("api", "arima", state); err != nil {
+		t.Fatalf("PutModelState() error = %v", err)
+	}
+	state[0] = 'x' // the store must keep its own copy
+
+	got, found, err := store.GetModelState("api", "arima")
+	if err != nil || !found {
+		t.Fatalf("GetModelState() = found %v, err %v; want found", found, err)
+	}
+	if string(got) != `{"version":1}` {
+		t.Errorf("GetModelState() = %q, want %q", got, `{"version":1}`)
+	}
+
+	if _, found, _ := store.GetModelState("api", "baseline"); found {
+		t.Error("GetModelState() found state for another model")
+	}
+	if store.Len() != 0 {
+		t.Errorf("Len() = %d, want model state not counted as snapshots", store.Len())
+	}
+	if err := store.PutModelState("", "arima", state); err == nil {
+		t.Error("PutModelState() error = nil, want error for empty workload")
+	}
+}
+
 func TestMemoryStoreWithTTL_Expiration(t *testing.T) {
 	ttl := 100 * time.Millisecond
 	cleanupInterval := 50 * time.Millisecond
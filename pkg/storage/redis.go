@@ -12,7 +12,10 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-// RedisStore implements the Store interface using Redis as a backend.
+// modelStateTTL is how long persisted model state outlives its last update.
+const modelStateTTL = 7 * 24 * time.Hour
+
+// RedisStore implements the Store and StateStore interfaces using Redis as a backend.
 // It enables multi-instance forecaster deployments by providing shared
 // storage for forecast snapshots with configurable TTL-based expiration.
 type RedisStore struct {
@@ -73,11 +76,8 @@ func (r *RedisStore) Put(s Snapshot) error {
 		return errors.New("workload name required")
 	}
 
-	for _, c := range s.Workload {
-		if !((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
-			(c >= '0' && c <= '9') || c == '-' || c == '_') {
-			return fmt.Errorf("invalid workload name %q: only alphanumeric, hyphens, and underscores allowed", s.Workload)
-		}
+	if err := validateKeyPart("workload", s.Workload); err != nil {
+		return err
 	}
 
 	data, err := json.Marshal(s)
@@ -130,6 +130,74 @@ func (r *RedisStore) GetLatest(workload string) (Snapshot, bool, error) {
 	return snapshot, true, nil
 }
 
+// PutModelState stores a model's trained state for a workload.
+// The key format is "kedastral:model:{workload}:{model}". State expires after
+// modelStateTTL so that state of removed workloads does not accumulate; it is
+// refreshed on every successful training run.
+func (r *RedisStore) PutModelState(workload, model string, state []byte) error {
+	if err := validateKeyPart("workload", workload); err != nil {
+		return err
+	}
+	if err := validateKeyPart("model", model); err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("kedastral:model:%s:%s", workload, model)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := r.client.Set(ctx, key, state, modelStateTTL).Err(); err != nil {
+		return fmt.Errorf("failed to store model state in redis: %w", err)
+	}
+
+	return nil
+}
+
+// GetModelState retrieves a model's trained state for a workload.
+//
+// Returns:
+//   - state: The stored state (nil if not found)
+//   - found: true if state exists, false if not found
+//   - error: non-nil if an error occurred (excluding "not found")
+func (r *RedisStore) GetModelState(workload, model string) ([]byte, bool, error) {
+	if err := validateKeyPart("workload", workload); err != nil {
+		return nil, false, err
+	}
+	if err := validateKeyPart("model", model); err != nil {
+		return nil, false, err
+	}
+
+	key := fmt.Sprintf("kedastral:model:%s:%s", workload, model)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	state, err := r.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to get model state from redis: %w", err)
+	}
+
+	return state, true, nil
+}
+
+// validateKeyPart checks that a name is safe to embed in a Redis key.
+func validateKeyPart(kind, name string) error {
+	if name == "" {
+		return fmt.Errorf("%s name required", kind)
+	}
+	for _, c := range name {
+		if !((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+			(c >= '0' && c <= '9') || c == '-' || c == '_') {
+			return fmt.Errorf("invalid %s name %q: only alphanumeric, hyphens, and underscores allowed", kind, name)
+		}
+	}
+	return nil
+}
+
 // Close closes the Redis client connection.
 // It is safe to call multiple times (idempotent).
 func (r *RedisStore) Close() error {
@@ -259,6 +259,44 @@ func TestRedisStore_GetLatest_EmptyWorkload(t *testing.T) {
 	}
 }
 
+func TestRedisStore_ModelState_RoundTrip(t *testing.T) {
+	_, addr := setupRedisContainer(t)
+
+	store, err := NewRedisStore(addr, "", 0, 1*time.Minute)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if _, found, err := store.GetModelState("test-api", "arima"); found || err != nil {
+		t.Fatalf("expected no state, got found=%v err=%v", found, err)
+	}
+
+	if err := store.PutModelState("test-api", "arima", []byte(`{"version":1}`)); err != nil {
+		t.Fatalf("PutModelState failed: %v", err)
+	}
+
+	state, found, err := store.GetModelState("test-api", "arima")
+	if err != nil || !found {
+		t.Fatalf("expected state, got found=%v err=%v", found, err)
+	}
+	if string(state) != `{"version":1}` {
+		t.Errorf("expected stored state, got %q", state)
+	}
+
+	ttl, err := store.client.TTL(context.Background(), "kedastral:model:test-api:arima").Result()
+	if err != nil {
+		t.Fatalf("failed to read TTL: %v", err)
+	}
+	if ttl <= 0 || ttl > modelStateTTL {
+		t.Errorf("expected TTL in (0, %v], got %v", modelStateTTL, ttl)
+	}
+
+	if err := store.PutModelState("test-api", "arima:1", nil); err == nil {
+		t.Error("expected error for invalid model name")
+	}
+}
+
 func TestRedisStore_TTL_Expiration(t *testing.T) {
 	_, addr := setupRedisContainer(t)
 
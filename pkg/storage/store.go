@@ -10,9 +10,31 @@ type Snapshot struct {
 	HorizonSeconds  int
 	Values          []float64
 	DesiredReplicas []int
+
+	// Quantiles holds per-step forecast quantiles keyed like "p10" or "p90".
+	// Empty when the model did not produce quantiles.
+	Quantiles map[string][]float64
+
+	// Model is the name of the model that produced the forecast.
+	Model string
+
+	// Components holds the per-step components explaining Values, keyed
+	// like "level" or "seasonal" (see models.Forecast.Components). Empty
+	// when the model does not decompose its forecasts.
+	Components map[string][]float64
 }
 
 type Store interface {
 	Put(Snapshot) error
 	GetLatest(workload string) (Snapshot, bool, error)
 }
+
+// StateStore persists opaque trained model state per workload and model, so
+// that a restarted or newly elected forecaster can warm-start its model.
+type StateStore interface {
+	// PutModelState stores state for a workload's model, replacing any previous state.
+	PutModelState(workload, model string, state []byte) error
+
+	// GetModelState returns the stored state, or found=false if there is none.
+	GetModelState(workload, model string) (state []byte, found bool, err error)
+}